@@ -66,10 +66,24 @@ func (d *TwoPhaseSetCommandListener) DoList(client *crdb.Client) {
     }
 }
 
+func (d *TwoPhaseSetCommandListener) DoListMetadata(client *crdb.Client) {
+    d.CheckNArg(3, "list-metadata <ReferenceId>")
+
+    ch, e := client.TwoPhaseSetClient.ListWithMetadata(crdb.ReferenceId(flag.Arg(2)))
+    d.CheckError("Failed to list set", e)
+
+    for element := range ch {
+        printSetElement(element)
+    }
+}
+
 func (d *TwoPhaseSetCommandListener) DoInsert(client *crdb.Client) {
-    d.CheckNArg(4, "insert <ReferenceId> <OBJECT_DATA>")
+    d.CheckNArg(4, "insert <ReferenceId> <OBJECT_DATA> [KEY=VALUE...]")
+
+    labels, e := parseLabels(flag.Args()[4:])
+    d.CheckError("Invalid labels", e)
 
-    e := client.TwoPhaseSetClient.Insert(crdb.ReferenceId(flag.Arg(2)), []byte(flag.Arg(3)))
+    e = client.TwoPhaseSetClient.InsertWithLabels(crdb.ReferenceId(flag.Arg(2)), []byte(flag.Arg(3)), labels)
     d.CheckError("Failed to insert item", e)
 }
 
@@ -99,7 +113,7 @@ func (d *TwoPhaseSetCommandListener) DoContains(client *crdb.Client) {
 }
 
 func (d *TwoPhaseSetCommandListener) Execute(client *crdb.Client) {
-    usage := "<list|insert|length|contains|equals|merge|clone>"
+    usage := "<list|list-metadata|insert|length|contains|equals|merge|clone>"
 
     if flag.NArg() < 2 {
         d.ShowUsage(usage)
@@ -108,6 +122,7 @@ func (d *TwoPhaseSetCommandListener) Execute(client *crdb.Client) {
 
     switch flag.Arg(1) {
     case "list": d.DoList(client)
+    case "list-metadata": d.DoListMetadata(client)
     case "insert": d.DoInsert(client)
     case "remove": d.DoRemove(client)
     case "length": d.DoLength(client)
@@ -0,0 +1,133 @@
+/*
+ * Copyright (c) 2015 Tom Swindell (t.swindell@rubyx.co.uk)
+ *
+ * Permission is hereby granted, free of charge, to any person obtaining a copy
+ * of this software and associated documentation files (the "Software"), to deal
+ * in the Software without restriction, including without limitation the rights
+ * to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+ * copies of the Software, and to permit persons to whom the Software is
+ * furnished to do so, subject to the following conditions:
+ *
+ * The above copyright notice and this permission notice shall be included in
+ * all copies or substantial portions of the Software.
+ *
+ * THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+ * IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+ * FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+ * AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+ * LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+ * OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+ * SOFTWARE.
+ *
+ */
+
+package main
+
+import (
+    "flag"
+    "fmt"
+    "os"
+    "strconv"
+
+    "github.com/tswindell/go-crdt/db"
+)
+
+type CounterCommandListener struct {}
+
+func (d *CounterCommandListener) RespondTo(cmd string) bool {
+    return cmd == "crdt:gcounter" || cmd == "gcounter" ||
+           cmd == "crdt:pncounter" || cmd == "pncounter" ||
+           cmd == "crdt:bcounter" || cmd == "bcounter"
+}
+
+func (d *CounterCommandListener) ShowUsage(usage string) {
+    fmt.Fprintf(os.Stderr, "Usage: crdb-tool %s %s\n", flag.Arg(0), usage)
+}
+
+func (d *CounterCommandListener) CheckNArg(count int, usage string) {
+    if flag.NArg() < count {
+        d.ShowUsage(usage)
+        os.Exit(1)
+    }
+}
+
+func (d *CounterCommandListener) CheckError(m string, e error) {
+    if e != nil {
+        fmt.Fprintf(os.Stderr, "Error: %s: %v\n", m, e)
+        os.Exit(1)
+    }
+}
+
+// Returns the optional delta argument at index, defaulting to 1.
+func (d *CounterCommandListener) Delta(index int, usage string) uint64 {
+    if flag.NArg() <= index { return 1 }
+
+    delta, e := strconv.ParseUint(flag.Arg(index), 10, 64)
+    if e != nil {
+        d.ShowUsage(usage)
+        os.Exit(1)
+    }
+    return delta
+}
+
+func (d *CounterCommandListener) DoIncrement(client *crdb.Client) {
+    usage := "increment <ReferenceId> [DELTA]"
+    d.CheckNArg(3, usage)
+
+    e := client.CounterClient.Increment(crdb.ReferenceId(flag.Arg(2)), d.Delta(3, usage))
+    d.CheckError("Failed to increment counter", e)
+}
+
+func (d *CounterCommandListener) DoDecrement(client *crdb.Client) {
+    usage := "decrement <ReferenceId> [DELTA]"
+    d.CheckNArg(3, usage)
+
+    e := client.CounterClient.Decrement(crdb.ReferenceId(flag.Arg(2)), d.Delta(3, usage))
+    d.CheckError("Failed to decrement counter", e)
+}
+
+func (d *CounterCommandListener) DoValue(client *crdb.Client) {
+    d.CheckNArg(3, "value <ReferenceId>")
+
+    value, e := client.CounterClient.Value(crdb.ReferenceId(flag.Arg(2)))
+    d.CheckError("Failed to get value of counter", e)
+
+    fmt.Println(value)
+}
+
+func (d *CounterCommandListener) DoTransfer(client *crdb.Client) {
+    usage := "transfer <ReferenceId> <ReplicaId> [DELTA]"
+    d.CheckNArg(4, usage)
+
+    e := client.CounterClient.Transfer(crdb.ReferenceId(flag.Arg(2)), flag.Arg(3), d.Delta(4, usage))
+    d.CheckError("Failed to transfer rights", e)
+}
+
+func (d *CounterCommandListener) DoRights(client *crdb.Client) {
+    d.CheckNArg(3, "rights <ReferenceId> [ReplicaId]")
+
+    replicaId, rights, e := client.CounterClient.Rights(crdb.ReferenceId(flag.Arg(2)), flag.Arg(3))
+    d.CheckError("Failed to get rights of replica", e)
+
+    fmt.Printf("ReplicaId:%s\nRights:%d\n", replicaId, rights)
+}
+
+func (d *CounterCommandListener) Execute(client *crdb.Client) {
+    usage := "<increment|decrement|value|transfer|rights>"
+
+    if flag.NArg() < 2 {
+        d.ShowUsage(usage)
+        os.Exit(1)
+    }
+
+    switch flag.Arg(1) {
+    case "increment": d.DoIncrement(client)
+    case "decrement": d.DoDecrement(client)
+    case "value": d.DoValue(client)
+    case "transfer": d.DoTransfer(client)
+    case "rights": d.DoRights(client)
+    default:
+        d.ShowUsage(usage)
+        os.Exit(1)
+    }
+}
@@ -0,0 +1,98 @@
+/*
+ * Copyright (c) 2015 Tom Swindell (t.swindell@rubyx.co.uk)
+ *
+ * Permission is hereby granted, free of charge, to any person obtaining a copy
+ * of this software and associated documentation files (the "Software"), to deal
+ * in the Software without restriction, including without limitation the rights
+ * to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+ * copies of the Software, and to permit persons to whom the Software is
+ * furnished to do so, subject to the following conditions:
+ *
+ * The above copyright notice and this permission notice shall be included in
+ * all copies or substantial portions of the Software.
+ *
+ * THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+ * IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+ * FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+ * AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+ * LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+ * OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+ * SOFTWARE.
+ *
+ */
+
+package main
+
+import (
+    "flag"
+    "fmt"
+    "os"
+
+    "github.com/tswindell/go-crdt/db"
+)
+
+type FlagCommandListener struct {}
+
+func (d *FlagCommandListener) RespondTo(cmd string) bool {
+    return cmd == "crdt:ewflag" || cmd == "ewflag" ||
+           cmd == "crdt:dwflag" || cmd == "dwflag"
+}
+
+func (d *FlagCommandListener) ShowUsage(usage string) {
+    fmt.Fprintf(os.Stderr, "Usage: crdb-tool %s %s\n", flag.Arg(0), usage)
+}
+
+func (d *FlagCommandListener) CheckNArg(count int, usage string) {
+    if flag.NArg() < count {
+        d.ShowUsage(usage)
+        os.Exit(1)
+    }
+}
+
+func (d *FlagCommandListener) CheckError(m string, e error) {
+    if e != nil {
+        fmt.Fprintf(os.Stderr, "Error: %s: %v\n", m, e)
+        os.Exit(1)
+    }
+}
+
+func (d *FlagCommandListener) DoEnable(client *crdb.Client) {
+    d.CheckNArg(3, "enable <ReferenceId>")
+
+    e := client.FlagClient.Enable(crdb.ReferenceId(flag.Arg(2)))
+    d.CheckError("Failed to enable flag", e)
+}
+
+func (d *FlagCommandListener) DoDisable(client *crdb.Client) {
+    d.CheckNArg(3, "disable <ReferenceId>")
+
+    e := client.FlagClient.Disable(crdb.ReferenceId(flag.Arg(2)))
+    d.CheckError("Failed to disable flag", e)
+}
+
+func (d *FlagCommandListener) DoValue(client *crdb.Client) {
+    d.CheckNArg(3, "value <ReferenceId>")
+
+    value, e := client.FlagClient.Value(crdb.ReferenceId(flag.Arg(2)))
+    d.CheckError("Failed to get value of flag", e)
+
+    fmt.Println(value)
+}
+
+func (d *FlagCommandListener) Execute(client *crdb.Client) {
+    usage := "<enable|disable|value>"
+
+    if flag.NArg() < 2 {
+        d.ShowUsage(usage)
+        os.Exit(1)
+    }
+
+    switch flag.Arg(1) {
+    case "enable": d.DoEnable(client)
+    case "disable": d.DoDisable(client)
+    case "value": d.DoValue(client)
+    default:
+        d.ShowUsage(usage)
+        os.Exit(1)
+    }
+}
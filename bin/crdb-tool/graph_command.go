@@ -0,0 +1,127 @@
+/*
+ * Copyright (c) 2015 Tom Swindell (t.swindell@rubyx.co.uk)
+ *
+ * Permission is hereby granted, free of charge, to any person obtaining a copy
+ * of this software and associated documentation files (the "Software"), to deal
+ * in the Software without restriction, including without limitation the rights
+ * to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+ * copies of the Software, and to permit persons to whom the Software is
+ * furnished to do so, subject to the following conditions:
+ *
+ * The above copyright notice and this permission notice shall be included in
+ * all copies or substantial portions of the Software.
+ *
+ * THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+ * IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+ * FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+ * AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+ * LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+ * OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+ * SOFTWARE.
+ *
+ */
+
+package main
+
+import (
+    "flag"
+    "fmt"
+    "os"
+
+    "github.com/tswindell/go-crdt/db"
+)
+
+type GraphCommandListener struct {}
+
+func (d *GraphCommandListener) RespondTo(cmd string) bool {
+    return cmd == "crdt:graph" || cmd == "graph"
+}
+
+func (d *GraphCommandListener) ShowUsage(usage string) {
+    fmt.Fprintf(os.Stderr, "Usage: crdb-tool crdt:graph %s\n", usage)
+}
+
+func (d *GraphCommandListener) CheckNArg(count int, usage string) {
+    if flag.NArg() < count {
+        d.ShowUsage(usage)
+        os.Exit(1)
+    }
+}
+
+func (d *GraphCommandListener) CheckError(m string, e error) {
+    if e != nil {
+        fmt.Fprintf(os.Stderr, "Error: %s: %v\n", m, e)
+        os.Exit(1)
+    }
+}
+
+func (d *GraphCommandListener) DoAddVertex(client *crdb.Client) {
+    d.CheckNArg(4, "add-vertex <ReferenceId> <VERTEX>")
+
+    e := client.GraphClient.AddVertex(crdb.ReferenceId(flag.Arg(2)), []byte(flag.Arg(3)))
+    d.CheckError("Failed to add vertex", e)
+}
+
+func (d *GraphCommandListener) DoRemoveVertex(client *crdb.Client) {
+    d.CheckNArg(4, "remove-vertex <ReferenceId> <VERTEX>")
+
+    e := client.GraphClient.RemoveVertex(crdb.ReferenceId(flag.Arg(2)), []byte(flag.Arg(3)))
+    d.CheckError("Failed to remove vertex", e)
+}
+
+func (d *GraphCommandListener) DoAddEdge(client *crdb.Client) {
+    d.CheckNArg(5, "add-edge <ReferenceId> <FROM> <TO>")
+
+    e := client.GraphClient.AddEdge(crdb.ReferenceId(flag.Arg(2)), []byte(flag.Arg(3)), []byte(flag.Arg(4)))
+    d.CheckError("Failed to add edge", e)
+}
+
+func (d *GraphCommandListener) DoRemoveEdge(client *crdb.Client) {
+    d.CheckNArg(5, "remove-edge <ReferenceId> <FROM> <TO>")
+
+    e := client.GraphClient.RemoveEdge(crdb.ReferenceId(flag.Arg(2)), []byte(flag.Arg(3)), []byte(flag.Arg(4)))
+    d.CheckError("Failed to remove edge", e)
+}
+
+func (d *GraphCommandListener) DoNeighbours(client *crdb.Client) {
+    d.CheckNArg(4, "neighbours <ReferenceId> <VERTEX>")
+
+    vertices, e := client.GraphClient.Neighbours(crdb.ReferenceId(flag.Arg(2)), []byte(flag.Arg(3)))
+    d.CheckError("Failed to get neighbours of vertex", e)
+
+    for _, vertex := range vertices {
+        fmt.Println(string(vertex))
+    }
+}
+
+func (d *GraphCommandListener) DoVertices(client *crdb.Client) {
+    d.CheckNArg(3, "vertices <ReferenceId>")
+
+    vertices, e := client.GraphClient.Vertices(crdb.ReferenceId(flag.Arg(2)))
+    d.CheckError("Failed to list vertices", e)
+
+    for _, vertex := range vertices {
+        fmt.Println(string(vertex))
+    }
+}
+
+func (d *GraphCommandListener) Execute(client *crdb.Client) {
+    usage := "<add-vertex|remove-vertex|add-edge|remove-edge|neighbours|vertices>"
+
+    if flag.NArg() < 2 {
+        d.ShowUsage(usage)
+        os.Exit(1)
+    }
+
+    switch flag.Arg(1) {
+    case "add-vertex": d.DoAddVertex(client)
+    case "remove-vertex": d.DoRemoveVertex(client)
+    case "add-edge": d.DoAddEdge(client)
+    case "remove-edge": d.DoRemoveEdge(client)
+    case "neighbours": d.DoNeighbours(client)
+    case "vertices": d.DoVertices(client)
+    default:
+        d.ShowUsage(usage)
+        os.Exit(1)
+    }
+}
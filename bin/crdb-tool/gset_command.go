@@ -34,7 +34,7 @@ import (
 type CRDBGSetCommandListener struct {}
 
 func (d *CRDBGSetCommandListener) RespondTo(cmd string) bool {
-    return cmd == "crdt:gset" || cmd == "gset"
+    return cmd == "crdt:gset" || cmd == "gset" || cmd == "crdt:disk-gset"
 }
 
 func (d *CRDBGSetCommandListener) ShowUsage(usage string) {
@@ -66,10 +66,24 @@ func (d *CRDBGSetCommandListener) DoList(client *crdb.Client) {
     }
 }
 
+func (d *CRDBGSetCommandListener) DoListMetadata(client *crdb.Client) {
+    d.CheckNArg(3, "list-metadata <ReferenceId>")
+
+    ch, e := client.GSetClient.ListWithMetadata(crdb.ReferenceId(flag.Arg(2)))
+    d.CheckError("Failed to list set", e)
+
+    for element := range ch {
+        printSetElement(element)
+    }
+}
+
 func (d *CRDBGSetCommandListener) DoInsert(client *crdb.Client) {
-    d.CheckNArg(4, "insert <ReferenceId> <OBJECT_DATA>")
+    d.CheckNArg(4, "insert <ReferenceId> <OBJECT_DATA> [KEY=VALUE...]")
+
+    labels, e := parseLabels(flag.Args()[4:])
+    d.CheckError("Invalid labels", e)
 
-    e := client.GSetClient.Insert(crdb.ReferenceId(flag.Arg(2)), []byte(flag.Arg(3)))
+    e = client.GSetClient.InsertWithLabels(crdb.ReferenceId(flag.Arg(2)), []byte(flag.Arg(3)), labels)
     d.CheckError("Failed to insert item", e)
 }
 
@@ -92,7 +106,7 @@ func (d *CRDBGSetCommandListener) DoContains(client *crdb.Client) {
 }
 
 func (d *CRDBGSetCommandListener) Execute(client *crdb.Client) {
-    usage := "<list|insert|length|contains|equals|merge|clone>"
+    usage := "<list|list-metadata|insert|length|contains|equals|merge|clone>"
 
     if flag.NArg() < 2 {
         d.ShowUsage(usage)
@@ -101,6 +115,7 @@ func (d *CRDBGSetCommandListener) Execute(client *crdb.Client) {
 
     switch flag.Arg(1) {
     case "list": d.DoList(client)
+    case "list-metadata": d.DoListMetadata(client)
     case "insert": d.DoInsert(client)
     case "length": d.DoLength(client)
     case "contains": d.DoContains(client)
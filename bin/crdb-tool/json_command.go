@@ -0,0 +1,112 @@
+/*
+ * Copyright (c) 2015 Tom Swindell (t.swindell@rubyx.co.uk)
+ *
+ * Permission is hereby granted, free of charge, to any person obtaining a copy
+ * of this software and associated documentation files (the "Software"), to deal
+ * in the Software without restriction, including without limitation the rights
+ * to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+ * copies of the Software, and to permit persons to whom the Software is
+ * furnished to do so, subject to the following conditions:
+ *
+ * The above copyright notice and this permission notice shall be included in
+ * all copies or substantial portions of the Software.
+ *
+ * THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+ * IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+ * FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+ * AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+ * LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+ * OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+ * SOFTWARE.
+ *
+ */
+
+package main
+
+import (
+    "bytes"
+    "encoding/json"
+    "flag"
+    "fmt"
+    "os"
+
+    "github.com/tswindell/go-crdt/db"
+)
+
+type DocumentCommandListener struct {}
+
+func (d *DocumentCommandListener) RespondTo(cmd string) bool {
+    return cmd == "crdt:json" || cmd == "json"
+}
+
+func (d *DocumentCommandListener) ShowUsage(usage string) {
+    fmt.Fprintf(os.Stderr, "Usage: crdb-tool crdt:json %s\n", usage)
+}
+
+func (d *DocumentCommandListener) CheckNArg(count int, usage string) {
+    if flag.NArg() < count {
+        d.ShowUsage(usage)
+        os.Exit(1)
+    }
+}
+
+func (d *DocumentCommandListener) CheckError(m string, e error) {
+    if e != nil {
+        fmt.Fprintf(os.Stderr, "Error: %s: %v\n", m, e)
+        os.Exit(1)
+    }
+}
+
+// Renders the converged document, or the value at PATH, as indented JSON.
+func (d *DocumentCommandListener) DoGet(client *crdb.Client) {
+    d.CheckNArg(3, "get <ReferenceId> [PATH]")
+
+    value, e := client.DocumentClient.Get(crdb.ReferenceId(flag.Arg(2)), flag.Arg(3))
+    d.CheckError("Failed to get value", e)
+
+    buff := new(bytes.Buffer)
+    e = json.Indent(buff, value, "", "  ")
+    d.CheckError("Failed to render value", e)
+
+    fmt.Println(buff.String())
+}
+
+func (d *DocumentCommandListener) DoSet(client *crdb.Client) {
+    d.CheckNArg(5, "set <ReferenceId> <PATH> <JSON>")
+
+    e := client.DocumentClient.Set(crdb.ReferenceId(flag.Arg(2)), flag.Arg(3), []byte(flag.Arg(4)))
+    d.CheckError("Failed to set value", e)
+}
+
+func (d *DocumentCommandListener) DoInsert(client *crdb.Client) {
+    d.CheckNArg(5, "insert <ReferenceId> <PATH> <JSON>")
+
+    e := client.DocumentClient.Insert(crdb.ReferenceId(flag.Arg(2)), flag.Arg(3), []byte(flag.Arg(4)))
+    d.CheckError("Failed to insert value", e)
+}
+
+func (d *DocumentCommandListener) DoDelete(client *crdb.Client) {
+    d.CheckNArg(4, "delete <ReferenceId> <PATH>")
+
+    e := client.DocumentClient.Delete(crdb.ReferenceId(flag.Arg(2)), flag.Arg(3))
+    d.CheckError("Failed to delete value", e)
+}
+
+func (d *DocumentCommandListener) Execute(client *crdb.Client) {
+    usage := "<get|set|insert|delete>"
+
+    if flag.NArg() < 2 {
+        d.ShowUsage(usage)
+        os.Exit(1)
+    }
+
+    switch flag.Arg(1) {
+    case "get": d.DoGet(client)
+    case "set": d.DoSet(client)
+    case "insert": d.DoInsert(client)
+    case "delete": d.DoDelete(client)
+    default:
+        d.ShowUsage(usage)
+        os.Exit(1)
+    }
+}
@@ -43,7 +43,7 @@ type Command interface {
 type CRDBCommandListener struct{}
 
 func (d *CRDBCommandListener) RespondTo(cmd string) bool {
-    return cmd == "create" || cmd == "attach" || cmd == "detach" || cmd == "commit" || cmd == "list"
+    return cmd == "create" || cmd == "attach" || cmd == "detach" || cmd == "commit" || cmd == "gc" || cmd == "list"
 }
 
 func (d *CRDBCommandListener) Execute(client *crdb.Client) {
@@ -52,6 +52,7 @@ func (d *CRDBCommandListener) Execute(client *crdb.Client) {
     case "attach": d.DoAttach(client)
     case "detach": d.DoDetach(client)
     case "commit": d.DoCommit(client)
+    case    "gc": d.DoGC(client)
     case  "list": d.DoListTypes(client)
     }
 }
@@ -108,6 +109,21 @@ func (d *CRDBCommandListener) DoCommit(client *crdb.Client) {
     }
 }
 
+func (d *CRDBCommandListener) DoGC(client *crdb.Client) {
+    if flag.NArg() < 2 {
+        fmt.Fprintf(os.Stderr, "Usage: crdb-tool gc <ReferenceId>\n")
+        os.Exit(1)
+    }
+
+    tombstones, reclaimed, e := client.Compact(crdb.ReferenceId(flag.Arg(1)))
+    if e != nil {
+        fmt.Fprintf(os.Stderr, "Error: Failed to execute gc: %v\n", e)
+        os.Exit(1)
+    }
+
+    fmt.Printf("Tombstones:%d\nReclaimed:%d bytes\n", tombstones, reclaimed)
+}
+
 func (d *CRDBCommandListener) DoListTypes(client *crdb.Client) {
     if flag.NArg() < 2 {
         fmt.Fprintf(os.Stderr, "Usage: crdb-tool list <datatype|storage|crypto>\n")
@@ -161,6 +177,7 @@ func main() {
         attach - Attach to resource and get reference.
         detach - Detach from resource and GC data.
         commit - Write modifications to persistent storage.
+            gc - Discard tombstones every known replica holds.
           list - List datatypes, storage types and crypto types.
 
 `
@@ -170,6 +187,11 @@ func main() {
     commands = append(commands, &CRDBCommandListener{})
     commands = append(commands, &CRDBGSetCommandListener{})
     commands = append(commands, &TwoPhaseSetCommandListener{})
+    commands = append(commands, &ORSetCommandListener{})
+    commands = append(commands, &CounterCommandListener{})
+    commands = append(commands, &FlagCommandListener{})
+    commands = append(commands, &GraphCommandListener{})
+    commands = append(commands, &DocumentCommandListener{})
 
     client := crdb.NewClient()
     if e := client.ConnectToHost(*hostport); e != nil {
@@ -0,0 +1,68 @@
+/*
+ * Copyright (c) 2015 Tom Swindell (t.swindell@rubyx.co.uk)
+ *
+ * Permission is hereby granted, free of charge, to any person obtaining a copy
+ * of this software and associated documentation files (the "Software"), to deal
+ * in the Software without restriction, including without limitation the rights
+ * to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+ * copies of the Software, and to permit persons to whom the Software is
+ * furnished to do so, subject to the following conditions:
+ *
+ * The above copyright notice and this permission notice shall be included in
+ * all copies or substantial portions of the Software.
+ *
+ * THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+ * IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+ * FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+ * AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+ * LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+ * OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+ * SOFTWARE.
+ *
+ */
+package main
+
+import (
+    "fmt"
+    "sort"
+    "strings"
+    "time"
+
+    "github.com/tswindell/go-crdt/db"
+)
+
+// Parses KEY=VALUE arguments into the labels of an insert, nil when there are
+// none.
+func parseLabels(args []string) (map[string]string, error) {
+    if len(args) == 0 { return nil, nil }
+
+    labels := make(map[string]string)
+    for _, arg := range args {
+        k, v, found := strings.Cut(arg, "=")
+        if !found { return nil, fmt.Errorf("label %q is not KEY=VALUE", arg) }
+        labels[k] = v
+    }
+    return labels, nil
+}
+
+// Prints an element with the time and the replica of its first insert, and
+// its labels in key order. Elements without metadata are printed alone.
+func printSetElement(element crdb.SetElement) {
+    md := element.Metadata
+    if md.IsZero() {
+        fmt.Println(string(element.Object))
+        return
+    }
+
+    keys := make([]string, 0, len(md.Labels))
+    for k := range md.Labels { keys = append(keys, k) }
+    sort.Strings(keys)
+
+    labels := make([]string, len(keys))
+    for i, k := range keys { labels[i] = k + "=" + md.Labels[k] }
+
+    fmt.Printf("%s\t%s\t%s\t%s\n", element.Object,
+               time.Unix(0, md.Timestamp).UTC().Format(time.RFC3339Nano),
+               md.ReplicaId,
+               strings.Join(labels, ","))
+}
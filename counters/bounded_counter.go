@@ -0,0 +1,249 @@
+/*
+ * Copyright (c) 2015 Tom Swindell (t.swindell@rubyx.co.uk)
+ *
+ * Permission is hereby granted, free of charge, to any person obtaining a copy
+ * of this software and associated documentation files (the "Software"), to deal
+ * in the Software without restriction, including without limitation the rights
+ * to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+ * copies of the Software, and to permit persons to whom the Software is
+ * furnished to do so, subject to the following conditions:
+ *
+ * The above copyright notice and this permission notice shall be included in
+ * all copies or substantial portions of the Software.
+ *
+ * THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+ * IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+ * FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+ * AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+ * LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+ * OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+ * SOFTWARE.
+ *
+ */
+
+package counter
+
+import (
+    "bytes"
+    "encoding/binary"
+    "fmt"
+    "io"
+    "math"
+    "sync"
+)
+
+var (
+    E_INSUFFICIENT_RIGHTS = fmt.Errorf("crdt:error-insufficient-rights")
+)
+
+// Common Go representation of a bounded counter, whose value never drops below
+// zero. It is a PN-Counter in which each replica may only decrement by as much
+// as its rights allow. A replica gains rights by incrementing, or when another
+// replica transfers some of its own rights to it, so concurrent decrements on
+// different replicas can never take the counter below zero.
+type BCounter struct {
+    sync.RWMutex
+    counts    *PNCounter
+    transfers map[string]map[string]uint64 // Rights transferred, by source and target.
+}
+
+func NewBCounter() *BCounter {
+    return &BCounter{counts: NewPNCounter(), transfers: make(map[string]map[string]uint64)}
+}
+
+// Returns the rights held by replicaId. The caller must hold the lock.
+func (c *BCounter) rights(replicaId string) int64 {
+    rights := int64(c.counts.inc.get(replicaId)) - int64(c.counts.dec.get(replicaId))
+
+    for source, targets := range c.transfers {
+        if source == replicaId {
+            for _, v := range targets { rights -= int64(v) }
+        } else {
+            rights += int64(targets[replicaId])
+        }
+    }
+    return rights
+}
+
+// Returns true when replicaId holds at least delta rights. No replica can hold
+// more than math.MaxInt64 rights, so larger deltas are always refused. The
+// caller must hold the lock.
+func (c *BCounter) holds(replicaId string, delta uint64) bool {
+    return delta <= math.MaxInt64 && c.rights(replicaId) >= int64(delta)
+}
+
+func (c *BCounter) Increment(replicaId string, delta uint64) {
+    c.Lock()
+    defer c.Unlock()
+
+    c.counts.Increment(replicaId, delta)
+}
+
+// The Decrement() method returns E_INSUFFICIENT_RIGHTS, and leaves the counter
+// unchanged, when replicaId holds fewer than delta rights.
+func (c *BCounter) Decrement(replicaId string, delta uint64) error {
+    c.Lock()
+    defer c.Unlock()
+
+    if !c.holds(replicaId, delta) { return E_INSUFFICIENT_RIGHTS }
+
+    c.counts.Decrement(replicaId, delta)
+    return nil
+}
+
+// The Transfer() method moves delta of the rights held by source to target.
+func (c *BCounter) Transfer(source, target string, delta uint64) error {
+    c.Lock()
+    defer c.Unlock()
+
+    if !c.holds(source, delta) { return E_INSUFFICIENT_RIGHTS }
+    if source == target { return nil }
+
+    targets, found := c.transfers[source]
+    if !found {
+        targets = make(map[string]uint64)
+        c.transfers[source] = targets
+    }
+    targets[target] += delta
+    return nil
+}
+
+func (c *BCounter) Rights(replicaId string) uint64 {
+    c.RLock()
+    defer c.RUnlock()
+
+    return uint64(c.rights(replicaId))
+}
+
+func (c *BCounter) Value() int64 {
+    c.RLock()
+    defer c.RUnlock()
+
+    return c.counts.Value()
+}
+
+func (c *BCounter) Equals(other *BCounter) bool {
+    in := other.Clone()
+
+    c.RLock()
+    defer c.RUnlock()
+
+    if !c.counts.Equals(in.counts) || len(c.transfers) != len(in.transfers) { return false }
+
+    for source, targets := range c.transfers {
+        others, found := in.transfers[source]
+        if !found || len(targets) != len(others) { return false }
+
+        for target, v := range targets {
+            if w, found := others[target]; !found || v != w { return false }
+        }
+    }
+    return true
+}
+
+// The caller must hold the lock.
+func (c *BCounter) mergeTransfer(source, target string, v uint64) {
+    targets, found := c.transfers[source]
+    if !found {
+        targets = make(map[string]uint64)
+        c.transfers[source] = targets
+    }
+    if v > targets[target] { targets[target] = v }
+}
+
+func (c *BCounter) Merge(other *BCounter) {
+    in := other.Clone()
+
+    c.Lock()
+    defer c.Unlock()
+
+    c.counts.Merge(in.counts)
+    for source, targets := range in.transfers {
+        for target, v := range targets { c.mergeTransfer(source, target, v) }
+    }
+}
+
+func (c *BCounter) Clone() *BCounter {
+    c.RLock()
+    defer c.RUnlock()
+
+    result := NewBCounter()
+    result.counts = c.counts.Clone()
+    for source, targets := range c.transfers {
+        for target, v := range targets { result.mergeTransfer(source, target, v) }
+    }
+    return result
+}
+
+var BCOUNTER_HEADER_MAGIC = []byte{'c', 'r', 'd', 't', ':', 'b', 'c', 'o', 'u', 'n', 't', 'e', 'r', 0x00}
+
+func writeReplicaId(buff *bytes.Buffer, replicaId string) error {
+    if len(replicaId) > 0xffff { return fmt.Errorf("replica id too long") }
+
+    binary.Write(buff, binary.LittleEndian, uint16(len(replicaId)))
+    buff.WriteString(replicaId)
+    return nil
+}
+
+func readReplicaId(buff *bytes.Buffer) (string, error) {
+    var keyl uint16
+    if e := binary.Read(buff, binary.LittleEndian, &keyl); e != nil { return "", e }
+
+    key := make([]byte, keyl)
+    if _, e := io.ReadFull(buff, key); e != nil { return "", fmt.Errorf("invalid format") }
+    return string(key), nil
+}
+
+func (c *BCounter) Serialize(buff *bytes.Buffer) error {
+    c.RLock()
+    defer c.RUnlock()
+
+    buff.Write(BCOUNTER_HEADER_MAGIC)
+    if e := c.counts.Serialize(buff); e != nil { return e }
+
+    count := 0
+    for _, targets := range c.transfers { count += len(targets) }
+    binary.Write(buff, binary.LittleEndian, uint32(count))
+
+    for source, targets := range c.transfers {
+        for target, v := range targets {
+            if e := writeReplicaId(buff, source); e != nil { return e }
+            if e := writeReplicaId(buff, target); e != nil { return e }
+            binary.Write(buff, binary.LittleEndian, v)
+        }
+    }
+
+    return nil
+}
+
+// Deserialize merges the serialized state into this counter.
+func (c *BCounter) Deserialize(buff *bytes.Buffer) error {
+    if buff.Len() < len(BCOUNTER_HEADER_MAGIC) { return fmt.Errorf("data too small") }
+
+    header := make([]byte, len(BCOUNTER_HEADER_MAGIC))
+    _, e := buff.Read(header)
+    if e != nil || !bytes.Equal(header, BCOUNTER_HEADER_MAGIC) { return fmt.Errorf("invalid header") }
+
+    in := NewBCounter()
+    if e := in.counts.Deserialize(buff); e != nil { return e }
+
+    var sizeof uint32
+    if e := binary.Read(buff, binary.LittleEndian, &sizeof); e != nil { return e }
+
+    for i := uint32(0); i < sizeof; i++ {
+        var v uint64
+
+        source, e := readReplicaId(buff)
+        if e != nil { return e }
+
+        target, e := readReplicaId(buff)
+        if e != nil { return e }
+
+        if e := binary.Read(buff, binary.LittleEndian, &v); e != nil { return e }
+
+        in.mergeTransfer(source, target, v)
+    }
+
+    c.Merge(in)
+    return nil
+}
@@ -0,0 +1,153 @@
+/*
+ * Copyright (c) 2015 Tom Swindell (t.swindell@rubyx.co.uk)
+ *
+ * Permission is hereby granted, free of charge, to any person obtaining a copy
+ * of this software and associated documentation files (the "Software"), to deal
+ * in the Software without restriction, including without limitation the rights
+ * to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+ * copies of the Software, and to permit persons to whom the Software is
+ * furnished to do so, subject to the following conditions:
+ *
+ * The above copyright notice and this permission notice shall be included in
+ * all copies or substantial portions of the Software.
+ *
+ * THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+ * IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+ * FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+ * AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+ * LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+ * OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+ * SOFTWARE.
+ *
+ */
+
+package counter
+
+import "testing"
+import "bytes"
+
+func TestNewBCounter(t *testing.T) {
+    a := NewBCounter()
+    if a.Value() != 0 || a.Rights("a") != 0 {
+        t.Error("New counter should have 0 value and no rights!")
+    }
+}
+
+func TestBCounterDecrement(t *testing.T) {
+    a := NewBCounter()
+    a.Increment("a", 5)
+
+    if e := a.Decrement("b", 1); e != E_INSUFFICIENT_RIGHTS {
+        t.Error("Expected insufficient rights error!")
+    }
+
+    if e := a.Decrement("a", 6); e != E_INSUFFICIENT_RIGHTS {
+        t.Error("Expected insufficient rights error!")
+    }
+
+    if e := a.Decrement("a", 2); e != nil {
+        t.Fatal(e)
+    }
+
+    if a.Value() != 3 || a.Rights("a") != 3 {
+        t.Errorf("Expected value and rights of 3, got %d and %d", a.Value(), a.Rights("a"))
+    }
+}
+
+func TestBCounterTransfer(t *testing.T) {
+    a := NewBCounter()
+    a.Increment("a", 5)
+
+    if e := a.Transfer("a", "b", 6); e != E_INSUFFICIENT_RIGHTS {
+        t.Error("Expected insufficient rights error!")
+    }
+
+    if e := a.Transfer("a", "b", 2); e != nil {
+        t.Fatal(e)
+    }
+
+    if a.Rights("a") != 3 || a.Rights("b") != 2 {
+        t.Errorf("Unexpected rights after transfer, %d and %d", a.Rights("a"), a.Rights("b"))
+    }
+
+    if e := a.Decrement("b", 2); e != nil {
+        t.Fatal(e)
+    }
+
+    if a.Value() != 3 {
+        t.Errorf("Expected value of 3, got %d", a.Value())
+    }
+}
+
+// Deltas too large for the rights of any replica must be refused, rather than
+// wrapping around to a negative number of rights.
+func TestBCounterHugeDelta(t *testing.T) {
+    a := NewBCounter()
+    a.Increment("a", 5)
+
+    if e := a.Decrement("b", 1 << 63); e != E_INSUFFICIENT_RIGHTS {
+        t.Error("Expected insufficient rights error!")
+    }
+
+    if e := a.Decrement("a", 1 << 63 + 2); e != E_INSUFFICIENT_RIGHTS {
+        t.Error("Expected insufficient rights error!")
+    }
+
+    if e := a.Transfer("a", "b", 1 << 63 + 10); e != E_INSUFFICIENT_RIGHTS {
+        t.Error("Expected insufficient rights error!")
+    }
+
+    if e := a.Transfer("b", "a", ^uint64(0)); e != E_INSUFFICIENT_RIGHTS {
+        t.Error("Expected insufficient rights error!")
+    }
+
+    if a.Value() != 5 || a.Rights("a") != 5 || a.Rights("b") != 0 {
+        t.Errorf("Counter changed by refused deltas, value %d and rights %d and %d",
+                 a.Value(), a.Rights("a"), a.Rights("b"))
+    }
+}
+
+func TestBCounterConcurrentDecrements(t *testing.T) {
+    a := NewBCounter()
+    a.Increment("a", 4)
+    a.Transfer("a", "b", 2)
+
+    b := a.Clone()
+
+    // Each replica can only spend its own rights, so the value stays positive.
+    if e := a.Decrement("a", 2); e != nil {
+        t.Fatal(e)
+    }
+    if e := b.Decrement("b", 3); e != E_INSUFFICIENT_RIGHTS {
+        t.Error("Expected insufficient rights error!")
+    }
+    b.Decrement("b", 2)
+
+    a.Merge(b)
+    b.Merge(a)
+
+    if !a.Equals(b) || a.Value() != 0 {
+        t.Errorf("Expected equal counters with value 0, got %d", a.Value())
+    }
+}
+
+func TestBCounterSerialization(t *testing.T) {
+    a := NewBCounter()
+    a.Increment("a", 10)
+    a.Transfer("a", "b", 4)
+    a.Decrement("b", 1)
+
+    buff := new(bytes.Buffer)
+    if e := a.Serialize(buff); e != nil {
+        t.Fatal(e)
+    }
+
+    b := NewBCounter()
+    if e := b.Deserialize(buff); e != nil {
+        t.Fatal(e)
+    }
+
+    if !a.Equals(b) || b.Rights("b") != 3 {
+        t.Error("Deserialized counter should equal original!")
+    }
+}
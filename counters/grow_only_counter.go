@@ -0,0 +1,153 @@
+/*
+ * Copyright (c) 2015 Tom Swindell (t.swindell@rubyx.co.uk)
+ *
+ * Permission is hereby granted, free of charge, to any person obtaining a copy
+ * of this software and associated documentation files (the "Software"), to deal
+ * in the Software without restriction, including without limitation the rights
+ * to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+ * copies of the Software, and to permit persons to whom the Software is
+ * furnished to do so, subject to the following conditions:
+ *
+ * The above copyright notice and this permission notice shall be included in
+ * all copies or substantial portions of the Software.
+ *
+ * THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+ * IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+ * FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+ * AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+ * LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+ * OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+ * SOFTWARE.
+ *
+ */
+
+package counter
+
+import (
+    "bytes"
+    "encoding/binary"
+    "fmt"
+    "io"
+    "sync"
+)
+
+// Common Go representation of a grow only counter. Each replica increments its
+// own entry, and the value of the counter is the sum of all entries.
+type GCounter struct {
+    sync.RWMutex
+    counts map[string]uint64
+}
+
+func NewGCounter() *GCounter {
+    return &GCounter{counts: make(map[string]uint64)}
+}
+
+func (c *GCounter) Increment(replicaId string, delta uint64) {
+    c.Lock()
+    defer c.Unlock()
+
+    c.counts[replicaId] += delta
+}
+
+// Returns the entry of replicaId.
+func (c *GCounter) get(replicaId string) uint64 {
+    c.RLock()
+    defer c.RUnlock()
+
+    return c.counts[replicaId]
+}
+
+func (c *GCounter) Value() int64 {
+    c.RLock()
+    defer c.RUnlock()
+
+    var value uint64
+    for _, v := range c.counts { value += v }
+    return int64(value)
+}
+
+func (c *GCounter) Equals(other *GCounter) bool {
+    in := other.Clone()
+
+    c.RLock()
+    defer c.RUnlock()
+
+    if len(c.counts) != len(in.counts) { return false }
+
+    for k, v := range c.counts {
+        if w, found := in.counts[k]; !found || v != w { return false }
+    }
+    return true
+}
+
+func (c *GCounter) Merge(other *GCounter) {
+    in := other.Clone()
+
+    c.Lock()
+    defer c.Unlock()
+
+    for k, v := range in.counts {
+        if v > c.counts[k] { c.counts[k] = v }
+    }
+}
+
+func (c *GCounter) Clone() *GCounter {
+    c.RLock()
+    defer c.RUnlock()
+
+    result := NewGCounter()
+    for k, v := range c.counts { result.counts[k] = v }
+    return result
+}
+
+var GCOUNTER_HEADER_MAGIC = []byte{'c', 'r', 'd', 't', ':', 'g', 'c', 'o', 'u', 'n', 't', 'e', 'r', 0x00}
+
+func (c *GCounter) Serialize(buff *bytes.Buffer) error {
+    c.RLock()
+    defer c.RUnlock()
+
+    buff.Write(GCOUNTER_HEADER_MAGIC)
+    binary.Write(buff, binary.LittleEndian, uint32(len(c.counts)))
+
+    for k, v := range c.counts {
+        if len(k) > 0xffff { return fmt.Errorf("replica id too long") }
+
+        binary.Write(buff, binary.LittleEndian, uint16(len(k)))
+        buff.WriteString(k)
+        binary.Write(buff, binary.LittleEndian, v)
+    }
+
+    return nil
+}
+
+// Deserialize merges the serialized state into this counter. Nothing is merged
+// unless all of it is read successfully.
+func (c *GCounter) Deserialize(buff *bytes.Buffer) error {
+    if buff.Len() < len(GCOUNTER_HEADER_MAGIC) { return fmt.Errorf("data too small") }
+
+    header := make([]byte, len(GCOUNTER_HEADER_MAGIC))
+    _, e := buff.Read(header)
+    if e != nil || !bytes.Equal(header, GCOUNTER_HEADER_MAGIC) { return fmt.Errorf("invalid header") }
+
+    in := NewGCounter()
+
+    var sizeof uint32
+    if e := binary.Read(buff, binary.LittleEndian, &sizeof); e != nil { return e }
+
+    for i := uint32(0); i < sizeof; i++ {
+        var keyl uint16
+        var v    uint64
+
+        if e := binary.Read(buff, binary.LittleEndian, &keyl); e != nil { return e }
+
+        key := make([]byte, keyl)
+        if _, e := io.ReadFull(buff, key); e != nil { return fmt.Errorf("invalid format") }
+
+        if e := binary.Read(buff, binary.LittleEndian, &v); e != nil { return e }
+
+        if v > in.counts[string(key)] { in.counts[string(key)] = v }
+    }
+
+    c.Merge(in)
+    return nil
+}
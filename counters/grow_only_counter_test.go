@@ -0,0 +1,144 @@
+/*
+ * Copyright (c) 2015 Tom Swindell (t.swindell@rubyx.co.uk)
+ *
+ * Permission is hereby granted, free of charge, to any person obtaining a copy
+ * of this software and associated documentation files (the "Software"), to deal
+ * in the Software without restriction, including without limitation the rights
+ * to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+ * copies of the Software, and to permit persons to whom the Software is
+ * furnished to do so, subject to the following conditions:
+ *
+ * The above copyright notice and this permission notice shall be included in
+ * all copies or substantial portions of the Software.
+ *
+ * THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+ * IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+ * FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+ * AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+ * LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+ * OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+ * SOFTWARE.
+ *
+ */
+
+package counter
+
+import "testing"
+import "bytes"
+
+func TestNewGCounter(t *testing.T) {
+    a := NewGCounter()
+    if a.Value() != 0 {
+        t.Error("New counter should have 0 value!")
+    }
+}
+
+func TestGCounterIncrement(t *testing.T) {
+    a := NewGCounter()
+
+    a.Increment("a", 1)
+    a.Increment("a", 2)
+    a.Increment("b", 3)
+
+    if a.Value() != 6 {
+        t.Errorf("Expected value of 6, got %d", a.Value())
+    }
+}
+
+func TestGCounterEquals(t *testing.T) {
+    a := NewGCounter()
+    b := NewGCounter()
+    c := NewGCounter()
+
+    a.Increment("a", 5)
+    b.Increment("a", 5)
+    c.Increment("c", 5)
+
+    if !a.Equals(b) {
+        t.Error("a equals b check failed!")
+    }
+
+    if a.Equals(c) {
+        t.Error("a equals c check failed!")
+    }
+}
+
+func TestGCounterClone(t *testing.T) {
+    a := NewGCounter()
+    a.Increment("a", 10)
+
+    b := a.Clone()
+
+    if !a.Equals(b) {
+        t.Error("Expected a equals b!")
+    }
+
+    a.Increment("a", 1)
+
+    if a.Equals(b) {
+        t.Error("Expected a not to equal b!")
+    }
+}
+
+func TestGCounterMerge(t *testing.T) {
+    a := NewGCounter()
+    b := NewGCounter()
+
+    a.Increment("a", 5)
+    b.Merge(a)
+
+    // Concurrent increments on both replicas.
+    a.Increment("a", 1)
+    b.Increment("b", 2)
+
+    a.Merge(b)
+    b.Merge(a)
+
+    if !a.Equals(b) {
+        t.Error("Equals failed after merge!")
+    }
+
+    if a.Value() != 8 {
+        t.Errorf("Expected value of 8 after merge, got %d", a.Value())
+    }
+
+    // Merging is idempotent.
+    a.Merge(b)
+
+    if a.Value() != 8 {
+        t.Errorf("Expected value of 8 after repeated merge, got %d", a.Value())
+    }
+}
+
+func TestGCounterSerialize(t *testing.T) {
+    a := NewGCounter()
+    b := NewGCounter()
+
+    a.Increment("a", 5)
+    a.Increment("b", 7)
+
+    out := &bytes.Buffer{}
+    if e := a.Serialize(out); e != nil { t.Error(e) }
+
+    in := bytes.NewBuffer(out.Bytes())
+    if e := b.Deserialize(in); e != nil { t.Error(e) }
+
+    if !a.Equals(b) { t.Error("Match failed") }
+}
+
+// Truncated input is refused without merging any of it.
+func TestGCounterDeserializeTruncated(t *testing.T) {
+    a := NewGCounter()
+    a.Increment("a", 5)
+    a.Increment("b", 7)
+
+    out := &bytes.Buffer{}
+    if e := a.Serialize(out); e != nil { t.Fatal(e) }
+    data := out.Bytes()
+
+    for n := len(GCOUNTER_HEADER_MAGIC); n < len(data); n++ {
+        b := NewGCounter()
+        if e := b.Deserialize(bytes.NewBuffer(data[:n])); e == nil { t.Fatalf("Expected error reading %d bytes!", n) }
+        if !b.Equals(NewGCounter()) { t.Fatalf("Partial merge after reading %d bytes!", n) }
+    }
+}
@@ -0,0 +1,99 @@
+/*
+ * Copyright (c) 2015 Tom Swindell (t.swindell@rubyx.co.uk)
+ *
+ * Permission is hereby granted, free of charge, to any person obtaining a copy
+ * of this software and associated documentation files (the "Software"), to deal
+ * in the Software without restriction, including without limitation the rights
+ * to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+ * copies of the Software, and to permit persons to whom the Software is
+ * furnished to do so, subject to the following conditions:
+ *
+ * The above copyright notice and this permission notice shall be included in
+ * all copies or substantial portions of the Software.
+ *
+ * THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+ * IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+ * FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+ * AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+ * LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+ * OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+ * SOFTWARE.
+ *
+ */
+
+package counter
+
+import (
+    "bytes"
+    "fmt"
+)
+
+// Common Go representation of a positive-negative counter, made of one grow
+// only counter for increments and another for decrements.
+type PNCounter struct {
+    inc *GCounter
+    dec *GCounter
+}
+
+func NewPNCounter() *PNCounter {
+    c := new(PNCounter)
+    c.inc = NewGCounter()
+    c.dec = NewGCounter()
+    return c
+}
+
+func (c *PNCounter) Increment(replicaId string, delta uint64) {
+    c.inc.Increment(replicaId, delta)
+}
+
+func (c *PNCounter) Decrement(replicaId string, delta uint64) {
+    c.dec.Increment(replicaId, delta)
+}
+
+func (c *PNCounter) Value() int64 {
+    return c.inc.Value() - c.dec.Value()
+}
+
+func (c *PNCounter) Equals(other *PNCounter) bool {
+    return c.inc.Equals(other.inc) && c.dec.Equals(other.dec)
+}
+
+func (c *PNCounter) Merge(other *PNCounter) {
+    c.inc.Merge(other.inc)
+    c.dec.Merge(other.dec)
+}
+
+func (c *PNCounter) Clone() *PNCounter {
+    result := new(PNCounter)
+    result.inc = c.inc.Clone()
+    result.dec = c.dec.Clone()
+    return result
+}
+
+var PNCOUNTER_HEADER_MAGIC = []byte{'c', 'r', 'd', 't', ':', 'p', 'n', 'c', 'o', 'u', 'n', 't', 'e', 'r', 0x00}
+
+func (c *PNCounter) Serialize(buff *bytes.Buffer) error {
+    buff.Write(PNCOUNTER_HEADER_MAGIC)
+
+    if e := c.inc.Serialize(buff); e != nil { return e }
+    if e := c.dec.Serialize(buff); e != nil { return e }
+
+    return nil
+}
+
+// Deserialize merges the serialized state into this counter. Nothing is merged
+// unless both halves are read successfully.
+func (c *PNCounter) Deserialize(buff *bytes.Buffer) error {
+    if buff.Len() < len(PNCOUNTER_HEADER_MAGIC) { return fmt.Errorf("data too small") }
+
+    header := make([]byte, len(PNCOUNTER_HEADER_MAGIC))
+    _, e := buff.Read(header)
+    if e != nil || !bytes.Equal(header, PNCOUNTER_HEADER_MAGIC) { return fmt.Errorf("invalid header") }
+
+    in := NewPNCounter()
+    if e := in.inc.Deserialize(buff); e != nil { return e }
+    if e := in.dec.Deserialize(buff); e != nil { return e }
+
+    c.Merge(in)
+    return nil
+}
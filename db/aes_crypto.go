@@ -24,12 +24,15 @@
 package crdb
 
 import (
+    "bytes"
     "crypto/aes"
     "crypto/cipher"
     "crypto/hmac"
     "crypto/rand"
     "crypto/sha256"
     "fmt"
+    "hash"
+    "io"
 )
 
 const (
@@ -88,7 +91,7 @@ func __append_padding(data []byte) []byte {
 func __remove_padding(data []byte) []byte {
     if len(data) == 0 { return nil }
     pbyte := data[len(data) - 1]
-    if int(pbyte) > len(data) || pbyte >= aes.BlockSize || pbyte == 0 { return nil }
+    if int(pbyte) > len(data) || pbyte > aes.BlockSize || pbyte == 0 { return nil }
     for i := len(data) - 1; i > len(data) - int(pbyte) - 1; i-- {
         if data[i] != pbyte { return nil }
     }
@@ -96,8 +99,44 @@ func __remove_padding(data []byte) []byte {
 }
 
 
-// The Encrypt() instance method
-func (d *AESCryptoMethod) Encrypt(resourceKey ResourceKey, data []byte) ([]byte, error) {
+// The aesEncryptWriter type encrypts whole blocks as they are written, and
+// pads the final block and appends the HMAC of the IV and ciphertext on Close.
+type aesEncryptWriter struct {
+    w       io.Writer
+    cbc     cipher.BlockMode
+    mac     hash.Hash
+    pending []byte // Partial block awaiting more data.
+}
+
+func (d *aesEncryptWriter) write(data []byte) error {
+    d.cbc.CryptBlocks(data, data)
+    d.mac.Write(data)
+    _, e := d.w.Write(data)
+    return e
+}
+
+func (d *aesEncryptWriter) Write(p []byte) (int, error) {
+    d.pending = append(d.pending, p...)
+
+    full := len(d.pending) - len(d.pending) % aes.BlockSize
+    if full == 0 { return len(p), nil }
+
+    if e := d.write(d.pending[:full]); e != nil { return 0, e }
+    d.pending = append(d.pending[:0], d.pending[full:]...)
+    return len(p), nil
+}
+
+func (d *aesEncryptWriter) Close() error {
+    if e := d.write(__append_padding(d.pending)); e != nil { return e }
+    d.pending = nil
+
+    _, e := d.w.Write(d.mac.Sum(nil))
+    return e
+}
+
+// The EncryptWriter() instance method returns a writer encrypting to w in the
+// same format as Encrypt().
+func (d *AESCryptoMethod) EncryptWriter(resourceKey ResourceKey, w io.Writer) (io.WriteCloser, error) {
     keydata := resourceKey.KeyData()
 
     // Validate key length.
@@ -108,56 +147,143 @@ func (d *AESCryptoMethod) Encrypt(resourceKey ResourceKey, data []byte) ([]byte,
     _, e := rand.Read(iv)
     if e != nil { return nil, e }
 
-    // Pad to aes.BlockSize
-    data = __append_padding(data)
-    text := make([]byte, len(data))
-
-    // Encrypt AES-%d-CBC
     ci, _ := aes.NewCipher(keydata[:d.ckeysize])
-    cbc := cipher.NewCBCEncrypter(ci, iv)
+    hm := hmac.New(sha256.New, keydata[d.ckeysize:])
 
-    cbc.CryptBlocks(text, data)
+    hm.Write(iv)
+    if _, e := w.Write(iv); e != nil { return nil, e }
 
-    // Apply HMAC
-    hm := hmac.New(sha256.New, keydata[d.ckeysize:])
-    text = append(iv, text...)
-    hm.Write(text)
+    return &aesEncryptWriter{w: w, cbc: cipher.NewCBCEncrypter(ci, iv), mac: hm}, nil
+}
 
-    return hm.Sum(text), nil
+// The aesDecryptReader type decrypts blocks as they are read, holding back
+// enough of the input to hold the HMAC and final block, which are only
+// checked once the input ends.
+type aesDecryptReader struct {
+    r       io.Reader
+    key     []byte
+    method  *AESCryptoMethod
+
+    cbc     cipher.BlockMode
+    mac     hash.Hash
+    chunk   []byte
+    held    []byte // Input not yet decrypted.
+    out     []byte // Decrypted data not yet read.
+    err     error
 }
 
-// The Decrypt instance method
-func (d *AESCryptoMethod) Decrypt(resourceKey ResourceKey, data []byte) ([]byte, error) {
+func (d *aesDecryptReader) fill() error {
+    n, e := d.r.Read(d.chunk)
+    d.held = append(d.held, d.chunk[:n]...)
+
+    if e == io.EOF { return d.finish() }
+    if e != nil { return e }
+
+    // Initialise from the IV once it has been read.
+    if d.cbc == nil {
+        if len(d.held) < d.method.ivsize { return nil }
+
+        ci, _ := aes.NewCipher(d.key[:d.method.ckeysize])
+        d.cbc = cipher.NewCBCDecrypter(ci, d.held[:d.method.ivsize])
+        d.mac.Write(d.held[:d.method.ivsize])
+        d.held = append([]byte{}, d.held[d.method.ivsize:]...)
+    }
+
+    // Keep back the HMAC and the final, padded, block.
+    ready := len(d.held) - d.method.macsize - aes.BlockSize
+    ready -= ready % aes.BlockSize
+    if ready <= 0 { return nil }
+
+    block := d.held[:ready]
+    d.mac.Write(block)
+    d.cbc.CryptBlocks(block, block)
+    d.out = append(d.out, block...)
+    d.held = append(d.held[:0], d.held[ready:]...)
+    return nil
+}
+
+// Checks the HMAC and decrypts the final blocks once all input is read.
+func (d *aesDecryptReader) finish() error {
+    if d.cbc == nil {
+        // Check against minimum message length (HMAC + IV + 1 or more Message Blocks)
+        if len(d.held) < aes.BlockSize + d.method.ivsize + d.method.macsize { return E_INVALID_RESOURCE_DATA }
+
+        ci, _ := aes.NewCipher(d.key[:d.method.ckeysize])
+        d.cbc = cipher.NewCBCDecrypter(ci, d.held[:d.method.ivsize])
+        d.mac.Write(d.held[:d.method.ivsize])
+        d.held = d.held[d.method.ivsize:]
+    }
+
+    if len(d.held) < aes.BlockSize + d.method.macsize { return E_INVALID_RESOURCE_DATA }
+    if len(d.held) % aes.BlockSize != 0 { return E_INVALID_RESOURCE_DATA }
+
+    macs := len(d.held) - d.method.macsize
+    text := d.held[:macs]
+
+    // Check HMAC
+    d.mac.Write(text)
+    if !hmac.Equal(d.mac.Sum(nil), d.held[macs:]) { return fmt.Errorf("Invalid HMAC in data.") }
+
+    d.cbc.CryptBlocks(text, text)
+
+    text = __remove_padding(text)
+    if text == nil { return E_INVALID_RESOURCE_DATA }
+
+    d.out = append(d.out, text...)
+    d.held = nil
+    return io.EOF
+}
+
+func (d *aesDecryptReader) Read(p []byte) (int, error) {
+    for len(d.out) == 0 && d.err == nil {
+        d.err = d.fill()
+    }
+
+    n := copy(p, d.out)
+    d.out = d.out[n:]
+
+    if len(d.out) == 0 && d.err != nil { return n, d.err }
+    return n, nil
+}
+
+// The DecryptReader() instance method returns a reader decrypting data in the
+// format written by Encrypt(). The data is only authenticated at its end, so
+// it must be read to EOF without error before any of it is trusted.
+func (d *AESCryptoMethod) DecryptReader(resourceKey ResourceKey, r io.Reader) (io.Reader, error) {
     keydata := resourceKey.KeyData()
 
     // Validate key length.
     if len(keydata) != d.keysize { return nil, E_INVALID_KEY }
 
-    // Validate HMAC size
-    if (len(data) % aes.BlockSize) != 0 { return nil, E_INVALID_RESOURCE_DATA }
+    return &aesDecryptReader{
+               r: r,
+               key: keydata,
+               method: d,
+               chunk: make([]byte, 32 * 1024),
+               mac: hmac.New(sha256.New, keydata[d.ckeysize:]),
+           }, nil
+}
 
-    // Check against minimum message length (HMAC + IV + 1 or more Message Blocks)
-    if len(data) < (aes.BlockSize + d.ivsize + d.macsize) { return nil, E_INVALID_RESOURCE_DATA }
+// The Encrypt() instance method
+func (d *AESCryptoMethod) Encrypt(resourceKey ResourceKey, data []byte) ([]byte, error) {
+    buff := new(bytes.Buffer)
 
-    // Extract HMAC
-    macs := len(data) - d.macsize
-    mtag := data[macs:]
-    text := data[:macs]
+    w, e := d.EncryptWriter(resourceKey, buff)
+    if e != nil { return nil, e }
 
-    // Check HMAC
-    hm := hmac.New(sha256.New, keydata[d.ckeysize:])
-    hm.Write(text)
-    mac := hm.Sum(nil)
-    if !hmac.Equal(mac, mtag) { return nil, fmt.Errorf("Invalid HMAC in data.") }
+    if _, e := w.Write(data); e != nil { return nil, e }
+    if e := w.Close(); e != nil { return nil, e }
 
-    // Decrypt AES-%d-CBC
-    ci, _ := aes.NewCipher(keydata[:d.ckeysize])
-    cbc := cipher.NewCBCDecrypter(ci, text[:d.ivsize])
+    return buff.Bytes(), nil
+}
 
-    result := make([]byte, macs - d.ivsize)
-    cbc.CryptBlocks(result, text[d.ivsize:])
+// The Decrypt instance method
+func (d *AESCryptoMethod) Decrypt(resourceKey ResourceKey, data []byte) ([]byte, error) {
+    r, e := d.DecryptReader(resourceKey, bytes.NewReader(data))
+    if e != nil { return nil, e }
 
-    result = __remove_padding(result)
+    // Never return data which failed authentication.
+    result, e := io.ReadAll(r)
+    if e != nil { return nil, e }
     return result, nil
 }
-
@@ -0,0 +1,111 @@
+package crdb
+
+import "testing"
+import "fmt"
+import "runtime"
+import "runtime/debug"
+import "sync"
+import "time"
+
+const BENCHMARK_SET_SIZE = 500000
+
+// Samples the heap while f runs, returning the peak growth over the heap in
+// use before it started. Garbage is collected eagerly meanwhile, so the peak
+// reflects what f holds on to rather than when the collector ran.
+func peakHeapGrowth(f func()) uint64 {
+    defer debug.SetGCPercent(debug.SetGCPercent(5))
+
+    var stats runtime.MemStats
+    runtime.GC()
+    runtime.ReadMemStats(&stats)
+    base, peak := stats.HeapAlloc, stats.HeapAlloc
+
+    var wg sync.WaitGroup
+    done := make(chan struct{})
+
+    wg.Add(1)
+    go func() {
+        defer wg.Done()
+        var s runtime.MemStats
+        for {
+            runtime.ReadMemStats(&s)
+            if s.HeapAlloc > peak { peak = s.HeapAlloc }
+
+            select {
+            case <-done: return
+            case <-time.After(time.Millisecond):
+            }
+        }
+    }()
+
+    f()
+    close(done)
+    wg.Wait()
+
+    return peak - base
+}
+
+// Creates a large GSet resource in d, returning a reference to it.
+func benchmarkSet(b *testing.B, d *Database) (Resource, ReferenceId) {
+    resource, e := d.Create(GROWONLYSET_RESOURCE_TYPE, "file", "aes-256-cbc")
+    if e != nil { b.Fatal(e) }
+
+    insert := resource.(*SetResource).context.(SetInsertInterface)
+    for i := 0; i < BENCHMARK_SET_SIZE; i++ { insert.Insert(fmt.Sprintf("element-%016d", i)) }
+
+    reference, e := d.Attach(resource.Id(), resource.Key())
+    if e != nil { b.Fatal(e) }
+    return resource, reference
+}
+
+func benchmarkCommit(b *testing.B, d *Database) {
+    _, reference := benchmarkSet(b, d)
+
+    peak := uint64(0)
+    b.ResetTimer()
+
+    for i := 0; i < b.N; i++ {
+        growth := peakHeapGrowth(func() {
+            if e := d.Commit(reference); e != nil { b.Fatal(e) }
+        })
+        if growth > peak { peak = growth }
+    }
+
+    b.ReportMetric(float64(peak) / (1 << 20), "peak-MiB")
+}
+
+// The peak of a restore includes the restored set itself.
+func benchmarkRestore(b *testing.B, d *Database) {
+    resource, reference := benchmarkSet(b, d)
+    if e := d.Commit(reference); e != nil { b.Fatal(e) }
+
+    peak := uint64(0)
+    b.ResetTimer()
+
+    for i := 0; i < b.N; i++ {
+        growth := peakHeapGrowth(func() {
+            if _, e := d.Restore(resource.Id(), resource.Key()); e != nil { b.Fatal(e) }
+        })
+        if growth > peak { peak = growth }
+    }
+
+    b.ReportMetric(float64(peak) / (1 << 20), "peak-MiB")
+}
+
+func BenchmarkCommitStreamed(b *testing.B) {
+    initDatabase(b)
+    benchmarkCommit(b, db)
+}
+
+func BenchmarkCommitBuffered(b *testing.B) {
+    benchmarkCommit(b, initBufferedDatabase(b))
+}
+
+func BenchmarkRestoreStreamed(b *testing.B) {
+    initDatabase(b)
+    benchmarkRestore(b, db)
+}
+
+func BenchmarkRestoreBuffered(b *testing.B) {
+    benchmarkRestore(b, initBufferedDatabase(b))
+}
@@ -38,6 +38,17 @@ type Client struct {
     // Resource type declaration
     GSetClient
     TwoPhaseSetClient
+    ORSetClient
+    LWWSetClient
+    CounterClient
+    LWWRegisterClient
+    MVRegisterClient
+    ORMapClient
+    SequenceClient
+    FlagClient
+    GraphClient
+    TreeClient
+    DocumentClient
 
     connection *grpc.ClientConn
 }
@@ -63,6 +74,39 @@ func (d *Client) ConnectToHost(hostport string) error {
     tpsets := NewTwoPhaseSetClient(conn)
     d.TwoPhaseSetClient = *tpsets
 
+    orsets := NewORSetClient(conn)
+    d.ORSetClient = *orsets
+
+    lwwsets := NewLWWSetClient(conn)
+    d.LWWSetClient = *lwwsets
+
+    counters := NewCounterClient(conn)
+    d.CounterClient = *counters
+
+    lwwregs := NewLWWRegisterClient(conn)
+    d.LWWRegisterClient = *lwwregs
+
+    mvregs := NewMVRegisterClient(conn)
+    d.MVRegisterClient = *mvregs
+
+    ormaps := NewORMapClient(conn)
+    d.ORMapClient = *ormaps
+
+    sequences := NewSequenceClient(conn)
+    d.SequenceClient = *sequences
+
+    flags := NewFlagClient(conn)
+    d.FlagClient = *flags
+
+    graphs := NewGraphClient(conn)
+    d.GraphClient = *graphs
+
+    trees := NewTreeClient(conn)
+    d.TreeClient = *trees
+
+    documents := NewDocumentClient(conn)
+    d.DocumentClient = *documents
+
     return nil
 }
 
@@ -161,6 +205,32 @@ func (d *Client) Clone(referenceId ReferenceId) (ResourceId, ResourceKey, error)
     return ResourceId(r.ResourceId), ResourceKey(r.ResourceKey), nil
 }
 
+// The Acknowledge client request method records that replicaId has applied
+// the deltas of a resource up to marker.
+func (d *Client) Acknowledge(referenceId ReferenceId, replicaId string, marker []byte) error {
+    r, e := d.CRDTClient.Acknowledge(context.Background(),
+                                     &pb.AcknowledgeRequest{
+                                         ReferenceId: string(referenceId),
+                                         ReplicaId: replicaId,
+                                         Marker: marker,
+                                     })
+    if e != nil { return e }
+    if !r.Status.Success { return fmt.Errorf(r.Status.ErrorType) }
+    return nil
+}
+
+// The Compact client request method returns the number of tombstones
+// discarded, and the number of bytes reclaimed.
+func (d *Client) Compact(referenceId ReferenceId) (uint64, uint64, error) {
+    r, e := d.CRDTClient.Compact(context.Background(),
+                                 &pb.CompactRequest{
+                                     ReferenceId: string(referenceId),
+                                 })
+    if e != nil { return 0, 0, e }
+    if !r.Status.Success { return 0, 0, fmt.Errorf(r.Status.ErrorType) }
+    return r.Tombstones, r.Bytes, nil
+}
+
 // The SupportedTypes client request method
 func (d *Client) SupportedTypes() ([]string, error) {
     results := make([]string, 0)
@@ -0,0 +1,101 @@
+/*
+ * Copyright (c) 2015 Tom Swindell (t.swindell@rubyx.co.uk)
+ *
+ * Permission is hereby granted, free of charge, to any person obtaining a copy
+ * of this software and associated documentation files (the "Software"), to deal
+ * in the Software without restriction, including without limitation the rights
+ * to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+ * copies of the Software, and to permit persons to whom the Software is
+ * furnished to do so, subject to the following conditions:
+ *
+ * The above copyright notice and this permission notice shall be included in
+ * all copies or substantial portions of the Software.
+ *
+ * THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+ * IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+ * FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+ * AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+ * LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+ * OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+ * SOFTWARE.
+ *
+ */
+
+package crdb
+
+import (
+    "fmt"
+
+    "google.golang.org/grpc"
+    "golang.org/x/net/context"
+
+    pb "github.com/tswindell/go-crdt/protos"
+)
+
+type CounterClient struct {
+    pb.CounterClient
+}
+
+func NewCounterClient(connection *grpc.ClientConn) *CounterClient {
+    d := new(CounterClient)
+    d.CounterClient = pb.NewCounterClient(connection)
+    return d
+}
+
+// Counter API extensions to CRDB Client type
+func (d *CounterClient) Increment(referenceId ReferenceId, delta uint64) error {
+    r, e := d.CounterClient.Increment(context.Background(),
+                                      &pb.CounterIncrementRequest{
+                                          ReferenceId: string(referenceId),
+                                          Delta: delta,
+                                      })
+    if e != nil { return e }
+    if !r.Status.Success { return fmt.Errorf(r.Status.ErrorType) }
+    return nil
+}
+
+func (d *CounterClient) Decrement(referenceId ReferenceId, delta uint64) error {
+    r, e := d.CounterClient.Decrement(context.Background(),
+                                      &pb.CounterDecrementRequest{
+                                          ReferenceId: string(referenceId),
+                                          Delta: delta,
+                                      })
+    if e != nil { return e }
+    if !r.Status.Success { return fmt.Errorf(r.Status.ErrorType) }
+    return nil
+}
+
+func (d *CounterClient) Value(referenceId ReferenceId) (int64, error) {
+    r, e := d.CounterClient.Value(context.Background(),
+                                  &pb.CounterValueRequest{
+                                      ReferenceId: string(referenceId),
+                                  })
+    if e != nil { return 0, e }
+    if !r.Status.Success { return 0, fmt.Errorf(r.Status.ErrorType) }
+    return r.Value, nil
+}
+
+func (d *CounterClient) Transfer(referenceId ReferenceId, replicaId string, delta uint64) error {
+    r, e := d.CounterClient.Transfer(context.Background(),
+                                     &pb.CounterTransferRequest{
+                                         ReferenceId: string(referenceId),
+                                         ReplicaId: replicaId,
+                                         Delta: delta,
+                                     })
+    if e != nil { return e }
+    if !r.Status.Success { return fmt.Errorf(r.Status.ErrorType) }
+    return nil
+}
+
+// The Rights() method returns the id of the replica asked about, and the rights
+// it holds. An empty replicaId asks about the replica serving the request.
+func (d *CounterClient) Rights(referenceId ReferenceId, replicaId string) (string, uint64, error) {
+    r, e := d.CounterClient.Rights(context.Background(),
+                                   &pb.CounterRightsRequest{
+                                       ReferenceId: string(referenceId),
+                                       ReplicaId: replicaId,
+                                   })
+    if e != nil { return "", 0, e }
+    if !r.Status.Success { return "", 0, fmt.Errorf(r.Status.ErrorType) }
+    return r.ReplicaId, r.Rights, nil
+}
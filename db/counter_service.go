@@ -0,0 +1,263 @@
+/*
+ * Copyright (c) 2015 Tom Swindell (t.swindell@rubyx.co.uk)
+ *
+ * Permission is hereby granted, free of charge, to any person obtaining a copy
+ * of this software and associated documentation files (the "Software"), to deal
+ * in the Software without restriction, including without limitation the rights
+ * to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+ * copies of the Software, and to permit persons to whom the Software is
+ * furnished to do so, subject to the following conditions:
+ *
+ * The above copyright notice and this permission notice shall be included in
+ * all copies or substantial portions of the Software.
+ *
+ * THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+ * IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+ * FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+ * AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+ * LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+ * OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+ * SOFTWARE.
+ *
+ */
+
+package crdb
+
+import (
+    "io"
+    "fmt"
+    "reflect"
+
+    "github.com/tswindell/go-crdt/counters"
+
+    "golang.org/x/net/context"
+    pb "github.com/tswindell/go-crdt/protos"
+)
+
+const (
+    GROWONLYCOUNTER_RESOURCE_TYPE = ResourceType("crdt:gcounter")
+    PNCOUNTER_RESOURCE_TYPE       = ResourceType("crdt:pncounter")
+    BCOUNTER_RESOURCE_TYPE        = ResourceType("crdt:bcounter")
+)
+
+var (
+    E_NOT_SUPPORTED = fmt.Errorf("crdt:error-operation-not-supported")
+)
+
+
+// Generic ``Counter'' function interfaces
+type CounterIncrementInterface interface { Increment(string, uint64) }
+type CounterDecrementInterface interface { Decrement(string, uint64) }
+type CounterValueInterface     interface { Value() int64             }
+
+// Function interfaces of counters that limit decrements by per-replica rights.
+type CounterCheckedDecrementInterface interface { Decrement(string, uint64) error         }
+type CounterTransferInterface         interface { Transfer(string, string, uint64) error }
+type CounterRightsInterface           interface { Rights(string) uint64                  }
+
+
+// Generic ``Counter'' resource type.
+type CounterResource struct {
+    ResourceBase
+
+    context interface{} // Polymorphic reference to concrete data type instance.
+}
+
+func (d *CounterResource) Serialize(w io.Writer) error {
+    return serializeBuffered(w, d.context.(SerializeInterface).Serialize)
+}
+
+func (d *CounterResource) Deserialize(r io.Reader) error {
+    return deserializeBuffered(r, d.context.(SerializeInterface).Deserialize)
+}
+
+
+// The NewGCounterResource function adheres to ResourceFactoryFunc prototype.
+func NewGCounterResource(resourceId ResourceId, resourceKey ResourceKey) Resource {
+    return &CounterResource{
+               ResourceBase{resourceId, resourceKey, GROWONLYCOUNTER_RESOURCE_TYPE},
+               counter.NewGCounter(),
+           }
+}
+
+// The NewPNCounterResource function adheres to ResourceFactoryFunc prototype.
+func NewPNCounterResource(resourceId ResourceId, resourceKey ResourceKey) Resource {
+    return &CounterResource{
+               ResourceBase{resourceId, resourceKey, PNCOUNTER_RESOURCE_TYPE},
+               counter.NewPNCounter(),
+           }
+}
+
+// The NewBCounterResource function adheres to ResourceFactoryFunc prototype.
+func NewBCounterResource(resourceId ResourceId, resourceKey ResourceKey) Resource {
+    return &CounterResource{
+               ResourceBase{resourceId, resourceKey, BCOUNTER_RESOURCE_TYPE},
+               counter.NewBCounter(),
+           }
+}
+
+
+// The CounterResourceType type
+type CounterResourceType struct {
+    database *Database
+    typeId    ResourceType
+    factory   ResourceFactoryFunc
+}
+
+func NewCounterResourceType(database *Database,
+                            typeId ResourceType,
+                            factory ResourceFactoryFunc) *CounterResourceType {
+
+    return &CounterResourceType{database, typeId, factory}
+}
+
+func (d *CounterResourceType) TypeId() ResourceType { return d.typeId }
+
+func (d *CounterResourceType) Create(resourceId ResourceId, resourceKey ResourceKey) Resource {
+    return d.factory(resourceId, resourceKey)
+}
+
+func (d *CounterResourceType) Equals(aResource, bResource Resource) (bool, error) {
+    aCounter := aResource.(*CounterResource).context
+    bCounter := bResource.(*CounterResource).context
+
+    return reflect.ValueOf(aCounter).MethodByName("Equals").Call([]reflect.Value{reflect.ValueOf(bCounter)})[0].Bool(), nil
+}
+
+func (d *CounterResourceType) Merge(aResource, bResource Resource) error {
+    aCounter := aResource.(*CounterResource).context
+    bCounter := bResource.(*CounterResource).context
+
+    reflect.ValueOf(aCounter).MethodByName("Merge").Call([]reflect.Value{reflect.ValueOf(bCounter)})
+
+    return nil
+}
+
+func (d *CounterResourceType) Clone(resource Resource) (Resource, error) {
+    newResource, e := d.database.Create(resource.Type(),
+                                        resource.Id().GetStorageId(),
+                                        resource.Key().TypeId())
+    if e != nil { return nil, e }
+
+    c := resource.(*CounterResource).context
+    newContext := reflect.ValueOf(c).MethodByName("Clone").Call([]reflect.Value{})[0].Interface()
+    newResource.(*CounterResource).context = newContext
+    return newResource, nil
+}
+
+func (d *CounterResourceType) Restore(resourceId ResourceId, resourceKey ResourceKey, r io.Reader) (Resource, error) {
+    resource := d.factory(resourceId, resourceKey)
+    if e := resource.Deserialize(r); e != nil { return nil, e }
+    return resource, nil
+}
+
+
+// The CounterResourceService type
+type CounterResourceService struct {
+    database *Database
+}
+
+func NewCounterResourceService(database *Database) *CounterResourceService {
+    return &CounterResourceService{database}
+}
+
+// Resolves referenceId to the concrete counter instance.
+func (d *CounterResourceService) resolve(referenceId ReferenceId) (interface{}, error) {
+    r, e := d.database.Resolve(referenceId)
+    if e != nil { return nil, e }
+
+    resource, ok := r.(*CounterResource)
+    if !ok { return nil, E_TYPE_MISMATCH }
+
+    return resource.context, nil
+}
+
+// The Increment() service method
+func (d *CounterResourceService) Increment(ctx context.Context, m *pb.CounterIncrementRequest) (*pb.CounterIncrementResponse, error) {
+    context, e := d.resolve(ReferenceId(m.ReferenceId))
+    if e != nil {
+        return &pb.CounterIncrementResponse{Status:&pb.Status{Success:false,ErrorType:e.Error()}}, nil
+    }
+
+    context.(CounterIncrementInterface).Increment(d.database.ReplicaId(), m.Delta)
+
+    return &pb.CounterIncrementResponse{Status:&pb.Status{Success:true}}, nil
+}
+
+// The Decrement() service method
+func (d *CounterResourceService) Decrement(ctx context.Context, m *pb.CounterDecrementRequest) (*pb.CounterDecrementResponse, error) {
+    context, e := d.resolve(ReferenceId(m.ReferenceId))
+    if e != nil {
+        return &pb.CounterDecrementResponse{Status:&pb.Status{Success:false,ErrorType:e.Error()}}, nil
+    }
+
+    switch counter := context.(type) {
+    case CounterDecrementInterface:
+        counter.Decrement(d.database.ReplicaId(), m.Delta)
+
+    case CounterCheckedDecrementInterface:
+        if e := counter.Decrement(d.database.ReplicaId(), m.Delta); e != nil {
+            return &pb.CounterDecrementResponse{Status:&pb.Status{Success:false,ErrorType:e.Error()}}, nil
+        }
+
+    default:
+        return &pb.CounterDecrementResponse{Status:&pb.Status{Success:false,ErrorType:E_NOT_SUPPORTED.Error()}}, nil
+    }
+
+    return &pb.CounterDecrementResponse{Status:&pb.Status{Success:true}}, nil
+}
+
+// The Value() service method
+func (d *CounterResourceService) Value(ctx context.Context, m *pb.CounterValueRequest) (*pb.CounterValueResponse, error) {
+    context, e := d.resolve(ReferenceId(m.ReferenceId))
+    if e != nil {
+        return &pb.CounterValueResponse{Status:&pb.Status{Success:false,ErrorType:e.Error()}}, nil
+    }
+
+    return &pb.CounterValueResponse{
+               Status: &pb.Status{Success:true},
+               Value: context.(CounterValueInterface).Value(),
+           }, nil
+}
+
+// The Transfer() service method moves rights from this replica to another.
+func (d *CounterResourceService) Transfer(ctx context.Context, m *pb.CounterTransferRequest) (*pb.CounterTransferResponse, error) {
+    context, e := d.resolve(ReferenceId(m.ReferenceId))
+    if e != nil {
+        return &pb.CounterTransferResponse{Status:&pb.Status{Success:false,ErrorType:e.Error()}}, nil
+    }
+
+    counter, ok := context.(CounterTransferInterface)
+    if !ok {
+        return &pb.CounterTransferResponse{Status:&pb.Status{Success:false,ErrorType:E_NOT_SUPPORTED.Error()}}, nil
+    }
+
+    if e := counter.Transfer(d.database.ReplicaId(), m.ReplicaId, m.Delta); e != nil {
+        return &pb.CounterTransferResponse{Status:&pb.Status{Success:false,ErrorType:e.Error()}}, nil
+    }
+
+    return &pb.CounterTransferResponse{Status:&pb.Status{Success:true}}, nil
+}
+
+// The Rights() service method returns the rights held by a replica, this
+// replica when none is given.
+func (d *CounterResourceService) Rights(ctx context.Context, m *pb.CounterRightsRequest) (*pb.CounterRightsResponse, error) {
+    context, e := d.resolve(ReferenceId(m.ReferenceId))
+    if e != nil {
+        return &pb.CounterRightsResponse{Status:&pb.Status{Success:false,ErrorType:e.Error()}}, nil
+    }
+
+    counter, ok := context.(CounterRightsInterface)
+    if !ok {
+        return &pb.CounterRightsResponse{Status:&pb.Status{Success:false,ErrorType:E_NOT_SUPPORTED.Error()}}, nil
+    }
+
+    replicaId := m.ReplicaId
+    if replicaId == "" { replicaId = d.database.ReplicaId() }
+
+    return &pb.CounterRightsResponse{
+               Status: &pb.Status{Success:true},
+               ReplicaId: replicaId,
+               Rights: counter.Rights(replicaId),
+           }, nil
+}
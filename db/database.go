@@ -24,9 +24,14 @@
 package crdb
 
 import (
-    "bytes"
+    "bufio"
     "errors"
     "fmt"
+    "io"
+    "io/ioutil"
+    "os"
+    "path"
+    "strings"
 )
 
 var (
@@ -59,7 +64,34 @@ type ResourceFactory interface {
 
     Clone(Resource) (Resource, error)
 
-    Restore(ResourceId, ResourceKey, *bytes.Buffer) (Resource, error)
+    Restore(ResourceId, ResourceKey, io.Reader) (Resource, error)
+}
+
+// The DeltaResourceFactory interface is optionally implemented by resource
+// types which can ship only the changes made to a resource since an opaque
+// marker, instead of its whole state.
+type DeltaResourceFactory interface {
+    ResourceFactory
+
+    // Returns the serialized delta since marker, and the marker to request
+    // the next delta from. An empty marker requests the whole state.
+    Delta(Resource, []byte) ([]byte, []byte, error)
+
+    ApplyDelta(Resource, []byte) error
+}
+
+// The CompactResourceFactory interface is optionally implemented by resource
+// types which keep tombstones, that can be discarded once every replica of
+// the resource is known to hold them.
+type CompactResourceFactory interface {
+    ResourceFactory
+
+    // Records that a replica holds the resource up to a marker from Delta().
+    Acknowledge(Resource, string, []byte) error
+
+    // Discards the tombstones every one of the given replicas holds,
+    // returning how many were discarded.
+    Compact(Resource, []string) (int, error)
 }
 
 
@@ -130,6 +162,20 @@ type Storage interface {
     SetData(ResourceId, ResourceKey, []byte) error
 }
 
+// The StreamStorage interface is optionally implemented by storage backends
+// which can write and read resource data without holding all of it in memory.
+type StreamStorage interface {
+    Storage
+
+    // Calls write with a writer for the resource data, which only replaces
+    // the stored data if write succeeds.
+    WriteData(ResourceId, ResourceKey, func(io.Writer) error) error
+
+    // Sends a reader for each stored version of the resource data, closing the
+    // channel when done. Each reader must be closed by the receiver.
+    ReadData(ResourceId, ResourceKey, chan io.ReadCloser) error
+}
+
 // The StorageDirectory type
 type StorageDirectory ThreadSafeMap
 
@@ -161,6 +207,20 @@ type CryptoMethod interface {
     Decrypt(ResourceKey, []byte) ([]byte, error)
 }
 
+// The StreamCryptoMethod interface is optionally implemented by crypto methods
+// which can encrypt and decrypt resource data as it is streamed.
+type StreamCryptoMethod interface {
+    CryptoMethod
+
+    // Returns a writer encrypting to w, which must be closed to complete it.
+    EncryptWriter(ResourceKey, io.Writer) (io.WriteCloser, error)
+
+    // Returns a reader decrypting r. Data is returned before it can be
+    // authenticated, so it must be read to EOF without error before any of it
+    // is trusted.
+    DecryptReader(ResourceKey, io.Reader) (io.Reader, error)
+}
+
 // The CryptoMethodDirectory type
 type CryptoMethodDirectory ThreadSafeMap
 
@@ -183,8 +243,9 @@ func (d *CryptoMethodDirectory) List() []string {
 
 // The Notification type
 type Notification struct {
-    Type    int
-    Object []byte
+    Type      int
+    Object   []byte
+    Position  int // Element position within sequence types, -1 otherwise.
 }
 
 
@@ -196,6 +257,12 @@ type Database struct {
     crypto     CryptoMethodDirectory
     storage    StorageDirectory
 
+    // Identifies this database instance in per-replica resource state.
+    replicaId  string
+
+    // Every replica of the resources in this database, see SetReplicas().
+    replicas   []string
+
     subscriptions map[string]map[chan Notification]struct{}
 }
 
@@ -207,9 +274,57 @@ func NewDatabase() *Database {
     d.references = ReferenceTable(NewThreadSafeMap())
     d.storage    = StorageDirectory(NewThreadSafeMap())
     d.crypto     = CryptoMethodDirectory(NewThreadSafeMap())
+    d.replicaId  = GenerateUUID()
+    d.subscriptions = make(map[string]map[chan Notification]struct{})
     return d
 }
 
+// The ReplicaId() method returns the identifier of this database instance, used
+// by resource types which keep per-replica state.
+func (d *Database) ReplicaId() string {
+    return d.replicaId
+}
+
+// The LoadReplicaId() method reads the replica identifier of this database from
+// filename, so per-replica resource state (such as bounded counter rights) stays
+// owned by this instance across restarts. When the file does not exist yet, the
+// current identifier is written to it.
+func (d *Database) LoadReplicaId(filename string) error {
+    data, e := ioutil.ReadFile(filename)
+    if os.IsNotExist(e) {
+        if e := os.MkdirAll(path.Dir(filename), 0755); e != nil { return e }
+        return ioutil.WriteFile(filename, []byte(d.replicaId + "\n"), 0644)
+    }
+    if e != nil { return e }
+
+    replicaId := strings.TrimSpace(string(data))
+    if replicaId == "" { return fmt.Errorf("Empty replica id in: %s", filename) }
+    d.replicaId = replicaId
+    return nil
+}
+
+// The SetReplicas() method sets the replica ids of every database that
+// exchanges resource state with this one. Tombstones are only compacted once
+// each of them has acknowledged them, and never while no replicas are set.
+func (d *Database) SetReplicas(replicas []string) {
+    d.replicas = append([]string{}, replicas...)
+}
+
+// The LoadReplicas() method sets the replicas of this database from filename,
+// holding one replica id per line. A missing file sets no replicas.
+func (d *Database) LoadReplicas(filename string) error {
+    data, e := ioutil.ReadFile(filename)
+    if os.IsNotExist(e) { return nil }
+    if e != nil { return e }
+
+    replicas := make([]string, 0)
+    for _, line := range strings.Split(string(data), "\n") {
+        if replicaId := strings.TrimSpace(line); replicaId != "" { replicas = append(replicas, replicaId) }
+    }
+    d.SetReplicas(replicas)
+    return nil
+}
+
 // The RegisterType() function registers a new resource type factory within this
 // instance.
 func (d *Database) RegisterType(factory ResourceFactory) error {
@@ -302,8 +417,14 @@ func (d *Database) Subscribe(referenceId ReferenceId) (chan Notification, error)
 }
 
 func (d *Database) Notify(resourceId ResourceId, nType int, object []byte) {
+    d.NotifyAt(resourceId, nType, -1, object)
+}
+
+// The NotifyAt() database method notifies subscribers of a change at position
+// within a sequence resource.
+func (d *Database) NotifyAt(resourceId ResourceId, nType int, position int, object []byte) {
     for k, _ := range d.subscriptions[string(resourceId)] {
-        k <- Notification {Type: nType, Object: object}
+        k <- Notification {Type: nType, Object: object, Position: position}
     }
 }
 
@@ -322,25 +443,22 @@ func (d *Database) Commit(referenceId ReferenceId) error {
     crypto := d.crypto.GetMethod(resource.Key().TypeId())
     if crypto == nil { return E_INVALID_CRYPTO }
 
-    buff := bytes.Buffer{}
-    buff.WriteString(string(resource.Type()))
-    buff.WriteByte(byte(0x00))
-    if e := resource.Serialize(&buff); e != nil { return e }
-
-    data, e := crypto.Encrypt(resource.Key(), buff.Bytes())
-    if e != nil { return e }
+    return writeData(storage, resource.Id(), resource.Key(), func(w io.Writer) error {
+        out, e := encryptWriter(crypto, resource.Key(), w)
+        if e != nil { return e }
 
-    e = storage.SetData(resource.Id(), resource.Key(), data)
-    if e != nil { return e }
-    return nil
+        if _, e := io.WriteString(out, string(resource.Type()) + "\x00"); e != nil { return e }
+        if e := resource.Serialize(out); e != nil { return e }
+        return out.Close()
+    })
 }
 
 // The Restore() database method restores a resource from persistent storage.
+// Each stored version is streamed through decryption into its resource type,
+// and later versions are merged into the first.
 func (d *Database) Restore(resourceId ResourceId, resourceKey ResourceKey) (Resource, error) {
-    var resource     Resource
-    var resourceType ResourceType
-    var factory      ResourceFactory
-    var e            error
+    var resource Resource
+    var factory  ResourceFactory
 
     storage := d.storage.GetStore(resourceId.GetStorageId())
     if storage == nil { return nil, E_UNKNOWN_RESOURCE }
@@ -348,51 +466,28 @@ func (d *Database) Restore(resourceId ResourceId, resourceKey ResourceKey) (Reso
     crypto := d.crypto.GetMethod(resourceKey.TypeId())
     if crypto == nil { return nil, E_INVALID_KEY }
 
-    ch := make(chan []byte)
+    ch := make(chan io.ReadCloser)
+
+    go readData(storage, resourceId, resourceKey, ch)
 
-    go storage.GetData(resourceId, resourceKey, ch)
+    // Unblock the storage backend if we give up early.
+    defer func() { for r := range ch { r.Close() } }()
 
     for data := range ch {
         LogInfo("Decrypting stored data...")
-        data, e = crypto.Decrypt(resourceKey, data)
-
-        if e != nil {
-            LogError("Decryption failed: %v", e)
-            return nil, E_INVALID_RESOURCE_DATA
-        }
+        next, e := d.restoreVersion(crypto, resourceId, resourceKey, data)
+        data.Close()
+        if e != nil { return nil, e }
 
-        buff := bytes.NewBuffer(data)
-        s, e := buff.ReadString(byte(0x00))
-        if e != nil {
-            LogError("Failed to read CRDT datatype header.")
-            return nil, E_INVALID_RESOURCE_DATA
-        }
-
-        iType := ResourceType(s[:len(s) - 1])
-
-        LogInfo("Extracted resource type information: %s", iType)
         if resource == nil {
-            resourceType = iType
-
-            factory = d.datatypes.GetFactory(resourceType)
-            if factory == nil {
-                LogError("Failed to find factory for type: %s", resourceType)
-                return nil, E_UNKNOWN_TYPE
-            }
-
-            LogInfo("Invoking factory restore method...")
-            resource, e = factory.Restore(resourceId, resourceKey, buff)
-            if e != nil || resource == nil {
-                LogError("Factory restore failed: %v", e)
-                return nil, e
-            }
-        } else {
-            if iType != resourceType { return nil, E_TYPE_MISMATCH }
-
-            // Acts as merge if resource is already initialized.
-            e = resource.Deserialize(buff)
+            resource = next
+            factory = d.datatypes.GetFactory(resource.Type())
+            continue
         }
 
+        if next.Type() != resource.Type() { return nil, E_TYPE_MISMATCH }
+
+        if e := factory.Merge(resource, next); e != nil { return nil, e }
     }
 
     if resource == nil {
@@ -405,6 +500,68 @@ func (d *Database) Restore(resourceId ResourceId, resourceKey ResourceKey) (Reso
     return resource, nil
 }
 
+// Restores a single stored version of a resource. The whole version is read,
+// so the resource is only returned once the data has been authenticated.
+func (d *Database) restoreVersion(crypto CryptoMethod, resourceId ResourceId, resourceKey ResourceKey, data io.Reader) (Resource, error) {
+    plain, e := decryptReader(crypto, resourceKey, data)
+    if e != nil {
+        LogError("Decryption failed: %v", e)
+        return nil, E_INVALID_RESOURCE_DATA
+    }
+
+    return d.restorePlain(resourceId, resourceKey, plain)
+}
+
+// The longest resource type header accepted from stored data.
+const MAX_TYPE_HEADER_SIZE = 256
+
+// Reads the NUL terminated resource type header of stored data, refusing any
+// longer than MAX_TYPE_HEADER_SIZE so corrupt data can not be buffered whole.
+func readTypeHeader(in *bufio.Reader) (ResourceType, error) {
+    var header []byte
+    for len(header) <= MAX_TYPE_HEADER_SIZE {
+        c, e := in.ReadByte()
+        if e != nil { return ResourceType(""), e }
+        if c == 0x00 { return ResourceType(header), nil }
+        header = append(header, c)
+    }
+    return ResourceType(""), fmt.Errorf("type header too long")
+}
+
+// Restores a resource from decrypted data, the type header followed by the
+// serialized resource.
+func (d *Database) restorePlain(resourceId ResourceId, resourceKey ResourceKey, plain io.Reader) (Resource, error) {
+    in := bufio.NewReader(plain)
+    resourceType, e := readTypeHeader(in)
+    if e != nil {
+        LogError("Failed to read CRDT datatype header: %v", e)
+        return nil, E_INVALID_RESOURCE_DATA
+    }
+
+    LogInfo("Extracted resource type information: %s", resourceType)
+
+    factory := d.datatypes.GetFactory(resourceType)
+    if factory == nil {
+        LogError("Failed to find factory for type: %s", resourceType)
+        return nil, E_UNKNOWN_TYPE
+    }
+
+    LogInfo("Invoking factory restore method...")
+    resource, e := factory.Restore(resourceId, resourceKey, in)
+    if e != nil || resource == nil {
+        LogError("Factory restore failed: %v", e)
+        return nil, E_INVALID_RESOURCE_DATA
+    }
+
+    // Reading to the end authenticates the data.
+    if _, e := io.Copy(io.Discard, in); e != nil {
+        LogError("Decryption failed: %v", e)
+        return nil, E_INVALID_RESOURCE_DATA
+    }
+
+    return resource, nil
+}
+
 // The Equals() database method
 func (d *Database) Equals(a ReferenceId, b ReferenceId) (bool, error) {
     aResource, e := d.Resolve(a)
@@ -441,6 +598,83 @@ func (d *Database) Merge(a ReferenceId, b ReferenceId) error {
     return factory.Merge(aResource, bResource)
 }
 
+// The Delta() database method returns the changes made to a resource since
+// marker, and the marker to request the next delta from.
+func (d *Database) Delta(referenceId ReferenceId, marker []byte) ([]byte, []byte, error) {
+    resource, e := d.Resolve(referenceId)
+    if e != nil { return nil, nil, e }
+
+    factory := d.datatypes.GetFactory(resource.Type())
+    if factory == nil { return nil, nil, E_INVALID_TYPE }
+
+    deltas, ok := factory.(DeltaResourceFactory)
+    if !ok { return nil, nil, E_NOT_SUPPORTED }
+
+    return deltas.Delta(resource, marker)
+}
+
+// The ApplyDelta() database method merges a delta from Delta() into a
+// resource of the same type.
+func (d *Database) ApplyDelta(referenceId ReferenceId, delta []byte) error {
+    resource, e := d.Resolve(referenceId)
+    if e != nil { return e }
+
+    factory := d.datatypes.GetFactory(resource.Type())
+    if factory == nil { return E_INVALID_TYPE }
+
+    deltas, ok := factory.(DeltaResourceFactory)
+    if !ok { return E_NOT_SUPPORTED }
+
+    return deltas.ApplyDelta(resource, delta)
+}
+
+// The Acknowledge() database method records that replicaId has applied the
+// deltas of a resource up to marker, as returned by Delta().
+func (d *Database) Acknowledge(referenceId ReferenceId, replicaId string, marker []byte) error {
+    resource, e := d.Resolve(referenceId)
+    if e != nil { return e }
+
+    factory := d.datatypes.GetFactory(resource.Type())
+    if factory == nil { return E_INVALID_TYPE }
+
+    compactor, ok := factory.(CompactResourceFactory)
+    if !ok { return E_NOT_SUPPORTED }
+
+    return compactor.Acknowledge(resource, replicaId, marker)
+}
+
+// The Compact() database method discards the tombstones of a resource which
+// every replica set with SetReplicas() has acknowledged. It returns how many
+// were discarded and how many bytes smaller the serialized resource became.
+func (d *Database) Compact(referenceId ReferenceId) (int, uint64, error) {
+    resource, e := d.Resolve(referenceId)
+    if e != nil { return 0, 0, e }
+
+    factory := d.datatypes.GetFactory(resource.Type())
+    if factory == nil { return 0, 0, E_INVALID_TYPE }
+
+    compactor, ok := factory.(CompactResourceFactory)
+    if !ok { return 0, 0, E_NOT_SUPPORTED }
+
+    // This replica holds its own tombstones.
+    replicas := make([]string, 0, len(d.replicas))
+    for _, replicaId := range d.replicas {
+        if replicaId != d.replicaId { replicas = append(replicas, replicaId) }
+    }
+
+    before, e := serializedSize(resource)
+    if e != nil { return 0, 0, e }
+
+    count, e := compactor.Compact(resource, replicas)
+    if e != nil { return 0, 0, e }
+
+    after, e := serializedSize(resource)
+    if e != nil { return 0, 0, e }
+
+    if after > before { return count, 0, nil }
+    return count, before - after, nil
+}
+
 // The Clone() database method
 func (d *Database) Clone(referenceId ReferenceId) (Resource, error) {
     aResource, e := d.Resolve(referenceId)
@@ -1,10 +1,23 @@
 package crdb
 
 import "testing"
+import "bytes"
+import "encoding/binary"
+import "fmt"
+import "hash/crc32"
+import "io"
+import "log"
+import "math/rand"
+import "os"
+import "path"
+import "sort"
+import "strings"
+
+import "github.com/tswindell/go-crdt/sets"
 
 var db *Database
 
-func initDatabase(t *testing.T) {
+func initDatabase(t testing.TB) {
     db = NewDatabase()
 
     if e := db.RegisterType(NewSetResourceType(db,
@@ -219,3 +232,294 @@ func Test_Database_Commit(t *testing.T) {
     if e != nil { t.Errorf("Failed to attach resource: %v", e) }
 }
 
+func Test_Database_LoadReplicaId(t *testing.T) {
+    filename := path.Join(t.TempDir(), "crdb", "replica-id")
+
+    d := NewDatabase()
+    if e := d.LoadReplicaId(filename); e != nil { t.Fatalf("Failed to create replica id: %v", e) }
+    replicaId := d.ReplicaId()
+
+    d = NewDatabase()
+    if d.ReplicaId() == replicaId { t.Fatal("Expected a fresh database to have a new replica id!") }
+    if e := d.LoadReplicaId(filename); e != nil { t.Fatalf("Failed to load replica id: %v", e) }
+    if d.ReplicaId() != replicaId {
+        t.Errorf("Expected replica id %s, got %s", replicaId, d.ReplicaId())
+    }
+
+    empty := path.Join(t.TempDir(), "replica-id")
+    if e := os.WriteFile(empty, []byte("\n"), 0644); e != nil { t.Fatal(e) }
+    if e := NewDatabase().LoadReplicaId(empty); e == nil {
+        t.Error("Expected an empty replica id file to be refused!")
+    }
+}
+
+func Test_Database_LoadReplicas(t *testing.T) {
+    filename := path.Join(t.TempDir(), "replicas")
+
+    d := NewDatabase()
+    if e := d.LoadReplicas(filename); e != nil || len(d.replicas) != 0 {
+        t.Errorf("Expected no replicas without a file: %v (%v)", d.replicas, e)
+    }
+
+    if e := os.WriteFile(filename, []byte("replica-a\n\n  replica-b \n"), 0644); e != nil { t.Fatal(e) }
+    if e := d.LoadReplicas(filename); e != nil { t.Fatal(e) }
+    if len(d.replicas) != 2 || d.replicas[0] != "replica-a" || d.replicas[1] != "replica-b" {
+        t.Errorf("Unexpected replicas: %v", d.replicas)
+    }
+}
+
+func Test_Database_BCounterRights_SurviveRestart(t *testing.T) {
+    filename := path.Join(t.TempDir(), "replica-id")
+
+    initDatabase(t)
+    RegisterResourceTypes(db, set.SystemClock, t.TempDir())
+    if e := db.LoadReplicaId(filename); e != nil { t.Fatal(e) }
+
+    resource, e := db.Create(BCOUNTER_RESOURCE_TYPE, "file", "aes-256-cbc")
+    if e != nil { t.Fatalf("Failed to create resource: %v", e) }
+
+    reference, e := db.Attach(resource.Id(), resource.Key())
+    if e != nil { t.Fatalf("Failed to attach resource: %v", e) }
+
+    resolved, _ := db.Resolve(reference)
+    resolved.(*CounterResource).context.(CounterIncrementInterface).Increment(db.ReplicaId(), 5)
+    if e := db.Commit(reference); e != nil { t.Fatalf("Failed to commit resource: %v", e) }
+
+    // Re-initialize database, as a restarted server would.
+    initDatabase(t)
+    RegisterResourceTypes(db, set.SystemClock, t.TempDir())
+    if e := db.LoadReplicaId(filename); e != nil { t.Fatal(e) }
+
+    reference, e = db.Attach(resource.Id(), resource.Key())
+    if e != nil { t.Fatalf("Failed to attach resource: %v", e) }
+
+    resolved, _ = db.Resolve(reference)
+    counter := resolved.(*CounterResource).context
+    if rights := counter.(CounterRightsInterface).Rights(db.ReplicaId()); rights != 5 {
+        t.Fatalf("Expected 5 rights after restart, got %d", rights)
+    }
+    if e := counter.(CounterCheckedDecrementInterface).Decrement(db.ReplicaId(), 3); e != nil {
+        t.Errorf("Failed to decrement after restart: %v", e)
+    }
+}
+
+
+func Test_Database_Delta(t *testing.T) {
+    initDatabase(t)
+
+    a, e := db.Create(ResourceType("crdt:gset"), "file", "aes-256-cbc")
+    if e != nil { t.Errorf("Failed to create resource: %v", e) }
+
+    b, e := db.Create(ResourceType("crdt:gset"), "file", "aes-256-cbc")
+    if e != nil { t.Errorf("Failed to create resource: %v", e) }
+
+    aReference, _ := db.Attach(a.Id(), a.Key())
+    bReference, _ := db.Attach(b.Id(), b.Key())
+
+    insert := a.(*SetResource).context.(SetInsertInterface)
+    insert.Insert("AQ==")
+
+    delta, marker, e := db.Delta(aReference, nil)
+    if e != nil { t.Errorf("Failed to get delta: %v", e) }
+    if e := db.ApplyDelta(bReference, delta); e != nil { t.Errorf("Failed to apply delta: %v", e) }
+
+    insert.Insert("Ag==")
+
+    delta, marker, e = db.Delta(aReference, marker)
+    if e != nil { t.Errorf("Failed to get delta: %v", e) }
+    if e := db.ApplyDelta(bReference, delta); e != nil { t.Errorf("Failed to apply delta: %v", e) }
+
+    if equal, _ := db.Equals(aReference, bReference); !equal {
+        t.Error("Applied deltas should reproduce the original resource!")
+    }
+
+    if e := db.ApplyDelta(bReference, []byte("invalid")); e == nil {
+        t.Error("Invalid delta returned no error!")
+    }
+}
+
+func Test_Database_Commit_MigratesV1(t *testing.T) {
+    initDatabase(t)
+
+    resource, e := db.Create(ResourceType("crdt:gset"), "file", "aes-256-cbc")
+    if e != nil { t.Fatalf("Failed to create resource: %v", e) }
+
+    storage := db.storage.GetStore(resource.Id().GetStorageId())
+    crypto := db.crypto.GetMethod(resource.Key().TypeId())
+
+    // Store a GSet holding "hello" in the v1 format.
+    v1 := bytes.NewBufferString("crdt:gset\x00")
+    v1.Write(set.GSET_HEADER_MAGIC)
+    binary.Write(v1, binary.LittleEndian, uint32(1))
+    binary.Write(v1, binary.LittleEndian, uint64(5))
+    binary.Write(v1, binary.LittleEndian, crc32.ChecksumIEEE([]byte("hello")))
+    v1.WriteString("hello")
+
+    data, e := crypto.Encrypt(resource.Key(), v1.Bytes())
+    if e != nil { t.Fatal(e) }
+    if e := storage.SetData(resource.Id(), resource.Key(), data); e != nil { t.Fatal(e) }
+
+    // Drop the resource from memory, so Attach restores the stored data.
+    db.datastore = ResourceDatastore(NewThreadSafeMap())
+
+    reference, e := db.Attach(resource.Id(), resource.Key())
+    if e != nil { t.Fatalf("Failed to restore v1 data: %v", e) }
+
+    restored, _ := db.Resolve(reference)
+    if !restored.(*SetResource).context.(SetContainsInterface).Contains("hello") {
+        t.Error("Restored resource is missing v1 element!")
+    }
+
+    if e := db.Commit(reference); e != nil { t.Fatalf("Failed to commit: %v", e) }
+
+    ch := make(chan []byte)
+    go storage.GetData(resource.Id(), resource.Key(), ch)
+
+    data, e = crypto.Decrypt(resource.Key(), <-ch)
+    if e != nil { t.Fatal(e) }
+
+    header := []byte("crdt:gset\x00")
+    if !bytes.HasPrefix(data, header) || !bytes.HasPrefix(data[len(header):], set.FORMAT_MAGIC) {
+        t.Error("Expected committed data to be migrated to the v2 format!")
+    }
+}
+
+// Hide the streaming methods of a storage backend and crypto method, so the
+// database falls back to buffering the whole resource.
+type bufferedStorage struct { Storage }
+type bufferedCrypto  struct { CryptoMethod }
+
+func initBufferedDatabase(t testing.TB) *Database {
+    d := NewDatabase()
+    d.RegisterType(NewSetResourceType(d, GROWONLYSET_RESOURCE_TYPE, NewGSetResource))
+    d.RegisterStorage(bufferedStorage{NewFileStore("/tmp/crdb-test")})
+
+    aes, e := NewAESCryptoMethod(AES_256_KEY_SIZE)
+    if e != nil { t.Fatalf("Failed to create crypto: %v", e) }
+    d.RegisterCryptoMethod(bufferedCrypto{aes})
+    return d
+}
+
+func Test_Database_Commit_Streamed(t *testing.T) {
+    initDatabase(t)
+
+    resource, e := db.Create(ResourceType("crdt:gset"), "file", "aes-256-cbc")
+    if e != nil { t.Fatalf("Failed to create resource: %v", e) }
+
+    insert := resource.(*SetResource).context.(SetInsertInterface)
+    for i := 0; i < 10000; i++ { insert.Insert(fmt.Sprintf("element-%d", i)) }
+
+    reference, _ := db.Attach(resource.Id(), resource.Key())
+    if e := db.Commit(reference); e != nil { t.Fatalf("Failed to commit: %v", e) }
+
+    // Streamed and buffered data must be interchangeable.
+    buffered := initBufferedDatabase(t)
+
+    bReference, e := buffered.Attach(resource.Id(), resource.Key())
+    if e != nil { t.Fatalf("Failed to restore streamed data: %v", e) }
+
+    restored, _ := buffered.Resolve(bReference)
+    if restored.(*SetResource).context.(SetLengthInterface).Length() != 10000 {
+        t.Fatal("Restored resource does not match committed resource!")
+    }
+
+    restored.(*SetResource).context.(SetInsertInterface).Insert("buffered")
+    if e := buffered.Commit(bReference); e != nil { t.Fatalf("Failed to commit: %v", e) }
+
+    initDatabase(t)
+
+    reference, e = db.Attach(resource.Id(), resource.Key())
+    if e != nil { t.Fatalf("Failed to restore buffered data: %v", e) }
+
+    restored, _ = db.Resolve(reference)
+    if !restored.(*SetResource).context.(SetContainsInterface).Contains("buffered") {
+        t.Error("Restored resource does not match committed resource!")
+    }
+}
+
+func Test_Database_Restore_Tampered(t *testing.T) {
+    initDatabase(t)
+
+    resource, e := db.Create(ResourceType("crdt:gset"), "file", "aes-256-cbc")
+    if e != nil { t.Fatalf("Failed to create resource: %v", e) }
+
+    resource.(*SetResource).context.(SetInsertInterface).Insert("hello")
+
+    reference, _ := db.Attach(resource.Id(), resource.Key())
+    if e := db.Commit(reference); e != nil { t.Fatalf("Failed to commit: %v", e) }
+
+    // Corrupt the HMAC, which is only checked after the set has been read.
+    filename := path.Join("/tmp/crdb-test", resource.Id().GetId())
+    data, e := os.ReadFile(filename)
+    if e != nil { t.Fatal(e) }
+
+    data[len(data) - 1] ^= 0xff
+    if e := os.WriteFile(filename, data, 0644); e != nil { t.Fatal(e) }
+
+    initDatabase(t)
+
+    if _, e := db.Attach(resource.Id(), resource.Key()); e != E_INVALID_RESOURCE_DATA {
+        t.Errorf("Expected invalid resource data from tampered resource, got: %v", e)
+    }
+}
+
+func Test_Database_Restore_Header(t *testing.T) {
+    initDatabase(t)
+
+    resourceId, resourceKey := ResourceId("file:header"), ResourceKey("aes-256-cbc:")
+
+    for _, data := range []string{"", "crdt:gset", strings.Repeat("crdt:", 1 << 20) + "\x00"} {
+        if _, e := db.restorePlain(resourceId, resourceKey, strings.NewReader(data)); e != E_INVALID_RESOURCE_DATA {
+            t.Errorf("Expected invalid resource data from bad header, got: %v", e)
+        }
+    }
+
+    if _, e := db.restorePlain(resourceId, resourceKey, strings.NewReader("crdt:unknown\x00")); e != E_UNKNOWN_TYPE {
+        t.Errorf("Expected unknown type, got: %v", e)
+    }
+}
+
+// Checks the type header and serialized state of stored data, as decrypted by
+// Restore(), are refused without panicking when malformed, and that any data
+// accepted restores the same resource again.
+func FuzzRestore(f *testing.F) {
+    d := NewDatabase()
+    RegisterResourceTypes(d, set.SystemClock, f.TempDir())
+
+    resourceId, resourceKey := ResourceId("file:fuzz"), ResourceKey("aes-256-cbc:")
+    r := rand.New(rand.NewSource(1))
+
+    datatypes := d.SupportedTypes()
+    sort.Slice(datatypes, func(i, j int) bool { return datatypes[i] < datatypes[j] })
+
+    for _, resourceType := range datatypes {
+        resource := d.datatypes.GetFactory(resourceType).Create(resourceId, resourceKey)
+        for i := 0; i < 5; i++ { lawOperations[resourceType](r, resource, "replica-0") }
+
+        buff := bytes.NewBufferString(string(resourceType) + "\x00")
+        resource.Serialize(buff)
+        f.Add(buff.Bytes())
+    }
+    f.Add([]byte("crdt:gset"))
+    f.Add(bytes.Repeat([]byte("crdt:"), 100))
+
+    log.SetOutput(io.Discard)
+    f.Cleanup(func() { log.SetOutput(os.Stderr) })
+
+    f.Fuzz(func(t *testing.T, data []byte) {
+        resource, e := d.restorePlain(resourceId, resourceKey, bytes.NewReader(data))
+        if e != nil { return }
+
+        factory := d.datatypes.GetFactory(resource.Type())
+
+        buff := new(bytes.Buffer)
+        if e := resource.Serialize(buff); e != nil { t.Fatal(e) }
+
+        again, e := factory.Restore(resourceId, resourceKey, buff)
+        if e != nil { t.Fatalf("Failed to restore %s again: %v", resource.Type(), e) }
+
+        if equal, e := factory.Equals(resource, again); !equal || e != nil {
+            t.Errorf("Restored %s does not match! (%v)", resource.Type(), e)
+        }
+    })
+}
@@ -0,0 +1,90 @@
+/*
+ * Copyright (c) 2015 Tom Swindell (t.swindell@rubyx.co.uk)
+ *
+ * Permission is hereby granted, free of charge, to any person obtaining a copy
+ * of this software and associated documentation files (the "Software"), to deal
+ * in the Software without restriction, including without limitation the rights
+ * to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+ * copies of the Software, and to permit persons to whom the Software is
+ * furnished to do so, subject to the following conditions:
+ *
+ * The above copyright notice and this permission notice shall be included in
+ * all copies or substantial portions of the Software.
+ *
+ * THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+ * IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+ * FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+ * AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+ * LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+ * OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+ * SOFTWARE.
+ *
+ */
+
+package crdb
+
+import (
+    "fmt"
+
+    "google.golang.org/grpc"
+    "golang.org/x/net/context"
+
+    pb "github.com/tswindell/go-crdt/protos"
+)
+
+type DocumentClient struct {
+    pb.DocumentClient
+}
+
+func NewDocumentClient(connection *grpc.ClientConn) *DocumentClient {
+    d := new(DocumentClient)
+    d.DocumentClient = pb.NewDocumentClient(connection)
+    return d
+}
+
+// Document API extensions to CRDB Client type
+func (d *DocumentClient) Get(referenceId ReferenceId, path string) ([]byte, error) {
+    r, e := d.DocumentClient.Get(context.Background(),
+                                 &pb.DocumentGetRequest{
+                                     ReferenceId: string(referenceId),
+                                     Path: path,
+                                 })
+    if e != nil { return nil, e }
+    if !r.Status.Success { return nil, fmt.Errorf(r.Status.ErrorType) }
+    return r.Value, nil
+}
+
+func (d *DocumentClient) Set(referenceId ReferenceId, path string, value []byte) error {
+    r, e := d.DocumentClient.Set(context.Background(),
+                                 &pb.DocumentSetRequest{
+                                     ReferenceId: string(referenceId),
+                                     Path: path,
+                                     Value: value,
+                                 })
+    if e != nil { return e }
+    if !r.Status.Success { return fmt.Errorf(r.Status.ErrorType) }
+    return nil
+}
+
+func (d *DocumentClient) Insert(referenceId ReferenceId, path string, value []byte) error {
+    r, e := d.DocumentClient.Insert(context.Background(),
+                                    &pb.DocumentInsertRequest{
+                                        ReferenceId: string(referenceId),
+                                        Path: path,
+                                        Value: value,
+                                    })
+    if e != nil { return e }
+    if !r.Status.Success { return fmt.Errorf(r.Status.ErrorType) }
+    return nil
+}
+
+func (d *DocumentClient) Delete(referenceId ReferenceId, path string) error {
+    r, e := d.DocumentClient.Delete(context.Background(),
+                                    &pb.DocumentDeleteRequest{
+                                        ReferenceId: string(referenceId),
+                                        Path: path,
+                                    })
+    if e != nil { return e }
+    if !r.Status.Success { return fmt.Errorf(r.Status.ErrorType) }
+    return nil
+}
@@ -0,0 +1,174 @@
+/*
+ * Copyright (c) 2015 Tom Swindell (t.swindell@rubyx.co.uk)
+ *
+ * Permission is hereby granted, free of charge, to any person obtaining a copy
+ * of this software and associated documentation files (the "Software"), to deal
+ * in the Software without restriction, including without limitation the rights
+ * to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+ * copies of the Software, and to permit persons to whom the Software is
+ * furnished to do so, subject to the following conditions:
+ *
+ * The above copyright notice and this permission notice shall be included in
+ * all copies or substantial portions of the Software.
+ *
+ * THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+ * IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+ * FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+ * AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+ * LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+ * OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+ * SOFTWARE.
+ *
+ */
+
+package crdb
+
+import (
+    "io"
+
+    "github.com/tswindell/go-crdt/documents"
+    "github.com/tswindell/go-crdt/sets"
+
+    "golang.org/x/net/context"
+    pb "github.com/tswindell/go-crdt/protos"
+)
+
+const (
+    JSON_RESOURCE_TYPE = ResourceType("crdt:json")
+)
+
+
+// The DocumentResource type
+type DocumentResource struct {
+    ResourceBase
+
+    context *document.Document
+}
+
+func (d *DocumentResource) Serialize(w io.Writer) error {
+    return serializeBuffered(w, d.context.Serialize)
+}
+
+func (d *DocumentResource) Deserialize(r io.Reader) error {
+    return deserializeBuffered(r, d.context.Deserialize)
+}
+
+// The NewDocumentResourceFactory function returns a ResourceFactoryFunc
+// creating JSON documents timestamped by clock.
+func NewDocumentResourceFactory(clock set.Clock) ResourceFactoryFunc {
+    return func(resourceId ResourceId, resourceKey ResourceKey) Resource {
+        return &DocumentResource{
+                   ResourceBase{resourceId, resourceKey, JSON_RESOURCE_TYPE},
+                   document.NewDocument(clock),
+               }
+    }
+}
+
+
+// The DocumentResourceType type
+type DocumentResourceType struct {
+    database *Database
+    factory  ResourceFactoryFunc
+}
+
+func NewDocumentResourceType(database *Database, clock set.Clock) *DocumentResourceType {
+    return &DocumentResourceType{database, NewDocumentResourceFactory(clock)}
+}
+
+func (d *DocumentResourceType) TypeId() ResourceType { return JSON_RESOURCE_TYPE }
+
+func (d *DocumentResourceType) Create(resourceId ResourceId, resourceKey ResourceKey) Resource {
+    return d.factory(resourceId, resourceKey)
+}
+
+func (d *DocumentResourceType) Equals(aResource, bResource Resource) (bool, error) {
+    return aResource.(*DocumentResource).context.Equals(bResource.(*DocumentResource).context), nil
+}
+
+func (d *DocumentResourceType) Merge(aResource, bResource Resource) error {
+    aResource.(*DocumentResource).context.Merge(bResource.(*DocumentResource).context)
+    return nil
+}
+
+func (d *DocumentResourceType) Clone(resource Resource) (Resource, error) {
+    newResource, e := d.database.Create(resource.Type(),
+                                        resource.Id().GetStorageId(),
+                                        resource.Key().TypeId())
+    if e != nil { return nil, e }
+
+    newResource.(*DocumentResource).context = resource.(*DocumentResource).context.Clone()
+    return newResource, nil
+}
+
+func (d *DocumentResourceType) Restore(resourceId ResourceId, resourceKey ResourceKey, r io.Reader) (Resource, error) {
+    resource := d.factory(resourceId, resourceKey)
+    if e := resource.Deserialize(r); e != nil { return nil, e }
+    return resource, nil
+}
+
+
+// The DocumentResourceService type
+type DocumentResourceService struct {
+    database *Database
+}
+
+func NewDocumentResourceService(database *Database) *DocumentResourceService {
+    return &DocumentResourceService{database}
+}
+
+// Resolves referenceId to the concrete document instance.
+func (d *DocumentResourceService) resolve(referenceId ReferenceId) (*document.Document, error) {
+    r, e := d.database.Resolve(referenceId)
+    if e != nil { return nil, e }
+
+    resource, ok := r.(*DocumentResource)
+    if !ok { return nil, E_TYPE_MISMATCH }
+
+    return resource.context, nil
+}
+
+// The Get() service method
+func (d *DocumentResourceService) Get(ctx context.Context, m *pb.DocumentGetRequest) (*pb.DocumentGetResponse, error) {
+    var value []byte
+
+    doc, e := d.resolve(ReferenceId(m.ReferenceId))
+    if e == nil { value, e = doc.Get(m.Path) }
+    if e != nil {
+        return &pb.DocumentGetResponse{Status:&pb.Status{Success:false,ErrorType:e.Error()}}, nil
+    }
+
+    return &pb.DocumentGetResponse{Status:&pb.Status{Success:true}, Value:value}, nil
+}
+
+// The Set() service method
+func (d *DocumentResourceService) Set(ctx context.Context, m *pb.DocumentSetRequest) (*pb.DocumentSetResponse, error) {
+    doc, e := d.resolve(ReferenceId(m.ReferenceId))
+    if e == nil { e = doc.Set(d.database.ReplicaId(), m.Path, m.Value) }
+    if e != nil {
+        return &pb.DocumentSetResponse{Status:&pb.Status{Success:false,ErrorType:e.Error()}}, nil
+    }
+
+    return &pb.DocumentSetResponse{Status:&pb.Status{Success:true}}, nil
+}
+
+// The Insert() service method
+func (d *DocumentResourceService) Insert(ctx context.Context, m *pb.DocumentInsertRequest) (*pb.DocumentInsertResponse, error) {
+    doc, e := d.resolve(ReferenceId(m.ReferenceId))
+    if e == nil { e = doc.Insert(d.database.ReplicaId(), m.Path, m.Value) }
+    if e != nil {
+        return &pb.DocumentInsertResponse{Status:&pb.Status{Success:false,ErrorType:e.Error()}}, nil
+    }
+
+    return &pb.DocumentInsertResponse{Status:&pb.Status{Success:true}}, nil
+}
+
+// The Delete() service method
+func (d *DocumentResourceService) Delete(ctx context.Context, m *pb.DocumentDeleteRequest) (*pb.DocumentDeleteResponse, error) {
+    doc, e := d.resolve(ReferenceId(m.ReferenceId))
+    if e == nil { e = doc.Delete(m.Path) }
+    if e != nil {
+        return &pb.DocumentDeleteResponse{Status:&pb.Status{Success:false,ErrorType:e.Error()}}, nil
+    }
+
+    return &pb.DocumentDeleteResponse{Status:&pb.Status{Success:true}}, nil
+}
@@ -24,6 +24,8 @@
 package crdb
 
 import (
+    "bufio"
+    "io"
     "io/ioutil"
     "os"
     "path"
@@ -64,7 +66,10 @@ func (d *FileStore) HasResource(resourceId ResourceId) bool {
 
 // The GetResourceData instance method.
 func (d *FileStore) GetData(resourceId ResourceId, key ResourceKey, ch chan []byte) error {
-    if !d.HasResource(resourceId) { return E_UNKNOWN_RESOURCE }
+    if !d.HasResource(resourceId) {
+        close(ch)
+        return E_UNKNOWN_RESOURCE
+    }
 
     data, e := ioutil.ReadFile(path.Join(d.basepath, resourceId.GetId()))
     if e != nil {
@@ -83,3 +88,31 @@ func (d *FileStore) SetData(resourceId ResourceId, key ResourceKey, data []byte)
     return ioutil.WriteFile(filepath, data, 0644)
 }
 
+
+// The WriteData instance method streams the resource data to a temporary file
+// which replaces the stored data only once write succeeds.
+func (d *FileStore) WriteData(resourceId ResourceId, key ResourceKey, write func(io.Writer) error) error {
+    f, e := ioutil.TempFile(d.basepath, "." + resourceId.GetId() + ".")
+    if e != nil { return e }
+    defer os.Remove(f.Name())
+
+    out := bufio.NewWriter(f)
+    if e := write(out); e != nil { f.Close(); return e }
+    if e := out.Flush(); e != nil { f.Close(); return e }
+    if e := f.Chmod(0644); e != nil { f.Close(); return e }
+    if e := f.Close(); e != nil { return e }
+
+    return os.Rename(f.Name(), path.Join(d.basepath, resourceId.GetId()))
+}
+
+// The ReadData instance method streams the stored resource data.
+func (d *FileStore) ReadData(resourceId ResourceId, key ResourceKey, ch chan io.ReadCloser) error {
+    defer close(ch)
+
+    f, e := os.Open(path.Join(d.basepath, resourceId.GetId()))
+    if os.IsNotExist(e) { return E_UNKNOWN_RESOURCE }
+    if e != nil { return e }
+
+    ch<- struct{ *bufio.Reader; io.Closer }{bufio.NewReader(f), f}
+    return nil
+}
@@ -3,6 +3,7 @@ package crdb
 import "testing"
 import "bytes"
 import "crypto/rand"
+import "io"
 import "os"
 
 const FILESTORE_TEST_PATH = "/tmp/crdb-fstore-test"
@@ -45,3 +46,44 @@ func Test_FileStore(t *testing.T) {
     }
 }
 
+
+func Test_FileStore_Stream(t *testing.T) {
+    os.RemoveAll(FILESTORE_TEST_PATH)
+
+    fs := NewFileStore(FILESTORE_TEST_PATH)
+    id := ResourceId("file:0123456789ABCDEF")
+
+    ch := make(chan io.ReadCloser)
+    if e := fs.ReadData(id, ResourceKey(""), ch); e != E_UNKNOWN_RESOURCE {
+        t.Errorf("Wrong error returned from bad ReadData: %v", e)
+    }
+    if _, ok := <-ch; ok { t.Error("Expected ReadData to close channel!") }
+
+    in := make([]byte, 32)
+    rand.Read(in)
+
+    if e := fs.WriteData(id, ResourceKey(""), func(w io.Writer) error { _, e := w.Write(in); return e }); e != nil {
+        t.Errorf("Failed call to WriteData with valid data: %v", e)
+    }
+
+    // A failed write leaves the stored data untouched.
+    failed := fs.WriteData(id, ResourceKey(""), func(w io.Writer) error {
+        w.Write([]byte("partial"))
+        return E_INVALID_RESOURCE_DATA
+    })
+    if failed != E_INVALID_RESOURCE_DATA { t.Errorf("Wrong error returned from failed WriteData: %v", failed) }
+
+    ch = make(chan io.ReadCloser)
+    go fs.ReadData(id, ResourceKey(""), ch)
+
+    r := <-ch
+    out, e := io.ReadAll(r)
+    r.Close()
+
+    if e != nil || !bytes.Equal(in, out) {
+        t.Error("Loaded data does not equal saved data!")
+    }
+
+    entries, _ := os.ReadDir(FILESTORE_TEST_PATH)
+    if len(entries) != 1 { t.Errorf("Expected temporary files to be removed, found %d files", len(entries)) }
+}
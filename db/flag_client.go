@@ -0,0 +1,74 @@
+/*
+ * Copyright (c) 2015 Tom Swindell (t.swindell@rubyx.co.uk)
+ *
+ * Permission is hereby granted, free of charge, to any person obtaining a copy
+ * of this software and associated documentation files (the "Software"), to deal
+ * in the Software without restriction, including without limitation the rights
+ * to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+ * copies of the Software, and to permit persons to whom the Software is
+ * furnished to do so, subject to the following conditions:
+ *
+ * The above copyright notice and this permission notice shall be included in
+ * all copies or substantial portions of the Software.
+ *
+ * THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+ * IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+ * FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+ * AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+ * LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+ * OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+ * SOFTWARE.
+ *
+ */
+
+package crdb
+
+import (
+    "fmt"
+
+    "google.golang.org/grpc"
+    "golang.org/x/net/context"
+
+    pb "github.com/tswindell/go-crdt/protos"
+)
+
+type FlagClient struct {
+    pb.FlagClient
+}
+
+func NewFlagClient(connection *grpc.ClientConn) *FlagClient {
+    d := new(FlagClient)
+    d.FlagClient = pb.NewFlagClient(connection)
+    return d
+}
+
+// Flag API extensions to CRDB Client type
+func (d *FlagClient) Enable(referenceId ReferenceId) error {
+    r, e := d.FlagClient.Enable(context.Background(),
+                                &pb.FlagEnableRequest{
+                                    ReferenceId: string(referenceId),
+                                })
+    if e != nil { return e }
+    if !r.Status.Success { return fmt.Errorf(r.Status.ErrorType) }
+    return nil
+}
+
+func (d *FlagClient) Disable(referenceId ReferenceId) error {
+    r, e := d.FlagClient.Disable(context.Background(),
+                                 &pb.FlagDisableRequest{
+                                     ReferenceId: string(referenceId),
+                                 })
+    if e != nil { return e }
+    if !r.Status.Success { return fmt.Errorf(r.Status.ErrorType) }
+    return nil
+}
+
+func (d *FlagClient) Value(referenceId ReferenceId) (bool, error) {
+    r, e := d.FlagClient.Value(context.Background(),
+                               &pb.FlagValueRequest{
+                                   ReferenceId: string(referenceId),
+                               })
+    if e != nil { return false, e }
+    if !r.Status.Success { return false, fmt.Errorf(r.Status.ErrorType) }
+    return r.Value, nil
+}
@@ -0,0 +1,191 @@
+/*
+ * Copyright (c) 2015 Tom Swindell (t.swindell@rubyx.co.uk)
+ *
+ * Permission is hereby granted, free of charge, to any person obtaining a copy
+ * of this software and associated documentation files (the "Software"), to deal
+ * in the Software without restriction, including without limitation the rights
+ * to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+ * copies of the Software, and to permit persons to whom the Software is
+ * furnished to do so, subject to the following conditions:
+ *
+ * The above copyright notice and this permission notice shall be included in
+ * all copies or substantial portions of the Software.
+ *
+ * THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+ * IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+ * FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+ * AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+ * LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+ * OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+ * SOFTWARE.
+ *
+ */
+
+package crdb
+
+import (
+    "io"
+    "reflect"
+
+    "github.com/tswindell/go-crdt/registers"
+
+    "golang.org/x/net/context"
+    pb "github.com/tswindell/go-crdt/protos"
+)
+
+const (
+    EWFLAG_RESOURCE_TYPE = ResourceType("crdt:ewflag")
+    DWFLAG_RESOURCE_TYPE = ResourceType("crdt:dwflag")
+)
+
+
+// Generic ``Flag'' function interfaces
+type FlagEnableInterface  interface { Enable(string)  }
+type FlagDisableInterface interface { Disable(string) }
+type FlagValueInterface   interface { Value() bool    }
+
+
+// Generic ``Flag'' resource type.
+type FlagResource struct {
+    ResourceBase
+
+    context interface{} // Polymorphic reference to concrete data type instance.
+}
+
+func (d *FlagResource) Serialize(w io.Writer) error {
+    return serializeBuffered(w, d.context.(SerializeInterface).Serialize)
+}
+
+func (d *FlagResource) Deserialize(r io.Reader) error {
+    return deserializeBuffered(r, d.context.(SerializeInterface).Deserialize)
+}
+
+
+// The NewEWFlagResource function adheres to ResourceFactoryFunc prototype.
+func NewEWFlagResource(resourceId ResourceId, resourceKey ResourceKey) Resource {
+    return &FlagResource{
+               ResourceBase{resourceId, resourceKey, EWFLAG_RESOURCE_TYPE},
+               register.NewEWFlag(),
+           }
+}
+
+// The NewDWFlagResource function adheres to ResourceFactoryFunc prototype.
+func NewDWFlagResource(resourceId ResourceId, resourceKey ResourceKey) Resource {
+    return &FlagResource{
+               ResourceBase{resourceId, resourceKey, DWFLAG_RESOURCE_TYPE},
+               register.NewDWFlag(),
+           }
+}
+
+
+// The FlagResourceType type
+type FlagResourceType struct {
+    database *Database
+    typeId    ResourceType
+    factory   ResourceFactoryFunc
+}
+
+func NewFlagResourceType(database *Database,
+                         typeId ResourceType,
+                         factory ResourceFactoryFunc) *FlagResourceType {
+
+    return &FlagResourceType{database, typeId, factory}
+}
+
+func (d *FlagResourceType) TypeId() ResourceType { return d.typeId }
+
+func (d *FlagResourceType) Create(resourceId ResourceId, resourceKey ResourceKey) Resource {
+    return d.factory(resourceId, resourceKey)
+}
+
+func (d *FlagResourceType) Equals(aResource, bResource Resource) (bool, error) {
+    aFlag := aResource.(*FlagResource).context
+    bFlag := bResource.(*FlagResource).context
+
+    return reflect.ValueOf(aFlag).MethodByName("Equals").Call([]reflect.Value{reflect.ValueOf(bFlag)})[0].Bool(), nil
+}
+
+func (d *FlagResourceType) Merge(aResource, bResource Resource) error {
+    aFlag := aResource.(*FlagResource).context
+    bFlag := bResource.(*FlagResource).context
+
+    reflect.ValueOf(aFlag).MethodByName("Merge").Call([]reflect.Value{reflect.ValueOf(bFlag)})
+
+    return nil
+}
+
+func (d *FlagResourceType) Clone(resource Resource) (Resource, error) {
+    newResource, e := d.database.Create(resource.Type(),
+                                        resource.Id().GetStorageId(),
+                                        resource.Key().TypeId())
+    if e != nil { return nil, e }
+
+    c := resource.(*FlagResource).context
+    newContext := reflect.ValueOf(c).MethodByName("Clone").Call([]reflect.Value{})[0].Interface()
+    newResource.(*FlagResource).context = newContext
+    return newResource, nil
+}
+
+func (d *FlagResourceType) Restore(resourceId ResourceId, resourceKey ResourceKey, r io.Reader) (Resource, error) {
+    resource := d.factory(resourceId, resourceKey)
+    if e := resource.Deserialize(r); e != nil { return nil, e }
+    return resource, nil
+}
+
+
+// The FlagResourceService type
+type FlagResourceService struct {
+    database *Database
+}
+
+func NewFlagResourceService(database *Database) *FlagResourceService {
+    return &FlagResourceService{database}
+}
+
+// Resolves referenceId to the concrete flag instance.
+func (d *FlagResourceService) resolve(referenceId ReferenceId) (interface{}, error) {
+    r, e := d.database.Resolve(referenceId)
+    if e != nil { return nil, e }
+
+    resource, ok := r.(*FlagResource)
+    if !ok { return nil, E_TYPE_MISMATCH }
+
+    return resource.context, nil
+}
+
+// The Enable() service method
+func (d *FlagResourceService) Enable(ctx context.Context, m *pb.FlagEnableRequest) (*pb.FlagEnableResponse, error) {
+    context, e := d.resolve(ReferenceId(m.ReferenceId))
+    if e != nil {
+        return &pb.FlagEnableResponse{Status:&pb.Status{Success:false,ErrorType:e.Error()}}, nil
+    }
+
+    context.(FlagEnableInterface).Enable(d.database.ReplicaId())
+
+    return &pb.FlagEnableResponse{Status:&pb.Status{Success:true}}, nil
+}
+
+// The Disable() service method
+func (d *FlagResourceService) Disable(ctx context.Context, m *pb.FlagDisableRequest) (*pb.FlagDisableResponse, error) {
+    context, e := d.resolve(ReferenceId(m.ReferenceId))
+    if e != nil {
+        return &pb.FlagDisableResponse{Status:&pb.Status{Success:false,ErrorType:e.Error()}}, nil
+    }
+
+    context.(FlagDisableInterface).Disable(d.database.ReplicaId())
+
+    return &pb.FlagDisableResponse{Status:&pb.Status{Success:true}}, nil
+}
+
+// The Value() service method
+func (d *FlagResourceService) Value(ctx context.Context, m *pb.FlagValueRequest) (*pb.FlagValueResponse, error) {
+    context, e := d.resolve(ReferenceId(m.ReferenceId))
+    if e != nil {
+        return &pb.FlagValueResponse{Status:&pb.Status{Success:false,ErrorType:e.Error()}}, nil
+    }
+
+    return &pb.FlagValueResponse{
+               Status: &pb.Status{Success:true},
+               Value: context.(FlagValueInterface).Value(),
+           }, nil
+}
@@ -0,0 +1,111 @@
+/*
+ * Copyright (c) 2015 Tom Swindell (t.swindell@rubyx.co.uk)
+ *
+ * Permission is hereby granted, free of charge, to any person obtaining a copy
+ * of this software and associated documentation files (the "Software"), to deal
+ * in the Software without restriction, including without limitation the rights
+ * to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+ * copies of the Software, and to permit persons to whom the Software is
+ * furnished to do so, subject to the following conditions:
+ *
+ * The above copyright notice and this permission notice shall be included in
+ * all copies or substantial portions of the Software.
+ *
+ * THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+ * IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+ * FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+ * AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+ * LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+ * OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+ * SOFTWARE.
+ *
+ */
+
+package crdb
+
+import (
+    "fmt"
+
+    "google.golang.org/grpc"
+    "golang.org/x/net/context"
+
+    pb "github.com/tswindell/go-crdt/protos"
+)
+
+type GraphClient struct {
+    pb.GraphClient
+}
+
+func NewGraphClient(connection *grpc.ClientConn) *GraphClient {
+    d := new(GraphClient)
+    d.GraphClient = pb.NewGraphClient(connection)
+    return d
+}
+
+// Graph API extensions to CRDB Client type
+func (d *GraphClient) AddVertex(referenceId ReferenceId, vertex []byte) error {
+    r, e := d.GraphClient.AddVertex(context.Background(),
+                                    &pb.GraphAddVertexRequest{
+                                        ReferenceId: string(referenceId),
+                                        Vertex: vertex,
+                                    })
+    if e != nil { return e }
+    if !r.Status.Success { return fmt.Errorf(r.Status.ErrorType) }
+    return nil
+}
+
+func (d *GraphClient) RemoveVertex(referenceId ReferenceId, vertex []byte) error {
+    r, e := d.GraphClient.RemoveVertex(context.Background(),
+                                       &pb.GraphRemoveVertexRequest{
+                                           ReferenceId: string(referenceId),
+                                           Vertex: vertex,
+                                       })
+    if e != nil { return e }
+    if !r.Status.Success { return fmt.Errorf(r.Status.ErrorType) }
+    return nil
+}
+
+func (d *GraphClient) AddEdge(referenceId ReferenceId, from []byte, to []byte) error {
+    r, e := d.GraphClient.AddEdge(context.Background(),
+                                  &pb.GraphAddEdgeRequest{
+                                      ReferenceId: string(referenceId),
+                                      From: from,
+                                      To: to,
+                                  })
+    if e != nil { return e }
+    if !r.Status.Success { return fmt.Errorf(r.Status.ErrorType) }
+    return nil
+}
+
+func (d *GraphClient) RemoveEdge(referenceId ReferenceId, from []byte, to []byte) error {
+    r, e := d.GraphClient.RemoveEdge(context.Background(),
+                                     &pb.GraphRemoveEdgeRequest{
+                                         ReferenceId: string(referenceId),
+                                         From: from,
+                                         To: to,
+                                     })
+    if e != nil { return e }
+    if !r.Status.Success { return fmt.Errorf(r.Status.ErrorType) }
+    return nil
+}
+
+func (d *GraphClient) Neighbours(referenceId ReferenceId, vertex []byte) ([][]byte, error) {
+    r, e := d.GraphClient.Neighbours(context.Background(),
+                                     &pb.GraphNeighboursRequest{
+                                         ReferenceId: string(referenceId),
+                                         Vertex: vertex,
+                                     })
+    if e != nil { return nil, e }
+    if !r.Status.Success { return nil, fmt.Errorf(r.Status.ErrorType) }
+    return r.Vertices, nil
+}
+
+func (d *GraphClient) Vertices(referenceId ReferenceId) ([][]byte, error) {
+    r, e := d.GraphClient.Vertices(context.Background(),
+                                   &pb.GraphVerticesRequest{
+                                       ReferenceId: string(referenceId),
+                                   })
+    if e != nil { return nil, e }
+    if !r.Status.Success { return nil, fmt.Errorf(r.Status.ErrorType) }
+    return r.Vertices, nil
+}
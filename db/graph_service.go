@@ -0,0 +1,196 @@
+/*
+ * Copyright (c) 2015 Tom Swindell (t.swindell@rubyx.co.uk)
+ *
+ * Permission is hereby granted, free of charge, to any person obtaining a copy
+ * of this software and associated documentation files (the "Software"), to deal
+ * in the Software without restriction, including without limitation the rights
+ * to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+ * copies of the Software, and to permit persons to whom the Software is
+ * furnished to do so, subject to the following conditions:
+ *
+ * The above copyright notice and this permission notice shall be included in
+ * all copies or substantial portions of the Software.
+ *
+ * THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+ * IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+ * FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+ * AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+ * LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+ * OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+ * SOFTWARE.
+ *
+ */
+
+package crdb
+
+import (
+    "io"
+
+    "github.com/tswindell/go-crdt/graphs"
+
+    "golang.org/x/net/context"
+    pb "github.com/tswindell/go-crdt/protos"
+)
+
+const (
+    GRAPH_RESOURCE_TYPE = ResourceType("crdt:graph")
+)
+
+
+// The GraphResource type
+type GraphResource struct {
+    ResourceBase
+
+    context *graph.Graph
+}
+
+func (d *GraphResource) Serialize(w io.Writer) error {
+    return serializeBuffered(w, d.context.Serialize)
+}
+
+func (d *GraphResource) Deserialize(r io.Reader) error {
+    return deserializeBuffered(r, d.context.Deserialize)
+}
+
+// The NewGraphResource function adheres to ResourceFactoryFunc prototype.
+func NewGraphResource(resourceId ResourceId, resourceKey ResourceKey) Resource {
+    return &GraphResource{
+               ResourceBase{resourceId, resourceKey, GRAPH_RESOURCE_TYPE},
+               graph.NewGraph(),
+           }
+}
+
+
+// The GraphResourceType type
+type GraphResourceType struct {
+    database *Database
+}
+
+func NewGraphResourceType(database *Database) *GraphResourceType {
+    return &GraphResourceType{database}
+}
+
+func (d *GraphResourceType) TypeId() ResourceType { return GRAPH_RESOURCE_TYPE }
+
+func (d *GraphResourceType) Create(resourceId ResourceId, resourceKey ResourceKey) Resource {
+    return NewGraphResource(resourceId, resourceKey)
+}
+
+func (d *GraphResourceType) Equals(aResource, bResource Resource) (bool, error) {
+    return aResource.(*GraphResource).context.Equals(bResource.(*GraphResource).context), nil
+}
+
+func (d *GraphResourceType) Merge(aResource, bResource Resource) error {
+    aResource.(*GraphResource).context.Merge(bResource.(*GraphResource).context)
+    return nil
+}
+
+func (d *GraphResourceType) Clone(resource Resource) (Resource, error) {
+    newResource, e := d.database.Create(resource.Type(),
+                                        resource.Id().GetStorageId(),
+                                        resource.Key().TypeId())
+    if e != nil { return nil, e }
+
+    newResource.(*GraphResource).context = resource.(*GraphResource).context.Clone()
+    return newResource, nil
+}
+
+func (d *GraphResourceType) Restore(resourceId ResourceId, resourceKey ResourceKey, r io.Reader) (Resource, error) {
+    resource := NewGraphResource(resourceId, resourceKey)
+    if e := resource.Deserialize(r); e != nil { return nil, e }
+    return resource, nil
+}
+
+
+// The GraphResourceService type
+type GraphResourceService struct {
+    database *Database
+}
+
+func NewGraphResourceService(database *Database) *GraphResourceService {
+    return &GraphResourceService{database}
+}
+
+// Resolves referenceId to the concrete graph instance.
+func (d *GraphResourceService) resolve(referenceId ReferenceId) (*graph.Graph, error) {
+    r, e := d.database.Resolve(referenceId)
+    if e != nil { return nil, e }
+
+    resource, ok := r.(*GraphResource)
+    if !ok { return nil, E_TYPE_MISMATCH }
+
+    return resource.context, nil
+}
+
+// The AddVertex() service method
+func (d *GraphResourceService) AddVertex(ctx context.Context, m *pb.GraphAddVertexRequest) (*pb.GraphAddVertexResponse, error) {
+    g, e := d.resolve(ReferenceId(m.ReferenceId))
+    if e == nil { e = g.AddVertex(m.Vertex) }
+    if e != nil {
+        return &pb.GraphAddVertexResponse{Status:&pb.Status{Success:false,ErrorType:e.Error()}}, nil
+    }
+
+    return &pb.GraphAddVertexResponse{Status:&pb.Status{Success:true}}, nil
+}
+
+// The RemoveVertex() service method
+func (d *GraphResourceService) RemoveVertex(ctx context.Context, m *pb.GraphRemoveVertexRequest) (*pb.GraphRemoveVertexResponse, error) {
+    g, e := d.resolve(ReferenceId(m.ReferenceId))
+    if e == nil { e = g.RemoveVertex(m.Vertex) }
+    if e != nil {
+        return &pb.GraphRemoveVertexResponse{Status:&pb.Status{Success:false,ErrorType:e.Error()}}, nil
+    }
+
+    return &pb.GraphRemoveVertexResponse{Status:&pb.Status{Success:true}}, nil
+}
+
+// The AddEdge() service method
+func (d *GraphResourceService) AddEdge(ctx context.Context, m *pb.GraphAddEdgeRequest) (*pb.GraphAddEdgeResponse, error) {
+    g, e := d.resolve(ReferenceId(m.ReferenceId))
+    if e == nil { e = g.AddEdge(m.From, m.To) }
+    if e != nil {
+        return &pb.GraphAddEdgeResponse{Status:&pb.Status{Success:false,ErrorType:e.Error()}}, nil
+    }
+
+    return &pb.GraphAddEdgeResponse{Status:&pb.Status{Success:true}}, nil
+}
+
+// The RemoveEdge() service method
+func (d *GraphResourceService) RemoveEdge(ctx context.Context, m *pb.GraphRemoveEdgeRequest) (*pb.GraphRemoveEdgeResponse, error) {
+    g, e := d.resolve(ReferenceId(m.ReferenceId))
+    if e == nil { e = g.RemoveEdge(m.From, m.To) }
+    if e != nil {
+        return &pb.GraphRemoveEdgeResponse{Status:&pb.Status{Success:false,ErrorType:e.Error()}}, nil
+    }
+
+    return &pb.GraphRemoveEdgeResponse{Status:&pb.Status{Success:true}}, nil
+}
+
+// The Neighbours() service method
+func (d *GraphResourceService) Neighbours(ctx context.Context, m *pb.GraphNeighboursRequest) (*pb.GraphNeighboursResponse, error) {
+    var vertices [][]byte
+
+    g, e := d.resolve(ReferenceId(m.ReferenceId))
+    if e == nil { vertices, e = g.Neighbours(m.Vertex) }
+    if e != nil {
+        return &pb.GraphNeighboursResponse{Status:&pb.Status{Success:false,ErrorType:e.Error()}}, nil
+    }
+
+    return &pb.GraphNeighboursResponse{
+               Status: &pb.Status{Success:true},
+               Vertices: vertices,
+           }, nil
+}
+
+// The Vertices() service method
+func (d *GraphResourceService) Vertices(ctx context.Context, m *pb.GraphVerticesRequest) (*pb.GraphVerticesResponse, error) {
+    g, e := d.resolve(ReferenceId(m.ReferenceId))
+    if e != nil {
+        return &pb.GraphVerticesResponse{Status:&pb.Status{Success:false,ErrorType:e.Error()}}, nil
+    }
+
+    return &pb.GraphVerticesResponse{
+               Status: &pb.Status{Success:true},
+               Vertices: g.Vertices(),
+           }, nil
+}
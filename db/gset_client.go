@@ -65,12 +65,20 @@ func (d *GSetClient) List(referenceId ReferenceId) (chan []byte, error) {
 }
 
 func (d *GSetClient) Insert(referenceId ReferenceId, object []byte) error {
+    return d.InsertWithLabels(referenceId, object, nil)
+}
+
+// The InsertWithLabels() method inserts object, recording labels in its
+// metadata along with the time and the replica serving the insert.
+func (d *GSetClient) InsertWithLabels(referenceId ReferenceId, object []byte, labels map[string]string) error {
     r, e := d.GrowOnlySetClient.Insert(context.Background(),
                                        &pb.SetInsertRequest{
                                            Object: &pb.ResourceObject{
                                                ReferenceId: string(referenceId),
                                                Object: object,
-                                           }})
+                                           },
+                                           Labels: labels,
+                                       })
     if e != nil { return e }
     if !r.Status.Success { return fmt.Errorf(r.Status.ErrorType) }
     return nil
@@ -98,3 +106,56 @@ func (d *GSetClient) Contains(referenceId ReferenceId, object []byte) (bool, err
     return r.Result, nil
 }
 
+
+// The Union(), Intersect() and Difference() methods stream the elements in
+// either, both, or only the first of the two sets. Operands may be set
+// resources of any type.
+func (d *GSetClient) Union(aRef, bRef ReferenceId) (chan []byte, error) {
+    return recvSetAlgebra(d.GrowOnlySetClient.Union(context.Background(), setAlgebraRequest(aRef, bRef)))
+}
+
+func (d *GSetClient) Intersect(aRef, bRef ReferenceId) (chan []byte, error) {
+    return recvSetAlgebra(d.GrowOnlySetClient.Intersect(context.Background(), setAlgebraRequest(aRef, bRef)))
+}
+
+func (d *GSetClient) Difference(aRef, bRef ReferenceId) (chan []byte, error) {
+    return recvSetAlgebra(d.GrowOnlySetClient.Difference(context.Background(), setAlgebraRequest(aRef, bRef)))
+}
+
+// The UnionInto(), IntersectInto() and DifferenceInto() methods materialise
+// the result into a new resource of the type of aRef, which must then be
+// attached and committed like any other created resource.
+func (d *GSetClient) UnionInto(aRef, bRef ReferenceId, storageId, cryptoId string) (ResourceId, ResourceKey, error) {
+    ctx, cancel := context.WithCancel(context.Background())
+    defer cancel()
+    return recvSetAlgebraInto(d.GrowOnlySetClient.Union(ctx, setAlgebraIntoRequest(aRef, bRef, storageId, cryptoId)))
+}
+
+func (d *GSetClient) IntersectInto(aRef, bRef ReferenceId, storageId, cryptoId string) (ResourceId, ResourceKey, error) {
+    ctx, cancel := context.WithCancel(context.Background())
+    defer cancel()
+    return recvSetAlgebraInto(d.GrowOnlySetClient.Intersect(ctx, setAlgebraIntoRequest(aRef, bRef, storageId, cryptoId)))
+}
+
+func (d *GSetClient) DifferenceInto(aRef, bRef ReferenceId, storageId, cryptoId string) (ResourceId, ResourceKey, error) {
+    ctx, cancel := context.WithCancel(context.Background())
+    defer cancel()
+    return recvSetAlgebraInto(d.GrowOnlySetClient.Difference(ctx, setAlgebraIntoRequest(aRef, bRef, storageId, cryptoId)))
+}
+
+func (d *GSetClient) IsSubset(aRef, bRef ReferenceId) (bool, error) {
+    return recvSetIsSubset(d.GrowOnlySetClient.IsSubset(context.Background(),
+                                          &pb.SetIsSubsetRequest{
+                                              ReferenceId: string(aRef),
+                                              OtherReferenceId: string(bRef),
+                                          }))
+}
+
+// The ListWithMetadata() method streams the elements of the set along with
+// their metadata.
+func (d *GSetClient) ListWithMetadata(referenceId ReferenceId) (chan SetElement, error) {
+    return recvSetListWithMetadata(d.GrowOnlySetClient.ListWithMetadata(context.Background(),
+                                          &pb.SetListRequest{
+                                              ReferenceId: string(referenceId),
+                                          }))
+}
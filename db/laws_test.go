@@ -0,0 +1,213 @@
+package crdb
+
+import "testing"
+import "bytes"
+import "encoding/base64"
+import "fmt"
+import "math/rand"
+
+import "github.com/tswindell/go-crdt/sets"
+
+// Histories are generated from a fixed seed, so a failing run reproduces.
+const LAW_TEST_SEED = 1
+
+// Applies one random operation to resource as replicaId. Operations refused by
+// a resource, such as removing an element it does not hold, are part of a
+// history too and are not errors.
+type lawOperation func(r *rand.Rand, resource Resource, replicaId string) error
+
+func lawElement(r *rand.Rand) string {
+    return base64.StdEncoding.EncodeToString([]byte(fmt.Sprint(r.Intn(8))))
+}
+
+func lawValue(r *rand.Rand) []byte {
+    return []byte(fmt.Sprint(r.Intn(100)))
+}
+
+func lawSetOperation(r *rand.Rand, resource Resource, replicaId string) error {
+    s := resource.(*SetResource).context
+    if remove, ok := s.(SetRemoveInterface); ok && r.Intn(3) == 0 {
+        remove.Remove(lawElement(r))
+    } else {
+        s.(SetInsertInterface).Insert(lawElement(r))
+    }
+    return nil
+}
+
+func lawCounterOperation(r *rand.Rand, resource Resource, replicaId string) error {
+    c := resource.(*CounterResource).context
+    delta := uint64(r.Intn(5) + 1)
+
+    switch counter := c.(type) {
+    case CounterTransferInterface:
+        switch r.Intn(3) {
+        case 0: counter.Transfer(replicaId, fmt.Sprint("replica-", r.Intn(3)), delta)
+        case 1: c.(CounterCheckedDecrementInterface).Decrement(replicaId, delta)
+        default: c.(CounterIncrementInterface).Increment(replicaId, delta)
+        }
+    case CounterDecrementInterface:
+        if r.Intn(2) == 0 {
+            counter.Decrement(replicaId, delta)
+        } else {
+            c.(CounterIncrementInterface).Increment(replicaId, delta)
+        }
+    default:
+        c.(CounterIncrementInterface).Increment(replicaId, delta)
+    }
+    return nil
+}
+
+func lawRegisterOperation(r *rand.Rand, resource Resource, replicaId string) error {
+    resource.(*RegisterResource).context.(RegisterSetInterface).Set(replicaId, lawValue(r))
+    return nil
+}
+
+func lawFlagOperation(r *rand.Rand, resource Resource, replicaId string) error {
+    f := resource.(*FlagResource).context
+    if r.Intn(2) == 0 {
+        f.(FlagEnableInterface).Enable(replicaId)
+    } else {
+        f.(FlagDisableInterface).Disable(replicaId)
+    }
+    return nil
+}
+
+func lawSequenceOperation(r *rand.Rand, resource Resource, replicaId string) error {
+    s := resource.(*SequenceResource).context
+    length := s.(SequenceLengthInterface).Length()
+
+    if length > 0 && r.Intn(3) == 0 {
+        s.(SequenceDeleteInterface).DeleteAt(r.Intn(length), 1)
+    } else {
+        s.(SequenceInsertInterface).InsertAt(replicaId, r.Intn(length + 1), lawValue(r))
+    }
+    return nil
+}
+
+func lawGraphOperation(r *rand.Rand, resource Resource, replicaId string) error {
+    g := resource.(*GraphResource).context
+    from, to := []byte(fmt.Sprint(r.Intn(5))), []byte(fmt.Sprint(r.Intn(5)))
+
+    switch r.Intn(4) {
+    case 0: g.AddVertex(from)
+    case 1: g.RemoveVertex(from)
+    case 2: g.AddEdge(from, to)
+    default: g.RemoveEdge(from, to)
+    }
+    return nil
+}
+
+func lawTreeOperation(r *rand.Rand, resource Resource, replicaId string) error {
+    t := resource.(*TreeResource).context
+    node, parent := fmt.Sprint(replicaId, "-", r.Intn(5)), fmt.Sprint(replicaId, "-", r.Intn(5))
+    if r.Intn(3) == 0 { parent = "" }
+
+    switch r.Intn(3) {
+    case 0: t.Create(replicaId, node, parent)
+    case 1: t.Move(replicaId, fmt.Sprint("replica-", r.Intn(3), "-", r.Intn(5)), parent)
+    default: t.Delete(replicaId, node)
+    }
+    return nil
+}
+
+func lawDocumentOperation(r *rand.Rand, resource Resource, replicaId string) error {
+    d := resource.(*DocumentResource).context
+    field := fmt.Sprint("/f", r.Intn(3))
+
+    switch r.Intn(5) {
+    case 0: d.Set(replicaId, "", []byte(`{"list":[]}`))
+    case 1: d.Set(replicaId, field, lawValue(r))
+    case 2: d.Delete(field)
+    case 3: d.Insert(replicaId, fmt.Sprint("/list/", r.Intn(2)), lawValue(r))
+    default: d.Insert(replicaId, "/list/-", lawValue(r))
+    }
+    return nil
+}
+
+// Puts the state of a nested counter or register, each key always holding the
+// same type of value, or removes a key.
+func lawMapOperation(r *rand.Rand, resource Resource, replicaId string) error {
+    m := resource.(*ORMapResource)
+
+    if r.Intn(4) == 0 {
+        m.Remove(fmt.Sprint("counter-", r.Intn(2)))
+        return nil
+    }
+
+    key, value := fmt.Sprint("counter-", r.Intn(2)), NewGCounterResource(m.Id(), m.Key())
+    e := lawCounterOperation(r, value, replicaId)
+    if r.Intn(2) == 0 {
+        key, value = fmt.Sprint("register-", r.Intn(2)), NewMVRegisterResource(m.Id(), m.Key())
+        e = lawRegisterOperation(r, value, replicaId)
+    }
+    if e != nil { return e }
+
+    buff := new(bytes.Buffer)
+    if e := value.Serialize(buff); e != nil { return e }
+    return m.Put(key, value.Type(), buff.Bytes())
+}
+
+var lawOperations = map[ResourceType]lawOperation{
+    GROWONLYSET_RESOURCE_TYPE:     lawSetOperation,
+    TWOPHASESET_RESOURCE_TYPE:     lawSetOperation,
+    ORSET_RESOURCE_TYPE:           lawSetOperation,
+    LWWSET_RESOURCE_TYPE:          lawSetOperation,
+    DISKGSET_RESOURCE_TYPE:        lawSetOperation,
+    GROWONLYCOUNTER_RESOURCE_TYPE: lawCounterOperation,
+    PNCOUNTER_RESOURCE_TYPE:       lawCounterOperation,
+    BCOUNTER_RESOURCE_TYPE:        lawCounterOperation,
+    LWWREGISTER_RESOURCE_TYPE:     lawRegisterOperation,
+    MVREGISTER_RESOURCE_TYPE:      lawRegisterOperation,
+    ORMAP_RESOURCE_TYPE:           lawMapOperation,
+    RGA_RESOURCE_TYPE:             lawSequenceOperation,
+    EWFLAG_RESOURCE_TYPE:          lawFlagOperation,
+    DWFLAG_RESOURCE_TYPE:          lawFlagOperation,
+    GRAPH_RESOURCE_TYPE:           lawGraphOperation,
+    TREE_RESOURCE_TYPE:            lawTreeOperation,
+    JSON_RESOURCE_TYPE:            lawDocumentOperation,
+}
+
+// Returns a subject for the resources of factory, each simulated replica
+// operating under its own replica id.
+func lawSubject(factory ResourceFactory, operate lawOperation) set.LawSubject[Resource] {
+    resourceId, resourceKey := ResourceId("file:laws"), ResourceKey("aes-256-cbc:")
+
+    restore := func(data []byte) (Resource, error) {
+        return factory.Restore(resourceId, resourceKey, bytes.NewBuffer(data))
+    }
+
+    serialize := func(resource Resource) ([]byte, error) {
+        buff := new(bytes.Buffer)
+        e := resource.Serialize(buff)
+        return buff.Bytes(), e
+    }
+
+    return set.LawSubject[Resource]{
+        New: func(replica int) Resource { return factory.Create(resourceId, resourceKey) },
+        Operate: func(r *rand.Rand, resource Resource, replica int) error {
+            return operate(r, resource, fmt.Sprint("replica-", replica))
+        },
+        Merge: factory.Merge,
+        Equals: factory.Equals,
+        Clone: func(resource Resource) (Resource, error) {
+            data, e := serialize(resource)
+            if e != nil { return nil, e }
+            return restore(data)
+        },
+        Serialize: serialize,
+        Deserialize: func(data []byte, replica int) (Resource, error) { return restore(data) },
+    }
+}
+
+func Test_Laws(t *testing.T) {
+    for _, resourceType := range s.database.SupportedTypes() {
+        operate, found := lawOperations[resourceType]
+        if !found {
+            t.Errorf("No law operations for registered type: %s", resourceType)
+            continue
+        }
+
+        subject := lawSubject(s.database.datatypes.GetFactory(resourceType), operate)
+        if e := set.CheckLaws(subject, set.LawConfig{Seed: LAW_TEST_SEED}); e != nil { t.Errorf("%s: %v", resourceType, e) }
+    }
+}
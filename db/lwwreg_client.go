@@ -0,0 +1,66 @@
+/*
+ * Copyright (c) 2015 Tom Swindell (t.swindell@rubyx.co.uk)
+ *
+ * Permission is hereby granted, free of charge, to any person obtaining a copy
+ * of this software and associated documentation files (the "Software"), to deal
+ * in the Software without restriction, including without limitation the rights
+ * to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+ * copies of the Software, and to permit persons to whom the Software is
+ * furnished to do so, subject to the following conditions:
+ *
+ * The above copyright notice and this permission notice shall be included in
+ * all copies or substantial portions of the Software.
+ *
+ * THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+ * IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+ * FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+ * AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+ * LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+ * OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+ * SOFTWARE.
+ *
+ */
+
+package crdb
+
+import (
+    "fmt"
+
+    "google.golang.org/grpc"
+    "golang.org/x/net/context"
+
+    pb "github.com/tswindell/go-crdt/protos"
+)
+
+type LWWRegisterClient struct {
+    pb.LastWriterWinsRegisterClient
+}
+
+func NewLWWRegisterClient(connection *grpc.ClientConn) *LWWRegisterClient {
+    d := new(LWWRegisterClient)
+    d.LastWriterWinsRegisterClient = pb.NewLastWriterWinsRegisterClient(connection)
+    return d
+}
+
+// LWWRegister API extensions to CRDB Client type
+func (d *LWWRegisterClient) Set(referenceId ReferenceId, object []byte) error {
+    r, e := d.LastWriterWinsRegisterClient.Set(context.Background(),
+                                               &pb.RegisterSetRequest{
+                                                   Object: &pb.ResourceObject{
+                                                       ReferenceId: string(referenceId),
+                                                       Object: object,
+                                                   }})
+    if e != nil { return e }
+    if !r.Status.Success { return fmt.Errorf(r.Status.ErrorType) }
+    return nil
+}
+
+func (d *LWWRegisterClient) Get(referenceId ReferenceId) ([]byte, error) {
+    r, e := d.LastWriterWinsRegisterClient.Get(context.Background(),
+                                               &pb.RegisterGetRequest{
+                                                   ReferenceId: string(referenceId),
+                                               })
+    if e != nil { return nil, e }
+    if !r.Status.Success { return nil, fmt.Errorf(r.Status.ErrorType) }
+    return r.Object, nil
+}
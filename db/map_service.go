@@ -0,0 +1,522 @@
+/*
+ * Copyright (c) 2015 Tom Swindell (t.swindell@rubyx.co.uk)
+ *
+ * Permission is hereby granted, free of charge, to any person obtaining a copy
+ * of this software and associated documentation files (the "Software"), to deal
+ * in the Software without restriction, including without limitation the rights
+ * to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+ * copies of the Software, and to permit persons to whom the Software is
+ * furnished to do so, subject to the following conditions:
+ *
+ * The above copyright notice and this permission notice shall be included in
+ * all copies or substantial portions of the Software.
+ *
+ * THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+ * IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+ * FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+ * AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+ * LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+ * OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+ * SOFTWARE.
+ *
+ */
+
+package crdb
+
+import (
+    "bytes"
+    "crypto/rand"
+    "encoding/binary"
+    "fmt"
+    "io"
+    "sort"
+    "sync"
+
+    "github.com/tswindell/go-crdt/sets"
+
+    "golang.org/x/net/context"
+    pb "github.com/tswindell/go-crdt/protos"
+)
+
+const (
+    ORMAP_RESOURCE_TYPE = ResourceType("crdt:ormap")
+
+    // Size in bytes of the unique id of each entry of a map key.
+    ORMAP_ENTRY_ID_SIZE = 16
+)
+
+var (
+    E_UNKNOWN_KEY = fmt.Errorf("crdt:error-unknown-key")
+)
+
+
+// The ORMapResource type maps keys to nested resources of any type registered
+// with the database. Keys are held in an observed-remove set, so a Put()
+// concurrent with a Remove() of the same key survives, and values are merged
+// by delegating to the factory of their resource type. If two replicas put
+// different resource types at the same key, the value with the lowest type id
+// wins.
+//
+// Every Put() stores its value under a fresh entry of the key, holding the
+// state observed at the key so far, and a Remove() discards the entries it has
+// observed along with their values. A key put again after its removal so starts
+// from an empty value, while concurrent puts each keep an entry and are merged
+// when the key is read.
+type ORMapResource struct {
+    ResourceBase
+    sync.RWMutex
+
+    datatypes *ResourceTypeRegistry
+    keys      *set.ORSet[string]              // Entries of the present keys.
+    index      map[string]map[string]struct{} // Entries in keys of each key.
+    values     map[string]Resource            // Values of the entries in keys.
+}
+
+func NewORMapResource(datatypes *ResourceTypeRegistry,
+                      resourceId ResourceId,
+                      resourceKey ResourceKey) *ORMapResource {
+
+    return &ORMapResource{
+               ResourceBase: ResourceBase{resourceId, resourceKey, ORMAP_RESOURCE_TYPE},
+               datatypes: datatypes,
+               keys: set.NewORSet[string](),
+               index: make(map[string]map[string]struct{}),
+               values: make(map[string]Resource),
+           }
+}
+
+// Entries are a unique id followed by the key they belong to.
+func newMapEntry(key string) string {
+    id := make([]byte, ORMAP_ENTRY_ID_SIZE)
+    rand.Read(id)
+    return string(id) + key
+}
+
+func mapEntryKey(entry string) string {
+    return entry[ORMAP_ENTRY_ID_SIZE:]
+}
+
+// Returns the entries of key present in the map, the caller must hold the lock.
+func (d *ORMapResource) entries(key string) []string {
+    results := make([]string, 0, len(d.index[key]))
+    for entry := range d.index[key] { results = append(results, entry) }
+    return results
+}
+
+// Returns true when key is present in the map, the caller must hold the lock.
+func (d *ORMapResource) contains(key string) bool {
+    return len(d.index[key]) > 0
+}
+
+// Rebuilds the index of entries by key from the key set, after a merge has
+// changed it. The caller must hold the lock.
+func (d *ORMapResource) reindex() {
+    d.index = make(map[string]map[string]struct{})
+    for _, entry := range d.keys.ToSlice() { d.addEntry(entry) }
+}
+
+// Adds entry to the index, the caller must hold the lock.
+func (d *ORMapResource) addEntry(entry string) {
+    key := mapEntryKey(entry)
+    if d.index[key] == nil { d.index[key] = make(map[string]struct{}) }
+    d.index[key][entry] = struct{}{}
+}
+
+// Returns a copy of the value at key, merged from the entries of the winning
+// resource type, or nil when key is not present. The caller must hold the lock.
+func (d *ORMapResource) value(key string) (Resource, error) {
+    var result Resource
+    for _, entry := range d.entries(key) {
+        in, found := d.values[entry]
+        if !found { continue }
+
+        if result != nil && in.Type() > result.Type() { continue }
+        if result == nil || in.Type() < result.Type() {
+            v, e := d.copyValue(in)
+            if e != nil { return nil, e }
+            result = v
+            continue
+        }
+
+        factory, e := d.getFactory(result.Type())
+        if e != nil { return nil, e }
+        if e := factory.Merge(result, in); e != nil { return nil, e }
+    }
+    return result, nil
+}
+
+func (d *ORMapResource) getFactory(resourceType ResourceType) (ResourceFactory, error) {
+    factory := d.datatypes.GetFactory(resourceType)
+    if factory == nil { return nil, E_UNKNOWN_TYPE }
+    return factory, nil
+}
+
+// Creates a value from serialized state, nested values share the identity of
+// the map they belong to.
+func (d *ORMapResource) restoreValue(factory ResourceFactory, data []byte) (Resource, error) {
+    if len(data) == 0 { return factory.Create(d.Id(), d.Key()), nil }
+    return factory.Restore(d.Id(), d.Key(), bytes.NewBuffer(data))
+}
+
+func (d *ORMapResource) copyValue(value Resource) (Resource, error) {
+    factory, e := d.getFactory(value.Type())
+    if e != nil { return nil, e }
+
+    buff := new(bytes.Buffer)
+    if e := value.Serialize(buff); e != nil { return nil, e }
+    return d.restoreValue(factory, buff.Bytes())
+}
+
+// Discards the observed entries of key and their values. The caller must hold
+// the lock.
+func (d *ORMapResource) reset(key string) bool {
+    entries := d.index[key]
+    for entry := range entries {
+        d.keys.Remove(entry)
+        delete(d.values, entry)
+    }
+    delete(d.index, key)
+    return len(entries) > 0
+}
+
+// The Put() method merges serialized state of resourceType into the value at
+// key, creating the value if needed. Empty state creates an empty value.
+func (d *ORMapResource) Put(key string, resourceType ResourceType, data []byte) error {
+    factory, e := d.getFactory(resourceType)
+    if e != nil { return e }
+
+    in, e := d.restoreValue(factory, data)
+    if e != nil { return E_INVALID_RESOURCE_DATA }
+
+    d.Lock()
+    defer d.Unlock()
+
+    value, e := d.value(key)
+    if e != nil { return e }
+
+    if value != nil {
+        if value.Type() != resourceType { return E_TYPE_MISMATCH }
+        if e := factory.Merge(value, in); e != nil { return e }
+        in = value
+    }
+
+    // Replace the observed entries with a fresh one, so a concurrent remove
+    // which observed only the older entries does not discard this update.
+    d.reset(key)
+
+    entry := newMapEntry(key)
+    d.keys.Insert(entry)
+    d.addEntry(entry)
+    d.values[entry] = in
+    return nil
+}
+
+// The Get() method returns the resource type and serialized state of the
+// value at key.
+func (d *ORMapResource) Get(key string) (ResourceType, []byte, error) {
+    d.RLock()
+    defer d.RUnlock()
+
+    value, e := d.value(key)
+    if e != nil { return ResourceType(""), nil, e }
+    if value == nil { return ResourceType(""), nil, E_UNKNOWN_KEY }
+
+    buff := new(bytes.Buffer)
+    if e := value.Serialize(buff); e != nil { return ResourceType(""), nil, e }
+    return value.Type(), buff.Bytes(), nil
+}
+
+// The Remove() method removes key along with the state of its value, a later
+// Put() of key starts from an empty value.
+func (d *ORMapResource) Remove(key string) error {
+    d.Lock()
+    defer d.Unlock()
+
+    if !d.reset(key) { return E_UNKNOWN_KEY }
+    return nil
+}
+
+// The Keys() method returns the keys present in the map, in sorted order.
+func (d *ORMapResource) Keys() []string {
+    d.RLock()
+    defer d.RUnlock()
+
+    results := make([]string, 0, len(d.index))
+    for key := range d.index { results = append(results, key) }
+    sort.Strings(results)
+    return results
+}
+
+func (d *ORMapResource) Equals(other *ORMapResource) (bool, error) {
+    keys, values := other.snapshot()
+
+    d.RLock()
+    defer d.RUnlock()
+
+    if !d.keys.Equals(keys) || len(d.values) != len(values) { return false, nil }
+
+    for entry, value := range d.values {
+        in, found := values[entry]
+        if !found || in.Type() != value.Type() { return false, nil }
+
+        factory, e := d.getFactory(value.Type())
+        if e != nil { return false, e }
+
+        equal, e := factory.Equals(value, in)
+        if e != nil || !equal { return false, e }
+    }
+    return true, nil
+}
+
+// Returns a copy of the key set and of the value table, the values themselves
+// are shared.
+func (d *ORMapResource) snapshot() (*set.ORSet[string], map[string]Resource) {
+    d.RLock()
+    defer d.RUnlock()
+
+    values := make(map[string]Resource)
+    for entry, value := range d.values { values[entry] = value }
+    return d.keys.Clone(), values
+}
+
+func (d *ORMapResource) Merge(other *ORMapResource) error {
+    // Snapshot the other map first, so two maps merging into each other can
+    // never deadlock.
+    keys, values := other.snapshot()
+
+    d.Lock()
+    defer d.Unlock()
+
+    d.keys.Merge(keys)
+    d.reindex()
+
+    // Values of removed entries are dropped, on both sides of the merge.
+    for entry := range d.values {
+        if !d.keys.Contains(entry) { delete(d.values, entry) }
+    }
+
+    for entry, in := range values {
+        if !d.keys.Contains(entry) { continue }
+
+        value, found := d.values[entry]
+        if !found || in.Type() < value.Type() {
+            v, e := d.copyValue(in)
+            if e != nil { return e }
+            d.values[entry] = v
+            continue
+        }
+        if in.Type() != value.Type() { continue }
+
+        factory, e := d.getFactory(value.Type())
+        if e != nil { return e }
+        if e := factory.Merge(value, in); e != nil { return e }
+    }
+
+    return nil
+}
+
+var ORMAP_HEADER_MAGIC = []byte{'c', 'r', 'd', 't', ':', 'o', 'r', 'm', 'a', 'p', 0x00}
+
+func writeBytes(buff *bytes.Buffer, data []byte) {
+    binary.Write(buff, binary.LittleEndian, uint32(len(data)))
+    buff.Write(data)
+}
+
+func readBytes(buff *bytes.Buffer) ([]byte, error) {
+    var datl uint32
+    if e := binary.Read(buff, binary.LittleEndian, &datl); e != nil { return nil, e }
+    if uint64(datl) > uint64(buff.Len()) { return nil, fmt.Errorf("invalid format") }
+
+    data := make([]byte, datl)
+    if _, e := io.ReadFull(buff, data); e != nil { return nil, fmt.Errorf("invalid format") }
+    return data, nil
+}
+
+func (d *ORMapResource) Serialize(w io.Writer) error {
+    return serializeBuffered(w, d.serialize)
+}
+
+// Deserialize merges the serialized state into this map.
+func (d *ORMapResource) Deserialize(r io.Reader) error {
+    return deserializeBuffered(r, d.deserialize)
+}
+
+// Values are nested with their length, so the map is serialized through a
+// buffer.
+func (d *ORMapResource) serialize(buff *bytes.Buffer) error {
+    d.RLock()
+    defer d.RUnlock()
+
+    buff.Write(ORMAP_HEADER_MAGIC)
+    if e := d.keys.Serialize(buff); e != nil { return e }
+
+    binary.Write(buff, binary.LittleEndian, uint32(len(d.values)))
+    for entry, value := range d.values {
+        data := new(bytes.Buffer)
+        if e := value.Serialize(data); e != nil { return e }
+
+        writeBytes(buff, []byte(entry))
+        writeBytes(buff, []byte(value.Type()))
+        writeBytes(buff, data.Bytes())
+    }
+
+    return nil
+}
+
+func (d *ORMapResource) deserialize(buff *bytes.Buffer) error {
+    if buff.Len() < len(ORMAP_HEADER_MAGIC) ||
+       !bytes.Equal(buff.Next(len(ORMAP_HEADER_MAGIC)), ORMAP_HEADER_MAGIC) {
+        return fmt.Errorf("invalid header")
+    }
+
+    in := NewORMapResource(d.datatypes, d.Id(), d.Key())
+    if e := in.keys.Deserialize(buff); e != nil { return e }
+
+    for _, entry := range in.keys.ToSlice() {
+        if len(entry) < ORMAP_ENTRY_ID_SIZE { return fmt.Errorf("invalid format") }
+    }
+
+    var count uint32
+    if e := binary.Read(buff, binary.LittleEndian, &count); e != nil { return e }
+
+    for i := uint32(0); i < count; i++ {
+        entry, e := readBytes(buff)
+        if e != nil { return e }
+        if len(entry) < ORMAP_ENTRY_ID_SIZE { return fmt.Errorf("invalid format") }
+
+        resourceType, e := readBytes(buff)
+        if e != nil { return e }
+
+        data, e := readBytes(buff)
+        if e != nil { return e }
+
+        factory, e := d.getFactory(ResourceType(resourceType))
+        if e != nil { return e }
+
+        value, e := d.restoreValue(factory, data)
+        if e != nil { return e }
+
+        in.values[string(entry)] = value
+    }
+
+    return d.Merge(in)
+}
+
+
+// The ORMapResourceType type
+type ORMapResourceType struct {
+    database *Database
+}
+
+func NewORMapResourceType(database *Database) *ORMapResourceType {
+    return &ORMapResourceType{database}
+}
+
+func (d *ORMapResourceType) TypeId() ResourceType { return ORMAP_RESOURCE_TYPE }
+
+func (d *ORMapResourceType) Create(resourceId ResourceId, resourceKey ResourceKey) Resource {
+    return NewORMapResource(&d.database.datatypes, resourceId, resourceKey)
+}
+
+func (d *ORMapResourceType) Equals(aResource, bResource Resource) (bool, error) {
+    return aResource.(*ORMapResource).Equals(bResource.(*ORMapResource))
+}
+
+func (d *ORMapResourceType) Merge(aResource, bResource Resource) error {
+    return aResource.(*ORMapResource).Merge(bResource.(*ORMapResource))
+}
+
+func (d *ORMapResourceType) Clone(resource Resource) (Resource, error) {
+    newResource, e := d.database.Create(resource.Type(),
+                                        resource.Id().GetStorageId(),
+                                        resource.Key().TypeId())
+    if e != nil { return nil, e }
+
+    if e := newResource.(*ORMapResource).Merge(resource.(*ORMapResource)); e != nil { return nil, e }
+    return newResource, nil
+}
+
+func (d *ORMapResourceType) Restore(resourceId ResourceId, resourceKey ResourceKey, r io.Reader) (Resource, error) {
+    resource := d.Create(resourceId, resourceKey)
+    if e := resource.Deserialize(r); e != nil { return nil, e }
+    return resource, nil
+}
+
+
+// The ObserveRemoveMapService type
+type ObserveRemoveMapService struct {
+    database *Database
+}
+
+func NewObserveRemoveMapService(database *Database) *ObserveRemoveMapService {
+    return &ObserveRemoveMapService{database}
+}
+
+// Resolves referenceId to the concrete map resource.
+func (d *ObserveRemoveMapService) resolve(referenceId ReferenceId) (*ORMapResource, error) {
+    r, e := d.database.Resolve(referenceId)
+    if e != nil { return nil, e }
+
+    resource, ok := r.(*ORMapResource)
+    if !ok { return nil, E_TYPE_MISMATCH }
+
+    return resource, nil
+}
+
+// The Put() service method
+func (d *ObserveRemoveMapService) Put(ctx context.Context, m *pb.MapPutRequest) (*pb.MapPutResponse, error) {
+    r, e := d.resolve(ReferenceId(m.ReferenceId))
+    if e == nil { e = r.Put(m.Key, ResourceType(m.ResourceType), m.Object) }
+    if e != nil {
+        return &pb.MapPutResponse{Status:&pb.Status{Success:false,ErrorType:e.Error()}}, nil
+    }
+
+    go func() { d.database.Notify(r.Id(), 0, []byte(m.Key)) }()
+
+    return &pb.MapPutResponse{Status:&pb.Status{Success:true}}, nil
+}
+
+// The Get() service method
+func (d *ObserveRemoveMapService) Get(ctx context.Context, m *pb.MapGetRequest) (*pb.MapGetResponse, error) {
+    r, e := d.resolve(ReferenceId(m.ReferenceId))
+    if e != nil {
+        return &pb.MapGetResponse{Status:&pb.Status{Success:false,ErrorType:e.Error()}}, nil
+    }
+
+    resourceType, data, e := r.Get(m.Key)
+    if e != nil {
+        return &pb.MapGetResponse{Status:&pb.Status{Success:false,ErrorType:e.Error()}}, nil
+    }
+
+    return &pb.MapGetResponse{
+               Status: &pb.Status{Success:true},
+               ResourceType: string(resourceType),
+               Object: data,
+           }, nil
+}
+
+// The Remove() service method
+func (d *ObserveRemoveMapService) Remove(ctx context.Context, m *pb.MapRemoveRequest) (*pb.MapRemoveResponse, error) {
+    r, e := d.resolve(ReferenceId(m.ReferenceId))
+    if e == nil { e = r.Remove(m.Key) }
+    if e != nil {
+        return &pb.MapRemoveResponse{Status:&pb.Status{Success:false,ErrorType:e.Error()}}, nil
+    }
+
+    go func() { d.database.Notify(r.Id(), 1, []byte(m.Key)) }()
+
+    return &pb.MapRemoveResponse{Status:&pb.Status{Success:true}}, nil
+}
+
+// The Keys() service method
+func (d *ObserveRemoveMapService) Keys(ctx context.Context, m *pb.MapKeysRequest) (*pb.MapKeysResponse, error) {
+    r, e := d.resolve(ReferenceId(m.ReferenceId))
+    if e != nil {
+        return &pb.MapKeysResponse{Status:&pb.Status{Success:false,ErrorType:e.Error()}}, nil
+    }
+
+    return &pb.MapKeysResponse{
+               Status: &pb.Status{Success:true},
+               Keys: r.Keys(),
+           }, nil
+}
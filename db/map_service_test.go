@@ -0,0 +1,237 @@
+package crdb
+
+import "testing"
+import "bytes"
+
+func newTestMapDatabase() *Database {
+    d := NewDatabase()
+    d.RegisterType(NewSetResourceType(d, GROWONLYSET_RESOURCE_TYPE, NewGSetResource))
+    d.RegisterType(NewORMapResourceType(d))
+    return d
+}
+
+func newTestMap(d *Database) *ORMapResource {
+    return NewORMapResource(&d.datatypes, ResourceId("file:map"), ResourceKey("aes-256-cbc:"))
+}
+
+// Returns the serialized state of a GSet holding items.
+func gsetState(t *testing.T, items ...string) []byte {
+    r := NewGSetResource(ResourceId("file:set"), ResourceKey("aes-256-cbc:"))
+    for _, item := range items {
+        r.(*SetResource).context.(SetInsertInterface).Insert(item)
+    }
+
+    buff := new(bytes.Buffer)
+    if e := r.Serialize(buff); e != nil { t.Fatal(e) }
+    return buff.Bytes()
+}
+
+func gsetValue(t *testing.T, m *ORMapResource, key string) interface{} {
+    resourceType, data, e := m.Get(key)
+    if e != nil { t.Fatal(e) }
+    if resourceType != GROWONLYSET_RESOURCE_TYPE { t.Fatalf("Unexpected type: %s", resourceType) }
+
+    r, e := NewSetResourceType(nil, GROWONLYSET_RESOURCE_TYPE, NewGSetResource).Restore(ResourceId("file:set"),
+                                                                                        ResourceKey("aes-256-cbc:"),
+                                                                                        bytes.NewBuffer(data))
+    if e != nil { t.Fatal(e) }
+    return r.(*SetResource).context
+}
+
+func gsetLength(t *testing.T, m *ORMapResource, key string) int {
+    return gsetValue(t, m, key).(SetLengthInterface).Length()
+}
+
+func gsetContains(t *testing.T, m *ORMapResource, key string, item string) bool {
+    return gsetValue(t, m, key).(SetContainsInterface).Contains(item)
+}
+
+func Test_ORMap_PutGetRemove(t *testing.T) {
+    m := newTestMap(newTestMapDatabase())
+
+    if _, _, e := m.Get("alice"); e != E_UNKNOWN_KEY {
+        t.Error("Expected unknown key error!")
+    }
+
+    if e := m.Put("alice", GROWONLYSET_RESOURCE_TYPE, gsetState(t, "admin")); e != nil {
+        t.Fatal(e)
+    }
+
+    if e := m.Put("alice", GROWONLYSET_RESOURCE_TYPE, gsetState(t, "user")); e != nil {
+        t.Fatal(e)
+    }
+
+    if n := gsetLength(t, m, "alice"); n != 2 {
+        t.Errorf("Expected 2 elements, got %d", n)
+    }
+
+    if e := m.Put("alice", ORMAP_RESOURCE_TYPE, nil); e != E_TYPE_MISMATCH {
+        t.Error("Expected type mismatch error!")
+    }
+
+    if e := m.Put("bob", ResourceType("crdt:unknown"), nil); e != E_UNKNOWN_TYPE {
+        t.Error("Expected unknown type error!")
+    }
+
+    m.Put("bob", GROWONLYSET_RESOURCE_TYPE, nil)
+
+    if keys := m.Keys(); len(keys) != 2 || keys[0] != "alice" || keys[1] != "bob" {
+        t.Errorf("Unexpected keys: %v", keys)
+    }
+
+    if e := m.Remove("alice"); e != nil {
+        t.Fatal(e)
+    }
+
+    if e := m.Remove("alice"); e != E_UNKNOWN_KEY {
+        t.Error("Expected unknown key error!")
+    }
+
+    if keys := m.Keys(); len(keys) != 1 || keys[0] != "bob" {
+        t.Errorf("Unexpected keys: %v", keys)
+    }
+}
+
+func Test_ORMap_Merge(t *testing.T) {
+    d := newTestMapDatabase()
+    a := newTestMap(d)
+    b := newTestMap(d)
+
+    a.Put("alice", GROWONLYSET_RESOURCE_TYPE, gsetState(t, "admin"))
+    b.Put("alice", GROWONLYSET_RESOURCE_TYPE, gsetState(t, "user"))
+    b.Put("bob", GROWONLYSET_RESOURCE_TYPE, gsetState(t, "user"))
+
+    if e := a.Merge(b); e != nil { t.Fatal(e) }
+    if e := b.Merge(a); e != nil { t.Fatal(e) }
+
+    if equal, _ := a.Equals(b); !equal {
+        t.Error("Merged maps should be equal!")
+    }
+
+    if n := gsetLength(t, a, "alice"); n != 2 {
+        t.Errorf("Expected 2 elements, got %d", n)
+    }
+
+    // A put concurrent with a remove survives it.
+    a.Remove("alice")
+    b.Put("alice", GROWONLYSET_RESOURCE_TYPE, gsetState(t, "guest"))
+    a.Remove("bob")
+
+    a.Merge(b)
+
+    if keys := a.Keys(); len(keys) != 1 || keys[0] != "alice" {
+        t.Errorf("Unexpected keys: %v", keys)
+    }
+}
+
+// Replicas which merged a value before and after its key was removed converge.
+func Test_ORMap_RemoveConverges(t *testing.T) {
+    d := newTestMapDatabase()
+    a := newTestMap(d)
+    b := newTestMap(d)
+    c := newTestMap(d)
+
+    a.Put("alice", GROWONLYSET_RESOURCE_TYPE, gsetState(t, "admin"))
+    b.Merge(a)
+    a.Remove("alice")
+    c.Put("alice", GROWONLYSET_RESOURCE_TYPE, gsetState(t, "user"))
+
+    a.Merge(b)
+    a.Merge(c)
+    b.Merge(a)
+
+    if equal, _ := a.Equals(b); !equal {
+        t.Error("Merged maps should be equal!")
+    }
+
+    if n := gsetLength(t, a, "alice"); n != 1 || !gsetContains(t, a, "alice", "user") {
+        t.Errorf("Expected only the concurrent put to survive, got %d elements", n)
+    }
+}
+
+// A key put again after its removal does not merge into its old value.
+func Test_ORMap_PutAfterRemove(t *testing.T) {
+    d := newTestMapDatabase()
+    a := newTestMap(d)
+    b := newTestMap(d)
+
+    a.Put("alice", GROWONLYSET_RESOURCE_TYPE, gsetState(t, "admin"))
+    b.Merge(a)
+
+    if e := a.Remove("alice"); e != nil { t.Fatal(e) }
+    if e := a.Put("alice", GROWONLYSET_RESOURCE_TYPE, gsetState(t, "user")); e != nil { t.Fatal(e) }
+
+    if n := gsetLength(t, a, "alice"); n != 1 || !gsetContains(t, a, "alice", "user") {
+        t.Errorf("Expected only the new value, got %d elements", n)
+    }
+
+    // A removed key may take a value of another type.
+    a.Remove("alice")
+    if e := a.Put("alice", ORMAP_RESOURCE_TYPE, nil); e != nil { t.Fatal(e) }
+
+    a.Merge(b)
+    b.Merge(a)
+
+    if equal, _ := a.Equals(b); !equal {
+        t.Error("Merged maps should be equal!")
+    }
+
+    if resourceType, _, e := b.Get("alice"); e != nil || resourceType != ORMAP_RESOURCE_TYPE {
+        t.Errorf("Expected the latest value to survive, got %s (%v)", resourceType, e)
+    }
+}
+
+// Keys removed or put on another replica are found once merged.
+func Test_ORMap_KeysFollowMerge(t *testing.T) {
+    d := newTestMapDatabase()
+    a := newTestMap(d)
+    b := newTestMap(d)
+
+    a.Put("alice", GROWONLYSET_RESOURCE_TYPE, gsetState(t, "admin"))
+    a.Put("bob", GROWONLYSET_RESOURCE_TYPE, gsetState(t, "user"))
+    b.Merge(a)
+
+    if e := b.Remove("bob"); e != nil { t.Fatal(e) }
+    b.Put("carol", GROWONLYSET_RESOURCE_TYPE, gsetState(t, "user"))
+    a.Merge(b)
+
+    if keys := a.Keys(); len(keys) != 2 || keys[0] != "alice" || keys[1] != "carol" {
+        t.Errorf("Unexpected keys after merge: %v", keys)
+    }
+
+    if _, _, e := a.Get("bob"); e != E_UNKNOWN_KEY {
+        t.Errorf("Expected removed key to be unknown, got %v", e)
+    }
+
+    if !gsetContains(t, a, "carol", "user") {
+        t.Error("Expected merged key to be found!")
+    }
+}
+
+func Test_ORMap_Serialize(t *testing.T) {
+    d := newTestMapDatabase()
+    a := newTestMap(d)
+
+    nested := newTestMap(d)
+    nested.Put("roles", GROWONLYSET_RESOURCE_TYPE, gsetState(t, "admin"))
+
+    buff := new(bytes.Buffer)
+    if e := nested.Serialize(buff); e != nil { t.Fatal(e) }
+
+    a.Put("alice", ORMAP_RESOURCE_TYPE, buff.Bytes())
+    a.Put("bob", GROWONLYSET_RESOURCE_TYPE, gsetState(t, "user"))
+
+    buff.Reset()
+    if e := a.Serialize(buff); e != nil { t.Fatal(e) }
+
+    b, e := NewORMapResourceType(d).Restore(a.Id(), a.Key(), buff)
+    if e != nil { t.Fatal(e) }
+
+    if equal, e := a.Equals(b.(*ORMapResource)); !equal || e != nil {
+        t.Errorf("Restored map should equal original! (%v)", e)
+    }
+
+    if e := b.Deserialize(bytes.NewBufferString("crdt:gset")); e == nil {
+        t.Error("Expected header error!")
+    }
+}
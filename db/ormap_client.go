@@ -0,0 +1,89 @@
+/*
+ * Copyright (c) 2015 Tom Swindell (t.swindell@rubyx.co.uk)
+ *
+ * Permission is hereby granted, free of charge, to any person obtaining a copy
+ * of this software and associated documentation files (the "Software"), to deal
+ * in the Software without restriction, including without limitation the rights
+ * to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+ * copies of the Software, and to permit persons to whom the Software is
+ * furnished to do so, subject to the following conditions:
+ *
+ * The above copyright notice and this permission notice shall be included in
+ * all copies or substantial portions of the Software.
+ *
+ * THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+ * IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+ * FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+ * AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+ * LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+ * OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+ * SOFTWARE.
+ *
+ */
+
+package crdb
+
+import (
+    "fmt"
+
+    "google.golang.org/grpc"
+    "golang.org/x/net/context"
+
+    pb "github.com/tswindell/go-crdt/protos"
+)
+
+type ORMapClient struct {
+    pb.ObserveRemoveMapClient
+}
+
+func NewORMapClient(connection *grpc.ClientConn) *ORMapClient {
+    d := new(ORMapClient)
+    d.ObserveRemoveMapClient = pb.NewObserveRemoveMapClient(connection)
+    return d
+}
+
+// ORMap API extensions to CRDB Client type
+func (d *ORMapClient) Put(referenceId ReferenceId, key string, resourceType ResourceType, object []byte) error {
+    r, e := d.ObserveRemoveMapClient.Put(context.Background(),
+                                         &pb.MapPutRequest{
+                                             ReferenceId: string(referenceId),
+                                             Key: key,
+                                             ResourceType: string(resourceType),
+                                             Object: object,
+                                         })
+    if e != nil { return e }
+    if !r.Status.Success { return fmt.Errorf(r.Status.ErrorType) }
+    return nil
+}
+
+func (d *ORMapClient) Get(referenceId ReferenceId, key string) (ResourceType, []byte, error) {
+    r, e := d.ObserveRemoveMapClient.Get(context.Background(),
+                                         &pb.MapGetRequest{
+                                             ReferenceId: string(referenceId),
+                                             Key: key,
+                                         })
+    if e != nil { return ResourceType(""), nil, e }
+    if !r.Status.Success { return ResourceType(""), nil, fmt.Errorf(r.Status.ErrorType) }
+    return ResourceType(r.ResourceType), r.Object, nil
+}
+
+func (d *ORMapClient) Remove(referenceId ReferenceId, key string) error {
+    r, e := d.ObserveRemoveMapClient.Remove(context.Background(),
+                                            &pb.MapRemoveRequest{
+                                                ReferenceId: string(referenceId),
+                                                Key: key,
+                                            })
+    if e != nil { return e }
+    if !r.Status.Success { return fmt.Errorf(r.Status.ErrorType) }
+    return nil
+}
+
+func (d *ORMapClient) Keys(referenceId ReferenceId) ([]string, error) {
+    r, e := d.ObserveRemoveMapClient.Keys(context.Background(),
+                                          &pb.MapKeysRequest{
+                                              ReferenceId: string(referenceId),
+                                          })
+    if e != nil { return nil, e }
+    if !r.Status.Success { return nil, fmt.Errorf(r.Status.ErrorType) }
+    return r.Keys, nil
+}
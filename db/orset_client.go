@@ -0,0 +1,173 @@
+/*
+ * Copyright (c) 2015 Tom Swindell (t.swindell@rubyx.co.uk)
+ *
+ * Permission is hereby granted, free of charge, to any person obtaining a copy
+ * of this software and associated documentation files (the "Software"), to deal
+ * in the Software without restriction, including without limitation the rights
+ * to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+ * copies of the Software, and to permit persons to whom the Software is
+ * furnished to do so, subject to the following conditions:
+ *
+ * The above copyright notice and this permission notice shall be included in
+ * all copies or substantial portions of the Software.
+ *
+ * THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+ * IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+ * FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+ * AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+ * LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+ * OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+ * SOFTWARE.
+ *
+ */
+
+package crdb
+
+import (
+    "fmt"
+    "io"
+
+    "google.golang.org/grpc"
+    "golang.org/x/net/context"
+
+    pb "github.com/tswindell/go-crdt/protos"
+)
+
+type ORSetClient struct {
+    pb.ObserveRemoveSetClient
+}
+
+func NewORSetClient(connection *grpc.ClientConn) *ORSetClient {
+    d := new(ORSetClient)
+    d.ObserveRemoveSetClient = pb.NewObserveRemoveSetClient(connection)
+    return d
+}
+
+// ORSet API extensions to CRDB Client type
+func (d *ORSetClient) List(referenceId ReferenceId) (chan []byte, error) {
+    r, e := d.ObserveRemoveSetClient.List(context.Background(),
+                                          &pb.SetListRequest{
+                                              ReferenceId: string(referenceId),
+                                          })
+    if e != nil { return nil, nil }
+
+    ch := make(chan []byte) //TODO: Make buffered?
+    go func() {
+        for {
+            object, e := r.Recv()
+            if e == io.EOF { break }
+            ch<- object.Object
+        }
+        close(ch)
+    }()
+
+    return ch, nil
+}
+
+func (d *ORSetClient) Insert(referenceId ReferenceId, object []byte) error {
+    return d.InsertWithLabels(referenceId, object, nil)
+}
+
+// The InsertWithLabels() method inserts object, recording labels in its
+// metadata along with the time and the replica serving the insert.
+func (d *ORSetClient) InsertWithLabels(referenceId ReferenceId, object []byte, labels map[string]string) error {
+    r, e := d.ObserveRemoveSetClient.Insert(context.Background(),
+                                            &pb.SetInsertRequest{
+                                                Object: &pb.ResourceObject{
+                                                    ReferenceId: string(referenceId),
+                                                    Object: object,
+                                                },
+                                                Labels: labels,
+                                            })
+    if e != nil { return e }
+    if !r.Status.Success { return fmt.Errorf(r.Status.ErrorType) }
+    return nil
+}
+
+func (d *ORSetClient) Remove(referenceId ReferenceId, object []byte) error {
+    r, e := d.ObserveRemoveSetClient.Remove(context.Background(),
+                                            &pb.SetRemoveRequest{
+                                                Object: &pb.ResourceObject{
+                                                    ReferenceId: string(referenceId),
+                                                    Object: object,
+                                                }})
+    if e != nil { return e }
+    if !r.Status.Success { return fmt.Errorf(r.Status.ErrorType) }
+    return nil
+}
+
+func (d *ORSetClient) Length(referenceId ReferenceId) (uint64, error) {
+    r, e := d.ObserveRemoveSetClient.Length(context.Background(),
+                                            &pb.SetLengthRequest{
+                                                ReferenceId: string(referenceId),
+                                            })
+    if e != nil { return 0, e }
+    if !r.Status.Success { return 0, fmt.Errorf(r.Status.ErrorType) }
+    return r.Length, nil
+}
+
+func (d *ORSetClient) Contains(referenceId ReferenceId, object []byte) (bool, error) {
+    r, e := d.ObserveRemoveSetClient.Contains(context.Background(),
+                                              &pb.SetContainsRequest{
+                                                  Object: &pb.ResourceObject{
+                                                      ReferenceId: string(referenceId),
+                                                      Object: object,
+                                                  }})
+    if e != nil { return false, e }
+    if !r.Status.Success { return false, fmt.Errorf(r.Status.ErrorType) }
+    return r.Result, nil
+}
+
+
+// The Union(), Intersect() and Difference() methods stream the elements in
+// either, both, or only the first of the two sets. Operands may be set
+// resources of any type.
+func (d *ORSetClient) Union(aRef, bRef ReferenceId) (chan []byte, error) {
+    return recvSetAlgebra(d.ObserveRemoveSetClient.Union(context.Background(), setAlgebraRequest(aRef, bRef)))
+}
+
+func (d *ORSetClient) Intersect(aRef, bRef ReferenceId) (chan []byte, error) {
+    return recvSetAlgebra(d.ObserveRemoveSetClient.Intersect(context.Background(), setAlgebraRequest(aRef, bRef)))
+}
+
+func (d *ORSetClient) Difference(aRef, bRef ReferenceId) (chan []byte, error) {
+    return recvSetAlgebra(d.ObserveRemoveSetClient.Difference(context.Background(), setAlgebraRequest(aRef, bRef)))
+}
+
+// The UnionInto(), IntersectInto() and DifferenceInto() methods materialise
+// the result into a new resource of the type of aRef, which must then be
+// attached and committed like any other created resource.
+func (d *ORSetClient) UnionInto(aRef, bRef ReferenceId, storageId, cryptoId string) (ResourceId, ResourceKey, error) {
+    ctx, cancel := context.WithCancel(context.Background())
+    defer cancel()
+    return recvSetAlgebraInto(d.ObserveRemoveSetClient.Union(ctx, setAlgebraIntoRequest(aRef, bRef, storageId, cryptoId)))
+}
+
+func (d *ORSetClient) IntersectInto(aRef, bRef ReferenceId, storageId, cryptoId string) (ResourceId, ResourceKey, error) {
+    ctx, cancel := context.WithCancel(context.Background())
+    defer cancel()
+    return recvSetAlgebraInto(d.ObserveRemoveSetClient.Intersect(ctx, setAlgebraIntoRequest(aRef, bRef, storageId, cryptoId)))
+}
+
+func (d *ORSetClient) DifferenceInto(aRef, bRef ReferenceId, storageId, cryptoId string) (ResourceId, ResourceKey, error) {
+    ctx, cancel := context.WithCancel(context.Background())
+    defer cancel()
+    return recvSetAlgebraInto(d.ObserveRemoveSetClient.Difference(ctx, setAlgebraIntoRequest(aRef, bRef, storageId, cryptoId)))
+}
+
+func (d *ORSetClient) IsSubset(aRef, bRef ReferenceId) (bool, error) {
+    return recvSetIsSubset(d.ObserveRemoveSetClient.IsSubset(context.Background(),
+                                          &pb.SetIsSubsetRequest{
+                                              ReferenceId: string(aRef),
+                                              OtherReferenceId: string(bRef),
+                                          }))
+}
+
+// The ListWithMetadata() method streams the elements of the set along with
+// their metadata.
+func (d *ORSetClient) ListWithMetadata(referenceId ReferenceId) (chan SetElement, error) {
+    return recvSetListWithMetadata(d.ObserveRemoveSetClient.ListWithMetadata(context.Background(),
+                                          &pb.SetListRequest{
+                                              ReferenceId: string(referenceId),
+                                          }))
+}
@@ -0,0 +1,219 @@
+/*
+ * Copyright (c) 2015 Tom Swindell (t.swindell@rubyx.co.uk)
+ *
+ * Permission is hereby granted, free of charge, to any person obtaining a copy
+ * of this software and associated documentation files (the "Software"), to deal
+ * in the Software without restriction, including without limitation the rights
+ * to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+ * copies of the Software, and to permit persons to whom the Software is
+ * furnished to do so, subject to the following conditions:
+ *
+ * The above copyright notice and this permission notice shall be included in
+ * all copies or substantial portions of the Software.
+ *
+ * THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+ * IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+ * FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+ * AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+ * LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+ * OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+ * SOFTWARE.
+ *
+ */
+
+package crdb
+
+import (
+    "io"
+    "reflect"
+
+    "github.com/tswindell/go-crdt/registers"
+    "github.com/tswindell/go-crdt/sets"
+
+    "golang.org/x/net/context"
+    pb "github.com/tswindell/go-crdt/protos"
+)
+
+const (
+    LWWREGISTER_RESOURCE_TYPE = ResourceType("crdt:lwwreg")
+    MVREGISTER_RESOURCE_TYPE  = ResourceType("crdt:mvreg")
+)
+
+
+// Generic ``Register'' function interfaces
+type RegisterSetInterface      interface { Set(string, []byte) }
+type RegisterGetInterface      interface { Get() []byte        }
+type RegisterMultiGetInterface interface { Get() [][]byte      }
+
+
+// Generic ``Register'' resource type.
+type RegisterResource struct {
+    ResourceBase
+
+    context interface{} // Polymorphic reference to concrete data type instance.
+}
+
+func (d *RegisterResource) Serialize(w io.Writer) error {
+    return serializeBuffered(w, d.context.(SerializeInterface).Serialize)
+}
+
+func (d *RegisterResource) Deserialize(r io.Reader) error {
+    return deserializeBuffered(r, d.context.(SerializeInterface).Deserialize)
+}
+
+
+// The NewLWWRegisterResourceFactory function returns a ResourceFactoryFunc
+// creating LWW registers timestamped by clock.
+func NewLWWRegisterResourceFactory(clock set.Clock) ResourceFactoryFunc {
+    return func(resourceId ResourceId, resourceKey ResourceKey) Resource {
+        return &RegisterResource{
+                   ResourceBase{resourceId, resourceKey, LWWREGISTER_RESOURCE_TYPE},
+                   register.NewLWWRegister(clock),
+               }
+    }
+}
+
+// The NewMVRegisterResource function adheres to ResourceFactoryFunc prototype.
+func NewMVRegisterResource(resourceId ResourceId, resourceKey ResourceKey) Resource {
+    return &RegisterResource{
+               ResourceBase{resourceId, resourceKey, MVREGISTER_RESOURCE_TYPE},
+               register.NewMVRegister(),
+           }
+}
+
+
+// The RegisterResourceType type
+type RegisterResourceType struct {
+    database *Database
+    typeId    ResourceType
+    factory   ResourceFactoryFunc
+}
+
+func NewRegisterResourceType(database *Database,
+                             typeId ResourceType,
+                             factory ResourceFactoryFunc) *RegisterResourceType {
+
+    return &RegisterResourceType{database, typeId, factory}
+}
+
+func (d *RegisterResourceType) TypeId() ResourceType { return d.typeId }
+
+func (d *RegisterResourceType) Create(resourceId ResourceId, resourceKey ResourceKey) Resource {
+    return d.factory(resourceId, resourceKey)
+}
+
+func (d *RegisterResourceType) Equals(aResource, bResource Resource) (bool, error) {
+    aRegister := aResource.(*RegisterResource).context
+    bRegister := bResource.(*RegisterResource).context
+
+    return reflect.ValueOf(aRegister).MethodByName("Equals").Call([]reflect.Value{reflect.ValueOf(bRegister)})[0].Bool(), nil
+}
+
+func (d *RegisterResourceType) Merge(aResource, bResource Resource) error {
+    aRegister := aResource.(*RegisterResource).context
+    bRegister := bResource.(*RegisterResource).context
+
+    reflect.ValueOf(aRegister).MethodByName("Merge").Call([]reflect.Value{reflect.ValueOf(bRegister)})
+
+    return nil
+}
+
+func (d *RegisterResourceType) Clone(resource Resource) (Resource, error) {
+    newResource, e := d.database.Create(resource.Type(),
+                                        resource.Id().GetStorageId(),
+                                        resource.Key().TypeId())
+    if e != nil { return nil, e }
+
+    c := resource.(*RegisterResource).context
+    newContext := reflect.ValueOf(c).MethodByName("Clone").Call([]reflect.Value{})[0].Interface()
+    newResource.(*RegisterResource).context = newContext
+    return newResource, nil
+}
+
+func (d *RegisterResourceType) Restore(resourceId ResourceId, resourceKey ResourceKey, r io.Reader) (Resource, error) {
+    resource := d.factory(resourceId, resourceKey)
+    if e := resource.Deserialize(r); e != nil { return nil, e }
+    return resource, nil
+}
+
+
+// The RegisterResourceService type
+type RegisterResourceService struct {
+    database *Database
+}
+
+// Resolves referenceId to the concrete register resource.
+func (d *RegisterResourceService) resolve(referenceId ReferenceId) (*RegisterResource, error) {
+    r, e := d.database.Resolve(referenceId)
+    if e != nil { return nil, e }
+
+    resource, ok := r.(*RegisterResource)
+    if !ok { return nil, E_TYPE_MISMATCH }
+
+    return resource, nil
+}
+
+// The Set() service method
+func (d *RegisterResourceService) Set(ctx context.Context, m *pb.RegisterSetRequest) (*pb.RegisterSetResponse, error) {
+    r, e := d.resolve(ReferenceId(m.Object.ReferenceId))
+    if e != nil {
+        return &pb.RegisterSetResponse{Status:&pb.Status{Success:false,ErrorType:e.Error()}}, nil
+    }
+
+    r.context.(RegisterSetInterface).Set(d.database.ReplicaId(), m.Object.Object)
+    go func() { d.database.Notify(r.Id(), 0, m.Object.Object) }()
+
+    return &pb.RegisterSetResponse{Status:&pb.Status{Success:true}}, nil
+}
+
+
+// Concrete implementation for LWWRegister Get ( ... )
+type LastWriterWinsRegisterService struct {RegisterResourceService}
+
+func NewLastWriterWinsRegisterService(database *Database) *LastWriterWinsRegisterService {
+    return &LastWriterWinsRegisterService{RegisterResourceService{database}}
+}
+
+// The Get() service method
+func (d *LastWriterWinsRegisterService) Get(ctx context.Context, m *pb.RegisterGetRequest) (*pb.RegisterGetResponse, error) {
+    r, e := d.resolve(ReferenceId(m.ReferenceId))
+    if e != nil {
+        return &pb.RegisterGetResponse{Status:&pb.Status{Success:false,ErrorType:e.Error()}}, nil
+    }
+
+    register, ok := r.context.(RegisterGetInterface)
+    if !ok {
+        return &pb.RegisterGetResponse{Status:&pb.Status{Success:false,ErrorType:E_TYPE_MISMATCH.Error()}}, nil
+    }
+
+    return &pb.RegisterGetResponse{
+               Status: &pb.Status{Success:true},
+               Object: register.Get(),
+           }, nil
+}
+
+
+// Concrete implementation for MVRegister Get ( ... )
+type MultiValueRegisterService struct {RegisterResourceService}
+
+func NewMultiValueRegisterService(database *Database) *MultiValueRegisterService {
+    return &MultiValueRegisterService{RegisterResourceService{database}}
+}
+
+// The Get() service method
+func (d *MultiValueRegisterService) Get(ctx context.Context, m *pb.RegisterGetRequest) (*pb.MultiValueRegisterGetResponse, error) {
+    r, e := d.resolve(ReferenceId(m.ReferenceId))
+    if e != nil {
+        return &pb.MultiValueRegisterGetResponse{Status:&pb.Status{Success:false,ErrorType:e.Error()}}, nil
+    }
+
+    register, ok := r.context.(RegisterMultiGetInterface)
+    if !ok {
+        return &pb.MultiValueRegisterGetResponse{Status:&pb.Status{Success:false,ErrorType:E_TYPE_MISMATCH.Error()}}, nil
+    }
+
+    return &pb.MultiValueRegisterGetResponse{
+               Status: &pb.Status{Success:true},
+               Objects: register.Get(),
+           }, nil
+}
@@ -3,6 +3,7 @@ package crdb
 import (
     "bytes"
     "encoding/base64"
+    "io"
     "strings"
 )
 
@@ -72,8 +73,27 @@ type Resource interface {
     Key() ResourceKey
     Type() ResourceType
 
-    Serialize(*bytes.Buffer) error
-    Deserialize(*bytes.Buffer) error
+    Serialize(io.Writer) error
+    Deserialize(io.Reader) error
+}
+
+// Serializes through a buffer, for resource types whose state can only be
+// serialized to one.
+func serializeBuffered(w io.Writer, serialize func(*bytes.Buffer) error) error {
+    buff := new(bytes.Buffer)
+    if e := serialize(buff); e != nil { return e }
+
+    _, e := w.Write(buff.Bytes())
+    return e
+}
+
+// Deserializes all of r through a buffer, for resource types whose state can
+// only be deserialized from one.
+func deserializeBuffered(r io.Reader, deserialize func(*bytes.Buffer) error) error {
+    data, e := io.ReadAll(r)
+    if e != nil { return e }
+
+    return deserialize(bytes.NewBuffer(data))
 }
 
 
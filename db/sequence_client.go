@@ -0,0 +1,97 @@
+/*
+ * Copyright (c) 2015 Tom Swindell (t.swindell@rubyx.co.uk)
+ *
+ * Permission is hereby granted, free of charge, to any person obtaining a copy
+ * of this software and associated documentation files (the "Software"), to deal
+ * in the Software without restriction, including without limitation the rights
+ * to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+ * copies of the Software, and to permit persons to whom the Software is
+ * furnished to do so, subject to the following conditions:
+ *
+ * The above copyright notice and this permission notice shall be included in
+ * all copies or substantial portions of the Software.
+ *
+ * THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+ * IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+ * FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+ * AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+ * LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+ * OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+ * SOFTWARE.
+ *
+ */
+
+package crdb
+
+import (
+    "fmt"
+
+    "google.golang.org/grpc"
+    "golang.org/x/net/context"
+
+    pb "github.com/tswindell/go-crdt/protos"
+)
+
+type SequenceClient struct {
+    pb.SequenceClient
+}
+
+func NewSequenceClient(connection *grpc.ClientConn) *SequenceClient {
+    d := new(SequenceClient)
+    d.SequenceClient = pb.NewSequenceClient(connection)
+    return d
+}
+
+// Sequence API extensions to CRDB Client type
+func (d *SequenceClient) InsertAt(referenceId ReferenceId, position uint32, objects ...[]byte) error {
+    r, e := d.SequenceClient.InsertAt(context.Background(),
+                                      &pb.SequenceInsertRequest{
+                                          ReferenceId: string(referenceId),
+                                          Position: position,
+                                          Objects: objects,
+                                      })
+    if e != nil { return e }
+    if !r.Status.Success { return fmt.Errorf(r.Status.ErrorType) }
+    return nil
+}
+
+// The InsertText() method inserts text at position, one element per character.
+func (d *SequenceClient) InsertText(referenceId ReferenceId, position uint32, text string) error {
+    objects := make([][]byte, 0, len(text))
+    for _, c := range text { objects = append(objects, []byte(string(c))) }
+    return d.InsertAt(referenceId, position, objects...)
+}
+
+func (d *SequenceClient) DeleteAt(referenceId ReferenceId, position uint32, length uint32) error {
+    r, e := d.SequenceClient.DeleteAt(context.Background(),
+                                      &pb.SequenceDeleteRequest{
+                                          ReferenceId: string(referenceId),
+                                          Position: position,
+                                          Length: length,
+                                      })
+    if e != nil { return e }
+    if !r.Status.Success { return fmt.Errorf(r.Status.ErrorType) }
+    return nil
+}
+
+func (d *SequenceClient) Slice(referenceId ReferenceId, start uint32, end uint32) ([][]byte, error) {
+    r, e := d.SequenceClient.Slice(context.Background(),
+                                   &pb.SequenceSliceRequest{
+                                       ReferenceId: string(referenceId),
+                                       Start: start,
+                                       End: end,
+                                   })
+    if e != nil { return nil, e }
+    if !r.Status.Success { return nil, fmt.Errorf(r.Status.ErrorType) }
+    return r.Objects, nil
+}
+
+func (d *SequenceClient) Text(referenceId ReferenceId) (string, error) {
+    r, e := d.SequenceClient.Text(context.Background(),
+                                  &pb.SequenceTextRequest{
+                                      ReferenceId: string(referenceId),
+                                  })
+    if e != nil { return "", e }
+    if !r.Status.Success { return "", fmt.Errorf(r.Status.ErrorType) }
+    return r.Text, nil
+}
@@ -0,0 +1,227 @@
+/*
+ * Copyright (c) 2015 Tom Swindell (t.swindell@rubyx.co.uk)
+ *
+ * Permission is hereby granted, free of charge, to any person obtaining a copy
+ * of this software and associated documentation files (the "Software"), to deal
+ * in the Software without restriction, including without limitation the rights
+ * to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+ * copies of the Software, and to permit persons to whom the Software is
+ * furnished to do so, subject to the following conditions:
+ *
+ * The above copyright notice and this permission notice shall be included in
+ * all copies or substantial portions of the Software.
+ *
+ * THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+ * IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+ * FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+ * AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+ * LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+ * OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+ * SOFTWARE.
+ *
+ */
+
+package crdb
+
+import (
+    "io"
+    "fmt"
+    "reflect"
+
+    "github.com/tswindell/go-crdt/sequence"
+
+    "golang.org/x/net/context"
+    pb "github.com/tswindell/go-crdt/protos"
+)
+
+const (
+    RGA_RESOURCE_TYPE = ResourceType("crdt:rga")
+)
+
+var (
+    E_INVALID_POSITION = fmt.Errorf("crdt:error-invalid-position")
+)
+
+
+// Generic ``Sequence'' function interfaces
+type SequenceInsertInterface interface { InsertAt(string, int, ...[]byte) bool }
+type SequenceDeleteInterface interface { DeleteAt(int, int) ([][]byte, bool)  }
+type SequenceLengthInterface interface { Length() int                         }
+type SequenceSliceInterface  interface { Slice(int, int) ([][]byte, bool)     }
+type SequenceTextInterface   interface { Text() string                        }
+
+
+// Generic ``Sequence'' resource type.
+type SequenceResource struct {
+    ResourceBase
+
+    context interface{} // Polymorphic reference to concrete data type instance.
+}
+
+func (d *SequenceResource) Serialize(w io.Writer) error {
+    return serializeBuffered(w, d.context.(SerializeInterface).Serialize)
+}
+
+func (d *SequenceResource) Deserialize(r io.Reader) error {
+    return deserializeBuffered(r, d.context.(SerializeInterface).Deserialize)
+}
+
+
+// The NewRGAResource function adheres to ResourceFactoryFunc prototype.
+func NewRGAResource(resourceId ResourceId, resourceKey ResourceKey) Resource {
+    return &SequenceResource{
+               ResourceBase{resourceId, resourceKey, RGA_RESOURCE_TYPE},
+               sequence.NewRGA(),
+           }
+}
+
+
+// The SequenceResourceType type
+type SequenceResourceType struct {
+    database *Database
+    typeId    ResourceType
+    factory   ResourceFactoryFunc
+}
+
+func NewSequenceResourceType(database *Database,
+                             typeId ResourceType,
+                             factory ResourceFactoryFunc) *SequenceResourceType {
+
+    return &SequenceResourceType{database, typeId, factory}
+}
+
+func (d *SequenceResourceType) TypeId() ResourceType { return d.typeId }
+
+func (d *SequenceResourceType) Create(resourceId ResourceId, resourceKey ResourceKey) Resource {
+    return d.factory(resourceId, resourceKey)
+}
+
+func (d *SequenceResourceType) Equals(aResource, bResource Resource) (bool, error) {
+    aSequence := aResource.(*SequenceResource).context
+    bSequence := bResource.(*SequenceResource).context
+
+    return reflect.ValueOf(aSequence).MethodByName("Equals").Call([]reflect.Value{reflect.ValueOf(bSequence)})[0].Bool(), nil
+}
+
+func (d *SequenceResourceType) Merge(aResource, bResource Resource) error {
+    aSequence := aResource.(*SequenceResource).context
+    bSequence := bResource.(*SequenceResource).context
+
+    reflect.ValueOf(aSequence).MethodByName("Merge").Call([]reflect.Value{reflect.ValueOf(bSequence)})
+
+    return nil
+}
+
+func (d *SequenceResourceType) Clone(resource Resource) (Resource, error) {
+    newResource, e := d.database.Create(resource.Type(),
+                                        resource.Id().GetStorageId(),
+                                        resource.Key().TypeId())
+    if e != nil { return nil, e }
+
+    c := resource.(*SequenceResource).context
+    newContext := reflect.ValueOf(c).MethodByName("Clone").Call([]reflect.Value{})[0].Interface()
+    newResource.(*SequenceResource).context = newContext
+    return newResource, nil
+}
+
+func (d *SequenceResourceType) Restore(resourceId ResourceId, resourceKey ResourceKey, r io.Reader) (Resource, error) {
+    resource := d.factory(resourceId, resourceKey)
+    if e := resource.Deserialize(r); e != nil { return nil, e }
+    return resource, nil
+}
+
+
+// The SequenceResourceService type
+type SequenceResourceService struct {
+    database *Database
+}
+
+func NewSequenceResourceService(database *Database) *SequenceResourceService {
+    return &SequenceResourceService{database}
+}
+
+// Resolves referenceId to the concrete sequence resource.
+func (d *SequenceResourceService) resolve(referenceId ReferenceId) (*SequenceResource, error) {
+    r, e := d.database.Resolve(referenceId)
+    if e != nil { return nil, e }
+
+    resource, ok := r.(*SequenceResource)
+    if !ok { return nil, E_TYPE_MISMATCH }
+
+    return resource, nil
+}
+
+// The InsertAt() service method
+func (d *SequenceResourceService) InsertAt(ctx context.Context, m *pb.SequenceInsertRequest) (*pb.SequenceInsertResponse, error) {
+    r, e := d.resolve(ReferenceId(m.ReferenceId))
+    if e != nil {
+        return &pb.SequenceInsertResponse{Status:&pb.Status{Success:false,ErrorType:e.Error()}}, nil
+    }
+
+    position := int(m.Position)
+    if !r.context.(SequenceInsertInterface).InsertAt(d.database.ReplicaId(), position, m.Objects...) {
+        return &pb.SequenceInsertResponse{Status:&pb.Status{Success:false,ErrorType:E_INVALID_POSITION.Error()}}, nil
+    }
+
+    go func() {
+        for i, object := range m.Objects { d.database.NotifyAt(r.Id(), 0, position + i, object) }
+    }()
+
+    return &pb.SequenceInsertResponse{Status:&pb.Status{Success:true}}, nil
+}
+
+// The DeleteAt() service method
+func (d *SequenceResourceService) DeleteAt(ctx context.Context, m *pb.SequenceDeleteRequest) (*pb.SequenceDeleteResponse, error) {
+    r, e := d.resolve(ReferenceId(m.ReferenceId))
+    if e != nil {
+        return &pb.SequenceDeleteResponse{Status:&pb.Status{Success:false,ErrorType:e.Error()}}, nil
+    }
+
+    position := int(m.Position)
+    objects, ok := r.context.(SequenceDeleteInterface).DeleteAt(position, int(m.Length))
+    if !ok {
+        return &pb.SequenceDeleteResponse{Status:&pb.Status{Success:false,ErrorType:E_INVALID_POSITION.Error()}}, nil
+    }
+
+    // Each deletion shifts the following elements down, so all are reported
+    // at the starting position.
+    go func() {
+        for _, object := range objects { d.database.NotifyAt(r.Id(), 1, position, object) }
+    }()
+
+    return &pb.SequenceDeleteResponse{Status:&pb.Status{Success:true}}, nil
+}
+
+// The Slice() service method
+func (d *SequenceResourceService) Slice(ctx context.Context, m *pb.SequenceSliceRequest) (*pb.SequenceSliceResponse, error) {
+    r, e := d.resolve(ReferenceId(m.ReferenceId))
+    if e != nil {
+        return &pb.SequenceSliceResponse{Status:&pb.Status{Success:false,ErrorType:e.Error()}}, nil
+    }
+
+    end := int(m.End)
+    if end == 0 { end = r.context.(SequenceLengthInterface).Length() }
+
+    objects, ok := r.context.(SequenceSliceInterface).Slice(int(m.Start), end)
+    if !ok {
+        return &pb.SequenceSliceResponse{Status:&pb.Status{Success:false,ErrorType:E_INVALID_POSITION.Error()}}, nil
+    }
+
+    return &pb.SequenceSliceResponse{
+               Status: &pb.Status{Success:true},
+               Objects: objects,
+           }, nil
+}
+
+// The Text() service method
+func (d *SequenceResourceService) Text(ctx context.Context, m *pb.SequenceTextRequest) (*pb.SequenceTextResponse, error) {
+    r, e := d.resolve(ReferenceId(m.ReferenceId))
+    if e != nil {
+        return &pb.SequenceTextResponse{Status:&pb.Status{Success:false,ErrorType:e.Error()}}, nil
+    }
+
+    return &pb.SequenceTextResponse{
+               Status: &pb.Status{Success:true},
+               Text: r.context.(SequenceTextInterface).Text(),
+           }, nil
+}
@@ -29,15 +29,19 @@ import (
     "os/user"
     "path"
     "strconv"
+    "sync"
 
     "google.golang.org/grpc"
     "golang.org/x/net/context"
 
+    "github.com/tswindell/go-crdt/sets"
     pb "github.com/tswindell/go-crdt/protos"
 )
 
 // The Server type is a concrete implementation of a CRDT network service.
 type Server struct {
+    sync.RWMutex // Guards the listener, which is bound while requests are served.
+
     listener *net.Listener
     service  *grpc.Server
 
@@ -50,10 +54,78 @@ type Server struct {
 
 // Returns host addres server bound to.
 func (d *Server) HostAddr() string {
+    d.RLock()
+    defer d.RUnlock()
+
     listener := d.listener
     return (*listener).Addr().String()
 }
 
+// The RegisterResourceTypes() function registers every resource type a server
+// provides with database. Types ordering writes by time, such as the LWW set
+// and register, read the time from clock. Sets kept on disk keep their files in
+// dir.
+func RegisterResourceTypes(database *Database, clock set.Clock, dir string) {
+    database.RegisterType(NewSetResourceType(database,
+                                             GROWONLYSET_RESOURCE_TYPE,
+                                             NewGSetResource))
+
+    database.RegisterType(NewSetResourceType(database,
+                                             TWOPHASESET_RESOURCE_TYPE,
+                                             New2PSetResource))
+
+    database.RegisterType(NewSetResourceType(database,
+                                             ORSET_RESOURCE_TYPE,
+                                             NewORSetResource))
+
+    database.RegisterType(NewSetResourceType(database,
+                                             LWWSET_RESOURCE_TYPE,
+                                             NewLWWSetResourceFactory(clock,
+                                                                      set.LWW_ADD_WINS)))
+
+    database.RegisterType(NewSetResourceType(database,
+                                             DISKGSET_RESOURCE_TYPE,
+                                             NewDiskGSetResourceFactory(dir)))
+
+    database.RegisterType(NewCounterResourceType(database,
+                                                 GROWONLYCOUNTER_RESOURCE_TYPE,
+                                                 NewGCounterResource))
+
+    database.RegisterType(NewCounterResourceType(database,
+                                                 PNCOUNTER_RESOURCE_TYPE,
+                                                 NewPNCounterResource))
+
+    database.RegisterType(NewCounterResourceType(database,
+                                                 BCOUNTER_RESOURCE_TYPE,
+                                                 NewBCounterResource))
+
+    database.RegisterType(NewRegisterResourceType(database,
+                                                  LWWREGISTER_RESOURCE_TYPE,
+                                                  NewLWWRegisterResourceFactory(clock)))
+
+    database.RegisterType(NewRegisterResourceType(database,
+                                                  MVREGISTER_RESOURCE_TYPE,
+                                                  NewMVRegisterResource))
+
+    database.RegisterType(NewORMapResourceType(database))
+
+    database.RegisterType(NewSequenceResourceType(database,
+                                                  RGA_RESOURCE_TYPE,
+                                                  NewRGAResource))
+
+    database.RegisterType(NewFlagResourceType(database,
+                                              EWFLAG_RESOURCE_TYPE,
+                                              NewEWFlagResource))
+
+    database.RegisterType(NewFlagResourceType(database,
+                                              DWFLAG_RESOURCE_TYPE,
+                                              NewDWFlagResource))
+
+    database.RegisterType(NewGraphResourceType(database))
+    database.RegisterType(NewTreeResourceType(database))
+    database.RegisterType(NewDocumentResourceType(database, clock))
+}
+
 // Returns a newly created Server instance.
 func NewServer() (*Server, error) {
     d := new(Server)
@@ -65,6 +137,8 @@ func NewServer() (*Server, error) {
     // Register persistent storage modules.
     u, e := user.Current()
     if e != nil { return nil, fmt.Errorf("Failed to get user") }
+    if e := d.database.LoadReplicaId(path.Join(u.HomeDir, ".crdb", "replica-id")); e != nil { return nil, e }
+    if e := d.database.LoadReplicas(path.Join(u.HomeDir, ".crdb", "replicas")); e != nil { return nil, e }
     filestore := NewFileStore(path.Join(u.HomeDir, ".crdb", "store"))
     d.database.RegisterStorage(filestore)
 
@@ -90,19 +164,30 @@ func NewServer() (*Server, error) {
     d.database.RegisterCryptoMethod(rsa4096)
 
     // Register resource data types.
-    d.database.RegisterType(NewSetResourceType(d.database,
-                                               GROWONLYSET_RESOURCE_TYPE,
-                                               NewGSetResource))
-
-    d.database.RegisterType(NewSetResourceType(d.database,
-                                               TWOPHASESET_RESOURCE_TYPE,
-                                               New2PSetResource))
+    RegisterResourceTypes(d.database, set.SystemClock, path.Join(u.HomeDir, ".crdb", "sets"))
 
     // Register this instance as a CRDT service on our listener.
     pb.RegisterCRDTServer(d.service, d)
 
     pb.RegisterGrowOnlySetServer(d.service, &GrowOnlySetService{SetResourceService{d.database}})
     pb.RegisterTwoPhaseSetServer(d.service, &TwoPhaseSetService{SetResourceService{d.database}})
+    pb.RegisterObserveRemoveSetServer(d.service, &ObserveRemoveSetService{SetResourceService{d.database}})
+    pb.RegisterLastWriterWinsSetServer(d.service, &LastWriterWinsSetService{SetResourceService{d.database}})
+
+    pb.RegisterCounterServer(d.service, NewCounterResourceService(d.database))
+
+    pb.RegisterLastWriterWinsRegisterServer(d.service, NewLastWriterWinsRegisterService(d.database))
+    pb.RegisterMultiValueRegisterServer(d.service, NewMultiValueRegisterService(d.database))
+
+    pb.RegisterObserveRemoveMapServer(d.service, NewObserveRemoveMapService(d.database))
+
+    pb.RegisterSequenceServer(d.service, NewSequenceResourceService(d.database))
+
+    pb.RegisterFlagServer(d.service, NewFlagResourceService(d.database))
+
+    pb.RegisterGraphServer(d.service, NewGraphResourceService(d.database))
+    pb.RegisterTreeServer(d.service, NewTreeResourceService(d.database))
+    pb.RegisterDocumentServer(d.service, NewDocumentResourceService(d.database))
     return d, nil
 }
 
@@ -110,17 +195,20 @@ func NewServer() (*Server, error) {
 func (d *Server) Listen(hostport string) error {
     listener, e := net.Listen("tcp", hostport)
     if e != nil { return e }
-    d.listener = &listener
 
-    hostname, port, e := __hostport_from_listener(d.listener)
+    hostname, port, e := __hostport_from_listener(&listener)
     if e != nil { return e }
+
+    d.Lock()
+    d.listener = &listener
     d.Hostname = hostname
     d.Port = port
+    d.Unlock()
 
     LogInfo("Listening on %s:%d\n", hostname, port)
 
     // Start serving requests.
-    return d.service.Serve(*d.listener)
+    return d.service.Serve(listener)
 }
 
 func __hostport_from_listener(listener *net.Listener) (string, int, error) {
@@ -206,6 +294,7 @@ func (d *Server) Subscribe(m *pb.SubscribeRequest, stream pb.CRDT_SubscribeServe
                 ReferenceId: string(referenceId),
                 Object: ev.Object,
             },
+            Position: int64(ev.Position),
         }
 
         if e := stream.Send(&event); e != nil {
@@ -256,6 +345,31 @@ func (d *Server) Clone(ctx context.Context, m *pb.CloneRequest) (*pb.CloneRespon
            }, nil
 }
 
+// The Compact() server method
+func (d *Server) Compact(ctx context.Context, m *pb.CompactRequest) (*pb.CompactResponse, error) {
+    count, reclaimed, e := d.database.Compact(ReferenceId(m.ReferenceId))
+    if e != nil {
+        return &pb.CompactResponse{Status:&pb.Status{Success:false, ErrorType:e.Error()}}, nil
+    }
+
+    LogInfo("CompactResponse: tombstones=%d bytes=%d", count, reclaimed)
+    return &pb.CompactResponse{
+               Status:&pb.Status{Success:true},
+               Tombstones: uint64(count),
+               Bytes: reclaimed,
+           }, nil
+}
+
+// The Acknowledge() server method
+func (d *Server) Acknowledge(ctx context.Context, m *pb.AcknowledgeRequest) (*pb.AcknowledgeResponse, error) {
+    e := d.database.Acknowledge(ReferenceId(m.ReferenceId), m.ReplicaId, m.Marker)
+    if e != nil {
+        return &pb.AcknowledgeResponse{Status:&pb.Status{Success:false, ErrorType:e.Error()}}, nil
+    }
+
+    return &pb.AcknowledgeResponse{Status:&pb.Status{Success:true}}, nil
+}
+
 // The Equals() server method
 func (d *Server) Equals(ctx context.Context, m *pb.EqualsRequest) (*pb.EqualsResponse, error) {
     aRef := ReferenceId(m.ReferenceId)
@@ -4,6 +4,7 @@ import "testing"
 import "fmt"
 import "os"
 import "time"
+import "strings"
 
 var s *Server
 var c *Client
@@ -147,3 +148,45 @@ func Test_Commit_Invalid(t *testing.T) {
     if e := c.Commit(ReferenceId("invalid")); e == nil { t.Error("Commit returned no error with invalid reference id") }
     if e := c.Commit(ReferenceId("invalid")); e.Error() != E_UNKNOWN_REFERENCE.Error() { t.Errorf("Commit returned wrong error: %v", e) }
 }
+
+func Test_Compact(t *testing.T) {
+    resourceId, resourceKey, e := c.Create(ResourceType("crdt:2pset"), "file", "aes-256-cbc")
+    if e != nil { t.Fatalf("Failed to create resource: %v", e) }
+
+    referenceId, e := c.Attach(resourceId, resourceKey)
+    if e != nil { t.Fatalf("Failed to attach resource: %v", e) }
+
+    if n, reclaimed, e := c.Compact(referenceId); e != nil || n != 0 || reclaimed != 0 {
+        t.Errorf("Compact of unacknowledged set failed: %d, %d, %v", n, reclaimed, e)
+    }
+
+    // Compacted elements leave a digest behind, so reclaim a larger one.
+    element := strings.Repeat("a", 64)
+
+    r, _ := s.database.Resolve(referenceId)
+    r.(*SetResource).context.(SetInsertInterface).Insert(element)
+    r.(*SetResource).context.(SetRemoveInterface).Remove(element)
+
+    _, marker, e := s.database.Delta(referenceId, nil)
+    if e != nil { t.Fatal(e) }
+    if e := c.Acknowledge(referenceId, "replica-b", marker); e != nil { t.Fatalf("Acknowledge failed: %v", e) }
+
+    s.database.SetReplicas([]string{"replica-b"})
+    defer s.database.SetReplicas(nil)
+
+    if n, reclaimed, e := c.Compact(referenceId); e != nil || n != 1 || reclaimed == 0 {
+        t.Errorf("Compact of acknowledged set failed: %d, %d, %v", n, reclaimed, e)
+    }
+}
+
+func Test_Acknowledge_Invalid(t *testing.T) {
+    if e := c.Acknowledge(ReferenceId("invalid"), "replica-b", nil); e == nil || e.Error() != E_INVALID_REFERENCE.Error() {
+        t.Errorf("Acknowledge returned wrong error: %v", e)
+    }
+}
+
+func Test_Compact_Invalid(t *testing.T) {
+    if _, _, e := c.Compact(ReferenceId("invalid")); e == nil || e.Error() != E_INVALID_REFERENCE.Error() {
+        t.Errorf("Compact returned wrong error: %v", e)
+    }
+}
@@ -0,0 +1,93 @@
+/*
+ * Copyright (c) 2015 Tom Swindell (t.swindell@rubyx.co.uk)
+ *
+ * Permission is hereby granted, free of charge, to any person obtaining a copy
+ * of this software and associated documentation files (the "Software"), to deal
+ * in the Software without restriction, including without limitation the rights
+ * to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+ * copies of the Software, and to permit persons to whom the Software is
+ * furnished to do so, subject to the following conditions:
+ *
+ * The above copyright notice and this permission notice shall be included in
+ * all copies or substantial portions of the Software.
+ *
+ * THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+ * IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+ * FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+ * AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+ * LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+ * OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+ * SOFTWARE.
+ *
+ */
+
+package crdb
+
+import (
+    "fmt"
+
+    pb "github.com/tswindell/go-crdt/protos"
+)
+
+// Stream of a Union, Intersect or Difference call on any of the set service
+// clients.
+type setAlgebraStream interface {
+    Recv() (*pb.SetAlgebraResponse, error)
+}
+
+func setAlgebraRequest(aRef, bRef ReferenceId) *pb.SetAlgebraRequest {
+    return &pb.SetAlgebraRequest{ReferenceId: string(aRef), OtherReferenceId: string(bRef)}
+}
+
+func setAlgebraIntoRequest(aRef, bRef ReferenceId, storageId, cryptoId string) *pb.SetAlgebraRequest {
+    return &pb.SetAlgebraRequest{
+               ReferenceId: string(aRef),
+               OtherReferenceId: string(bRef),
+               StorageId: storageId,
+               CryptoId: cryptoId,
+           }
+}
+
+// Reads the first message of a set algebra stream, which holds the status of
+// the call.
+func recvSetAlgebraHeader(r setAlgebraStream, e error) (*pb.SetAlgebraResponse, error) {
+    if e != nil { return nil, e }
+
+    header, e := r.Recv()
+    if e != nil { return nil, e }
+    if !header.Status.Success { return nil, fmt.Errorf(header.Status.ErrorType) }
+    return header, nil
+}
+
+// Returns a channel fed with the elements of a set algebra stream, closed at
+// the end of the stream.
+func recvSetAlgebra(r setAlgebraStream, e error) (chan []byte, error) {
+    if _, e := recvSetAlgebraHeader(r, e); e != nil { return nil, e }
+
+    ch := make(chan []byte)
+    go func() {
+        for {
+            object, e := r.Recv()
+            if e != nil { break }
+            ch<- object.Object
+        }
+        close(ch)
+    }()
+
+    return ch, nil
+}
+
+// Returns the resource a set algebra result was materialised into. The result
+// is complete once the header arrives, so the caller cancels the stream rather
+// than reading the elements back.
+func recvSetAlgebraInto(r setAlgebraStream, e error) (ResourceId, ResourceKey, error) {
+    header, e := recvSetAlgebraHeader(r, e)
+    if e != nil { return ResourceId(""), ResourceKey(""), e }
+    return ResourceId(header.ResourceId), ResourceKey(header.ResourceKey), nil
+}
+
+func recvSetIsSubset(r *pb.SetIsSubsetResponse, e error) (bool, error) {
+    if e != nil { return false, e }
+    if !r.Status.Success { return false, fmt.Errorf(r.Status.ErrorType) }
+    return r.Result, nil
+}
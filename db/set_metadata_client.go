@@ -0,0 +1,74 @@
+/*
+ * Copyright (c) 2015 Tom Swindell (t.swindell@rubyx.co.uk)
+ *
+ * Permission is hereby granted, free of charge, to any person obtaining a copy
+ * of this software and associated documentation files (the "Software"), to deal
+ * in the Software without restriction, including without limitation the rights
+ * to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+ * copies of the Software, and to permit persons to whom the Software is
+ * furnished to do so, subject to the following conditions:
+ *
+ * The above copyright notice and this permission notice shall be included in
+ * all copies or substantial portions of the Software.
+ *
+ * THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+ * IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+ * FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+ * AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+ * LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+ * OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+ * SOFTWARE.
+ *
+ */
+package crdb
+
+import (
+    "fmt"
+
+    "github.com/tswindell/go-crdt/sets"
+
+    pb "github.com/tswindell/go-crdt/protos"
+)
+
+// The SetElement type holds an element of a set resource along with its
+// metadata, the zero Metadata for an element inserted without any.
+type SetElement struct {
+    Object   []byte
+    Metadata set.Metadata
+}
+
+// Stream of a ListWithMetadata call on any of the set service clients.
+type setMetadataStream interface {
+    Recv() (*pb.SetListWithMetadataResponse, error)
+}
+
+// Returns a channel fed with the elements of a ListWithMetadata stream, closed
+// at the end of the stream.
+func recvSetListWithMetadata(r setMetadataStream, e error) (chan SetElement, error) {
+    if e != nil { return nil, e }
+
+    header, e := r.Recv()
+    if e != nil { return nil, e }
+    if !header.Status.Success { return nil, fmt.Errorf(header.Status.ErrorType) }
+
+    ch := make(chan SetElement)
+    go func() {
+        for {
+            object, e := r.Recv()
+            if e != nil { break }
+
+            element := SetElement{Object: object.Object}
+            if md := object.Metadata; md != nil {
+                element.Metadata = set.Metadata{
+                                       Timestamp: md.Timestamp,
+                                       ReplicaId: md.ReplicaId,
+                                       Labels: md.Labels,
+                                   }
+            }
+            ch<- element
+        }
+        close(ch)
+    }()
+
+    return ch, nil
+}
@@ -25,8 +25,9 @@ package crdb
 
 import (
     "bytes"
-    "encoding/base64"
     "fmt"
+    "io"
+    "iter"
     "reflect"
 
     "github.com/tswindell/go-crdt/sets"
@@ -40,6 +41,9 @@ import (
 const (
     GROWONLYSET_RESOURCE_TYPE = ResourceType("crdt:gset")
     TWOPHASESET_RESOURCE_TYPE = ResourceType("crdt:2pset")
+    ORSET_RESOURCE_TYPE       = ResourceType("crdt:orset")
+    LWWSET_RESOURCE_TYPE      = ResourceType("crdt:lwwset")
+    DISKGSET_RESOURCE_TYPE    = ResourceType("crdt:disk-gset")
 )
 
 var (
@@ -48,12 +52,31 @@ var (
 )
 
 
-// Generic ``Set'' function interfaces
-type SetInsertInterface   interface { Insert(interface{}) bool     }
-type SetRemoveInterface   interface { Remove(interface{}) bool     }
-type SetContainsInterface interface { Contains(interface{}) bool   }
-type SetLengthInterface   interface { Length() int                 }
-type SetIterateInterface  interface { Iterate() <-chan interface{} }
+// Generic ``Set'' function interfaces, set resources hold the raw bytes of
+// each element in a string.
+type SetInsertInterface   interface { Insert(string) bool     }
+type SetRemoveInterface   interface { Remove(string) bool     }
+type SetContainsInterface interface { Contains(string) bool   }
+type SetLengthInterface   interface { Length() int            }
+type SetIterateInterface  interface { Iterate() iter.Seq[string] }
+
+// Sets which keep tombstones, that can be compacted once every replica has
+// acknowledged them.
+type SetCompactInterface  interface {
+    Acknowledge(string, set.Marker)
+    Compact([]string) int
+}
+
+// Sets kept on disk report the I/O error which stopped them, as their other
+// methods can not.
+type SetErrorInterface    interface { Err() error }
+
+// Sets which keep the metadata of their elements, recorded by the Insert()
+// service method.
+type SetMetadataInterface interface {
+    InsertWithMetadata(string, set.Metadata) bool
+    IterateWithMetadata() iter.Seq2[string, set.Metadata]
+}
 
 type SerializeInterface   interface {
 
@@ -63,6 +86,15 @@ type SerializeInterface   interface {
 
 }
 
+// Sets serialize as a stream, never holding more than an element in memory.
+type StreamSerializeInterface interface {
+
+    Serialize(w io.Writer) error
+
+    Deserialize(r io.Reader) error
+
+}
+
 
 // Generic ``Set'' resource type.
 type SetResource struct {
@@ -71,12 +103,12 @@ type SetResource struct {
     context interface{} // Polymorphic reference to concrete data type instance.
 }
 
-func (d *SetResource) Serialize(buff *bytes.Buffer) error {
-    return d.context.(SerializeInterface).Serialize(buff)
+func (d *SetResource) Serialize(w io.Writer) error {
+    return d.context.(StreamSerializeInterface).Serialize(w)
 }
 
-func (d *SetResource) Deserialize(buff *bytes.Buffer) error {
-    return d.context.(SerializeInterface).Deserialize(buff)
+func (d *SetResource) Deserialize(r io.Reader) error {
+    return d.context.(StreamSerializeInterface).Deserialize(r)
 }
 
 
@@ -87,7 +119,7 @@ type ResourceFactoryFunc func(ResourceId, ResourceKey) Resource
 func NewGSetResource(resourceId ResourceId, resourceKey ResourceKey) Resource {
     return &SetResource{
                ResourceBase{resourceId, resourceKey, GROWONLYSET_RESOURCE_TYPE},
-               set.NewGSet(),
+               set.NewGSet[string](),
            }
 }
 
@@ -95,10 +127,42 @@ func NewGSetResource(resourceId ResourceId, resourceKey ResourceKey) Resource {
 func New2PSetResource(resourceId ResourceId, resourceKey ResourceKey) Resource {
     return &SetResource{
                ResourceBase{resourceId, resourceKey, TWOPHASESET_RESOURCE_TYPE},
-               set.New2P(),
+               set.New2P[string](),
+           }
+}
+
+// The NewORSetResource function adheres to ResourceFactoryFunc prototype.
+func NewORSetResource(resourceId ResourceId, resourceKey ResourceKey) Resource {
+    return &SetResource{
+               ResourceBase{resourceId, resourceKey, ORSET_RESOURCE_TYPE},
+               set.NewORSet[string](),
            }
 }
 
+// The NewLWWSetResourceFactory function returns a ResourceFactoryFunc creating
+// LWW element sets timestamped by clock, and resolving ties with bias.
+func NewLWWSetResourceFactory(clock set.Clock, bias set.LWWBias) ResourceFactoryFunc {
+    return func(resourceId ResourceId, resourceKey ResourceKey) Resource {
+        return &SetResource{
+                   ResourceBase{resourceId, resourceKey, LWWSET_RESOURCE_TYPE},
+                   set.NewLWWSet[string](clock, bias),
+               }
+    }
+}
+
+// The NewDiskGSetResourceFactory function returns a ResourceFactoryFunc
+// creating grow only sets which keep their elements in files under dir rather
+// than in memory, for sets too large to hold in memory. Elements of these sets
+// are only read back when needed, and serialize exactly as those of a GSet.
+func NewDiskGSetResourceFactory(dir string) ResourceFactoryFunc {
+    return func(resourceId ResourceId, resourceKey ResourceKey) Resource {
+        return &SetResource{
+                   ResourceBase{resourceId, resourceKey, DISKGSET_RESOURCE_TYPE},
+                   set.NewDiskGSet[string](dir),
+               }
+    }
+}
+
 
 // The SetResourceType type
 type SetResourceType struct {
@@ -151,9 +215,49 @@ func (d *SetResourceType) Clone(resource Resource) (Resource, error) {
     return newResource, nil
 }
 
-func (d *SetResourceType) Restore(resourceId ResourceId, resourceKey ResourceKey, buff *bytes.Buffer) (Resource, error) {
+func (d *SetResourceType) Delta(resource Resource, marker []byte) ([]byte, []byte, error) {
+    context, ok := resource.(*SetResource).context.(set.DeltaState)
+    if !ok { return nil, nil, E_NOT_SUPPORTED }
+
+    since, e := set.ParseMarker(marker)
+    if e != nil { return nil, nil, e }
+
+    buff := new(bytes.Buffer)
+    next, e := context.SerializeDelta(buff, since)
+    if e != nil { return nil, nil, e }
+
+    return buff.Bytes(), next.Bytes(), nil
+}
+
+func (d *SetResourceType) ApplyDelta(resource Resource, delta []byte) error {
+    context, ok := resource.(*SetResource).context.(set.DeltaState)
+    if !ok { return E_NOT_SUPPORTED }
+
+    _, e := context.DeserializeDelta(bytes.NewBuffer(delta))
+    return e
+}
+
+func (d *SetResourceType) Acknowledge(resource Resource, replicaId string, marker []byte) error {
+    context, ok := resource.(*SetResource).context.(SetCompactInterface)
+    if !ok { return E_NOT_SUPPORTED }
+
+    acked, e := set.ParseMarker(marker)
+    if e != nil { return e }
+
+    context.Acknowledge(replicaId, acked)
+    return nil
+}
+
+func (d *SetResourceType) Compact(resource Resource, replicas []string) (int, error) {
+    context, ok := resource.(*SetResource).context.(SetCompactInterface)
+    if !ok { return 0, E_NOT_SUPPORTED }
+
+    return context.Compact(replicas), nil
+}
+
+func (d *SetResourceType) Restore(resourceId ResourceId, resourceKey ResourceKey, r io.Reader) (Resource, error) {
     resource := d.factory(resourceId, resourceKey)
-    if e := resource.Deserialize(buff); e != nil { return nil, e }
+    if e := resource.Deserialize(r); e != nil { return nil, e }
     return resource, nil
 }
 
@@ -168,11 +272,24 @@ func NewSetResourceService(database *Database) *SetResourceService {
 }
 
 
-// Concrete implementation for GSet List (It's either this, or we patch protoc output)
+// Concrete implementation for GSet List and the set algebra streams (It's
+// either this, or we patch protoc output)
 type GrowOnlySetService struct {SetResourceService}
 func (d *GrowOnlySetService) List(m *pb.SetListRequest, stream pb.GrowOnlySet_ListServer) error {
     return d.SetResourceService.List(m, stream)
 }
+func (d *GrowOnlySetService) Union(m *pb.SetAlgebraRequest, stream pb.GrowOnlySet_UnionServer) error {
+    return d.SetResourceService.algebra(set.Union[string], m, stream)
+}
+func (d *GrowOnlySetService) Intersect(m *pb.SetAlgebraRequest, stream pb.GrowOnlySet_IntersectServer) error {
+    return d.SetResourceService.algebra(set.Intersect[string], m, stream)
+}
+func (d *GrowOnlySetService) Difference(m *pb.SetAlgebraRequest, stream pb.GrowOnlySet_DifferenceServer) error {
+    return d.SetResourceService.algebra(set.Difference[string], m, stream)
+}
+func (d *GrowOnlySetService) ListWithMetadata(m *pb.SetListRequest, stream pb.GrowOnlySet_ListWithMetadataServer) error {
+    return d.SetResourceService.ListWithMetadata(m, stream)
+}
 
 
 // Concrete implementation for 2PSet List ( ... )
@@ -180,6 +297,56 @@ type TwoPhaseSetService struct {SetResourceService}
 func (d *TwoPhaseSetService) List(m *pb.SetListRequest, stream pb.TwoPhaseSet_ListServer) error {
     return d.SetResourceService.List(m, stream)
 }
+func (d *TwoPhaseSetService) Union(m *pb.SetAlgebraRequest, stream pb.TwoPhaseSet_UnionServer) error {
+    return d.SetResourceService.algebra(set.Union[string], m, stream)
+}
+func (d *TwoPhaseSetService) Intersect(m *pb.SetAlgebraRequest, stream pb.TwoPhaseSet_IntersectServer) error {
+    return d.SetResourceService.algebra(set.Intersect[string], m, stream)
+}
+func (d *TwoPhaseSetService) Difference(m *pb.SetAlgebraRequest, stream pb.TwoPhaseSet_DifferenceServer) error {
+    return d.SetResourceService.algebra(set.Difference[string], m, stream)
+}
+func (d *TwoPhaseSetService) ListWithMetadata(m *pb.SetListRequest, stream pb.TwoPhaseSet_ListWithMetadataServer) error {
+    return d.SetResourceService.ListWithMetadata(m, stream)
+}
+
+
+// Concrete implementation for ORSet List ( ... )
+type ObserveRemoveSetService struct {SetResourceService}
+func (d *ObserveRemoveSetService) List(m *pb.SetListRequest, stream pb.ObserveRemoveSet_ListServer) error {
+    return d.SetResourceService.List(m, stream)
+}
+func (d *ObserveRemoveSetService) Union(m *pb.SetAlgebraRequest, stream pb.ObserveRemoveSet_UnionServer) error {
+    return d.SetResourceService.algebra(set.Union[string], m, stream)
+}
+func (d *ObserveRemoveSetService) Intersect(m *pb.SetAlgebraRequest, stream pb.ObserveRemoveSet_IntersectServer) error {
+    return d.SetResourceService.algebra(set.Intersect[string], m, stream)
+}
+func (d *ObserveRemoveSetService) Difference(m *pb.SetAlgebraRequest, stream pb.ObserveRemoveSet_DifferenceServer) error {
+    return d.SetResourceService.algebra(set.Difference[string], m, stream)
+}
+func (d *ObserveRemoveSetService) ListWithMetadata(m *pb.SetListRequest, stream pb.ObserveRemoveSet_ListWithMetadataServer) error {
+    return d.SetResourceService.ListWithMetadata(m, stream)
+}
+
+
+// Concrete implementation for LWWSet List ( ... )
+type LastWriterWinsSetService struct {SetResourceService}
+func (d *LastWriterWinsSetService) List(m *pb.SetListRequest, stream pb.LastWriterWinsSet_ListServer) error {
+    return d.SetResourceService.List(m, stream)
+}
+func (d *LastWriterWinsSetService) Union(m *pb.SetAlgebraRequest, stream pb.LastWriterWinsSet_UnionServer) error {
+    return d.SetResourceService.algebra(set.Union[string], m, stream)
+}
+func (d *LastWriterWinsSetService) Intersect(m *pb.SetAlgebraRequest, stream pb.LastWriterWinsSet_IntersectServer) error {
+    return d.SetResourceService.algebra(set.Intersect[string], m, stream)
+}
+func (d *LastWriterWinsSetService) Difference(m *pb.SetAlgebraRequest, stream pb.LastWriterWinsSet_DifferenceServer) error {
+    return d.SetResourceService.algebra(set.Difference[string], m, stream)
+}
+func (d *LastWriterWinsSetService) ListWithMetadata(m *pb.SetListRequest, stream pb.LastWriterWinsSet_ListWithMetadataServer) error {
+    return d.SetResourceService.ListWithMetadata(m, stream)
+}
 
 
 // The List() service method (abstract)
@@ -190,32 +357,87 @@ func (d *SetResourceService) List(m *pb.SetListRequest, stream grpc.ServerStream
     context := r.(*SetResource).context
 
     for v := range context.(SetIterateInterface).Iterate() {
-        j, e := base64.StdEncoding.DecodeString(v.(string))
-        if e != nil { return nil }
+        e := stream.SendMsg(&pb.ResourceObject{
+                                ReferenceId: string(m.ReferenceId),
+                                Object: []byte(v),
+                            })
+        if e != nil { return e }
+    }
+
+    return nil
+}
+
+// Resolves referenceId to the contents of a set resource which keeps the
+// metadata of its elements.
+func (d *SetResourceService) resolveMetadata(referenceId ReferenceId) (SetMetadataInterface, error) {
+    r, e := d.database.Resolve(referenceId)
+    if e != nil { return nil, e }
+
+    resource, ok := r.(*SetResource)
+    if !ok { return nil, E_TYPE_MISMATCH }
 
-        stream.SendMsg(&pb.ResourceObject{
-                           ReferenceId: string(m.ReferenceId),
-                           Object: []byte(j),
-                       })
+    contents, ok := resource.context.(SetMetadataInterface)
+    if !ok { return nil, E_NOT_SUPPORTED }
+
+    return contents, nil
+}
+
+// The ListWithMetadata() service method (abstract). The first message sent
+// holds the status, and each following message an element with its metadata.
+func (d *SetResourceService) ListWithMetadata(m *pb.SetListRequest, stream grpc.ServerStream) error {
+    contents, e := d.resolveMetadata(ReferenceId(m.ReferenceId))
+    if e != nil {
+        return stream.SendMsg(&pb.SetListWithMetadataResponse{Status:&pb.Status{Success:false,ErrorType:e.Error()}})
+    }
+    if e := stream.SendMsg(&pb.SetListWithMetadataResponse{Status:&pb.Status{Success:true}}); e != nil { return e }
+
+    for v, md := range contents.IterateWithMetadata() {
+        response := &pb.SetListWithMetadataResponse{Object: []byte(v)}
+        if !md.IsZero() {
+            response.Metadata = &pb.ElementMetadata{
+                                    Timestamp: md.Timestamp,
+                                    ReplicaId: md.ReplicaId,
+                                    Labels: md.Labels,
+                                }
+        }
+        if e := stream.SendMsg(response); e != nil { return e }
     }
 
     return nil
 }
 
+// Inserts the object of m into context. Sets which keep metadata record the
+// time of the insert, the replica serving it and the labels of m.
+func (d *SetResourceService) insert(context interface{}, m *pb.SetInsertRequest) bool {
+    item := string(m.Object.Object)
+
+    if metadata, ok := context.(SetMetadataInterface); ok {
+        return metadata.InsertWithMetadata(item, set.Metadata{
+                                                     Timestamp: set.SystemClock.Now(),
+                                                     ReplicaId: d.database.ReplicaId(),
+                                                     Labels: m.Labels,
+                                                 })
+    }
+    return context.(SetInsertInterface).Insert(item)
+}
+
 // The Insert() service method
 func (d *SetResourceService) Insert(ctx context.Context, m *pb.SetInsertRequest) (*pb.SetInsertResponse, error) {
     status := &pb.Status{Success: true}
 
     r, e := d.database.Resolve(ReferenceId(m.Object.ReferenceId))
-    context := r.(*SetResource).context
 
     if e != nil {
         status.Success = false
         status.ErrorType = e.Error()
 
-    } else if !context.(SetInsertInterface).Insert(base64.StdEncoding.EncodeToString(m.Object.Object)) {
+    } else if context := r.(*SetResource).context; !d.insert(context, m) {
         status.Success = false
         status.ErrorType = E_ALREADY_INSERTED.Error()
+
+        if failed, ok := context.(SetErrorInterface); ok && failed.Err() != nil {
+            status.ErrorType = failed.Err().Error()
+        }
     } else {
         go func() { d.database.Notify(r.Id(), 0, m.Object.Object) }()
     }
@@ -231,7 +453,7 @@ func (d *SetResourceService) Remove(ctx context.Context, m *pb.SetRemoveRequest)
     }
 
     context := r.(*SetResource).context
-    v := context.(SetRemoveInterface).Remove(base64.StdEncoding.EncodeToString(m.Object.Object))
+    v := context.(SetRemoveInterface).Remove(string(m.Object.Object))
 
     if v {
         go func() { d.database.Notify(r.Id(), 1, m.Object.Object) }()
@@ -246,14 +468,13 @@ func (d *SetResourceService) Length(ctx context.Context, m *pb.SetLengthRequest)
     length := 0
 
     r, e := d.database.Resolve(ReferenceId(m.ReferenceId))
-    context := r.(*SetResource).context
 
     if e != nil {
         status.Success = false
         status.ErrorType = e.Error()
 
     } else {
-        length = context.(SetLengthInterface).Length()
+        length = r.(*SetResource).context.(SetLengthInterface).Length()
     }
 
     return &pb.SetLengthResponse{Status: status, Length: uint64(length)}, nil
@@ -265,15 +486,95 @@ func (d *SetResourceService) Contains(ctx context.Context, m *pb.SetContainsRequ
     result := false
 
     r, e := d.database.Resolve(ReferenceId(m.Object.ReferenceId))
-    context := r.(*SetResource).context
 
     if e != nil {
         status.Success = false
         status.ErrorType = e.Error()
     } else {
-        result = context.(SetContainsInterface).Contains(base64.StdEncoding.EncodeToString(m.Object.Object))
+        result = r.(*SetResource).context.(SetContainsInterface).Contains(string(m.Object.Object))
     }
 
     return &pb.SetContainsResponse{Status: status, Result: result}, nil
 }
 
+
+// Resolves referenceId to the contents of a set resource, along with the
+// resource itself.
+func (d *SetResourceService) resolveSet(referenceId ReferenceId) (Resource, set.Readable[string], error) {
+    r, e := d.database.Resolve(referenceId)
+    if e != nil { return nil, nil, e }
+
+    resource, ok := r.(*SetResource)
+    if !ok { return nil, nil, E_TYPE_MISMATCH }
+
+    contents, ok := resource.context.(set.Readable[string])
+    if !ok { return nil, nil, E_TYPE_MISMATCH }
+
+    return r, contents, nil
+}
+
+// Creates the resource a set algebra result is materialised into, which must
+// be of a set type.
+func (d *SetResourceService) materialise(resourceType ResourceType, storageId, cryptoId string) (Resource, error) {
+    factory := d.database.datatypes.GetFactory(resourceType)
+    if factory == nil { return nil, E_UNKNOWN_TYPE }
+    if _, ok := factory.(*SetResourceType); !ok { return nil, E_TYPE_MISMATCH }
+
+    return d.database.Create(resourceType, storageId, cryptoId)
+}
+
+// The algebra() service method streams the result of operation on the two
+// referenced sets (abstract). The first message sent holds the status, and the
+// id and key of the new resource when the result is materialised, which must
+// then be attached and committed like any other created resource. Elements
+// follow, one per message.
+func (d *SetResourceService) algebra(operation func(a, b set.Readable[string]) iter.Seq[string], m *pb.SetAlgebraRequest, stream grpc.ServerStream) error {
+    var target Resource
+
+    r, a, e := d.resolveSet(ReferenceId(m.ReferenceId))
+    var b set.Readable[string]
+    if e == nil { _, b, e = d.resolveSet(ReferenceId(m.OtherReferenceId)) }
+
+    if e == nil && m.StorageId != "" {
+        resourceType := ResourceType(m.ResourceType)
+        if resourceType == "" { resourceType = r.Type() }
+        target, e = d.materialise(resourceType, m.StorageId, m.CryptoId)
+    }
+
+    if e != nil {
+        return stream.SendMsg(&pb.SetAlgebraResponse{Status:&pb.Status{Success:false,ErrorType:e.Error()}})
+    }
+
+    // A materialised result is complete before anything is sent, and then
+    // streamed from the new resource.
+    header := &pb.SetAlgebraResponse{Status:&pb.Status{Success:true}}
+    result := operation(a, b)
+    if target != nil {
+        context := target.(*SetResource).context
+        for v := range result { context.(SetInsertInterface).Insert(v) }
+
+        header.ResourceId = string(target.Id())
+        header.ResourceKey = string(target.Key())
+        result = context.(SetIterateInterface).Iterate()
+    }
+    if e := stream.SendMsg(header); e != nil { return e }
+
+    for v := range result {
+        e := stream.SendMsg(&pb.SetAlgebraResponse{Object: []byte(v)})
+        if e != nil { return e }
+    }
+
+    return nil
+}
+
+// The IsSubset() service method
+func (d *SetResourceService) IsSubset(ctx context.Context, m *pb.SetIsSubsetRequest) (*pb.SetIsSubsetResponse, error) {
+    _, a, e := d.resolveSet(ReferenceId(m.ReferenceId))
+    var b set.Readable[string]
+    if e == nil { _, b, e = d.resolveSet(ReferenceId(m.OtherReferenceId)) }
+    if e != nil {
+        return &pb.SetIsSubsetResponse{Status:&pb.Status{Success:false,ErrorType:e.Error()}}, nil
+    }
+
+    return &pb.SetIsSubsetResponse{Status:&pb.Status{Success:true}, Result:set.IsSubset(a, b)}, nil
+}
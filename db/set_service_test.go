@@ -0,0 +1,547 @@
+package crdb
+
+import "testing"
+import "fmt"
+import "sort"
+import "sync"
+
+import "google.golang.org/grpc"
+import "golang.org/x/net/context"
+import "github.com/tswindell/go-crdt/sets"
+import pb "github.com/tswindell/go-crdt/protos"
+
+// Creates and attaches a resource of resourceType for the service tests.
+func newTestSetReference(t *testing.T, resourceType ResourceType) ReferenceId {
+    initDatabase(t)
+
+    if e := db.RegisterType(NewSetResourceType(db,
+                                               TWOPHASESET_RESOURCE_TYPE,
+                                               New2PSetResource)); e != nil {
+        t.Fatalf("Failed to register type: %v", e)
+    }
+
+    resource, e := db.Create(resourceType, "file", "aes-256-cbc")
+    if e != nil { t.Fatalf("Failed to create resource: %v", e) }
+
+    reference, e := db.Attach(resource.Id(), resource.Key())
+    if e != nil { t.Fatalf("Failed to attach resource: %v", e) }
+
+    return reference
+}
+
+// Inserts the same elements from workers goroutines at once, run with -race.
+func testSetServiceConcurrentInsert(t *testing.T, resourceType ResourceType) {
+    reference := newTestSetReference(t, resourceType)
+    service := NewSetResourceService(db)
+
+    const workers = 16
+    const elements = 200
+
+    var wg sync.WaitGroup
+    inserted := make([]int, workers)
+
+    for w := 0; w < workers; w++ {
+        wg.Add(1)
+        go func(w int) {
+            defer wg.Done()
+            for i := 0; i < elements; i++ {
+                object := &pb.ResourceObject{ReferenceId: string(reference), Object: []byte(fmt.Sprint(i))}
+
+                r, e := service.Insert(context.Background(), &pb.SetInsertRequest{Object: object})
+                if e != nil { t.Error(e); return }
+                if r.Status.Success { inserted[w]++ }
+
+                service.Contains(context.Background(), &pb.SetContainsRequest{Object: object})
+                service.Length(context.Background(), &pb.SetLengthRequest{ReferenceId: string(reference)})
+            }
+        }(w)
+    }
+    wg.Wait()
+
+    total := 0
+    for _, n := range inserted { total += n }
+    if total != elements {
+        t.Errorf("Expected %d successful inserts, got %d", elements, total)
+    }
+
+    r, _ := service.Length(context.Background(), &pb.SetLengthRequest{ReferenceId: string(reference)})
+    if r.Length != elements {
+        t.Errorf("Expected length of %d, got %d", elements, r.Length)
+    }
+}
+
+func Test_SetService_ConcurrentInsert_GSet(t *testing.T) {
+    testSetServiceConcurrentInsert(t, GROWONLYSET_RESOURCE_TYPE)
+}
+
+func Test_SetService_ConcurrentInsert_2PSet(t *testing.T) {
+    testSetServiceConcurrentInsert(t, TWOPHASESET_RESOURCE_TYPE)
+}
+
+func Test_SetService_InvalidReference(t *testing.T) {
+    newTestSetReference(t, GROWONLYSET_RESOURCE_TYPE)
+    service := NewSetResourceService(db)
+
+    object := &pb.ResourceObject{ReferenceId: "invalid", Object: []byte("a")}
+
+    r, e := service.Insert(context.Background(), &pb.SetInsertRequest{Object: object})
+    if e != nil || r.Status.Success || r.Status.ErrorType != E_INVALID_REFERENCE.Error() {
+        t.Error("Expected invalid reference error from Insert!")
+    }
+
+    l, e := service.Length(context.Background(), &pb.SetLengthRequest{ReferenceId: "invalid"})
+    if e != nil || l.Status.Success { t.Error("Expected invalid reference error from Length!") }
+
+    c, e := service.Contains(context.Background(), &pb.SetContainsRequest{Object: object})
+    if e != nil || c.Status.Success { t.Error("Expected invalid reference error from Contains!") }
+}
+
+// Stream failing every send after the first limit messages.
+type failingStream struct {
+    grpc.ServerStream
+    limit int
+    sent  int
+}
+
+func (d *failingStream) SendMsg(m interface{}) error {
+    if d.sent == d.limit { return fmt.Errorf("stream closed") }
+    d.sent++
+    return nil
+}
+
+func Test_SetService_ListStreamError(t *testing.T) {
+    reference := newTestSetReference(t, GROWONLYSET_RESOURCE_TYPE)
+    service := NewSetResourceService(db)
+
+    for i := 0; i < 10; i++ {
+        object := &pb.ResourceObject{ReferenceId: string(reference), Object: []byte(fmt.Sprint(i))}
+        service.Insert(context.Background(), &pb.SetInsertRequest{Object: object})
+    }
+
+    stream := &failingStream{limit: 3}
+    if e := service.List(&pb.SetListRequest{ReferenceId: string(reference)}, stream); e == nil {
+        t.Error("Expected List to return the stream error!")
+    }
+    if stream.sent != 3 { t.Errorf("Expected List to stop after 3 sends, got %d", stream.sent) }
+
+    stream = &failingStream{limit: 20}
+    if e := service.List(&pb.SetListRequest{ReferenceId: string(reference)}, stream); e != nil {
+        t.Errorf("List failed: %v", e)
+    }
+    if stream.sent != 10 { t.Errorf("Expected 10 elements listed, got %d", stream.sent) }
+}
+
+func Test_SetService_Compact_2PSet(t *testing.T) {
+    reference := newTestSetReference(t, TWOPHASESET_RESOURCE_TYPE)
+
+    r, _ := db.Resolve(reference)
+    context := r.(*SetResource).context
+
+    for i := 0; i < 100; i++ { context.(SetInsertInterface).Insert(fmt.Sprintf("element-%d", i)) }
+    for i := 0; i < 50; i++ { context.(SetRemoveInterface).Remove(fmt.Sprintf("element-%d", i)) }
+
+    _, marker, e := db.Delta(reference, nil)
+    if e != nil { t.Fatal(e) }
+    if e := db.Acknowledge(reference, "replica-b", marker); e != nil { t.Fatal(e) }
+
+    if n, reclaimed, e := db.Compact(reference); e != nil || n != 0 || reclaimed != 0 {
+        t.Fatalf("Nothing should be compacted without replicas: %d, %d, %v", n, reclaimed, e)
+    }
+
+    db.SetReplicas([]string{db.ReplicaId(), "replica-b", "replica-c"})
+
+    if n, reclaimed, e := db.Compact(reference); e != nil || n != 0 || reclaimed != 0 {
+        t.Fatalf("Nothing should be compacted before every replica acknowledged: %d, %d, %v", n, reclaimed, e)
+    }
+
+    if e := db.Acknowledge(reference, "replica-c", marker); e != nil { t.Fatal(e) }
+
+    n, reclaimed, e := db.Compact(reference)
+    if e != nil { t.Fatal(e) }
+    if n != 50 || reclaimed == 0 { t.Errorf("Expected 50 tombstones reclaimed, got %d (%d bytes)", n, reclaimed) }
+
+    if context.(SetLengthInterface).Length() != 50 || context.(SetContainsInterface).Contains("element-0") {
+        t.Error("Unexpected contents after compaction!")
+    }
+
+    if e := db.Acknowledge(reference, "replica-b", []byte("invalid")); e == nil {
+        t.Error("Expected error acknowledging an invalid marker!")
+    }
+}
+
+func Test_SetService_Compact_AcknowledgementsCommitted(t *testing.T) {
+    reference := newTestSetReference(t, TWOPHASESET_RESOURCE_TYPE)
+
+    r, _ := db.Resolve(reference)
+    for i := 0; i < 10; i++ { r.(*SetResource).context.(SetInsertInterface).Insert(fmt.Sprintf("element-%d", i)) }
+    for i := 0; i < 5; i++ { r.(*SetResource).context.(SetRemoveInterface).Remove(fmt.Sprintf("element-%d", i)) }
+
+    _, marker, e := db.Delta(reference, nil)
+    if e != nil { t.Fatal(e) }
+    if e := db.Acknowledge(reference, "replica-b", marker); e != nil { t.Fatal(e) }
+    if e := db.Commit(reference); e != nil { t.Fatal(e) }
+
+    // Re-initialize database, as a restarted server would.
+    initDatabase(t)
+    db.RegisterType(NewSetResourceType(db, TWOPHASESET_RESOURCE_TYPE, New2PSetResource))
+    db.SetReplicas([]string{"replica-b"})
+
+    reference, e = db.Attach(r.Id(), r.Key())
+    if e != nil { t.Fatalf("Failed to attach resource: %v", e) }
+
+    if n, _, e := db.Compact(reference); e != nil || n != 5 {
+        t.Errorf("Expected acknowledged tombstones compacted after restart, got %d (%v)", n, e)
+    }
+}
+
+func Test_SetService_Compact_NotSupported(t *testing.T) {
+    reference := newTestSetReference(t, GROWONLYSET_RESOURCE_TYPE)
+
+    if _, _, e := db.Compact(reference); e != E_NOT_SUPPORTED {
+        t.Errorf("Expected compaction to be unsupported, got: %v", e)
+    }
+}
+
+// Stream recording every message sent.
+type collectStream struct {
+    grpc.ServerStream
+    sent []*pb.SetAlgebraResponse
+}
+
+func (d *collectStream) SendMsg(m interface{}) error {
+    d.sent = append(d.sent, m.(*pb.SetAlgebraResponse))
+    return nil
+}
+
+func (d *collectStream) Send(m *pb.SetAlgebraResponse) error { return d.SendMsg(m) }
+
+// Returns the sorted elements following the header of stream.
+func (d *collectStream) elements() []string {
+    var result []string
+    for _, m := range d.sent[1:] { result = append(result, string(m.Object)) }
+    sort.Strings(result)
+    return result
+}
+
+// Attaches a GSet holding 1 to 6, and a 2PSet holding 4 to 8 with 8 removed.
+func newTestSetAlgebraReferences(t *testing.T) (ReferenceId, ReferenceId) {
+    aRef := newTestSetReference(t, GROWONLYSET_RESOURCE_TYPE)
+
+    resource, e := db.Create(TWOPHASESET_RESOURCE_TYPE, "file", "aes-256-cbc")
+    if e != nil { t.Fatalf("Failed to create resource: %v", e) }
+    bRef, e := db.Attach(resource.Id(), resource.Key())
+    if e != nil { t.Fatalf("Failed to attach resource: %v", e) }
+
+    a, _ := db.Resolve(aRef)
+    b, _ := db.Resolve(bRef)
+    for i := 1; i <= 6; i++ { a.(*SetResource).context.(SetInsertInterface).Insert(fmt.Sprint(i)) }
+    for i := 4; i <= 8; i++ { b.(*SetResource).context.(SetInsertInterface).Insert(fmt.Sprint(i)) }
+    b.(*SetResource).context.(SetRemoveInterface).Remove("8")
+
+    return aRef, bRef
+}
+
+func Test_SetService_Algebra(t *testing.T) {
+    aRef, bRef := newTestSetAlgebraReferences(t)
+    service := &GrowOnlySetService{SetResourceService{db}}
+    request := &pb.SetAlgebraRequest{ReferenceId: string(aRef), OtherReferenceId: string(bRef)}
+
+    for _, test := range []struct {
+        name     string
+        call     func(*collectStream) error
+        expected string
+    }{
+        {"Union", func(s *collectStream) error { return service.Union(request, s) }, "[1 2 3 4 5 6 7]"},
+        {"Intersect", func(s *collectStream) error { return service.Intersect(request, s) }, "[4 5 6]"},
+        {"Difference", func(s *collectStream) error { return service.Difference(request, s) }, "[1 2 3]"},
+    } {
+        stream := &collectStream{}
+        if e := test.call(stream); e != nil { t.Fatalf("%s failed: %v", test.name, e) }
+
+        if len(stream.sent) == 0 || !stream.sent[0].Status.Success || stream.sent[0].ResourceId != "" {
+            t.Fatalf("%s: unexpected header %v", test.name, stream.sent)
+        }
+        if r := fmt.Sprint(stream.elements()); r != test.expected {
+            t.Errorf("%s: expected %s, got %s", test.name, test.expected, r)
+        }
+    }
+
+    r, e := service.IsSubset(context.Background(), &pb.SetIsSubsetRequest{ReferenceId: string(aRef), OtherReferenceId: string(bRef)})
+    if e != nil || !r.Status.Success || r.Result { t.Errorf("Expected a not to be a subset of b: %v", r) }
+}
+
+func Test_SetService_Algebra_Materialise(t *testing.T) {
+    aRef, bRef := newTestSetAlgebraReferences(t)
+    service := NewSetResourceService(db)
+
+    stream := &collectStream{}
+    request := &pb.SetAlgebraRequest{
+                   ReferenceId: string(bRef),
+                   OtherReferenceId: string(aRef),
+                   StorageId: "file",
+                   CryptoId: "aes-256-cbc",
+               }
+    if e := service.algebra(set.Difference[string], request, stream); e != nil { t.Fatal(e) }
+
+    header := stream.sent[0]
+    if !header.Status.Success { t.Fatalf("Difference failed: %s", header.Status.ErrorType) }
+    if r := fmt.Sprint(stream.elements()); r != "[7]" { t.Errorf("Expected [7], got %s", r) }
+
+    cRef, e := db.Attach(ResourceId(header.ResourceId), ResourceKey(header.ResourceKey))
+    if e != nil { t.Fatalf("Failed to attach materialised resource: %v", e) }
+
+    c, _ := db.Resolve(cRef)
+    if c.Type() != TWOPHASESET_RESOURCE_TYPE { t.Errorf("Expected type of b, got %s", c.Type()) }
+    if c.(*SetResource).context.(SetLengthInterface).Length() != 1 { t.Error("Unexpected materialised contents!") }
+
+    r, _ := service.IsSubset(context.Background(), &pb.SetIsSubsetRequest{ReferenceId: string(cRef), OtherReferenceId: string(bRef)})
+    if !r.Result { t.Error("Expected the difference to be a subset of b!") }
+}
+
+func Test_SetService_Algebra_Invalid(t *testing.T) {
+    aRef, bRef := newTestSetAlgebraReferences(t)
+    service := NewSetResourceService(db)
+
+    for _, request := range []*pb.SetAlgebraRequest{
+        {ReferenceId: string(aRef), OtherReferenceId: "invalid"},
+        {ReferenceId: "invalid", OtherReferenceId: string(bRef)},
+        {ReferenceId: string(aRef), OtherReferenceId: string(bRef), StorageId: "invalid"},
+        {ReferenceId: string(aRef), OtherReferenceId: string(bRef), StorageId: "file", CryptoId: "aes-256-cbc", ResourceType: "invalid"},
+    } {
+        stream := &collectStream{}
+        if e := service.algebra(set.Union[string], request, stream); e != nil { t.Fatal(e) }
+        if len(stream.sent) != 1 || stream.sent[0].Status.Success {
+            t.Errorf("Expected a single failed status for %v", request)
+        }
+    }
+
+    r, e := service.IsSubset(context.Background(), &pb.SetIsSubsetRequest{ReferenceId: string(aRef), OtherReferenceId: "invalid"})
+    if e != nil || r.Status.Success { t.Error("Expected invalid reference error from IsSubset!") }
+}
+
+func Test_SetClient_Algebra(t *testing.T) {
+    refs := make([]ReferenceId, 2)
+    for i, resourceType := range []ResourceType{ORSET_RESOURCE_TYPE, LWWSET_RESOURCE_TYPE} {
+        resourceId, resourceKey, e := c.Create(resourceType, "file", "aes-256-cbc")
+        if e != nil { t.Fatalf("Create failed: %v", e) }
+        refs[i], e = c.Attach(resourceId, resourceKey)
+        if e != nil { t.Fatalf("Attach failed: %v", e) }
+    }
+
+    for _, v := range []string{"a", "b", "c"} { c.ORSetClient.Insert(refs[0], []byte(v)) }
+    for _, v := range []string{"b", "c", "d"} { c.LWWSetClient.Insert(refs[1], []byte(v)) }
+
+    ch, e := c.ORSetClient.Intersect(refs[0], refs[1])
+    if e != nil { t.Fatalf("Intersect failed: %v", e) }
+
+    var result []string
+    for v := range ch { result = append(result, string(v)) }
+    sort.Strings(result)
+    if fmt.Sprint(result) != "[b c]" { t.Errorf("Expected [b c], got %v", result) }
+
+    resourceId, resourceKey, e := c.ORSetClient.UnionInto(refs[0], refs[1], "file", "aes-256-cbc")
+    if e != nil { t.Fatalf("UnionInto failed: %v", e) }
+    union, e := c.Attach(resourceId, resourceKey)
+    if e != nil { t.Fatalf("Attach failed: %v", e) }
+
+    if n, e := c.ORSetClient.Length(union); e != nil || n != 4 { t.Errorf("Expected union of length 4, got %d: %v", n, e) }
+
+    for _, ref := range refs {
+        if r, e := c.ORSetClient.IsSubset(ref, union); e != nil || !r { t.Errorf("Expected a subset of the union: %v", e) }
+    }
+
+    if _, e := c.LWWSetClient.Difference(refs[1], "invalid"); e == nil || e.Error() != E_INVALID_REFERENCE.Error() {
+        t.Errorf("Expected invalid reference error from Difference, got: %v", e)
+    }
+}
+
+func Test_SetService_DiskGSet(t *testing.T) {
+    initDatabase(t)
+    dir := t.TempDir()
+    register := func() {
+        db.RegisterType(NewSetResourceType(db, DISKGSET_RESOURCE_TYPE, NewDiskGSetResourceFactory(dir)))
+    }
+    register()
+
+    resource, e := db.Create(DISKGSET_RESOURCE_TYPE, "file", "aes-256-cbc")
+    if e != nil { t.Fatalf("Failed to create resource: %v", e) }
+    if _, ok := resource.(*SetResource).context.(*set.DiskGSet[string]); !ok { t.Fatal("Expected a disk-backed set!") }
+
+    reference, e := db.Attach(resource.Id(), resource.Key())
+    if e != nil { t.Fatalf("Failed to attach resource: %v", e) }
+
+    service := NewSetResourceService(db)
+    for i := 0; i < 100; i++ {
+        object := &pb.ResourceObject{ReferenceId: string(reference), Object: []byte(fmt.Sprint(i))}
+        r, _ := service.Insert(context.Background(), &pb.SetInsertRequest{Object: object})
+        if !r.Status.Success { t.Fatalf("Insert failed: %s", r.Status.ErrorType) }
+    }
+
+    if e := db.Commit(reference); e != nil { t.Fatalf("Failed to commit resource: %v", e) }
+
+    // Re-initialize database, restoring the set from storage.
+    initDatabase(t)
+    register()
+
+    reference, e = db.Attach(resource.Id(), resource.Key())
+    if e != nil { t.Fatalf("Failed to attach resource: %v", e) }
+
+    service = NewSetResourceService(db)
+    l, _ := service.Length(context.Background(), &pb.SetLengthRequest{ReferenceId: string(reference)})
+    if l.Length != 100 { t.Errorf("Expected length of 100 after restore, got %d", l.Length) }
+
+    // A failed set reports its error rather than a duplicate insert.
+    r, _ := db.Resolve(reference)
+    r.(*SetResource).context.(*set.DiskGSet[string]).Close()
+
+    object := &pb.ResourceObject{ReferenceId: string(reference), Object: []byte("new")}
+    i, _ := service.Insert(context.Background(), &pb.SetInsertRequest{Object: object})
+    if i.Status.Success || i.Status.ErrorType == E_ALREADY_INSERTED.Error() {
+        t.Errorf("Expected the error of the closed set, got: %v", i.Status)
+    }
+}
+
+type metadataStream struct {
+    grpc.ServerStream
+    sent []*pb.SetListWithMetadataResponse
+}
+
+func (d *metadataStream) SendMsg(m interface{}) error {
+    d.sent = append(d.sent, m.(*pb.SetListWithMetadataResponse))
+    return nil
+}
+
+func (d *metadataStream) Send(m *pb.SetListWithMetadataResponse) error { return d.SendMsg(m) }
+
+// Attaches a new set of resourceType, with every resource type registered.
+func newTestMetadataSetReference(t *testing.T, resourceType ResourceType) ReferenceId {
+    initDatabase(t)
+    RegisterResourceTypes(db, set.SystemClock, t.TempDir())
+
+    resource, e := db.Create(resourceType, "file", "aes-256-cbc")
+    if e != nil { t.Fatalf("Failed to create resource: %v", e) }
+
+    reference, e := db.Attach(resource.Id(), resource.Key())
+    if e != nil { t.Fatalf("Failed to attach resource: %v", e) }
+
+    return reference
+}
+
+func Test_SetService_ListWithMetadata(t *testing.T) {
+    for _, resourceType := range []ResourceType{GROWONLYSET_RESOURCE_TYPE,
+                                                TWOPHASESET_RESOURCE_TYPE,
+                                                ORSET_RESOURCE_TYPE,
+                                                LWWSET_RESOURCE_TYPE} {
+        reference := newTestMetadataSetReference(t, resourceType)
+        service := NewSetResourceService(db)
+
+        object := &pb.ResourceObject{ReferenceId: string(reference), Object: []byte("a")}
+        labels := map[string]string{"user": "alice"}
+        r, _ := service.Insert(context.Background(), &pb.SetInsertRequest{Object: object, Labels: labels})
+        if !r.Status.Success { t.Fatalf("%s: insert failed: %s", resourceType, r.Status.ErrorType) }
+
+        // Elements inserted directly into the set carry no metadata.
+        resource, _ := db.Resolve(reference)
+        resource.(*SetResource).context.(SetInsertInterface).Insert("b")
+
+        stream := &metadataStream{}
+        if e := service.ListWithMetadata(&pb.SetListRequest{ReferenceId: string(reference)}, stream); e != nil {
+            t.Fatalf("%s: ListWithMetadata failed: %v", resourceType, e)
+        }
+        if len(stream.sent) != 3 || !stream.sent[0].Status.Success {
+            t.Fatalf("%s: expected a header and 2 elements, got %v", resourceType, stream.sent)
+        }
+
+        for _, m := range stream.sent[1:] {
+            switch string(m.Object) {
+            case "a":
+                md := m.Metadata
+                if md == nil || md.ReplicaId != db.ReplicaId() || md.Timestamp == 0 || md.Labels["user"] != "alice" {
+                    t.Errorf("%s: unexpected metadata %v", resourceType, md)
+                }
+            case "b":
+                if m.Metadata != nil { t.Errorf("%s: unexpected metadata %v", resourceType, m.Metadata) }
+            default:
+                t.Errorf("%s: unexpected element %q", resourceType, m.Object)
+            }
+        }
+    }
+}
+
+func Test_SetService_ListWithMetadata_Restore(t *testing.T) {
+    reference := newTestMetadataSetReference(t, ORSET_RESOURCE_TYPE)
+    r, _ := db.Resolve(reference)
+    resource := r.(*SetResource)
+
+    service := NewSetResourceService(db)
+    object := &pb.ResourceObject{ReferenceId: string(reference), Object: []byte("a")}
+    service.Insert(context.Background(), &pb.SetInsertRequest{Object: object, Labels: map[string]string{"k": "v"}})
+    replicaId := db.ReplicaId()
+
+    if e := db.Commit(reference); e != nil { t.Fatalf("Failed to commit resource: %v", e) }
+
+    // Re-initialize database, restoring the set from storage.
+    initDatabase(t)
+    RegisterResourceTypes(db, set.SystemClock, t.TempDir())
+
+    reference, e := db.Attach(resource.Id(), resource.Key())
+    if e != nil { t.Fatalf("Failed to attach resource: %v", e) }
+
+    stream := &metadataStream{}
+    NewSetResourceService(db).ListWithMetadata(&pb.SetListRequest{ReferenceId: string(reference)}, stream)
+    if len(stream.sent) != 2 { t.Fatalf("Expected a header and 1 element, got %v", stream.sent) }
+
+    md := stream.sent[1].Metadata
+    if md == nil || md.ReplicaId != replicaId || md.Labels["k"] != "v" {
+        t.Errorf("Unexpected metadata after restore %v", md)
+    }
+}
+
+func Test_SetService_ListWithMetadata_Invalid(t *testing.T) {
+    initDatabase(t)
+
+    stream := &metadataStream{}
+    NewSetResourceService(db).ListWithMetadata(&pb.SetListRequest{ReferenceId: "invalid"}, stream)
+    if len(stream.sent) != 1 || stream.sent[0].Status.ErrorType != E_INVALID_REFERENCE.Error() {
+        t.Errorf("Expected invalid reference error, got %v", stream.sent)
+    }
+
+    // Sets kept on disk keep no metadata.
+    reference := newTestMetadataSetReference(t, DISKGSET_RESOURCE_TYPE)
+
+    stream = &metadataStream{}
+    NewSetResourceService(db).ListWithMetadata(&pb.SetListRequest{ReferenceId: string(reference)}, stream)
+    if len(stream.sent) != 1 || stream.sent[0].Status.ErrorType != E_NOT_SUPPORTED.Error() {
+        t.Errorf("Expected not supported error, got %v", stream.sent)
+    }
+}
+
+func Test_SetClient_ListWithMetadata(t *testing.T) {
+    resourceId, resourceKey, e := c.Create(TWOPHASESET_RESOURCE_TYPE, "file", "aes-256-cbc")
+    if e != nil { t.Fatalf("Create failed: %v", e) }
+    reference, e := c.Attach(resourceId, resourceKey)
+    if e != nil { t.Fatalf("Attach failed: %v", e) }
+
+    if e := c.TwoPhaseSetClient.InsertWithLabels(reference, []byte("a"), map[string]string{"user": "bob"}); e != nil {
+        t.Fatalf("InsertWithLabels failed: %v", e)
+    }
+    if e := c.TwoPhaseSetClient.Insert(reference, []byte("b")); e != nil { t.Fatalf("Insert failed: %v", e) }
+
+    ch, e := c.TwoPhaseSetClient.ListWithMetadata(reference)
+    if e != nil { t.Fatalf("ListWithMetadata failed: %v", e) }
+
+    result := make(map[string]set.Metadata)
+    for element := range ch { result[string(element.Object)] = element.Metadata }
+
+    if len(result) != 2 { t.Fatalf("Expected 2 elements, got %v", result) }
+    if md := result["a"]; md.Labels["user"] != "bob" || md.ReplicaId == "" || md.Timestamp == 0 {
+        t.Errorf("Unexpected metadata %v", md)
+    }
+    if md := result["b"]; md.ReplicaId == "" || len(md.Labels) != 0 {
+        t.Errorf("Unexpected metadata %v", md)
+    }
+
+    if _, e := c.GSetClient.ListWithMetadata("invalid"); e == nil || e.Error() != E_INVALID_REFERENCE.Error() {
+        t.Errorf("Expected invalid reference error from ListWithMetadata, got: %v", e)
+    }
+}
@@ -0,0 +1,346 @@
+/*
+ * Copyright (c) 2015 Tom Swindell (t.swindell@rubyx.co.uk)
+ *
+ * Permission is hereby granted, free of charge, to any person obtaining a copy
+ * of this software and associated documentation files (the "Software"), to deal
+ * in the Software without restriction, including without limitation the rights
+ * to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+ * copies of the Software, and to permit persons to whom the Software is
+ * furnished to do so, subject to the following conditions:
+ *
+ * The above copyright notice and this permission notice shall be included in
+ * all copies or substantial portions of the Software.
+ *
+ * THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+ * IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+ * FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+ * AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+ * LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+ * OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+ * SOFTWARE.
+ *
+ */
+package crdb
+
+import (
+    "bytes"
+    "fmt"
+    "math/rand"
+    "os"
+    "sort"
+)
+
+// The SimConfig type describes a simulated deployment, and the faults of the
+// message bus between its nodes. Times are in ticks of the virtual clock.
+type SimConfig struct {
+    Seed  int64 // Seeds every random choice of the simulation.
+    Nodes int   // Number of databases in the deployment, DEFAULT 3.
+
+    DropRate      float64 // Probability that a message is lost.
+    DuplicateRate float64 // Probability that a message is delivered twice.
+    MinDelay      int64   // Ticks each message spends on the bus, chosen
+    MaxDelay      int64   // uniformly so that messages are reordered.
+
+    MaxSkew        int64   // Largest offset of a node clock from virtual time.
+    GossipInterval int64   // Ticks between the gossip rounds of a node, DEFAULT 5.
+    OperationRate  float64 // Probability a node operates on each tick, DEFAULT 0.5.
+}
+
+const (
+    SIM_DEFAULT_NODES           = 3
+    SIM_DEFAULT_GOSSIP_INTERVAL = 5
+    SIM_DEFAULT_OPERATION_RATE  = 0.5
+)
+
+// The SimOperation type applies one random operation to resource, on the node
+// with replicaId.
+type SimOperation func(r *rand.Rand, resource Resource, replicaId string) error
+
+// The SimStats type counts the messages handled by the bus.
+type SimStats struct {
+    Sent       int
+    Dropped    int // Lost, or sent across a partition.
+    Duplicated int
+    Delivered  int
+}
+
+// A node clock reads virtual time with a fixed skew.
+type simClock struct {
+    sim  *Simulator
+    skew int64
+}
+
+func (c *simClock) Now() int64 { return c.sim.now + c.skew }
+
+type simNode struct {
+    database   *Database
+    references []ReferenceId // Reference to each resource, by creation order.
+    gossipAt   int64         // Offsets the gossip rounds of each node.
+}
+
+type simMessage struct {
+    at       int64
+    seq      uint64 // Orders messages delivered on the same tick.
+    from, to int
+    resource int
+    data     []byte
+}
+
+type simResource struct {
+    id  ResourceId
+    key ResourceKey
+}
+
+// The Simulator type runs several databases in-process, exchanging the state
+// of their resources by gossip over a message bus which loses, duplicates,
+// delays and reorders messages, and which may be partitioned. Every choice of
+// the simulation is drawn from the seed, and time is virtual, so a scenario
+// replays the same schedule from the same seed. Identities which resources
+// draw at random themselves, such as ORSet tags, still differ between runs. A
+// Simulator is not safe for concurrent use.
+type Simulator struct {
+    config    SimConfig
+    rand      *rand.Rand
+    now       int64
+    nodes     []*simNode
+    resources []simResource
+    queue     []*simMessage
+    seq       uint64
+    groups    []int // Partition group of each node, nil when healed.
+
+    Stats SimStats
+}
+
+// The NewSimulator function returns a deployment of config.Nodes databases,
+// each with every resource type a server provides.
+func NewSimulator(config SimConfig) *Simulator {
+    if config.Nodes <= 0 { config.Nodes = SIM_DEFAULT_NODES }
+    if config.GossipInterval <= 0 { config.GossipInterval = SIM_DEFAULT_GOSSIP_INTERVAL }
+    if config.OperationRate <= 0 { config.OperationRate = SIM_DEFAULT_OPERATION_RATE }
+    if config.MaxDelay < config.MinDelay { config.MaxDelay = config.MinDelay }
+
+    s := &Simulator{config: config, rand: rand.New(rand.NewSource(config.Seed))}
+
+    for i := 0; i < config.Nodes; i++ {
+        clock := &simClock{s, 0}
+        if config.MaxSkew > 0 { clock.skew = s.rand.Int63n(2 * config.MaxSkew + 1) - config.MaxSkew }
+
+        database := NewDatabase()
+        database.replicaId = fmt.Sprint("replica-", i)
+        RegisterResourceTypes(database, clock, os.TempDir())
+
+        s.nodes = append(s.nodes, &simNode{
+            database: database,
+            gossipAt: s.rand.Int63n(config.GossipInterval),
+        })
+    }
+    return s
+}
+
+// The Now() method returns the virtual time, in ticks.
+func (s *Simulator) Now() int64 { return s.now }
+
+// The Create() method creates an empty resource of resourceType on every node,
+// returning the index of the resource.
+func (s *Simulator) Create(resourceType ResourceType) (int, error) {
+    index := len(s.resources)
+    resource := simResource{
+                    ResourceId(fmt.Sprint("sim:", index)),
+                    ResourceKey("sim:"),
+                }
+
+    for _, node := range s.nodes {
+        factory := node.database.datatypes.GetFactory(resourceType)
+        if factory == nil { return 0, E_UNKNOWN_TYPE }
+
+        node.database.datastore.Add(factory.Create(resource.id, resource.key))
+
+        referenceId, e := node.database.Attach(resource.id, resource.key)
+        if e != nil { return 0, e }
+        node.references = append(node.references, referenceId)
+    }
+
+    s.resources = append(s.resources, resource)
+    return index, nil
+}
+
+// The Resource() method returns the replica of resource held by node.
+func (s *Simulator) Resource(node, resource int) (Resource, error) {
+    return s.nodes[node].database.Resolve(s.nodes[node].references[resource])
+}
+
+// The Partition() method splits the nodes into groups, each node listed in at
+// most one group. Messages between groups, including those already on the bus,
+// are lost. Unlisted nodes form a group of their own.
+func (s *Simulator) Partition(groups ...[]int) {
+    s.groups = make([]int, len(s.nodes))
+    for i := range s.groups { s.groups[i] = -1 }
+
+    for g, nodes := range groups {
+        for _, node := range nodes { s.groups[node] = g }
+    }
+}
+
+// The Heal() method removes any partition.
+func (s *Simulator) Heal() {
+    s.groups = nil
+}
+
+func (s *Simulator) partitioned(a, b int) bool {
+    return s.groups != nil && s.groups[a] != s.groups[b]
+}
+
+func (s *Simulator) send(from, to, resource int, data []byte) {
+    s.Stats.Sent++
+
+    if s.partitioned(from, to) || s.rand.Float64() < s.config.DropRate {
+        s.Stats.Dropped++
+        return
+    }
+
+    copies := 1
+    if s.rand.Float64() < s.config.DuplicateRate {
+        s.Stats.Duplicated++
+        copies = 2
+    }
+
+    for i := 0; i < copies; i++ {
+        delay := s.config.MinDelay
+        if s.config.MaxDelay > s.config.MinDelay {
+            delay += s.rand.Int63n(s.config.MaxDelay - s.config.MinDelay + 1)
+        }
+
+        s.seq++
+        m := &simMessage{s.now + delay, s.seq, from, to, resource, data}
+
+        // Keep the queue ordered by delivery time, then by send order.
+        n := sort.Search(len(s.queue), func(i int) bool {
+            q := s.queue[i]
+            return q.at > m.at || (q.at == m.at && q.seq > m.seq)
+        })
+        s.queue = append(s.queue, nil)
+        copy(s.queue[n + 1:], s.queue[n:])
+        s.queue[n] = m
+    }
+}
+
+// Sends the state of each resource held by node to a random peer.
+func (s *Simulator) gossip(node int) error {
+    for i := range s.resources {
+        resource, e := s.Resource(node, i)
+        if e != nil { return e }
+
+        buff := new(bytes.Buffer)
+        if e := resource.Serialize(buff); e != nil { return e }
+
+        peer := s.rand.Intn(len(s.nodes) - 1)
+        if peer >= node { peer++ }
+
+        s.send(node, peer, i, buff.Bytes())
+    }
+    return nil
+}
+
+// Merges the state carried by m into the replica held by its recipient.
+func (s *Simulator) deliver(m *simMessage) error {
+    if s.partitioned(m.from, m.to) {
+        s.Stats.Dropped++
+        return nil
+    }
+    s.Stats.Delivered++
+
+    local, e := s.Resource(m.to, m.resource)
+    if e != nil { return e }
+
+    factory := s.nodes[m.to].database.datatypes.GetFactory(local.Type())
+    if factory == nil { return E_INVALID_TYPE }
+
+    resource := s.resources[m.resource]
+    in, e := factory.Restore(resource.id, resource.key, bytes.NewReader(m.data))
+    if e != nil { return e }
+
+    return factory.Merge(local, in)
+}
+
+// The Step() method advances virtual time by one tick. Messages due are
+// delivered, then each node applies operate to a random resource with the
+// configured probability, and nodes due to gossip do so. A nil operate only
+// exchanges state.
+func (s *Simulator) Step(operate SimOperation) error {
+    s.now++
+
+    for len(s.queue) > 0 && s.queue[0].at <= s.now {
+        m := s.queue[0]
+        s.queue = s.queue[1:]
+
+        if e := s.deliver(m); e != nil { return s.fail("deliver to node %d: %v", m.to, e) }
+    }
+
+    if operate != nil && len(s.resources) > 0 {
+        for i, node := range s.nodes {
+            if s.rand.Float64() >= s.config.OperationRate { continue }
+
+            resource, e := s.Resource(i, s.rand.Intn(len(s.resources)))
+            if e == nil { e = operate(s.rand, resource, node.database.ReplicaId()) }
+            if e != nil { return s.fail("operate on node %d: %v", i, e) }
+        }
+    }
+
+    if len(s.nodes) < 2 { return nil }
+
+    for i, node := range s.nodes {
+        if (s.now + node.gossipAt) % s.config.GossipInterval != 0 { continue }
+        if e := s.gossip(i); e != nil { return s.fail("gossip from node %d: %v", i, e) }
+    }
+    return nil
+}
+
+// The Run() method steps the simulation ticks times, applying operate.
+func (s *Simulator) Run(ticks int, operate SimOperation) error {
+    for i := 0; i < ticks; i++ {
+        if e := s.Step(operate); e != nil { return e }
+    }
+    return nil
+}
+
+// The Converged() method returns true when every node holds equal replicas of
+// every resource.
+func (s *Simulator) Converged() (bool, error) {
+    for i := range s.resources {
+        a, e := s.Resource(0, i)
+        if e != nil { return false, e }
+
+        factory := s.nodes[0].database.datatypes.GetFactory(a.Type())
+        if factory == nil { return false, E_INVALID_TYPE }
+
+        for node := 1; node < len(s.nodes); node++ {
+            b, e := s.Resource(node, i)
+            if e != nil { return false, e }
+
+            equal, e := factory.Equals(a, b)
+            if e != nil || !equal { return false, e }
+        }
+    }
+    return true, nil
+}
+
+// The Settle() method steps the simulation without operations until every
+// replica has converged, for at most ticks. It returns the ticks taken, and
+// an error if the replicas have not converged by then.
+func (s *Simulator) Settle(ticks int) (int, error) {
+    for i := 0; i <= ticks; i++ {
+        converged, e := s.Converged()
+        if e != nil { return i, s.fail("compare replicas: %v", e) }
+        if converged { return i, nil }
+
+        if i < ticks {
+            if e := s.Step(nil); e != nil { return i, e }
+        }
+    }
+    return ticks, s.fail("replicas did not converge within %d ticks", ticks)
+}
+
+// Returns an error identifying the seed and tick which reproduce it.
+func (s *Simulator) fail(format string, args ...interface{}) error {
+    return fmt.Errorf("simulation seed %d, tick %d: %s", s.config.Seed, s.now, fmt.Sprintf(format, args...))
+}
@@ -0,0 +1,116 @@
+/*
+ * Copyright (c) 2015 Tom Swindell (t.swindell@rubyx.co.uk)
+ *
+ * Permission is hereby granted, free of charge, to any person obtaining a copy
+ * of this software and associated documentation files (the "Software"), to deal
+ * in the Software without restriction, including without limitation the rights
+ * to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+ * copies of the Software, and to permit persons to whom the Software is
+ * furnished to do so, subject to the following conditions:
+ *
+ * The above copyright notice and this permission notice shall be included in
+ * all copies or substantial portions of the Software.
+ *
+ * THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+ * IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+ * FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+ * AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+ * LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+ * OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+ * SOFTWARE.
+ *
+ */
+
+package crdb
+
+import (
+    "bytes"
+    "io"
+)
+
+// Calls write with a writer for the resource data in storage, buffering the
+// data for backends which do not implement StreamStorage.
+func writeData(storage Storage, id ResourceId, key ResourceKey, write func(io.Writer) error) error {
+    if s, ok := storage.(StreamStorage); ok { return s.WriteData(id, key, write) }
+
+    buff := new(bytes.Buffer)
+    if e := write(buff); e != nil { return e }
+    return storage.SetData(id, key, buff.Bytes())
+}
+
+// Sends a reader for each version of the resource data in storage, and always
+// closes ch, even for backends whose GetData may return without closing it.
+func readData(storage Storage, id ResourceId, key ResourceKey, ch chan io.ReadCloser) error {
+    if s, ok := storage.(StreamStorage); ok { return s.ReadData(id, key, ch) }
+
+    defer close(ch)
+
+    in := make(chan []byte)
+    done := make(chan error, 1)
+    go func() { done <- storage.GetData(id, key, in) }()
+
+    for {
+        select {
+        case data, ok := <-in:
+            if !ok { return <-done }
+            ch<- io.NopCloser(bytes.NewReader(data))
+        case e := <-done:
+            return e
+        }
+    }
+}
+
+// The bufferedEncrypter type encrypts everything written to it on Close, for
+// crypto methods which do not implement StreamCryptoMethod.
+type bufferedEncrypter struct {
+    bytes.Buffer
+
+    crypto CryptoMethod
+    key    ResourceKey
+    w      io.Writer
+}
+
+func (d *bufferedEncrypter) Close() error {
+    data, e := d.crypto.Encrypt(d.key, d.Bytes())
+    if e != nil { return e }
+
+    _, e = d.w.Write(data)
+    return e
+}
+
+// Returns a writer encrypting to w with crypto, which must be closed.
+func encryptWriter(crypto CryptoMethod, key ResourceKey, w io.Writer) (io.WriteCloser, error) {
+    if c, ok := crypto.(StreamCryptoMethod); ok { return c.EncryptWriter(key, w) }
+    return &bufferedEncrypter{crypto: crypto, key: key, w: w}, nil
+}
+
+// Returns a reader decrypting r with crypto. Methods which do not implement
+// StreamCryptoMethod read and authenticate all of r first.
+func decryptReader(crypto CryptoMethod, key ResourceKey, r io.Reader) (io.Reader, error) {
+    if c, ok := crypto.(StreamCryptoMethod); ok { return c.DecryptReader(key, r) }
+
+    data, e := io.ReadAll(r)
+    if e != nil { return nil, e }
+
+    data, e = crypto.Decrypt(key, data)
+    if e != nil { return nil, e }
+    return bytes.NewReader(data), nil
+}
+
+// The countingWriter type discards everything written to it, counting the
+// bytes.
+type countingWriter struct {
+    n uint64
+}
+
+func (d *countingWriter) Write(p []byte) (int, error) {
+    d.n += uint64(len(p))
+    return len(p), nil
+}
+
+// Returns the size of resource serialized, without holding it in memory.
+func serializedSize(resource Resource) (uint64, error) {
+    w := new(countingWriter)
+    if e := resource.Serialize(w); e != nil { return 0, e }
+    return w.n, nil
+}
@@ -2,13 +2,15 @@ package crdb
 
 import "sync"
 
+// The registries of the database convert to and from ThreadSafeMap by value, so
+// the lock is held by pointer for every copy to share it.
 type ThreadSafeMap struct {
-    sync.RWMutex
+    *sync.RWMutex
     dict map[interface{}]interface{}
 }
 
 func NewThreadSafeMap() ThreadSafeMap {
-    return ThreadSafeMap{dict: make(map[interface{}]interface{})}
+    return ThreadSafeMap{&sync.RWMutex{}, make(map[interface{}]interface{})}
 }
 
 func (d ThreadSafeMap) Insert(k, v interface{}) bool {
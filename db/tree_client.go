@@ -0,0 +1,101 @@
+/*
+ * Copyright (c) 2015 Tom Swindell (t.swindell@rubyx.co.uk)
+ *
+ * Permission is hereby granted, free of charge, to any person obtaining a copy
+ * of this software and associated documentation files (the "Software"), to deal
+ * in the Software without restriction, including without limitation the rights
+ * to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+ * copies of the Software, and to permit persons to whom the Software is
+ * furnished to do so, subject to the following conditions:
+ *
+ * The above copyright notice and this permission notice shall be included in
+ * all copies or substantial portions of the Software.
+ *
+ * THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+ * IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+ * FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+ * AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+ * LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+ * OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+ * SOFTWARE.
+ *
+ */
+
+package crdb
+
+import (
+    "fmt"
+
+    "google.golang.org/grpc"
+    "golang.org/x/net/context"
+
+    pb "github.com/tswindell/go-crdt/protos"
+)
+
+type TreeClient struct {
+    pb.TreeClient
+}
+
+func NewTreeClient(connection *grpc.ClientConn) *TreeClient {
+    d := new(TreeClient)
+    d.TreeClient = pb.NewTreeClient(connection)
+    return d
+}
+
+// Tree API extensions to CRDB Client type
+func (d *TreeClient) Create(referenceId ReferenceId, node string, parent string) error {
+    r, e := d.TreeClient.Create(context.Background(),
+                                &pb.TreeCreateRequest{
+                                    ReferenceId: string(referenceId),
+                                    Node: node,
+                                    Parent: parent,
+                                })
+    if e != nil { return e }
+    if !r.Status.Success { return fmt.Errorf(r.Status.ErrorType) }
+    return nil
+}
+
+func (d *TreeClient) Move(referenceId ReferenceId, node string, parent string) error {
+    r, e := d.TreeClient.Move(context.Background(),
+                              &pb.TreeMoveRequest{
+                                  ReferenceId: string(referenceId),
+                                  Node: node,
+                                  Parent: parent,
+                              })
+    if e != nil { return e }
+    if !r.Status.Success { return fmt.Errorf(r.Status.ErrorType) }
+    return nil
+}
+
+func (d *TreeClient) Delete(referenceId ReferenceId, node string) error {
+    r, e := d.TreeClient.Delete(context.Background(),
+                                &pb.TreeDeleteRequest{
+                                    ReferenceId: string(referenceId),
+                                    Node: node,
+                                })
+    if e != nil { return e }
+    if !r.Status.Success { return fmt.Errorf(r.Status.ErrorType) }
+    return nil
+}
+
+func (d *TreeClient) Parent(referenceId ReferenceId, node string) (string, error) {
+    r, e := d.TreeClient.Parent(context.Background(),
+                                &pb.TreeParentRequest{
+                                    ReferenceId: string(referenceId),
+                                    Node: node,
+                                })
+    if e != nil { return "", e }
+    if !r.Status.Success { return "", fmt.Errorf(r.Status.ErrorType) }
+    return r.Parent, nil
+}
+
+func (d *TreeClient) Children(referenceId ReferenceId, node string) ([]string, error) {
+    r, e := d.TreeClient.Children(context.Background(),
+                                  &pb.TreeChildrenRequest{
+                                      ReferenceId: string(referenceId),
+                                      Node: node,
+                                  })
+    if e != nil { return nil, e }
+    if !r.Status.Success { return nil, fmt.Errorf(r.Status.ErrorType) }
+    return r.Nodes, nil
+}
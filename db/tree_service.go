@@ -0,0 +1,182 @@
+/*
+ * Copyright (c) 2015 Tom Swindell (t.swindell@rubyx.co.uk)
+ *
+ * Permission is hereby granted, free of charge, to any person obtaining a copy
+ * of this software and associated documentation files (the "Software"), to deal
+ * in the Software without restriction, including without limitation the rights
+ * to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+ * copies of the Software, and to permit persons to whom the Software is
+ * furnished to do so, subject to the following conditions:
+ *
+ * The above copyright notice and this permission notice shall be included in
+ * all copies or substantial portions of the Software.
+ *
+ * THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+ * IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+ * FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+ * AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+ * LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+ * OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+ * SOFTWARE.
+ *
+ */
+
+package crdb
+
+import (
+    "io"
+
+    "github.com/tswindell/go-crdt/trees"
+
+    "golang.org/x/net/context"
+    pb "github.com/tswindell/go-crdt/protos"
+)
+
+const (
+    TREE_RESOURCE_TYPE = ResourceType("crdt:tree")
+)
+
+
+// The TreeResource type
+type TreeResource struct {
+    ResourceBase
+
+    context *tree.Tree
+}
+
+func (d *TreeResource) Serialize(w io.Writer) error {
+    return serializeBuffered(w, d.context.Serialize)
+}
+
+func (d *TreeResource) Deserialize(r io.Reader) error {
+    return deserializeBuffered(r, d.context.Deserialize)
+}
+
+// The NewTreeResource function adheres to ResourceFactoryFunc prototype.
+func NewTreeResource(resourceId ResourceId, resourceKey ResourceKey) Resource {
+    return &TreeResource{
+               ResourceBase{resourceId, resourceKey, TREE_RESOURCE_TYPE},
+               tree.NewTree(),
+           }
+}
+
+
+// The TreeResourceType type
+type TreeResourceType struct {
+    database *Database
+}
+
+func NewTreeResourceType(database *Database) *TreeResourceType {
+    return &TreeResourceType{database}
+}
+
+func (d *TreeResourceType) TypeId() ResourceType { return TREE_RESOURCE_TYPE }
+
+func (d *TreeResourceType) Create(resourceId ResourceId, resourceKey ResourceKey) Resource {
+    return NewTreeResource(resourceId, resourceKey)
+}
+
+func (d *TreeResourceType) Equals(aResource, bResource Resource) (bool, error) {
+    return aResource.(*TreeResource).context.Equals(bResource.(*TreeResource).context), nil
+}
+
+func (d *TreeResourceType) Merge(aResource, bResource Resource) error {
+    aResource.(*TreeResource).context.Merge(bResource.(*TreeResource).context)
+    return nil
+}
+
+func (d *TreeResourceType) Clone(resource Resource) (Resource, error) {
+    newResource, e := d.database.Create(resource.Type(),
+                                        resource.Id().GetStorageId(),
+                                        resource.Key().TypeId())
+    if e != nil { return nil, e }
+
+    newResource.(*TreeResource).context = resource.(*TreeResource).context.Clone()
+    return newResource, nil
+}
+
+func (d *TreeResourceType) Restore(resourceId ResourceId, resourceKey ResourceKey, r io.Reader) (Resource, error) {
+    resource := NewTreeResource(resourceId, resourceKey)
+    if e := resource.Deserialize(r); e != nil { return nil, e }
+    return resource, nil
+}
+
+
+// The TreeResourceService type
+type TreeResourceService struct {
+    database *Database
+}
+
+func NewTreeResourceService(database *Database) *TreeResourceService {
+    return &TreeResourceService{database}
+}
+
+// Resolves referenceId to the concrete tree instance.
+func (d *TreeResourceService) resolve(referenceId ReferenceId) (*tree.Tree, error) {
+    r, e := d.database.Resolve(referenceId)
+    if e != nil { return nil, e }
+
+    resource, ok := r.(*TreeResource)
+    if !ok { return nil, E_TYPE_MISMATCH }
+
+    return resource.context, nil
+}
+
+// The Create() service method
+func (d *TreeResourceService) Create(ctx context.Context, m *pb.TreeCreateRequest) (*pb.TreeCreateResponse, error) {
+    t, e := d.resolve(ReferenceId(m.ReferenceId))
+    if e == nil { e = t.Create(d.database.ReplicaId(), m.Node, m.Parent) }
+    if e != nil {
+        return &pb.TreeCreateResponse{Status:&pb.Status{Success:false,ErrorType:e.Error()}}, nil
+    }
+
+    return &pb.TreeCreateResponse{Status:&pb.Status{Success:true}}, nil
+}
+
+// The Move() service method
+func (d *TreeResourceService) Move(ctx context.Context, m *pb.TreeMoveRequest) (*pb.TreeMoveResponse, error) {
+    t, e := d.resolve(ReferenceId(m.ReferenceId))
+    if e == nil { e = t.Move(d.database.ReplicaId(), m.Node, m.Parent) }
+    if e != nil {
+        return &pb.TreeMoveResponse{Status:&pb.Status{Success:false,ErrorType:e.Error()}}, nil
+    }
+
+    return &pb.TreeMoveResponse{Status:&pb.Status{Success:true}}, nil
+}
+
+// The Delete() service method
+func (d *TreeResourceService) Delete(ctx context.Context, m *pb.TreeDeleteRequest) (*pb.TreeDeleteResponse, error) {
+    t, e := d.resolve(ReferenceId(m.ReferenceId))
+    if e == nil { e = t.Delete(d.database.ReplicaId(), m.Node) }
+    if e != nil {
+        return &pb.TreeDeleteResponse{Status:&pb.Status{Success:false,ErrorType:e.Error()}}, nil
+    }
+
+    return &pb.TreeDeleteResponse{Status:&pb.Status{Success:true}}, nil
+}
+
+// The Parent() service method
+func (d *TreeResourceService) Parent(ctx context.Context, m *pb.TreeParentRequest) (*pb.TreeParentResponse, error) {
+    var parent string
+
+    t, e := d.resolve(ReferenceId(m.ReferenceId))
+    if e == nil { parent, e = t.Parent(m.Node) }
+    if e != nil {
+        return &pb.TreeParentResponse{Status:&pb.Status{Success:false,ErrorType:e.Error()}}, nil
+    }
+
+    return &pb.TreeParentResponse{Status:&pb.Status{Success:true}, Parent:parent}, nil
+}
+
+// The Children() service method
+func (d *TreeResourceService) Children(ctx context.Context, m *pb.TreeChildrenRequest) (*pb.TreeChildrenResponse, error) {
+    var children []string
+
+    t, e := d.resolve(ReferenceId(m.ReferenceId))
+    if e == nil { children, e = t.Children(m.Node) }
+    if e != nil {
+        return &pb.TreeChildrenResponse{Status:&pb.Status{Success:false,ErrorType:e.Error()}}, nil
+    }
+
+    return &pb.TreeChildrenResponse{Status:&pb.Status{Success:true}, Nodes:children}, nil
+}
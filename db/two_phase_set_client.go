@@ -65,12 +65,20 @@ func (d *TwoPhaseSetClient) List(referenceId ReferenceId) (chan []byte, error) {
 }
 
 func (d *TwoPhaseSetClient) Insert(referenceId ReferenceId, object []byte) error {
+    return d.InsertWithLabels(referenceId, object, nil)
+}
+
+// The InsertWithLabels() method inserts object, recording labels in its
+// metadata along with the time and the replica serving the insert.
+func (d *TwoPhaseSetClient) InsertWithLabels(referenceId ReferenceId, object []byte, labels map[string]string) error {
     r, e := d.TwoPhaseSetClient.Insert(context.Background(),
                                        &pb.SetInsertRequest{
                                            Object: &pb.ResourceObject{
                                                ReferenceId: string(referenceId),
                                                Object: object,
-                                           }})
+                                           },
+                                           Labels: labels,
+                                       })
     if e != nil { return e }
     if !r.Status.Success { return fmt.Errorf(r.Status.ErrorType) }
     return nil
@@ -110,3 +118,56 @@ func (d *TwoPhaseSetClient) Contains(referenceId ReferenceId, object []byte) (bo
     return r.Result, nil
 }
 
+
+// The Union(), Intersect() and Difference() methods stream the elements in
+// either, both, or only the first of the two sets. Operands may be set
+// resources of any type.
+func (d *TwoPhaseSetClient) Union(aRef, bRef ReferenceId) (chan []byte, error) {
+    return recvSetAlgebra(d.TwoPhaseSetClient.Union(context.Background(), setAlgebraRequest(aRef, bRef)))
+}
+
+func (d *TwoPhaseSetClient) Intersect(aRef, bRef ReferenceId) (chan []byte, error) {
+    return recvSetAlgebra(d.TwoPhaseSetClient.Intersect(context.Background(), setAlgebraRequest(aRef, bRef)))
+}
+
+func (d *TwoPhaseSetClient) Difference(aRef, bRef ReferenceId) (chan []byte, error) {
+    return recvSetAlgebra(d.TwoPhaseSetClient.Difference(context.Background(), setAlgebraRequest(aRef, bRef)))
+}
+
+// The UnionInto(), IntersectInto() and DifferenceInto() methods materialise
+// the result into a new resource of the type of aRef, which must then be
+// attached and committed like any other created resource.
+func (d *TwoPhaseSetClient) UnionInto(aRef, bRef ReferenceId, storageId, cryptoId string) (ResourceId, ResourceKey, error) {
+    ctx, cancel := context.WithCancel(context.Background())
+    defer cancel()
+    return recvSetAlgebraInto(d.TwoPhaseSetClient.Union(ctx, setAlgebraIntoRequest(aRef, bRef, storageId, cryptoId)))
+}
+
+func (d *TwoPhaseSetClient) IntersectInto(aRef, bRef ReferenceId, storageId, cryptoId string) (ResourceId, ResourceKey, error) {
+    ctx, cancel := context.WithCancel(context.Background())
+    defer cancel()
+    return recvSetAlgebraInto(d.TwoPhaseSetClient.Intersect(ctx, setAlgebraIntoRequest(aRef, bRef, storageId, cryptoId)))
+}
+
+func (d *TwoPhaseSetClient) DifferenceInto(aRef, bRef ReferenceId, storageId, cryptoId string) (ResourceId, ResourceKey, error) {
+    ctx, cancel := context.WithCancel(context.Background())
+    defer cancel()
+    return recvSetAlgebraInto(d.TwoPhaseSetClient.Difference(ctx, setAlgebraIntoRequest(aRef, bRef, storageId, cryptoId)))
+}
+
+func (d *TwoPhaseSetClient) IsSubset(aRef, bRef ReferenceId) (bool, error) {
+    return recvSetIsSubset(d.TwoPhaseSetClient.IsSubset(context.Background(),
+                                          &pb.SetIsSubsetRequest{
+                                              ReferenceId: string(aRef),
+                                              OtherReferenceId: string(bRef),
+                                          }))
+}
+
+// The ListWithMetadata() method streams the elements of the set along with
+// their metadata.
+func (d *TwoPhaseSetClient) ListWithMetadata(referenceId ReferenceId) (chan SetElement, error) {
+    return recvSetListWithMetadata(d.TwoPhaseSetClient.ListWithMetadata(context.Background(),
+                                          &pb.SetListRequest{
+                                              ReferenceId: string(referenceId),
+                                          }))
+}
@@ -0,0 +1,581 @@
+/*
+ * Copyright (c) 2015 Tom Swindell (t.swindell@rubyx.co.uk)
+ *
+ * Permission is hereby granted, free of charge, to any person obtaining a copy
+ * of this software and associated documentation files (the "Software"), to deal
+ * in the Software without restriction, including without limitation the rights
+ * to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+ * copies of the Software, and to permit persons to whom the Software is
+ * furnished to do so, subject to the following conditions:
+ *
+ * The above copyright notice and this permission notice shall be included in
+ * all copies or substantial portions of the Software.
+ *
+ * THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+ * IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+ * FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+ * AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+ * LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+ * OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+ * SOFTWARE.
+ *
+ */
+
+package document
+
+import (
+    "bytes"
+    "crypto/rand"
+    "encoding/binary"
+    "encoding/json"
+    "fmt"
+    "sort"
+    "strconv"
+    "strings"
+    "sync"
+
+    "github.com/tswindell/go-crdt/registers"
+    "github.com/tswindell/go-crdt/sequence"
+    "github.com/tswindell/go-crdt/sets"
+)
+
+var (
+    E_INVALID_PATH  = fmt.Errorf("crdt:error-invalid-path")
+    E_UNKNOWN_PATH  = fmt.Errorf("crdt:error-unknown-path")
+    E_INVALID_VALUE = fmt.Errorf("crdt:error-invalid-value")
+)
+
+// Kinds of JSON value, stored in the kind register of each node.
+const (
+    KIND_VALUE  = byte(1)
+    KIND_OBJECT = byte(2)
+    KIND_ARRAY  = byte(3)
+)
+
+// Size in bytes of the unique key given to each array element.
+const ELEMENT_KEY_SIZE = 16
+
+// A node holds every kind of JSON value at once, and its kind register selects
+// the visible one. Writes of different kinds to the same place therefore merge
+// like any other concurrent writes, the last writer wins.
+type node struct {
+    kind   *register.LWWRegister
+    value  *register.LWWRegister // Encoded JSON of a scalar value.
+    keys   *set.ORSet[string]    // Keys of an object.
+    fields map[string]*node
+    order  *sequence.RGA         // Element keys of an array.
+    items  map[string]*node
+}
+
+func newNode(clock set.Clock) *node {
+    return &node{
+               kind: register.NewLWWRegister(clock),
+               value: register.NewLWWRegister(clock),
+               keys: set.NewORSet[string](),
+               fields: make(map[string]*node),
+               order: sequence.NewRGA(),
+               items: make(map[string]*node),
+           }
+}
+
+func newElementKey() string {
+    key := make([]byte, ELEMENT_KEY_SIZE)
+    rand.Read(key)
+    return string(key)
+}
+
+func (n *node) getKind() byte {
+    kind := n.kind.Get()
+    if len(kind) == 0 { return KIND_VALUE }
+    return kind[0]
+}
+
+// Returns the position of the array element named by segment, which must be
+// less than limit.
+func (n *node) index(segment string, limit int) (int, error) {
+    i, e := strconv.Atoi(segment)
+    if e != nil || i < 0 { return 0, E_INVALID_PATH }
+    if i >= limit { return 0, E_UNKNOWN_PATH }
+    return i, nil
+}
+
+// Returns the visible child of n named by segment.
+func (n *node) child(segment string) (*node, error) {
+    switch n.getKind() {
+    case KIND_OBJECT:
+        if !n.keys.Contains(segment) { return nil, E_UNKNOWN_PATH }
+        return n.fields[segment], nil
+
+    case KIND_ARRAY:
+        i, e := n.index(segment, n.order.Length())
+        if e != nil { return nil, e }
+
+        keys, _ := n.order.Slice(i, i + 1)
+        return n.items[string(keys[0])], nil
+    }
+
+    return nil, E_INVALID_PATH
+}
+
+// Returns the converged value of n, ready for encoding as JSON.
+func (n *node) render() interface{} {
+    switch n.getKind() {
+    case KIND_OBJECT:
+        result := make(map[string]interface{})
+        for _, key := range n.keys.ToSlice() {
+            result[key] = n.fields[key].render()
+        }
+        return result
+
+    case KIND_ARRAY:
+        result := make([]interface{}, 0)
+        for _, key := range n.order.ToSlice() {
+            result = append(result, n.items[string(key)].render())
+        }
+        return result
+    }
+
+    value := n.value.Get()
+    if len(value) == 0 { return nil }
+    return json.RawMessage(value)
+}
+
+func (n *node) equals(other *node) bool {
+    if !n.kind.Equals(other.kind) || !n.value.Equals(other.value) { return false }
+    if !n.keys.Equals(other.keys) || !n.order.Equals(other.order) { return false }
+
+    return equalNodes(n.fields, other.fields) && equalNodes(n.items, other.items)
+}
+
+func equalNodes(a, b map[string]*node) bool {
+    if len(a) != len(b) { return false }
+
+    for key, an := range a {
+        bn, found := b[key]
+        if !found || !an.equals(bn) { return false }
+    }
+    return true
+}
+
+func (n *node) clone() *node {
+    return &node{
+               kind: n.kind.Clone(),
+               value: n.value.Clone(),
+               keys: n.keys.Clone(),
+               fields: cloneNodes(n.fields),
+               order: n.order.Clone(),
+               items: cloneNodes(n.items),
+           }
+}
+
+func cloneNodes(nodes map[string]*node) map[string]*node {
+    result := make(map[string]*node)
+    for key, n := range nodes { result[key] = n.clone() }
+    return result
+}
+
+func (n *node) merge(clock set.Clock, other *node) {
+    n.kind.Merge(other.kind)
+    n.value.Merge(other.value)
+    n.keys.Merge(other.keys)
+    n.order.Merge(other.order)
+
+    mergeNodes(clock, n.fields, other.fields)
+    mergeNodes(clock, n.items, other.items)
+    n.prune()
+}
+
+// Drops the nodes of deleted array elements, which can never be visible again.
+// Pruning depends only on the order of the array, so replicas prune alike once
+// their orders have converged.
+func (n *node) prune() {
+    if len(n.items) == n.order.Length() { return }
+
+    visible := make(map[string]struct{})
+    for _, key := range n.order.ToSlice() { visible[string(key)] = struct{}{} }
+
+    for key := range n.items {
+        if _, found := visible[key]; !found { delete(n.items, key) }
+    }
+}
+
+// Removes the observed keys and elements of n and its descendants, so that
+// only those written concurrently survive a merge.
+func (n *node) clear() {
+    for _, key := range n.keys.ToSlice() {
+        n.keys.Remove(key)
+        n.fields[key].clear()
+    }
+
+    n.order.DeleteAt(0, n.order.Length())
+    n.prune()
+}
+
+func mergeNodes(clock set.Clock, a, b map[string]*node) {
+    for key, bn := range b {
+        an, found := a[key]
+        if !found {
+            an = newNode(clock)
+            a[key] = an
+        }
+        an.merge(clock, bn)
+    }
+}
+
+// Returns true when every visible key and element of n, and its descendants,
+// has a node.
+func (n *node) valid() bool {
+    for _, key := range n.keys.ToSlice() {
+        if _, found := n.fields[key]; !found { return false }
+    }
+
+    for _, key := range n.order.ToSlice() {
+        if _, found := n.items[string(key)]; !found { return false }
+    }
+
+    for _, child := range n.fields {
+        if !child.valid() { return false }
+    }
+    for _, child := range n.items {
+        if !child.valid() { return false }
+    }
+    return true
+}
+
+func (n *node) serialize(buff *bytes.Buffer) error {
+    if e := n.kind.Serialize(buff); e != nil { return e }
+    if e := n.value.Serialize(buff); e != nil { return e }
+    if e := n.keys.Serialize(buff); e != nil { return e }
+    if e := serializeNodes(buff, n.fields); e != nil { return e }
+    if e := n.order.Serialize(buff); e != nil { return e }
+    return serializeNodes(buff, n.items)
+}
+
+func serializeNodes(buff *bytes.Buffer, nodes map[string]*node) error {
+    keys := make([]string, 0, len(nodes))
+    for key := range nodes { keys = append(keys, key) }
+    sort.Strings(keys)
+
+    binary.Write(buff, binary.LittleEndian, uint32(len(keys)))
+    for _, key := range keys {
+        writeKey(buff, key)
+        if e := nodes[key].serialize(buff); e != nil { return e }
+    }
+    return nil
+}
+
+func deserializeNode(clock set.Clock, buff *bytes.Buffer) (*node, error) {
+    n := newNode(clock)
+
+    if e := n.kind.Deserialize(buff); e != nil { return nil, e }
+    if e := n.value.Deserialize(buff); e != nil { return nil, e }
+    if e := n.keys.Deserialize(buff); e != nil { return nil, e }
+    if e := deserializeNodes(clock, buff, n.fields); e != nil { return nil, e }
+    if e := n.order.Deserialize(buff); e != nil { return nil, e }
+    if e := deserializeNodes(clock, buff, n.items); e != nil { return nil, e }
+
+    return n, nil
+}
+
+func deserializeNodes(clock set.Clock, buff *bytes.Buffer, nodes map[string]*node) error {
+    var count uint32
+    if e := binary.Read(buff, binary.LittleEndian, &count); e != nil { return e }
+
+    for i := uint32(0); i < count; i++ {
+        key, e := readKey(buff)
+        if e != nil { return e }
+
+        n, e := deserializeNode(clock, buff)
+        if e != nil { return e }
+
+        nodes[key] = n
+    }
+    return nil
+}
+
+// Common Go representation of a JSON document, composed of observed-remove
+// maps for objects, replicated growable arrays for arrays and last-writer-wins
+// registers for everything else. Values are addressed by JSON Pointer paths,
+// as described in RFC 6901, the empty path referring to the whole document.
+type Document struct {
+    sync.RWMutex
+    clock set.Clock
+    root  *node
+}
+
+func NewDocument(clock set.Clock) *Document {
+    return &Document{clock: clock, root: newNode(clock)}
+}
+
+// The ParsePath() function splits a JSON Pointer into its unescaped segments.
+func ParsePath(path string) ([]string, error) {
+    if path == "" { return []string{}, nil }
+    if path[0] != '/' { return nil, E_INVALID_PATH }
+
+    segments := strings.Split(path[1:], "/")
+    for i, segment := range segments {
+        segments[i] = strings.Replace(strings.Replace(segment, "~1", "/", -1), "~0", "~", -1)
+    }
+    return segments, nil
+}
+
+func parseValue(value []byte) (interface{}, error) {
+    if !json.Valid(value) { return nil, E_INVALID_VALUE }
+
+    var v interface{}
+    decoder := json.NewDecoder(bytes.NewReader(value))
+    decoder.UseNumber()
+    if e := decoder.Decode(&v); e != nil { return nil, E_INVALID_VALUE }
+    return v, nil
+}
+
+// Returns the node at the end of segments. The caller must hold the lock.
+func (d *Document) resolve(segments []string) (*node, error) {
+    n := d.root
+    for _, segment := range segments {
+        var e error
+        if n, e = n.child(segment); e != nil { return nil, e }
+    }
+    return n, nil
+}
+
+// Tags each object key along segments afresh, as field() does, so that a write
+// beneath them revives any deleted concurrently. Array elements are not
+// revived: a write beneath an element deleted concurrently is lost along with
+// it. Segments must resolve, and the caller must hold the lock.
+func (d *Document) touch(segments []string) {
+    n := d.root
+    for _, segment := range segments {
+        if n.getKind() == KIND_OBJECT {
+            n = d.field(n, segment)
+            continue
+        }
+        n, _ = n.child(segment)
+    }
+}
+
+// Returns the field of n named key, tagging key afresh so that the write
+// wins over a concurrent delete. The caller must hold the lock.
+func (d *Document) field(n *node, key string) *node {
+    n.keys.Remove(key)
+    n.keys.Insert(key)
+
+    child, found := n.fields[key]
+    if !found {
+        child = newNode(d.clock)
+        n.fields[key] = child
+    }
+    return child
+}
+
+// Inserts a new element into array n at pos. The caller must hold the lock.
+func (d *Document) element(replicaId string, n *node, pos int) *node {
+    key := newElementKey()
+    n.order.InsertAt(replicaId, pos, []byte(key))
+
+    child := newNode(d.clock)
+    n.items[key] = child
+    return child
+}
+
+// Writes value to n, replacing whatever n held. The caller must hold the lock.
+func (d *Document) assign(replicaId string, n *node, value interface{}) {
+    switch v := value.(type) {
+    case map[string]interface{}:
+        n.kind.Set(replicaId, []byte{KIND_OBJECT})
+
+        for _, key := range n.keys.ToSlice() {
+            if _, found := v[key]; !found {
+                n.keys.Remove(key)
+                n.fields[key].clear()
+            }
+        }
+
+        for key, child := range v {
+            d.assign(replicaId, d.field(n, key), child)
+        }
+
+    case []interface{}:
+        n.kind.Set(replicaId, []byte{KIND_ARRAY})
+        n.order.DeleteAt(0, n.order.Length())
+        n.prune()
+
+        for i, child := range v {
+            d.assign(replicaId, d.element(replicaId, n, i), child)
+        }
+
+    default:
+        data, _ := json.Marshal(v)
+        n.kind.Set(replicaId, []byte{KIND_VALUE})
+        n.value.Set(replicaId, data)
+    }
+}
+
+// The Get() method returns the converged value at path, encoded as JSON.
+func (d *Document) Get(path string) ([]byte, error) {
+    segments, e := ParsePath(path)
+    if e != nil { return nil, e }
+
+    d.RLock()
+    defer d.RUnlock()
+
+    n, e := d.resolve(segments)
+    if e != nil { return nil, e }
+
+    return json.Marshal(n.render())
+}
+
+// The Set() method writes the JSON encoded value at path. The parent of path
+// must exist, and be either an object or an array with an element at path.
+func (d *Document) Set(replicaId, path string, value []byte) error {
+    segments, e := ParsePath(path)
+    if e != nil { return e }
+
+    v, e := parseValue(value)
+    if e != nil { return e }
+
+    d.Lock()
+    defer d.Unlock()
+
+    if len(segments) == 0 {
+        d.assign(replicaId, d.root, v)
+        return nil
+    }
+
+    parent, e := d.resolve(segments[:len(segments) - 1])
+    if e != nil { return e }
+
+    last := segments[len(segments) - 1]
+    switch parent.getKind() {
+    case KIND_OBJECT:
+        d.touch(segments[:len(segments) - 1])
+        d.assign(replicaId, d.field(parent, last), v)
+
+    case KIND_ARRAY:
+        n, e := parent.child(last)
+        if e != nil { return e }
+
+        d.touch(segments[:len(segments) - 1])
+        d.assign(replicaId, n, v)
+
+    default:
+        return E_INVALID_PATH
+    }
+
+    return nil
+}
+
+// The Insert() method inserts the JSON encoded value into an array, before the
+// element at path. A last segment of "-" appends to the array.
+func (d *Document) Insert(replicaId, path string, value []byte) error {
+    segments, e := ParsePath(path)
+    if e != nil { return e }
+    if len(segments) == 0 { return E_INVALID_PATH }
+
+    v, e := parseValue(value)
+    if e != nil { return e }
+
+    d.Lock()
+    defer d.Unlock()
+
+    parent, e := d.resolve(segments[:len(segments) - 1])
+    if e != nil { return e }
+    if parent.getKind() != KIND_ARRAY { return E_INVALID_PATH }
+
+    length := parent.order.Length()
+
+    pos := length
+    if last := segments[len(segments) - 1]; last != "-" {
+        if pos, e = parent.index(last, length + 1); e != nil { return e }
+    }
+
+    d.touch(segments[:len(segments) - 1])
+    d.assign(replicaId, d.element(replicaId, parent, pos), v)
+    return nil
+}
+
+// The Delete() method removes the object field or array element at path, along
+// with everything beneath it which this replica has observed. A concurrent
+// write beneath a deleted field revives the field, holding only what was
+// written concurrently.
+func (d *Document) Delete(path string) error {
+    segments, e := ParsePath(path)
+    if e != nil { return e }
+    if len(segments) == 0 { return E_INVALID_PATH }
+
+    d.Lock()
+    defer d.Unlock()
+
+    parent, e := d.resolve(segments[:len(segments) - 1])
+    if e != nil { return e }
+
+    last := segments[len(segments) - 1]
+    switch parent.getKind() {
+    case KIND_OBJECT:
+        if !parent.keys.Remove(last) { return E_UNKNOWN_PATH }
+        parent.fields[last].clear()
+
+    case KIND_ARRAY:
+        i, e := parent.index(last, parent.order.Length())
+        if e != nil { return e }
+        parent.order.DeleteAt(i, 1)
+        parent.prune()
+
+    default:
+        return E_INVALID_PATH
+    }
+
+    return nil
+}
+
+func (d *Document) Equals(other *Document) bool {
+    in := other.Clone()
+
+    d.RLock()
+    defer d.RUnlock()
+
+    return d.root.equals(in.root)
+}
+
+func (d *Document) Merge(other *Document) {
+    other.RLock()
+    in := other.root.clone()
+    other.RUnlock()
+
+    d.Lock()
+    defer d.Unlock()
+
+    d.root.merge(d.clock, in)
+}
+
+func (d *Document) Clone() *Document {
+    d.RLock()
+    defer d.RUnlock()
+
+    return &Document{clock: d.clock, root: d.root.clone()}
+}
+
+var DOCUMENT_HEADER_MAGIC = []byte{'c', 'r', 'd', 't', ':', 'j', 's', 'o', 'n', 0x00}
+
+func (d *Document) Serialize(buff *bytes.Buffer) error {
+    d.RLock()
+    defer d.RUnlock()
+
+    buff.Write(DOCUMENT_HEADER_MAGIC)
+    return d.root.serialize(buff)
+}
+
+// Deserialize merges the serialized state into this document.
+func (d *Document) Deserialize(buff *bytes.Buffer) error {
+    if e := set.ReadHeader(buff, DOCUMENT_HEADER_MAGIC); e != nil { return e }
+
+    in, e := deserializeNode(d.clock, buff)
+    if e != nil { return e }
+    if !in.valid() { return fmt.Errorf("invalid format") }
+
+    d.Lock()
+    defer d.Unlock()
+
+    d.root.merge(d.clock, in)
+    return nil
+}
@@ -0,0 +1,266 @@
+/*
+ * Copyright (c) 2015 Tom Swindell (t.swindell@rubyx.co.uk)
+ *
+ * Permission is hereby granted, free of charge, to any person obtaining a copy
+ * of this software and associated documentation files (the "Software"), to deal
+ * in the Software without restriction, including without limitation the rights
+ * to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+ * copies of the Software, and to permit persons to whom the Software is
+ * furnished to do so, subject to the following conditions:
+ *
+ * The above copyright notice and this permission notice shall be included in
+ * all copies or substantial portions of the Software.
+ *
+ * THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+ * IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+ * FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+ * AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+ * LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+ * OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+ * SOFTWARE.
+ *
+ */
+
+package document
+
+import "testing"
+import "bytes"
+
+// Deterministic clock, advancing by one on each reading.
+type tickClock struct { now int64 }
+
+func (c *tickClock) Now() int64 { c.now++; return c.now }
+
+func get(t *testing.T, d *Document, path string) string {
+    value, e := d.Get(path)
+    if e != nil { t.Fatal(e) }
+    return string(value)
+}
+
+func TestNewDocument(t *testing.T) {
+    a := NewDocument(&tickClock{})
+
+    if v := get(t, a, ""); v != "null" {
+        t.Error("New document should be null!", v)
+    }
+}
+
+func TestParsePath(t *testing.T) {
+    segments, e := ParsePath("/a~1b/c~0d/0")
+    if e != nil || len(segments) != 3 || segments[0] != "a/b" || segments[1] != "c~d" {
+        t.Error("Failed to parse path!", segments, e)
+    }
+
+    if _, e := ParsePath("a"); e != E_INVALID_PATH {
+        t.Error("Expected invalid path error!")
+    }
+}
+
+func TestDocumentSet(t *testing.T) {
+    a := NewDocument(&tickClock{})
+
+    if e := a.Set("A", "", []byte(`{"name":"crdb","tags":["a","b"],"size":1.5}`)); e != nil {
+        t.Fatal(e)
+    }
+
+    if v := get(t, a, ""); v != `{"name":"crdb","size":1.5,"tags":["a","b"]}` {
+        t.Error("Unexpected document!", v)
+    }
+
+    if e := a.Set("A", "/tags/1", []byte(`{"x":null}`)); e != nil {
+        t.Fatal(e)
+    }
+
+    if v := get(t, a, "/tags"); v != `["a",{"x":null}]` {
+        t.Error("Unexpected array!", v)
+    }
+
+    if e := a.Set("A", "/tags/2", []byte(`1`)); e != E_UNKNOWN_PATH {
+        t.Error("Expected unknown path error!")
+    }
+
+    if e := a.Set("A", "/name/x", []byte(`1`)); e != E_INVALID_PATH {
+        t.Error("Expected invalid path error!")
+    }
+
+    if e := a.Set("A", "/name", []byte(`{`)); e != E_INVALID_VALUE {
+        t.Error("Expected invalid value error!")
+    }
+
+    // Replacing an object drops fields missing from the new value.
+    a.Set("A", "", []byte(`{"name":"other"}`))
+    if v := get(t, a, ""); v != `{"name":"other"}` {
+        t.Error("Unexpected document!", v)
+    }
+}
+
+func TestDocumentInsertDelete(t *testing.T) {
+    a := NewDocument(&tickClock{})
+    a.Set("A", "", []byte(`{"list":[]}`))
+
+    a.Insert("A", "/list/-", []byte(`"b"`))
+    a.Insert("A", "/list/0", []byte(`"a"`))
+    a.Insert("A", "/list/-", []byte(`"c"`))
+
+    if v := get(t, a, "/list"); v != `["a","b","c"]` {
+        t.Error("Unexpected array!", v)
+    }
+
+    if e := a.Insert("A", "/list/4", []byte(`"d"`)); e != E_UNKNOWN_PATH {
+        t.Error("Expected unknown path error!")
+    }
+
+    if e := a.Insert("A", "/x", []byte(`1`)); e != E_INVALID_PATH {
+        t.Error("Expected invalid path error!")
+    }
+
+    if e := a.Delete("/list/1"); e != nil {
+        t.Fatal(e)
+    }
+
+    if e := a.Delete("/list"); e != nil {
+        t.Fatal(e)
+    }
+
+    if e := a.Delete("/list"); e != E_UNKNOWN_PATH {
+        t.Error("Expected unknown path error!")
+    }
+
+    if v := get(t, a, ""); v != `{}` {
+        t.Error("Unexpected document!", v)
+    }
+}
+
+func TestDocumentMerge(t *testing.T) {
+    clock := &tickClock{}
+
+    a := NewDocument(clock)
+    a.Set("A", "", []byte(`{"users":{},"log":[]}`))
+
+    b := a.Clone()
+
+    a.Set("A", "/users/alice", []byte(`["admin"]`))
+    a.Insert("A", "/log/-", []byte(`"from a"`))
+    b.Set("B", "/users/bob", []byte(`["guest"]`))
+    b.Insert("B", "/log/-", []byte(`"from b"`))
+
+    a.Merge(b)
+    b.Merge(a)
+
+    if !a.Equals(b) {
+        t.Fatal("Documents should be equal after merge!")
+    }
+
+    if v := get(t, a, "/users"); v != `{"alice":["admin"],"bob":["guest"]}` {
+        t.Error("Unexpected merged object!", v)
+    }
+
+    if v := get(t, a, "/log"); len(v) != len(`["from a","from b"]`) {
+        t.Error("Unexpected merged array!", v)
+    }
+}
+
+func TestDocumentConcurrentSetAndDelete(t *testing.T) {
+    clock := &tickClock{}
+
+    a := NewDocument(clock)
+    a.Set("A", "", []byte(`{"x":1}`))
+
+    b := a.Clone()
+    a.Delete("/x")
+    b.Set("B", "/x", []byte(`2`))
+
+    a.Merge(b)
+
+    if v := get(t, a, ""); v != `{"x":2}` {
+        t.Error("Concurrent write should win over delete!", v)
+    }
+}
+
+// A write beneath a field revives it after a concurrent delete, holding only
+// what was written concurrently.
+func TestDocumentConcurrentNestedSetAndDelete(t *testing.T) {
+    clock := &tickClock{}
+
+    a := NewDocument(clock)
+    a.Set("A", "", []byte(`{"a":{"x":1,"y":2}}`))
+
+    b := a.Clone()
+    a.Delete("/a")
+    b.Set("B", "/a/x", []byte(`3`))
+
+    a.Merge(b)
+    b.Merge(a)
+
+    if !a.Equals(b) || get(t, a, "") != `{"a":{"x":3}}` {
+        t.Error("Concurrent nested write should survive the delete!", get(t, a, ""))
+    }
+}
+
+// Nodes of deleted array elements are dropped, on overwrite and on merge.
+func TestDocumentArrayOverwrite(t *testing.T) {
+    clock := &tickClock{}
+
+    a := NewDocument(clock)
+    a.Set("A", "", []byte(`{"l":[{"x":1},2,3]}`))
+
+    b := a.Clone()
+    a.Set("A", "/l", []byte(`[4]`))
+    b.Set("B", "/l/0/x", []byte(`5`))
+
+    if n := len(a.root.fields["l"].items); n != 1 {
+        t.Errorf("Expected only the visible element to be kept, got %d", n)
+    }
+
+    a.Merge(b)
+    b.Merge(a)
+
+    if !a.Equals(b) || get(t, a, "/l") != `[4]` {
+        t.Error("Overwritten array should converge!", get(t, a, "/l"))
+    }
+
+    if n := len(b.root.fields["l"].items); n != 1 {
+        t.Errorf("Expected deleted elements to be dropped on merge, got %d", n)
+    }
+}
+
+func TestDocumentConcurrentKinds(t *testing.T) {
+    clock := &tickClock{}
+
+    a := NewDocument(clock)
+    b := NewDocument(clock)
+
+    a.Set("A", "", []byte(`[1]`))
+    b.Set("B", "", []byte(`{"a":1}`))
+
+    a.Merge(b)
+    b.Merge(a)
+
+    if !a.Equals(b) || get(t, a, "") != `{"a":1}` {
+        t.Error("Last writer should decide the kind of value!", get(t, a, ""))
+    }
+}
+
+func TestDocumentSerialization(t *testing.T) {
+    a := NewDocument(&tickClock{})
+    a.Set("A", "", []byte(`{"a":[1,2,{"b":true}],"c":"d"}`))
+    a.Delete("/a/0")
+
+    buff := new(bytes.Buffer)
+    if e := a.Serialize(buff); e != nil {
+        t.Fatal(e)
+    }
+
+    b := NewDocument(&tickClock{})
+    if e := b.Deserialize(buff); e != nil {
+        t.Fatal(e)
+    }
+
+    if !a.Equals(b) || get(t, b, "") != `{"a":[2,{"b":true}],"c":"d"}` {
+        t.Error("Deserialized document should equal original!", get(t, b, ""))
+    }
+
+    if e := b.Deserialize(bytes.NewBuffer([]byte("crdt:js"))); e == nil {
+        t.Error("Expected error deserializing invalid data!")
+    }
+}
@@ -0,0 +1,48 @@
+/*
+ * Copyright (c) 2015 Tom Swindell (t.swindell@rubyx.co.uk)
+ *
+ * Permission is hereby granted, free of charge, to any person obtaining a copy
+ * of this software and associated documentation files (the "Software"), to deal
+ * in the Software without restriction, including without limitation the rights
+ * to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+ * copies of the Software, and to permit persons to whom the Software is
+ * furnished to do so, subject to the following conditions:
+ *
+ * The above copyright notice and this permission notice shall be included in
+ * all copies or substantial portions of the Software.
+ *
+ * THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+ * IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+ * FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+ * AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+ * LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+ * OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+ * SOFTWARE.
+ *
+ */
+
+package document
+
+import (
+    "bytes"
+    "encoding/binary"
+    "fmt"
+    "io"
+)
+
+// Writes a map key or element key as uint32 length and raw data.
+func writeKey(buff *bytes.Buffer, key string) {
+    binary.Write(buff, binary.LittleEndian, uint32(len(key)))
+    buff.WriteString(key)
+}
+
+func readKey(buff *bytes.Buffer) (string, error) {
+    var l uint32
+    if e := binary.Read(buff, binary.LittleEndian, &l); e != nil { return "", e }
+
+    if uint64(l) > uint64(buff.Len()) { return "", fmt.Errorf("invalid format") }
+
+    data := make([]byte, l)
+    if _, e := io.ReadFull(buff, data); e != nil { return "", fmt.Errorf("invalid format") }
+    return string(data), nil
+}
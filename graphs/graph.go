@@ -0,0 +1,237 @@
+/*
+ * Copyright (c) 2015 Tom Swindell (t.swindell@rubyx.co.uk)
+ *
+ * Permission is hereby granted, free of charge, to any person obtaining a copy
+ * of this software and associated documentation files (the "Software"), to deal
+ * in the Software without restriction, including without limitation the rights
+ * to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+ * copies of the Software, and to permit persons to whom the Software is
+ * furnished to do so, subject to the following conditions:
+ *
+ * The above copyright notice and this permission notice shall be included in
+ * all copies or substantial portions of the Software.
+ *
+ * THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+ * IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+ * FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+ * AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+ * LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+ * OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+ * SOFTWARE.
+ *
+ */
+
+package graph
+
+import (
+    "bytes"
+    "encoding/binary"
+    "fmt"
+    "sync"
+
+    "github.com/tswindell/go-crdt/sets"
+)
+
+var (
+    E_UNKNOWN_VERTEX   = fmt.Errorf("crdt:error-unknown-vertex")
+    E_UNKNOWN_EDGE     = fmt.Errorf("crdt:error-unknown-edge")
+    E_VERTEX_EXISTS    = fmt.Errorf("crdt:error-vertex-exists")
+    E_EDGE_EXISTS      = fmt.Errorf("crdt:error-edge-exists")
+    E_VERTEX_HAS_EDGES = fmt.Errorf("crdt:error-vertex-has-edges")
+)
+
+// Common Go representation of an add-wins directed graph, composed of
+// observed-remove sets of vertices and edges. An edge can only be added
+// between existing vertices, and a vertex only removed once it has no edges.
+// An edge added concurrently with the removal of one of its vertices is kept,
+// but hidden until the vertex is added again.
+type Graph struct {
+    sync.RWMutex
+    vertices *set.ORSet[string]
+    edges    *set.ORSet[string]
+}
+
+func NewGraph() *Graph {
+    return &Graph{vertices: set.NewORSet[string](), edges: set.NewORSet[string]()}
+}
+
+func encodeVertex(vertex []byte) string {
+    return string(vertex)
+}
+
+// Edges are stored as the length of the source vertex, followed by both
+// vertices.
+func encodeEdge(from, to []byte) string {
+    buff := new(bytes.Buffer)
+    binary.Write(buff, binary.LittleEndian, uint32(len(from)))
+    buff.Write(from)
+    buff.Write(to)
+    return buff.String()
+}
+
+func decodeEdge(edge string) ([]byte, []byte) {
+    data := []byte(edge)
+    l := binary.LittleEndian.Uint32(data)
+    return data[4:4 + l], data[4 + l:]
+}
+
+// Returns true when both vertices of edge exist. The caller must hold the lock.
+func (g *Graph) visible(edge string) bool {
+    from, to := decodeEdge(edge)
+    return g.vertices.Contains(encodeVertex(from)) && g.vertices.Contains(encodeVertex(to))
+}
+
+func (g *Graph) AddVertex(vertex []byte) error {
+    g.Lock()
+    defer g.Unlock()
+
+    if !g.vertices.Insert(encodeVertex(vertex)) { return E_VERTEX_EXISTS }
+    return nil
+}
+
+func (g *Graph) RemoveVertex(vertex []byte) error {
+    g.Lock()
+    defer g.Unlock()
+
+    if !g.vertices.Contains(encodeVertex(vertex)) { return E_UNKNOWN_VERTEX }
+
+    // Hidden edges, whose other vertex was removed concurrently, can not be
+    // removed by hand so are removed along with the vertex.
+    hidden := make([]string, 0)
+    for _, edge := range g.edges.ToSlice() {
+        from, to := decodeEdge(edge)
+        if !bytes.Equal(from, vertex) && !bytes.Equal(to, vertex) { continue }
+
+        if g.visible(edge) { return E_VERTEX_HAS_EDGES }
+        hidden = append(hidden, edge)
+    }
+
+    for _, edge := range hidden { g.edges.Remove(edge) }
+    g.vertices.Remove(encodeVertex(vertex))
+    return nil
+}
+
+func (g *Graph) ContainsVertex(vertex []byte) bool {
+    g.RLock()
+    defer g.RUnlock()
+
+    return g.vertices.Contains(encodeVertex(vertex))
+}
+
+func (g *Graph) AddEdge(from, to []byte) error {
+    g.Lock()
+    defer g.Unlock()
+
+    if !g.vertices.Contains(encodeVertex(from)) || !g.vertices.Contains(encodeVertex(to)) {
+        return E_UNKNOWN_VERTEX
+    }
+
+    if !g.edges.Insert(encodeEdge(from, to)) { return E_EDGE_EXISTS }
+    return nil
+}
+
+func (g *Graph) RemoveEdge(from, to []byte) error {
+    g.Lock()
+    defer g.Unlock()
+
+    edge := encodeEdge(from, to)
+    if !g.edges.Contains(edge) || !g.visible(edge) { return E_UNKNOWN_EDGE }
+
+    g.edges.Remove(edge)
+    return nil
+}
+
+func (g *Graph) ContainsEdge(from, to []byte) bool {
+    g.RLock()
+    defer g.RUnlock()
+
+    edge := encodeEdge(from, to)
+    return g.edges.Contains(edge) && g.visible(edge)
+}
+
+func (g *Graph) Vertices() [][]byte {
+    g.RLock()
+    defer g.RUnlock()
+
+    results := make([][]byte, 0)
+    for _, vertex := range g.vertices.ToSlice() {
+        results = append(results, []byte(vertex))
+    }
+    return results
+}
+
+// The Neighbours() method returns the targets of the edges leaving vertex.
+func (g *Graph) Neighbours(vertex []byte) ([][]byte, error) {
+    g.RLock()
+    defer g.RUnlock()
+
+    if !g.vertices.Contains(encodeVertex(vertex)) { return nil, E_UNKNOWN_VERTEX }
+
+    results := make([][]byte, 0)
+    for _, edge := range g.edges.ToSlice() {
+        from, to := decodeEdge(edge)
+        if bytes.Equal(from, vertex) && g.visible(edge) { results = append(results, to) }
+    }
+    return results, nil
+}
+
+func (g *Graph) Equals(other *Graph) bool {
+    in := other.Clone()
+
+    g.RLock()
+    defer g.RUnlock()
+
+    return g.vertices.Equals(in.vertices) && g.edges.Equals(in.edges)
+}
+
+func (g *Graph) Merge(other *Graph) {
+    // Snapshot the other graph first, so two graphs merging into each other
+    // can never deadlock.
+    in := other.Clone()
+
+    g.Lock()
+    defer g.Unlock()
+
+    g.vertices.Merge(in.vertices)
+    g.edges.Merge(in.edges)
+}
+
+func (g *Graph) Clone() *Graph {
+    g.RLock()
+    defer g.RUnlock()
+
+    return &Graph{vertices: g.vertices.Clone(), edges: g.edges.Clone()}
+}
+
+var GRAPH_HEADER_MAGIC = []byte{'c', 'r', 'd', 't', ':', 'g', 'r', 'a', 'p', 'h', 0x00}
+
+func (g *Graph) Serialize(buff *bytes.Buffer) error {
+    g.RLock()
+    defer g.RUnlock()
+
+    buff.Write(GRAPH_HEADER_MAGIC)
+    if e := g.vertices.Serialize(buff); e != nil { return e }
+    return g.edges.Serialize(buff)
+}
+
+// Deserialize merges the serialized state into this graph.
+func (g *Graph) Deserialize(buff *bytes.Buffer) error {
+    if buff.Len() < len(GRAPH_HEADER_MAGIC) { return fmt.Errorf("data too small") }
+
+    header := make([]byte, len(GRAPH_HEADER_MAGIC))
+    _, e := buff.Read(header)
+    if e != nil || !bytes.Equal(header, GRAPH_HEADER_MAGIC) { return fmt.Errorf("invalid header") }
+
+    in := NewGraph()
+    if e := in.vertices.Deserialize(buff); e != nil { return e }
+    if e := in.edges.Deserialize(buff); e != nil { return e }
+
+    for _, edge := range in.edges.ToSlice() {
+        if len(edge) < 4 || uint64(binary.LittleEndian.Uint32([]byte(edge))) > uint64(len(edge) - 4) {
+            return fmt.Errorf("invalid format")
+        }
+    }
+
+    g.Merge(in)
+    return nil
+}
@@ -0,0 +1,178 @@
+/*
+ * Copyright (c) 2015 Tom Swindell (t.swindell@rubyx.co.uk)
+ *
+ * Permission is hereby granted, free of charge, to any person obtaining a copy
+ * of this software and associated documentation files (the "Software"), to deal
+ * in the Software without restriction, including without limitation the rights
+ * to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+ * copies of the Software, and to permit persons to whom the Software is
+ * furnished to do so, subject to the following conditions:
+ *
+ * The above copyright notice and this permission notice shall be included in
+ * all copies or substantial portions of the Software.
+ *
+ * THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+ * IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+ * FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+ * AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+ * LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+ * OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+ * SOFTWARE.
+ *
+ */
+
+package graph
+
+import "testing"
+import "bytes"
+
+func v(s string) []byte { return []byte(s) }
+
+func TestNewGraph(t *testing.T) {
+    a := NewGraph()
+    if len(a.Vertices()) != 0 {
+        t.Error("New graph should have no vertices!")
+    }
+}
+
+func TestGraphVertices(t *testing.T) {
+    a := NewGraph()
+
+    if e := a.AddVertex(v("a")); e != nil {
+        t.Fatal(e)
+    }
+
+    if e := a.AddVertex(v("a")); e != E_VERTEX_EXISTS {
+        t.Error("Expected vertex exists error!")
+    }
+
+    if !a.ContainsVertex(v("a")) || a.ContainsVertex(v("b")) {
+        t.Error("ContainsVertex check failed!")
+    }
+
+    if e := a.RemoveVertex(v("b")); e != E_UNKNOWN_VERTEX {
+        t.Error("Expected unknown vertex error!")
+    }
+
+    if e := a.RemoveVertex(v("a")); e != nil || a.ContainsVertex(v("a")) {
+        t.Error("Failed to remove vertex!")
+    }
+}
+
+func TestGraphEdges(t *testing.T) {
+    a := NewGraph()
+    a.AddVertex(v("a"))
+    a.AddVertex(v("b"))
+
+    if e := a.AddEdge(v("a"), v("c")); e != E_UNKNOWN_VERTEX {
+        t.Error("Edges should require existing vertices!")
+    }
+
+    if e := a.AddEdge(v("a"), v("b")); e != nil {
+        t.Fatal(e)
+    }
+
+    if e := a.AddEdge(v("a"), v("b")); e != E_EDGE_EXISTS {
+        t.Error("Expected edge exists error!")
+    }
+
+    if !a.ContainsEdge(v("a"), v("b")) || a.ContainsEdge(v("b"), v("a")) {
+        t.Error("Edges should be directed!")
+    }
+
+    if e := a.RemoveVertex(v("b")); e != E_VERTEX_HAS_EDGES {
+        t.Error("Vertices with edges should not be removable!")
+    }
+
+    neighbours, e := a.Neighbours(v("a"))
+    if e != nil || len(neighbours) != 1 || !bytes.Equal(neighbours[0], v("b")) {
+        t.Errorf("Unexpected neighbours: %q", neighbours)
+    }
+
+    if e := a.RemoveEdge(v("a"), v("b")); e != nil {
+        t.Fatal(e)
+    }
+
+    if e := a.RemoveEdge(v("a"), v("b")); e != E_UNKNOWN_EDGE {
+        t.Error("Expected unknown edge error!")
+    }
+
+    if e := a.RemoveVertex(v("b")); e != nil {
+        t.Error("Vertex without edges should be removable!")
+    }
+}
+
+func TestGraphConcurrentRemoveVertex(t *testing.T) {
+    a := NewGraph()
+    a.AddVertex(v("a"))
+    a.AddVertex(v("b"))
+
+    b := a.Clone()
+
+    a.RemoveVertex(v("b"))
+    b.AddEdge(v("a"), v("b"))
+
+    c := a.Clone()
+    c.Merge(b)
+    d := b.Clone()
+    d.Merge(a)
+
+    if !c.Equals(d) {
+        t.Error("Merge should be commutative!")
+    }
+
+    if c.ContainsVertex(v("b")) || c.ContainsEdge(v("a"), v("b")) {
+        t.Error("Edge to removed vertex should be hidden!")
+    }
+
+    if neighbours, _ := c.Neighbours(v("a")); len(neighbours) != 0 {
+        t.Errorf("Unexpected neighbours: %q", neighbours)
+    }
+
+    // The hidden edge does not prevent removing its remaining vertex.
+    if e := c.RemoveVertex(v("a")); e != nil {
+        t.Error(e)
+    }
+}
+
+func TestGraphConcurrentAddVertex(t *testing.T) {
+    a := NewGraph()
+    a.AddVertex(v("a"))
+
+    b := a.Clone()
+
+    a.RemoveVertex(v("a"))
+    b.RemoveVertex(v("a"))
+    b.AddVertex(v("a"))
+
+    a.Merge(b)
+
+    if !a.ContainsVertex(v("a")) {
+        t.Error("Concurrent add should win!")
+    }
+}
+
+func TestGraphSerialize(t *testing.T) {
+    a := NewGraph()
+    a.AddVertex(v("a"))
+    a.AddVertex(v("b"))
+    a.AddEdge(v("a"), v("b"))
+
+    buff := new(bytes.Buffer)
+    if e := a.Serialize(buff); e != nil {
+        t.Fatal(e)
+    }
+
+    b := NewGraph()
+    if e := b.Deserialize(buff); e != nil {
+        t.Fatal(e)
+    }
+
+    if !a.Equals(b) || !b.ContainsEdge(v("a"), v("b")) {
+        t.Error("Deserialized graph should equal original!")
+    }
+
+    if e := b.Deserialize(bytes.NewBufferString("crdt:gset")); e == nil {
+        t.Error("Expected header error!")
+    }
+}
@@ -30,6 +30,10 @@ It has these top-level messages:
 	MergeResponse
 	CloneRequest
 	CloneResponse
+	AcknowledgeRequest
+	AcknowledgeResponse
+	CompactRequest
+	CompactResponse
 	SupportedTypesResponse
 	SupportedStorageTypesResponse
 	SupportedCryptoMethodsResponse
@@ -42,6 +46,79 @@ It has these top-level messages:
 	SetLengthResponse
 	SetContainsRequest
 	SetContainsResponse
+	SetAlgebraRequest
+	SetAlgebraResponse
+	SetIsSubsetRequest
+	SetIsSubsetResponse
+	ElementMetadata
+	SetListWithMetadataResponse
+	CounterIncrementRequest
+	CounterIncrementResponse
+	CounterDecrementRequest
+	CounterDecrementResponse
+	CounterValueRequest
+	CounterValueResponse
+	CounterTransferRequest
+	CounterTransferResponse
+	CounterRightsRequest
+	CounterRightsResponse
+	RegisterSetRequest
+	RegisterSetResponse
+	RegisterGetRequest
+	RegisterGetResponse
+	MultiValueRegisterGetResponse
+	MapPutRequest
+	MapPutResponse
+	MapGetRequest
+	MapGetResponse
+	MapRemoveRequest
+	MapRemoveResponse
+	MapKeysRequest
+	MapKeysResponse
+	SequenceInsertRequest
+	SequenceInsertResponse
+	SequenceDeleteRequest
+	SequenceDeleteResponse
+	SequenceSliceRequest
+	SequenceSliceResponse
+	SequenceTextRequest
+	SequenceTextResponse
+	FlagEnableRequest
+	FlagEnableResponse
+	FlagDisableRequest
+	FlagDisableResponse
+	FlagValueRequest
+	FlagValueResponse
+	GraphAddVertexRequest
+	GraphAddVertexResponse
+	GraphRemoveVertexRequest
+	GraphRemoveVertexResponse
+	GraphAddEdgeRequest
+	GraphAddEdgeResponse
+	GraphRemoveEdgeRequest
+	GraphRemoveEdgeResponse
+	GraphNeighboursRequest
+	GraphNeighboursResponse
+	GraphVerticesRequest
+	GraphVerticesResponse
+	TreeCreateRequest
+	TreeCreateResponse
+	TreeMoveRequest
+	TreeMoveResponse
+	TreeDeleteRequest
+	TreeDeleteResponse
+	TreeParentRequest
+	TreeParentResponse
+	TreeChildrenRequest
+	TreeChildrenResponse
+	DocumentGetRequest
+	DocumentGetResponse
+	DocumentSetRequest
+	DocumentSetResponse
+	DocumentInsertRequest
+	DocumentInsertResponse
+	DocumentDeleteRequest
+	DocumentDeleteResponse
 */
 package crdt
 
@@ -204,8 +281,9 @@ func (m *SubscribeRequest) String() string { return proto.CompactTextString(m) }
 func (*SubscribeRequest) ProtoMessage()    {}
 
 type Notification struct {
-	Type   Notification_EventType `protobuf:"varint,1,opt,name=type,enum=crdt.Notification_EventType" json:"type,omitempty"`
-	Object *ResourceObject        `protobuf:"bytes,2,opt,name=object" json:"object,omitempty"`
+	Type     Notification_EventType `protobuf:"varint,1,opt,name=type,enum=crdt.Notification_EventType" json:"type,omitempty"`
+	Object   *ResourceObject        `protobuf:"bytes,2,opt,name=object" json:"object,omitempty"`
+	Position int64                  `protobuf:"varint,3,opt,name=position" json:"position,omitempty"`
 }
 
 func (m *Notification) Reset()         { *m = Notification{} }
@@ -316,6 +394,56 @@ func (m *CloneResponse) GetStatus() *Status {
 	return nil
 }
 
+type AcknowledgeRequest struct {
+	ReferenceId string `protobuf:"bytes,1,opt,name=referenceId" json:"referenceId,omitempty"`
+	ReplicaId   string `protobuf:"bytes,2,opt,name=replicaId" json:"replicaId,omitempty"`
+	Marker      []byte `protobuf:"bytes,3,opt,name=marker,proto3" json:"marker,omitempty"`
+}
+
+func (m *AcknowledgeRequest) Reset()         { *m = AcknowledgeRequest{} }
+func (m *AcknowledgeRequest) String() string { return proto.CompactTextString(m) }
+func (*AcknowledgeRequest) ProtoMessage()    {}
+
+type AcknowledgeResponse struct {
+	Status *Status `protobuf:"bytes,1,opt,name=status" json:"status,omitempty"`
+}
+
+func (m *AcknowledgeResponse) Reset()         { *m = AcknowledgeResponse{} }
+func (m *AcknowledgeResponse) String() string { return proto.CompactTextString(m) }
+func (*AcknowledgeResponse) ProtoMessage()    {}
+
+func (m *AcknowledgeResponse) GetStatus() *Status {
+	if m != nil {
+		return m.Status
+	}
+	return nil
+}
+
+type CompactRequest struct {
+	ReferenceId string `protobuf:"bytes,1,opt,name=referenceId" json:"referenceId,omitempty"`
+}
+
+func (m *CompactRequest) Reset()         { *m = CompactRequest{} }
+func (m *CompactRequest) String() string { return proto.CompactTextString(m) }
+func (*CompactRequest) ProtoMessage()    {}
+
+type CompactResponse struct {
+	Status     *Status `protobuf:"bytes,1,opt,name=status" json:"status,omitempty"`
+	Tombstones uint64  `protobuf:"varint,2,opt,name=tombstones" json:"tombstones,omitempty"`
+	Bytes      uint64  `protobuf:"varint,3,opt,name=bytes" json:"bytes,omitempty"`
+}
+
+func (m *CompactResponse) Reset()         { *m = CompactResponse{} }
+func (m *CompactResponse) String() string { return proto.CompactTextString(m) }
+func (*CompactResponse) ProtoMessage()    {}
+
+func (m *CompactResponse) GetStatus() *Status {
+	if m != nil {
+		return m.Status
+	}
+	return nil
+}
+
 type SupportedTypesResponse struct {
 	Types []*TypeMessage `protobuf:"bytes,1,rep,name=types" json:"types,omitempty"`
 }
@@ -370,7 +498,8 @@ func (m *SetListRequest) String() string { return proto.CompactTextString(m) }
 func (*SetListRequest) ProtoMessage()    {}
 
 type SetInsertRequest struct {
-	Object *ResourceObject `protobuf:"bytes,1,opt,name=object" json:"object,omitempty"`
+	Object *ResourceObject   `protobuf:"bytes,1,opt,name=object" json:"object,omitempty"`
+	Labels map[string]string `protobuf:"bytes,2,rep,name=labels" json:"labels,omitempty" protobuf_key:"bytes,1,opt,name=key" protobuf_val:"bytes,2,opt,name=value"`
 }
 
 func (m *SetInsertRequest) Reset()         { *m = SetInsertRequest{} }
@@ -384,6 +513,13 @@ func (m *SetInsertRequest) GetObject() *ResourceObject {
 	return nil
 }
 
+func (m *SetInsertRequest) GetLabels() map[string]string {
+	if m != nil {
+		return m.Labels
+	}
+	return nil
+}
+
 type SetInsertResponse struct {
 	Status *Status `protobuf:"bytes,1,opt,name=status" json:"status,omitempty"`
 }
@@ -484,204 +620,1165 @@ func (m *SetContainsResponse) GetStatus() *Status {
 	return nil
 }
 
-func init() {
-	proto.RegisterEnum("crdt.Notification_EventType", Notification_EventType_name, Notification_EventType_value)
+// Operands may be set resources of any type. When storageId is set the result
+// is also inserted into a new resource, of resourceType if given or else of
+// the type of referenceId.
+type SetAlgebraRequest struct {
+	ReferenceId      string `protobuf:"bytes,1,opt,name=referenceId" json:"referenceId,omitempty"`
+	OtherReferenceId string `protobuf:"bytes,2,opt,name=otherReferenceId" json:"otherReferenceId,omitempty"`
+	StorageId        string `protobuf:"bytes,3,opt,name=storageId" json:"storageId,omitempty"`
+	CryptoId         string `protobuf:"bytes,4,opt,name=cryptoId" json:"cryptoId,omitempty"`
+	ResourceType     string `protobuf:"bytes,5,opt,name=resourceType" json:"resourceType,omitempty"`
 }
 
-// Reference imports to suppress errors if they are not otherwise used.
-var _ context.Context
-var _ grpc.ClientConn
+func (m *SetAlgebraRequest) Reset()         { *m = SetAlgebraRequest{} }
+func (m *SetAlgebraRequest) String() string { return proto.CompactTextString(m) }
+func (*SetAlgebraRequest) ProtoMessage()    {}
 
-// Client API for CRDT service
+// The first message of the stream carries the status, and the new resource if
+// one was requested. Each following message carries one element.
+type SetAlgebraResponse struct {
+	Status      *Status `protobuf:"bytes,1,opt,name=status" json:"status,omitempty"`
+	Object      []byte  `protobuf:"bytes,2,opt,name=object,proto3" json:"object,omitempty"`
+	ResourceId  string  `protobuf:"bytes,3,opt,name=resourceId" json:"resourceId,omitempty"`
+	ResourceKey string  `protobuf:"bytes,4,opt,name=resourceKey" json:"resourceKey,omitempty"`
+}
 
-type CRDTClient interface {
-	// Create a new data set in internal datastore.
-	Create(ctx context.Context, in *CreateRequest, opts ...grpc.CallOption) (*CreateResponse, error)
-	// Attach to an existing data set.
-	Attach(ctx context.Context, in *AttachRequest, opts ...grpc.CallOption) (*AttachResponse, error)
-	// Detach ReferenceId reference from internal datastore.
-	Detach(ctx context.Context, in *DetachRequest, opts ...grpc.CallOption) (*DetachResponse, error)
-	// Subscribe to data set modifications.
-	Subscribe(ctx context.Context, in *SubscribeRequest, opts ...grpc.CallOption) (CRDT_SubscribeClient, error)
-	// Commit resource to persistent storage.
-	Commit(ctx context.Context, in *CommitRequest, opts ...grpc.CallOption) (*CommitResponse, error)
-	// Test Equality of two resources by reference.
-	Equals(ctx context.Context, in *EqualsRequest, opts ...grpc.CallOption) (*EqualsResponse, error)
-	// Merge two references with matching datatype.
-	Merge(ctx context.Context, in *MergeRequest, opts ...grpc.CallOption) (*MergeResponse, error)
-	// Clone a reference into a new resource.
-	Clone(ctx context.Context, in *CloneRequest, opts ...grpc.CallOption) (*CloneResponse, error)
-	// Returns a list of supported data types.
-	SupportedTypes(ctx context.Context, in *EmptyMessage, opts ...grpc.CallOption) (*SupportedTypesResponse, error)
-	IsSupportedType(ctx context.Context, in *TypeMessage, opts ...grpc.CallOption) (*BooleanResponse, error)
-	// Returns a list of supported storage backends.
-	SupportedStorageTypes(ctx context.Context, in *EmptyMessage, opts ...grpc.CallOption) (*SupportedStorageTypesResponse, error)
-	IsSupportedStorageType(ctx context.Context, in *TypeMessage, opts ...grpc.CallOption) (*BooleanResponse, error)
-	// Supported crypto methods.
-	SupportedCryptoMethods(ctx context.Context, in *EmptyMessage, opts ...grpc.CallOption) (*SupportedCryptoMethodsResponse, error)
-	IsSupportedCryptoMethod(ctx context.Context, in *TypeMessage, opts ...grpc.CallOption) (*BooleanResponse, error)
+func (m *SetAlgebraResponse) Reset()         { *m = SetAlgebraResponse{} }
+func (m *SetAlgebraResponse) String() string { return proto.CompactTextString(m) }
+func (*SetAlgebraResponse) ProtoMessage()    {}
+
+func (m *SetAlgebraResponse) GetStatus() *Status {
+	if m != nil {
+		return m.Status
+	}
+	return nil
 }
 
-type cRDTClient struct {
-	cc *grpc.ClientConn
+type SetIsSubsetRequest struct {
+	ReferenceId      string `protobuf:"bytes,1,opt,name=referenceId" json:"referenceId,omitempty"`
+	OtherReferenceId string `protobuf:"bytes,2,opt,name=otherReferenceId" json:"otherReferenceId,omitempty"`
 }
 
-func NewCRDTClient(cc *grpc.ClientConn) CRDTClient {
-	return &cRDTClient{cc}
+func (m *SetIsSubsetRequest) Reset()         { *m = SetIsSubsetRequest{} }
+func (m *SetIsSubsetRequest) String() string { return proto.CompactTextString(m) }
+func (*SetIsSubsetRequest) ProtoMessage()    {}
+
+type SetIsSubsetResponse struct {
+	Status *Status `protobuf:"bytes,1,opt,name=status" json:"status,omitempty"`
+	Result bool    `protobuf:"varint,2,opt,name=result" json:"result,omitempty"`
 }
 
-func (c *cRDTClient) Create(ctx context.Context, in *CreateRequest, opts ...grpc.CallOption) (*CreateResponse, error) {
-	out := new(CreateResponse)
-	err := grpc.Invoke(ctx, "/crdt.CRDT/Create", in, out, c.cc, opts...)
-	if err != nil {
-		return nil, err
+func (m *SetIsSubsetResponse) Reset()         { *m = SetIsSubsetResponse{} }
+func (m *SetIsSubsetResponse) String() string { return proto.CompactTextString(m) }
+func (*SetIsSubsetResponse) ProtoMessage()    {}
+
+func (m *SetIsSubsetResponse) GetStatus() *Status {
+	if m != nil {
+		return m.Status
 	}
-	return out, nil
+	return nil
 }
 
-func (c *cRDTClient) Attach(ctx context.Context, in *AttachRequest, opts ...grpc.CallOption) (*AttachResponse, error) {
-	out := new(AttachResponse)
-	err := grpc.Invoke(ctx, "/crdt.CRDT/Attach", in, out, c.cc, opts...)
-	if err != nil {
-		return nil, err
-	}
-	return out, nil
+// Metadata recorded with an element by its first insert.
+type ElementMetadata struct {
+	Timestamp int64             `protobuf:"varint,1,opt,name=timestamp" json:"timestamp,omitempty"`
+	ReplicaId string            `protobuf:"bytes,2,opt,name=replicaId" json:"replicaId,omitempty"`
+	Labels    map[string]string `protobuf:"bytes,3,rep,name=labels" json:"labels,omitempty" protobuf_key:"bytes,1,opt,name=key" protobuf_val:"bytes,2,opt,name=value"`
 }
 
-func (c *cRDTClient) Detach(ctx context.Context, in *DetachRequest, opts ...grpc.CallOption) (*DetachResponse, error) {
-	out := new(DetachResponse)
-	err := grpc.Invoke(ctx, "/crdt.CRDT/Detach", in, out, c.cc, opts...)
-	if err != nil {
-		return nil, err
+func (m *ElementMetadata) Reset()         { *m = ElementMetadata{} }
+func (m *ElementMetadata) String() string { return proto.CompactTextString(m) }
+func (*ElementMetadata) ProtoMessage()    {}
+
+func (m *ElementMetadata) GetLabels() map[string]string {
+	if m != nil {
+		return m.Labels
 	}
-	return out, nil
+	return nil
 }
 
-func (c *cRDTClient) Subscribe(ctx context.Context, in *SubscribeRequest, opts ...grpc.CallOption) (CRDT_SubscribeClient, error) {
-	stream, err := grpc.NewClientStream(ctx, &_CRDT_serviceDesc.Streams[0], c.cc, "/crdt.CRDT/Subscribe", opts...)
-	if err != nil {
-		return nil, err
-	}
-	x := &cRDTSubscribeClient{stream}
-	if err := x.ClientStream.SendMsg(in); err != nil {
-		return nil, err
+// The first message of the stream carries the status. Each following message
+// carries one element with its metadata, which is absent for elements inserted
+// without any.
+type SetListWithMetadataResponse struct {
+	Status   *Status          `protobuf:"bytes,1,opt,name=status" json:"status,omitempty"`
+	Object   []byte           `protobuf:"bytes,2,opt,name=object,proto3" json:"object,omitempty"`
+	Metadata *ElementMetadata `protobuf:"bytes,3,opt,name=metadata" json:"metadata,omitempty"`
+}
+
+func (m *SetListWithMetadataResponse) Reset()         { *m = SetListWithMetadataResponse{} }
+func (m *SetListWithMetadataResponse) String() string { return proto.CompactTextString(m) }
+func (*SetListWithMetadataResponse) ProtoMessage()    {}
+
+func (m *SetListWithMetadataResponse) GetStatus() *Status {
+	if m != nil {
+		return m.Status
 	}
-	if err := x.ClientStream.CloseSend(); err != nil {
-		return nil, err
+	return nil
+}
+
+func (m *SetListWithMetadataResponse) GetMetadata() *ElementMetadata {
+	if m != nil {
+		return m.Metadata
 	}
-	return x, nil
+	return nil
 }
 
-type CRDT_SubscribeClient interface {
-	Recv() (*Notification, error)
-	grpc.ClientStream
+type CounterIncrementRequest struct {
+	ReferenceId string `protobuf:"bytes,1,opt,name=referenceId" json:"referenceId,omitempty"`
+	Delta       uint64 `protobuf:"varint,2,opt,name=delta" json:"delta,omitempty"`
 }
 
-type cRDTSubscribeClient struct {
-	grpc.ClientStream
+func (m *CounterIncrementRequest) Reset()         { *m = CounterIncrementRequest{} }
+func (m *CounterIncrementRequest) String() string { return proto.CompactTextString(m) }
+func (*CounterIncrementRequest) ProtoMessage()    {}
+
+type CounterIncrementResponse struct {
+	Status *Status `protobuf:"bytes,1,opt,name=status" json:"status,omitempty"`
 }
 
-func (x *cRDTSubscribeClient) Recv() (*Notification, error) {
-	m := new(Notification)
-	if err := x.ClientStream.RecvMsg(m); err != nil {
-		return nil, err
+func (m *CounterIncrementResponse) Reset()         { *m = CounterIncrementResponse{} }
+func (m *CounterIncrementResponse) String() string { return proto.CompactTextString(m) }
+func (*CounterIncrementResponse) ProtoMessage()    {}
+
+func (m *CounterIncrementResponse) GetStatus() *Status {
+	if m != nil {
+		return m.Status
 	}
-	return m, nil
+	return nil
 }
 
-func (c *cRDTClient) Commit(ctx context.Context, in *CommitRequest, opts ...grpc.CallOption) (*CommitResponse, error) {
-	out := new(CommitResponse)
-	err := grpc.Invoke(ctx, "/crdt.CRDT/Commit", in, out, c.cc, opts...)
-	if err != nil {
-		return nil, err
-	}
-	return out, nil
+type CounterDecrementRequest struct {
+	ReferenceId string `protobuf:"bytes,1,opt,name=referenceId" json:"referenceId,omitempty"`
+	Delta       uint64 `protobuf:"varint,2,opt,name=delta" json:"delta,omitempty"`
 }
 
-func (c *cRDTClient) Equals(ctx context.Context, in *EqualsRequest, opts ...grpc.CallOption) (*EqualsResponse, error) {
-	out := new(EqualsResponse)
-	err := grpc.Invoke(ctx, "/crdt.CRDT/Equals", in, out, c.cc, opts...)
-	if err != nil {
-		return nil, err
-	}
-	return out, nil
+func (m *CounterDecrementRequest) Reset()         { *m = CounterDecrementRequest{} }
+func (m *CounterDecrementRequest) String() string { return proto.CompactTextString(m) }
+func (*CounterDecrementRequest) ProtoMessage()    {}
+
+type CounterDecrementResponse struct {
+	Status *Status `protobuf:"bytes,1,opt,name=status" json:"status,omitempty"`
 }
 
-func (c *cRDTClient) Merge(ctx context.Context, in *MergeRequest, opts ...grpc.CallOption) (*MergeResponse, error) {
-	out := new(MergeResponse)
-	err := grpc.Invoke(ctx, "/crdt.CRDT/Merge", in, out, c.cc, opts...)
-	if err != nil {
-		return nil, err
+func (m *CounterDecrementResponse) Reset()         { *m = CounterDecrementResponse{} }
+func (m *CounterDecrementResponse) String() string { return proto.CompactTextString(m) }
+func (*CounterDecrementResponse) ProtoMessage()    {}
+
+func (m *CounterDecrementResponse) GetStatus() *Status {
+	if m != nil {
+		return m.Status
 	}
-	return out, nil
+	return nil
 }
 
-func (c *cRDTClient) Clone(ctx context.Context, in *CloneRequest, opts ...grpc.CallOption) (*CloneResponse, error) {
-	out := new(CloneResponse)
-	err := grpc.Invoke(ctx, "/crdt.CRDT/Clone", in, out, c.cc, opts...)
-	if err != nil {
-		return nil, err
-	}
-	return out, nil
+type CounterValueRequest struct {
+	ReferenceId string `protobuf:"bytes,1,opt,name=referenceId" json:"referenceId,omitempty"`
 }
 
-func (c *cRDTClient) SupportedTypes(ctx context.Context, in *EmptyMessage, opts ...grpc.CallOption) (*SupportedTypesResponse, error) {
-	out := new(SupportedTypesResponse)
-	err := grpc.Invoke(ctx, "/crdt.CRDT/SupportedTypes", in, out, c.cc, opts...)
-	if err != nil {
-		return nil, err
-	}
-	return out, nil
+func (m *CounterValueRequest) Reset()         { *m = CounterValueRequest{} }
+func (m *CounterValueRequest) String() string { return proto.CompactTextString(m) }
+func (*CounterValueRequest) ProtoMessage()    {}
+
+type CounterValueResponse struct {
+	Status *Status `protobuf:"bytes,1,opt,name=status" json:"status,omitempty"`
+	Value  int64   `protobuf:"varint,2,opt,name=value" json:"value,omitempty"`
 }
 
-func (c *cRDTClient) IsSupportedType(ctx context.Context, in *TypeMessage, opts ...grpc.CallOption) (*BooleanResponse, error) {
-	out := new(BooleanResponse)
-	err := grpc.Invoke(ctx, "/crdt.CRDT/IsSupportedType", in, out, c.cc, opts...)
-	if err != nil {
-		return nil, err
+func (m *CounterValueResponse) Reset()         { *m = CounterValueResponse{} }
+func (m *CounterValueResponse) String() string { return proto.CompactTextString(m) }
+func (*CounterValueResponse) ProtoMessage()    {}
+
+func (m *CounterValueResponse) GetStatus() *Status {
+	if m != nil {
+		return m.Status
 	}
-	return out, nil
+	return nil
 }
 
-func (c *cRDTClient) SupportedStorageTypes(ctx context.Context, in *EmptyMessage, opts ...grpc.CallOption) (*SupportedStorageTypesResponse, error) {
-	out := new(SupportedStorageTypesResponse)
-	err := grpc.Invoke(ctx, "/crdt.CRDT/SupportedStorageTypes", in, out, c.cc, opts...)
-	if err != nil {
-		return nil, err
-	}
-	return out, nil
+type CounterTransferRequest struct {
+	ReferenceId string `protobuf:"bytes,1,opt,name=referenceId" json:"referenceId,omitempty"`
+	ReplicaId   string `protobuf:"bytes,2,opt,name=replicaId" json:"replicaId,omitempty"`
+	Delta       uint64 `protobuf:"varint,3,opt,name=delta" json:"delta,omitempty"`
 }
 
-func (c *cRDTClient) IsSupportedStorageType(ctx context.Context, in *TypeMessage, opts ...grpc.CallOption) (*BooleanResponse, error) {
-	out := new(BooleanResponse)
-	err := grpc.Invoke(ctx, "/crdt.CRDT/IsSupportedStorageType", in, out, c.cc, opts...)
-	if err != nil {
-		return nil, err
+func (m *CounterTransferRequest) Reset()         { *m = CounterTransferRequest{} }
+func (m *CounterTransferRequest) String() string { return proto.CompactTextString(m) }
+func (*CounterTransferRequest) ProtoMessage()    {}
+
+type CounterTransferResponse struct {
+	Status *Status `protobuf:"bytes,1,opt,name=status" json:"status,omitempty"`
+}
+
+func (m *CounterTransferResponse) Reset()         { *m = CounterTransferResponse{} }
+func (m *CounterTransferResponse) String() string { return proto.CompactTextString(m) }
+func (*CounterTransferResponse) ProtoMessage()    {}
+
+func (m *CounterTransferResponse) GetStatus() *Status {
+	if m != nil {
+		return m.Status
 	}
-	return out, nil
+	return nil
 }
 
-func (c *cRDTClient) SupportedCryptoMethods(ctx context.Context, in *EmptyMessage, opts ...grpc.CallOption) (*SupportedCryptoMethodsResponse, error) {
-	out := new(SupportedCryptoMethodsResponse)
-	err := grpc.Invoke(ctx, "/crdt.CRDT/SupportedCryptoMethods", in, out, c.cc, opts...)
-	if err != nil {
-		return nil, err
+type CounterRightsRequest struct {
+	ReferenceId string `protobuf:"bytes,1,opt,name=referenceId" json:"referenceId,omitempty"`
+	ReplicaId   string `protobuf:"bytes,2,opt,name=replicaId" json:"replicaId,omitempty"`
+}
+
+func (m *CounterRightsRequest) Reset()         { *m = CounterRightsRequest{} }
+func (m *CounterRightsRequest) String() string { return proto.CompactTextString(m) }
+func (*CounterRightsRequest) ProtoMessage()    {}
+
+type CounterRightsResponse struct {
+	Status    *Status `protobuf:"bytes,1,opt,name=status" json:"status,omitempty"`
+	ReplicaId string  `protobuf:"bytes,2,opt,name=replicaId" json:"replicaId,omitempty"`
+	Rights    uint64  `protobuf:"varint,3,opt,name=rights" json:"rights,omitempty"`
+}
+
+func (m *CounterRightsResponse) Reset()         { *m = CounterRightsResponse{} }
+func (m *CounterRightsResponse) String() string { return proto.CompactTextString(m) }
+func (*CounterRightsResponse) ProtoMessage()    {}
+
+func (m *CounterRightsResponse) GetStatus() *Status {
+	if m != nil {
+		return m.Status
 	}
-	return out, nil
+	return nil
 }
 
-func (c *cRDTClient) IsSupportedCryptoMethod(ctx context.Context, in *TypeMessage, opts ...grpc.CallOption) (*BooleanResponse, error) {
-	out := new(BooleanResponse)
-	err := grpc.Invoke(ctx, "/crdt.CRDT/IsSupportedCryptoMethod", in, out, c.cc, opts...)
-	if err != nil {
-		return nil, err
+type RegisterSetRequest struct {
+	Object *ResourceObject `protobuf:"bytes,1,opt,name=object" json:"object,omitempty"`
+}
+
+func (m *RegisterSetRequest) Reset()         { *m = RegisterSetRequest{} }
+func (m *RegisterSetRequest) String() string { return proto.CompactTextString(m) }
+func (*RegisterSetRequest) ProtoMessage()    {}
+
+func (m *RegisterSetRequest) GetObject() *ResourceObject {
+	if m != nil {
+		return m.Object
 	}
-	return out, nil
+	return nil
 }
 
-// Server API for CRDT service
+type RegisterSetResponse struct {
+	Status *Status `protobuf:"bytes,1,opt,name=status" json:"status,omitempty"`
+}
 
-type CRDTServer interface {
+func (m *RegisterSetResponse) Reset()         { *m = RegisterSetResponse{} }
+func (m *RegisterSetResponse) String() string { return proto.CompactTextString(m) }
+func (*RegisterSetResponse) ProtoMessage()    {}
+
+func (m *RegisterSetResponse) GetStatus() *Status {
+	if m != nil {
+		return m.Status
+	}
+	return nil
+}
+
+type RegisterGetRequest struct {
+	ReferenceId string `protobuf:"bytes,1,opt,name=referenceId" json:"referenceId,omitempty"`
+}
+
+func (m *RegisterGetRequest) Reset()         { *m = RegisterGetRequest{} }
+func (m *RegisterGetRequest) String() string { return proto.CompactTextString(m) }
+func (*RegisterGetRequest) ProtoMessage()    {}
+
+type RegisterGetResponse struct {
+	Status *Status `protobuf:"bytes,1,opt,name=status" json:"status,omitempty"`
+	Object []byte  `protobuf:"bytes,2,opt,name=object,proto3" json:"object,omitempty"`
+}
+
+func (m *RegisterGetResponse) Reset()         { *m = RegisterGetResponse{} }
+func (m *RegisterGetResponse) String() string { return proto.CompactTextString(m) }
+func (*RegisterGetResponse) ProtoMessage()    {}
+
+func (m *RegisterGetResponse) GetStatus() *Status {
+	if m != nil {
+		return m.Status
+	}
+	return nil
+}
+
+type MultiValueRegisterGetResponse struct {
+	Status  *Status  `protobuf:"bytes,1,opt,name=status" json:"status,omitempty"`
+	Objects [][]byte `protobuf:"bytes,2,rep,name=objects,proto3" json:"objects,omitempty"`
+}
+
+func (m *MultiValueRegisterGetResponse) Reset()         { *m = MultiValueRegisterGetResponse{} }
+func (m *MultiValueRegisterGetResponse) String() string { return proto.CompactTextString(m) }
+func (*MultiValueRegisterGetResponse) ProtoMessage()    {}
+
+func (m *MultiValueRegisterGetResponse) GetStatus() *Status {
+	if m != nil {
+		return m.Status
+	}
+	return nil
+}
+
+type MapPutRequest struct {
+	ReferenceId  string `protobuf:"bytes,1,opt,name=referenceId" json:"referenceId,omitempty"`
+	Key          string `protobuf:"bytes,2,opt,name=key" json:"key,omitempty"`
+	ResourceType string `protobuf:"bytes,3,opt,name=resourceType" json:"resourceType,omitempty"`
+	Object       []byte `protobuf:"bytes,4,opt,name=object,proto3" json:"object,omitempty"`
+}
+
+func (m *MapPutRequest) Reset()         { *m = MapPutRequest{} }
+func (m *MapPutRequest) String() string { return proto.CompactTextString(m) }
+func (*MapPutRequest) ProtoMessage()    {}
+
+type MapPutResponse struct {
+	Status *Status `protobuf:"bytes,1,opt,name=status" json:"status,omitempty"`
+}
+
+func (m *MapPutResponse) Reset()         { *m = MapPutResponse{} }
+func (m *MapPutResponse) String() string { return proto.CompactTextString(m) }
+func (*MapPutResponse) ProtoMessage()    {}
+
+func (m *MapPutResponse) GetStatus() *Status {
+	if m != nil {
+		return m.Status
+	}
+	return nil
+}
+
+type MapGetRequest struct {
+	ReferenceId string `protobuf:"bytes,1,opt,name=referenceId" json:"referenceId,omitempty"`
+	Key         string `protobuf:"bytes,2,opt,name=key" json:"key,omitempty"`
+}
+
+func (m *MapGetRequest) Reset()         { *m = MapGetRequest{} }
+func (m *MapGetRequest) String() string { return proto.CompactTextString(m) }
+func (*MapGetRequest) ProtoMessage()    {}
+
+type MapGetResponse struct {
+	Status       *Status `protobuf:"bytes,1,opt,name=status" json:"status,omitempty"`
+	ResourceType string  `protobuf:"bytes,2,opt,name=resourceType" json:"resourceType,omitempty"`
+	Object       []byte  `protobuf:"bytes,3,opt,name=object,proto3" json:"object,omitempty"`
+}
+
+func (m *MapGetResponse) Reset()         { *m = MapGetResponse{} }
+func (m *MapGetResponse) String() string { return proto.CompactTextString(m) }
+func (*MapGetResponse) ProtoMessage()    {}
+
+func (m *MapGetResponse) GetStatus() *Status {
+	if m != nil {
+		return m.Status
+	}
+	return nil
+}
+
+type MapRemoveRequest struct {
+	ReferenceId string `protobuf:"bytes,1,opt,name=referenceId" json:"referenceId,omitempty"`
+	Key         string `protobuf:"bytes,2,opt,name=key" json:"key,omitempty"`
+}
+
+func (m *MapRemoveRequest) Reset()         { *m = MapRemoveRequest{} }
+func (m *MapRemoveRequest) String() string { return proto.CompactTextString(m) }
+func (*MapRemoveRequest) ProtoMessage()    {}
+
+type MapRemoveResponse struct {
+	Status *Status `protobuf:"bytes,1,opt,name=status" json:"status,omitempty"`
+}
+
+func (m *MapRemoveResponse) Reset()         { *m = MapRemoveResponse{} }
+func (m *MapRemoveResponse) String() string { return proto.CompactTextString(m) }
+func (*MapRemoveResponse) ProtoMessage()    {}
+
+func (m *MapRemoveResponse) GetStatus() *Status {
+	if m != nil {
+		return m.Status
+	}
+	return nil
+}
+
+type MapKeysRequest struct {
+	ReferenceId string `protobuf:"bytes,1,opt,name=referenceId" json:"referenceId,omitempty"`
+}
+
+func (m *MapKeysRequest) Reset()         { *m = MapKeysRequest{} }
+func (m *MapKeysRequest) String() string { return proto.CompactTextString(m) }
+func (*MapKeysRequest) ProtoMessage()    {}
+
+type MapKeysResponse struct {
+	Status *Status  `protobuf:"bytes,1,opt,name=status" json:"status,omitempty"`
+	Keys   []string `protobuf:"bytes,2,rep,name=keys" json:"keys,omitempty"`
+}
+
+func (m *MapKeysResponse) Reset()         { *m = MapKeysResponse{} }
+func (m *MapKeysResponse) String() string { return proto.CompactTextString(m) }
+func (*MapKeysResponse) ProtoMessage()    {}
+
+func (m *MapKeysResponse) GetStatus() *Status {
+	if m != nil {
+		return m.Status
+	}
+	return nil
+}
+
+type SequenceInsertRequest struct {
+	ReferenceId string   `protobuf:"bytes,1,opt,name=referenceId" json:"referenceId,omitempty"`
+	Position    uint32   `protobuf:"varint,2,opt,name=position" json:"position,omitempty"`
+	Objects     [][]byte `protobuf:"bytes,3,rep,name=objects,proto3" json:"objects,omitempty"`
+}
+
+func (m *SequenceInsertRequest) Reset()         { *m = SequenceInsertRequest{} }
+func (m *SequenceInsertRequest) String() string { return proto.CompactTextString(m) }
+func (*SequenceInsertRequest) ProtoMessage()    {}
+
+type SequenceInsertResponse struct {
+	Status *Status `protobuf:"bytes,1,opt,name=status" json:"status,omitempty"`
+}
+
+func (m *SequenceInsertResponse) Reset()         { *m = SequenceInsertResponse{} }
+func (m *SequenceInsertResponse) String() string { return proto.CompactTextString(m) }
+func (*SequenceInsertResponse) ProtoMessage()    {}
+
+func (m *SequenceInsertResponse) GetStatus() *Status {
+	if m != nil {
+		return m.Status
+	}
+	return nil
+}
+
+type SequenceDeleteRequest struct {
+	ReferenceId string `protobuf:"bytes,1,opt,name=referenceId" json:"referenceId,omitempty"`
+	Position    uint32 `protobuf:"varint,2,opt,name=position" json:"position,omitempty"`
+	Length      uint32 `protobuf:"varint,3,opt,name=length" json:"length,omitempty"`
+}
+
+func (m *SequenceDeleteRequest) Reset()         { *m = SequenceDeleteRequest{} }
+func (m *SequenceDeleteRequest) String() string { return proto.CompactTextString(m) }
+func (*SequenceDeleteRequest) ProtoMessage()    {}
+
+type SequenceDeleteResponse struct {
+	Status *Status `protobuf:"bytes,1,opt,name=status" json:"status,omitempty"`
+}
+
+func (m *SequenceDeleteResponse) Reset()         { *m = SequenceDeleteResponse{} }
+func (m *SequenceDeleteResponse) String() string { return proto.CompactTextString(m) }
+func (*SequenceDeleteResponse) ProtoMessage()    {}
+
+func (m *SequenceDeleteResponse) GetStatus() *Status {
+	if m != nil {
+		return m.Status
+	}
+	return nil
+}
+
+type SequenceSliceRequest struct {
+	ReferenceId string `protobuf:"bytes,1,opt,name=referenceId" json:"referenceId,omitempty"`
+	Start       uint32 `protobuf:"varint,2,opt,name=start" json:"start,omitempty"`
+	End         uint32 `protobuf:"varint,3,opt,name=end" json:"end,omitempty"`
+}
+
+func (m *SequenceSliceRequest) Reset()         { *m = SequenceSliceRequest{} }
+func (m *SequenceSliceRequest) String() string { return proto.CompactTextString(m) }
+func (*SequenceSliceRequest) ProtoMessage()    {}
+
+type SequenceSliceResponse struct {
+	Status  *Status  `protobuf:"bytes,1,opt,name=status" json:"status,omitempty"`
+	Objects [][]byte `protobuf:"bytes,2,rep,name=objects,proto3" json:"objects,omitempty"`
+}
+
+func (m *SequenceSliceResponse) Reset()         { *m = SequenceSliceResponse{} }
+func (m *SequenceSliceResponse) String() string { return proto.CompactTextString(m) }
+func (*SequenceSliceResponse) ProtoMessage()    {}
+
+func (m *SequenceSliceResponse) GetStatus() *Status {
+	if m != nil {
+		return m.Status
+	}
+	return nil
+}
+
+type SequenceTextRequest struct {
+	ReferenceId string `protobuf:"bytes,1,opt,name=referenceId" json:"referenceId,omitempty"`
+}
+
+func (m *SequenceTextRequest) Reset()         { *m = SequenceTextRequest{} }
+func (m *SequenceTextRequest) String() string { return proto.CompactTextString(m) }
+func (*SequenceTextRequest) ProtoMessage()    {}
+
+type SequenceTextResponse struct {
+	Status *Status `protobuf:"bytes,1,opt,name=status" json:"status,omitempty"`
+	Text   string  `protobuf:"bytes,2,opt,name=text" json:"text,omitempty"`
+}
+
+func (m *SequenceTextResponse) Reset()         { *m = SequenceTextResponse{} }
+func (m *SequenceTextResponse) String() string { return proto.CompactTextString(m) }
+func (*SequenceTextResponse) ProtoMessage()    {}
+
+func (m *SequenceTextResponse) GetStatus() *Status {
+	if m != nil {
+		return m.Status
+	}
+	return nil
+}
+
+type FlagEnableRequest struct {
+	ReferenceId string `protobuf:"bytes,1,opt,name=referenceId" json:"referenceId,omitempty"`
+}
+
+func (m *FlagEnableRequest) Reset()         { *m = FlagEnableRequest{} }
+func (m *FlagEnableRequest) String() string { return proto.CompactTextString(m) }
+func (*FlagEnableRequest) ProtoMessage()    {}
+
+type FlagEnableResponse struct {
+	Status *Status `protobuf:"bytes,1,opt,name=status" json:"status,omitempty"`
+}
+
+func (m *FlagEnableResponse) Reset()         { *m = FlagEnableResponse{} }
+func (m *FlagEnableResponse) String() string { return proto.CompactTextString(m) }
+func (*FlagEnableResponse) ProtoMessage()    {}
+
+func (m *FlagEnableResponse) GetStatus() *Status {
+	if m != nil {
+		return m.Status
+	}
+	return nil
+}
+
+type FlagDisableRequest struct {
+	ReferenceId string `protobuf:"bytes,1,opt,name=referenceId" json:"referenceId,omitempty"`
+}
+
+func (m *FlagDisableRequest) Reset()         { *m = FlagDisableRequest{} }
+func (m *FlagDisableRequest) String() string { return proto.CompactTextString(m) }
+func (*FlagDisableRequest) ProtoMessage()    {}
+
+type FlagDisableResponse struct {
+	Status *Status `protobuf:"bytes,1,opt,name=status" json:"status,omitempty"`
+}
+
+func (m *FlagDisableResponse) Reset()         { *m = FlagDisableResponse{} }
+func (m *FlagDisableResponse) String() string { return proto.CompactTextString(m) }
+func (*FlagDisableResponse) ProtoMessage()    {}
+
+func (m *FlagDisableResponse) GetStatus() *Status {
+	if m != nil {
+		return m.Status
+	}
+	return nil
+}
+
+type FlagValueRequest struct {
+	ReferenceId string `protobuf:"bytes,1,opt,name=referenceId" json:"referenceId,omitempty"`
+}
+
+func (m *FlagValueRequest) Reset()         { *m = FlagValueRequest{} }
+func (m *FlagValueRequest) String() string { return proto.CompactTextString(m) }
+func (*FlagValueRequest) ProtoMessage()    {}
+
+type FlagValueResponse struct {
+	Status *Status `protobuf:"bytes,1,opt,name=status" json:"status,omitempty"`
+	Value  bool    `protobuf:"varint,2,opt,name=value" json:"value,omitempty"`
+}
+
+func (m *FlagValueResponse) Reset()         { *m = FlagValueResponse{} }
+func (m *FlagValueResponse) String() string { return proto.CompactTextString(m) }
+func (*FlagValueResponse) ProtoMessage()    {}
+
+func (m *FlagValueResponse) GetStatus() *Status {
+	if m != nil {
+		return m.Status
+	}
+	return nil
+}
+
+type GraphAddVertexRequest struct {
+	ReferenceId string `protobuf:"bytes,1,opt,name=referenceId" json:"referenceId,omitempty"`
+	Vertex      []byte `protobuf:"bytes,2,opt,name=vertex,proto3" json:"vertex,omitempty"`
+}
+
+func (m *GraphAddVertexRequest) Reset()         { *m = GraphAddVertexRequest{} }
+func (m *GraphAddVertexRequest) String() string { return proto.CompactTextString(m) }
+func (*GraphAddVertexRequest) ProtoMessage()    {}
+
+type GraphAddVertexResponse struct {
+	Status *Status `protobuf:"bytes,1,opt,name=status" json:"status,omitempty"`
+}
+
+func (m *GraphAddVertexResponse) Reset()         { *m = GraphAddVertexResponse{} }
+func (m *GraphAddVertexResponse) String() string { return proto.CompactTextString(m) }
+func (*GraphAddVertexResponse) ProtoMessage()    {}
+
+func (m *GraphAddVertexResponse) GetStatus() *Status {
+	if m != nil {
+		return m.Status
+	}
+	return nil
+}
+
+type GraphRemoveVertexRequest struct {
+	ReferenceId string `protobuf:"bytes,1,opt,name=referenceId" json:"referenceId,omitempty"`
+	Vertex      []byte `protobuf:"bytes,2,opt,name=vertex,proto3" json:"vertex,omitempty"`
+}
+
+func (m *GraphRemoveVertexRequest) Reset()         { *m = GraphRemoveVertexRequest{} }
+func (m *GraphRemoveVertexRequest) String() string { return proto.CompactTextString(m) }
+func (*GraphRemoveVertexRequest) ProtoMessage()    {}
+
+type GraphRemoveVertexResponse struct {
+	Status *Status `protobuf:"bytes,1,opt,name=status" json:"status,omitempty"`
+}
+
+func (m *GraphRemoveVertexResponse) Reset()         { *m = GraphRemoveVertexResponse{} }
+func (m *GraphRemoveVertexResponse) String() string { return proto.CompactTextString(m) }
+func (*GraphRemoveVertexResponse) ProtoMessage()    {}
+
+func (m *GraphRemoveVertexResponse) GetStatus() *Status {
+	if m != nil {
+		return m.Status
+	}
+	return nil
+}
+
+type GraphAddEdgeRequest struct {
+	ReferenceId string `protobuf:"bytes,1,opt,name=referenceId" json:"referenceId,omitempty"`
+	From        []byte `protobuf:"bytes,2,opt,name=from,proto3" json:"from,omitempty"`
+	To          []byte `protobuf:"bytes,3,opt,name=to,proto3" json:"to,omitempty"`
+}
+
+func (m *GraphAddEdgeRequest) Reset()         { *m = GraphAddEdgeRequest{} }
+func (m *GraphAddEdgeRequest) String() string { return proto.CompactTextString(m) }
+func (*GraphAddEdgeRequest) ProtoMessage()    {}
+
+type GraphAddEdgeResponse struct {
+	Status *Status `protobuf:"bytes,1,opt,name=status" json:"status,omitempty"`
+}
+
+func (m *GraphAddEdgeResponse) Reset()         { *m = GraphAddEdgeResponse{} }
+func (m *GraphAddEdgeResponse) String() string { return proto.CompactTextString(m) }
+func (*GraphAddEdgeResponse) ProtoMessage()    {}
+
+func (m *GraphAddEdgeResponse) GetStatus() *Status {
+	if m != nil {
+		return m.Status
+	}
+	return nil
+}
+
+type GraphRemoveEdgeRequest struct {
+	ReferenceId string `protobuf:"bytes,1,opt,name=referenceId" json:"referenceId,omitempty"`
+	From        []byte `protobuf:"bytes,2,opt,name=from,proto3" json:"from,omitempty"`
+	To          []byte `protobuf:"bytes,3,opt,name=to,proto3" json:"to,omitempty"`
+}
+
+func (m *GraphRemoveEdgeRequest) Reset()         { *m = GraphRemoveEdgeRequest{} }
+func (m *GraphRemoveEdgeRequest) String() string { return proto.CompactTextString(m) }
+func (*GraphRemoveEdgeRequest) ProtoMessage()    {}
+
+type GraphRemoveEdgeResponse struct {
+	Status *Status `protobuf:"bytes,1,opt,name=status" json:"status,omitempty"`
+}
+
+func (m *GraphRemoveEdgeResponse) Reset()         { *m = GraphRemoveEdgeResponse{} }
+func (m *GraphRemoveEdgeResponse) String() string { return proto.CompactTextString(m) }
+func (*GraphRemoveEdgeResponse) ProtoMessage()    {}
+
+func (m *GraphRemoveEdgeResponse) GetStatus() *Status {
+	if m != nil {
+		return m.Status
+	}
+	return nil
+}
+
+type GraphNeighboursRequest struct {
+	ReferenceId string `protobuf:"bytes,1,opt,name=referenceId" json:"referenceId,omitempty"`
+	Vertex      []byte `protobuf:"bytes,2,opt,name=vertex,proto3" json:"vertex,omitempty"`
+}
+
+func (m *GraphNeighboursRequest) Reset()         { *m = GraphNeighboursRequest{} }
+func (m *GraphNeighboursRequest) String() string { return proto.CompactTextString(m) }
+func (*GraphNeighboursRequest) ProtoMessage()    {}
+
+type GraphNeighboursResponse struct {
+	Status   *Status  `protobuf:"bytes,1,opt,name=status" json:"status,omitempty"`
+	Vertices [][]byte `protobuf:"bytes,2,rep,name=vertices,proto3" json:"vertices,omitempty"`
+}
+
+func (m *GraphNeighboursResponse) Reset()         { *m = GraphNeighboursResponse{} }
+func (m *GraphNeighboursResponse) String() string { return proto.CompactTextString(m) }
+func (*GraphNeighboursResponse) ProtoMessage()    {}
+
+func (m *GraphNeighboursResponse) GetStatus() *Status {
+	if m != nil {
+		return m.Status
+	}
+	return nil
+}
+
+type GraphVerticesRequest struct {
+	ReferenceId string `protobuf:"bytes,1,opt,name=referenceId" json:"referenceId,omitempty"`
+}
+
+func (m *GraphVerticesRequest) Reset()         { *m = GraphVerticesRequest{} }
+func (m *GraphVerticesRequest) String() string { return proto.CompactTextString(m) }
+func (*GraphVerticesRequest) ProtoMessage()    {}
+
+type GraphVerticesResponse struct {
+	Status   *Status  `protobuf:"bytes,1,opt,name=status" json:"status,omitempty"`
+	Vertices [][]byte `protobuf:"bytes,2,rep,name=vertices,proto3" json:"vertices,omitempty"`
+}
+
+func (m *GraphVerticesResponse) Reset()         { *m = GraphVerticesResponse{} }
+func (m *GraphVerticesResponse) String() string { return proto.CompactTextString(m) }
+func (*GraphVerticesResponse) ProtoMessage()    {}
+
+func (m *GraphVerticesResponse) GetStatus() *Status {
+	if m != nil {
+		return m.Status
+	}
+	return nil
+}
+
+// An empty node id refers to the root of the tree.
+type TreeCreateRequest struct {
+	ReferenceId string `protobuf:"bytes,1,opt,name=referenceId" json:"referenceId,omitempty"`
+	Node        string `protobuf:"bytes,2,opt,name=node" json:"node,omitempty"`
+	Parent      string `protobuf:"bytes,3,opt,name=parent" json:"parent,omitempty"`
+}
+
+func (m *TreeCreateRequest) Reset()         { *m = TreeCreateRequest{} }
+func (m *TreeCreateRequest) String() string { return proto.CompactTextString(m) }
+func (*TreeCreateRequest) ProtoMessage()    {}
+
+type TreeCreateResponse struct {
+	Status *Status `protobuf:"bytes,1,opt,name=status" json:"status,omitempty"`
+}
+
+func (m *TreeCreateResponse) Reset()         { *m = TreeCreateResponse{} }
+func (m *TreeCreateResponse) String() string { return proto.CompactTextString(m) }
+func (*TreeCreateResponse) ProtoMessage()    {}
+
+func (m *TreeCreateResponse) GetStatus() *Status {
+	if m != nil {
+		return m.Status
+	}
+	return nil
+}
+
+type TreeMoveRequest struct {
+	ReferenceId string `protobuf:"bytes,1,opt,name=referenceId" json:"referenceId,omitempty"`
+	Node        string `protobuf:"bytes,2,opt,name=node" json:"node,omitempty"`
+	Parent      string `protobuf:"bytes,3,opt,name=parent" json:"parent,omitempty"`
+}
+
+func (m *TreeMoveRequest) Reset()         { *m = TreeMoveRequest{} }
+func (m *TreeMoveRequest) String() string { return proto.CompactTextString(m) }
+func (*TreeMoveRequest) ProtoMessage()    {}
+
+type TreeMoveResponse struct {
+	Status *Status `protobuf:"bytes,1,opt,name=status" json:"status,omitempty"`
+}
+
+func (m *TreeMoveResponse) Reset()         { *m = TreeMoveResponse{} }
+func (m *TreeMoveResponse) String() string { return proto.CompactTextString(m) }
+func (*TreeMoveResponse) ProtoMessage()    {}
+
+func (m *TreeMoveResponse) GetStatus() *Status {
+	if m != nil {
+		return m.Status
+	}
+	return nil
+}
+
+type TreeDeleteRequest struct {
+	ReferenceId string `protobuf:"bytes,1,opt,name=referenceId" json:"referenceId,omitempty"`
+	Node        string `protobuf:"bytes,2,opt,name=node" json:"node,omitempty"`
+}
+
+func (m *TreeDeleteRequest) Reset()         { *m = TreeDeleteRequest{} }
+func (m *TreeDeleteRequest) String() string { return proto.CompactTextString(m) }
+func (*TreeDeleteRequest) ProtoMessage()    {}
+
+type TreeDeleteResponse struct {
+	Status *Status `protobuf:"bytes,1,opt,name=status" json:"status,omitempty"`
+}
+
+func (m *TreeDeleteResponse) Reset()         { *m = TreeDeleteResponse{} }
+func (m *TreeDeleteResponse) String() string { return proto.CompactTextString(m) }
+func (*TreeDeleteResponse) ProtoMessage()    {}
+
+func (m *TreeDeleteResponse) GetStatus() *Status {
+	if m != nil {
+		return m.Status
+	}
+	return nil
+}
+
+type TreeParentRequest struct {
+	ReferenceId string `protobuf:"bytes,1,opt,name=referenceId" json:"referenceId,omitempty"`
+	Node        string `protobuf:"bytes,2,opt,name=node" json:"node,omitempty"`
+}
+
+func (m *TreeParentRequest) Reset()         { *m = TreeParentRequest{} }
+func (m *TreeParentRequest) String() string { return proto.CompactTextString(m) }
+func (*TreeParentRequest) ProtoMessage()    {}
+
+type TreeParentResponse struct {
+	Status *Status `protobuf:"bytes,1,opt,name=status" json:"status,omitempty"`
+	Parent string  `protobuf:"bytes,2,opt,name=parent" json:"parent,omitempty"`
+}
+
+func (m *TreeParentResponse) Reset()         { *m = TreeParentResponse{} }
+func (m *TreeParentResponse) String() string { return proto.CompactTextString(m) }
+func (*TreeParentResponse) ProtoMessage()    {}
+
+func (m *TreeParentResponse) GetStatus() *Status {
+	if m != nil {
+		return m.Status
+	}
+	return nil
+}
+
+type TreeChildrenRequest struct {
+	ReferenceId string `protobuf:"bytes,1,opt,name=referenceId" json:"referenceId,omitempty"`
+	Node        string `protobuf:"bytes,2,opt,name=node" json:"node,omitempty"`
+}
+
+func (m *TreeChildrenRequest) Reset()         { *m = TreeChildrenRequest{} }
+func (m *TreeChildrenRequest) String() string { return proto.CompactTextString(m) }
+func (*TreeChildrenRequest) ProtoMessage()    {}
+
+type TreeChildrenResponse struct {
+	Status *Status  `protobuf:"bytes,1,opt,name=status" json:"status,omitempty"`
+	Nodes  []string `protobuf:"bytes,2,rep,name=nodes" json:"nodes,omitempty"`
+}
+
+func (m *TreeChildrenResponse) Reset()         { *m = TreeChildrenResponse{} }
+func (m *TreeChildrenResponse) String() string { return proto.CompactTextString(m) }
+func (*TreeChildrenResponse) ProtoMessage()    {}
+
+func (m *TreeChildrenResponse) GetStatus() *Status {
+	if m != nil {
+		return m.Status
+	}
+	return nil
+}
+
+type DocumentGetRequest struct {
+	ReferenceId string `protobuf:"bytes,1,opt,name=referenceId" json:"referenceId,omitempty"`
+	Path        string `protobuf:"bytes,2,opt,name=path" json:"path,omitempty"`
+}
+
+func (m *DocumentGetRequest) Reset()         { *m = DocumentGetRequest{} }
+func (m *DocumentGetRequest) String() string { return proto.CompactTextString(m) }
+func (*DocumentGetRequest) ProtoMessage()    {}
+
+type DocumentGetResponse struct {
+	Status *Status `protobuf:"bytes,1,opt,name=status" json:"status,omitempty"`
+	Value  []byte  `protobuf:"bytes,2,opt,name=value,proto3" json:"value,omitempty"`
+}
+
+func (m *DocumentGetResponse) Reset()         { *m = DocumentGetResponse{} }
+func (m *DocumentGetResponse) String() string { return proto.CompactTextString(m) }
+func (*DocumentGetResponse) ProtoMessage()    {}
+
+func (m *DocumentGetResponse) GetStatus() *Status {
+	if m != nil {
+		return m.Status
+	}
+	return nil
+}
+
+type DocumentSetRequest struct {
+	ReferenceId string `protobuf:"bytes,1,opt,name=referenceId" json:"referenceId,omitempty"`
+	Path        string `protobuf:"bytes,2,opt,name=path" json:"path,omitempty"`
+	Value       []byte `protobuf:"bytes,3,opt,name=value,proto3" json:"value,omitempty"`
+}
+
+func (m *DocumentSetRequest) Reset()         { *m = DocumentSetRequest{} }
+func (m *DocumentSetRequest) String() string { return proto.CompactTextString(m) }
+func (*DocumentSetRequest) ProtoMessage()    {}
+
+type DocumentSetResponse struct {
+	Status *Status `protobuf:"bytes,1,opt,name=status" json:"status,omitempty"`
+}
+
+func (m *DocumentSetResponse) Reset()         { *m = DocumentSetResponse{} }
+func (m *DocumentSetResponse) String() string { return proto.CompactTextString(m) }
+func (*DocumentSetResponse) ProtoMessage()    {}
+
+func (m *DocumentSetResponse) GetStatus() *Status {
+	if m != nil {
+		return m.Status
+	}
+	return nil
+}
+
+type DocumentInsertRequest struct {
+	ReferenceId string `protobuf:"bytes,1,opt,name=referenceId" json:"referenceId,omitempty"`
+	Path        string `protobuf:"bytes,2,opt,name=path" json:"path,omitempty"`
+	Value       []byte `protobuf:"bytes,3,opt,name=value,proto3" json:"value,omitempty"`
+}
+
+func (m *DocumentInsertRequest) Reset()         { *m = DocumentInsertRequest{} }
+func (m *DocumentInsertRequest) String() string { return proto.CompactTextString(m) }
+func (*DocumentInsertRequest) ProtoMessage()    {}
+
+type DocumentInsertResponse struct {
+	Status *Status `protobuf:"bytes,1,opt,name=status" json:"status,omitempty"`
+}
+
+func (m *DocumentInsertResponse) Reset()         { *m = DocumentInsertResponse{} }
+func (m *DocumentInsertResponse) String() string { return proto.CompactTextString(m) }
+func (*DocumentInsertResponse) ProtoMessage()    {}
+
+func (m *DocumentInsertResponse) GetStatus() *Status {
+	if m != nil {
+		return m.Status
+	}
+	return nil
+}
+
+type DocumentDeleteRequest struct {
+	ReferenceId string `protobuf:"bytes,1,opt,name=referenceId" json:"referenceId,omitempty"`
+	Path        string `protobuf:"bytes,2,opt,name=path" json:"path,omitempty"`
+}
+
+func (m *DocumentDeleteRequest) Reset()         { *m = DocumentDeleteRequest{} }
+func (m *DocumentDeleteRequest) String() string { return proto.CompactTextString(m) }
+func (*DocumentDeleteRequest) ProtoMessage()    {}
+
+type DocumentDeleteResponse struct {
+	Status *Status `protobuf:"bytes,1,opt,name=status" json:"status,omitempty"`
+}
+
+func (m *DocumentDeleteResponse) Reset()         { *m = DocumentDeleteResponse{} }
+func (m *DocumentDeleteResponse) String() string { return proto.CompactTextString(m) }
+func (*DocumentDeleteResponse) ProtoMessage()    {}
+
+func (m *DocumentDeleteResponse) GetStatus() *Status {
+	if m != nil {
+		return m.Status
+	}
+	return nil
+}
+
+func init() {
+	proto.RegisterEnum("crdt.Notification_EventType", Notification_EventType_name, Notification_EventType_value)
+}
+
+// Reference imports to suppress errors if they are not otherwise used.
+var _ context.Context
+var _ grpc.ClientConn
+
+// Client API for CRDT service
+
+type CRDTClient interface {
+	// Create a new data set in internal datastore.
+	Create(ctx context.Context, in *CreateRequest, opts ...grpc.CallOption) (*CreateResponse, error)
+	// Attach to an existing data set.
+	Attach(ctx context.Context, in *AttachRequest, opts ...grpc.CallOption) (*AttachResponse, error)
+	// Detach ReferenceId reference from internal datastore.
+	Detach(ctx context.Context, in *DetachRequest, opts ...grpc.CallOption) (*DetachResponse, error)
+	// Subscribe to data set modifications.
+	Subscribe(ctx context.Context, in *SubscribeRequest, opts ...grpc.CallOption) (CRDT_SubscribeClient, error)
+	// Commit resource to persistent storage.
+	Commit(ctx context.Context, in *CommitRequest, opts ...grpc.CallOption) (*CommitResponse, error)
+	// Test Equality of two resources by reference.
+	Equals(ctx context.Context, in *EqualsRequest, opts ...grpc.CallOption) (*EqualsResponse, error)
+	// Merge two references with matching datatype.
+	Merge(ctx context.Context, in *MergeRequest, opts ...grpc.CallOption) (*MergeResponse, error)
+	// Clone a reference into a new resource.
+	Clone(ctx context.Context, in *CloneRequest, opts ...grpc.CallOption) (*CloneResponse, error)
+	// Record that a replica has applied the deltas of a resource.
+	Acknowledge(ctx context.Context, in *AcknowledgeRequest, opts ...grpc.CallOption) (*AcknowledgeResponse, error)
+	// Discard tombstones every replica of a resource holds.
+	Compact(ctx context.Context, in *CompactRequest, opts ...grpc.CallOption) (*CompactResponse, error)
+	// Returns a list of supported data types.
+	SupportedTypes(ctx context.Context, in *EmptyMessage, opts ...grpc.CallOption) (*SupportedTypesResponse, error)
+	IsSupportedType(ctx context.Context, in *TypeMessage, opts ...grpc.CallOption) (*BooleanResponse, error)
+	// Returns a list of supported storage backends.
+	SupportedStorageTypes(ctx context.Context, in *EmptyMessage, opts ...grpc.CallOption) (*SupportedStorageTypesResponse, error)
+	IsSupportedStorageType(ctx context.Context, in *TypeMessage, opts ...grpc.CallOption) (*BooleanResponse, error)
+	// Supported crypto methods.
+	SupportedCryptoMethods(ctx context.Context, in *EmptyMessage, opts ...grpc.CallOption) (*SupportedCryptoMethodsResponse, error)
+	IsSupportedCryptoMethod(ctx context.Context, in *TypeMessage, opts ...grpc.CallOption) (*BooleanResponse, error)
+}
+
+type cRDTClient struct {
+	cc *grpc.ClientConn
+}
+
+func NewCRDTClient(cc *grpc.ClientConn) CRDTClient {
+	return &cRDTClient{cc}
+}
+
+func (c *cRDTClient) Create(ctx context.Context, in *CreateRequest, opts ...grpc.CallOption) (*CreateResponse, error) {
+	out := new(CreateResponse)
+	err := grpc.Invoke(ctx, "/crdt.CRDT/Create", in, out, c.cc, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *cRDTClient) Attach(ctx context.Context, in *AttachRequest, opts ...grpc.CallOption) (*AttachResponse, error) {
+	out := new(AttachResponse)
+	err := grpc.Invoke(ctx, "/crdt.CRDT/Attach", in, out, c.cc, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *cRDTClient) Detach(ctx context.Context, in *DetachRequest, opts ...grpc.CallOption) (*DetachResponse, error) {
+	out := new(DetachResponse)
+	err := grpc.Invoke(ctx, "/crdt.CRDT/Detach", in, out, c.cc, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *cRDTClient) Subscribe(ctx context.Context, in *SubscribeRequest, opts ...grpc.CallOption) (CRDT_SubscribeClient, error) {
+	stream, err := grpc.NewClientStream(ctx, &_CRDT_serviceDesc.Streams[0], c.cc, "/crdt.CRDT/Subscribe", opts...)
+	if err != nil {
+		return nil, err
+	}
+	x := &cRDTSubscribeClient{stream}
+	if err := x.ClientStream.SendMsg(in); err != nil {
+		return nil, err
+	}
+	if err := x.ClientStream.CloseSend(); err != nil {
+		return nil, err
+	}
+	return x, nil
+}
+
+type CRDT_SubscribeClient interface {
+	Recv() (*Notification, error)
+	grpc.ClientStream
+}
+
+type cRDTSubscribeClient struct {
+	grpc.ClientStream
+}
+
+func (x *cRDTSubscribeClient) Recv() (*Notification, error) {
+	m := new(Notification)
+	if err := x.ClientStream.RecvMsg(m); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+func (c *cRDTClient) Commit(ctx context.Context, in *CommitRequest, opts ...grpc.CallOption) (*CommitResponse, error) {
+	out := new(CommitResponse)
+	err := grpc.Invoke(ctx, "/crdt.CRDT/Commit", in, out, c.cc, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *cRDTClient) Equals(ctx context.Context, in *EqualsRequest, opts ...grpc.CallOption) (*EqualsResponse, error) {
+	out := new(EqualsResponse)
+	err := grpc.Invoke(ctx, "/crdt.CRDT/Equals", in, out, c.cc, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *cRDTClient) Merge(ctx context.Context, in *MergeRequest, opts ...grpc.CallOption) (*MergeResponse, error) {
+	out := new(MergeResponse)
+	err := grpc.Invoke(ctx, "/crdt.CRDT/Merge", in, out, c.cc, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *cRDTClient) Clone(ctx context.Context, in *CloneRequest, opts ...grpc.CallOption) (*CloneResponse, error) {
+	out := new(CloneResponse)
+	err := grpc.Invoke(ctx, "/crdt.CRDT/Clone", in, out, c.cc, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *cRDTClient) Acknowledge(ctx context.Context, in *AcknowledgeRequest, opts ...grpc.CallOption) (*AcknowledgeResponse, error) {
+	out := new(AcknowledgeResponse)
+	err := grpc.Invoke(ctx, "/crdt.CRDT/Acknowledge", in, out, c.cc, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *cRDTClient) Compact(ctx context.Context, in *CompactRequest, opts ...grpc.CallOption) (*CompactResponse, error) {
+	out := new(CompactResponse)
+	err := grpc.Invoke(ctx, "/crdt.CRDT/Compact", in, out, c.cc, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *cRDTClient) SupportedTypes(ctx context.Context, in *EmptyMessage, opts ...grpc.CallOption) (*SupportedTypesResponse, error) {
+	out := new(SupportedTypesResponse)
+	err := grpc.Invoke(ctx, "/crdt.CRDT/SupportedTypes", in, out, c.cc, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *cRDTClient) IsSupportedType(ctx context.Context, in *TypeMessage, opts ...grpc.CallOption) (*BooleanResponse, error) {
+	out := new(BooleanResponse)
+	err := grpc.Invoke(ctx, "/crdt.CRDT/IsSupportedType", in, out, c.cc, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *cRDTClient) SupportedStorageTypes(ctx context.Context, in *EmptyMessage, opts ...grpc.CallOption) (*SupportedStorageTypesResponse, error) {
+	out := new(SupportedStorageTypesResponse)
+	err := grpc.Invoke(ctx, "/crdt.CRDT/SupportedStorageTypes", in, out, c.cc, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *cRDTClient) IsSupportedStorageType(ctx context.Context, in *TypeMessage, opts ...grpc.CallOption) (*BooleanResponse, error) {
+	out := new(BooleanResponse)
+	err := grpc.Invoke(ctx, "/crdt.CRDT/IsSupportedStorageType", in, out, c.cc, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *cRDTClient) SupportedCryptoMethods(ctx context.Context, in *EmptyMessage, opts ...grpc.CallOption) (*SupportedCryptoMethodsResponse, error) {
+	out := new(SupportedCryptoMethodsResponse)
+	err := grpc.Invoke(ctx, "/crdt.CRDT/SupportedCryptoMethods", in, out, c.cc, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *cRDTClient) IsSupportedCryptoMethod(ctx context.Context, in *TypeMessage, opts ...grpc.CallOption) (*BooleanResponse, error) {
+	out := new(BooleanResponse)
+	err := grpc.Invoke(ctx, "/crdt.CRDT/IsSupportedCryptoMethod", in, out, c.cc, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// Server API for CRDT service
+
+type CRDTServer interface {
 	// Create a new data set in internal datastore.
 	Create(context.Context, *CreateRequest) (*CreateResponse, error)
 	// Attach to an existing data set.
@@ -698,6 +1795,10 @@ type CRDTServer interface {
 	Merge(context.Context, *MergeRequest) (*MergeResponse, error)
 	// Clone a reference into a new resource.
 	Clone(context.Context, *CloneRequest) (*CloneResponse, error)
+	// Record that a replica has applied the deltas of a resource.
+	Acknowledge(context.Context, *AcknowledgeRequest) (*AcknowledgeResponse, error)
+	// Discard tombstones every replica of a resource holds.
+	Compact(context.Context, *CompactRequest) (*CompactResponse, error)
 	// Returns a list of supported data types.
 	SupportedTypes(context.Context, *EmptyMessage) (*SupportedTypesResponse, error)
 	IsSupportedType(context.Context, *TypeMessage) (*BooleanResponse, error)
@@ -709,620 +1810,3335 @@ type CRDTServer interface {
 	IsSupportedCryptoMethod(context.Context, *TypeMessage) (*BooleanResponse, error)
 }
 
-func RegisterCRDTServer(s *grpc.Server, srv CRDTServer) {
-	s.RegisterService(&_CRDT_serviceDesc, srv)
+func RegisterCRDTServer(s *grpc.Server, srv CRDTServer) {
+	s.RegisterService(&_CRDT_serviceDesc, srv)
+}
+
+func _CRDT_Create_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error) (interface{}, error) {
+	in := new(CreateRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	out, err := srv.(CRDTServer).Create(ctx, in)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func _CRDT_Attach_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error) (interface{}, error) {
+	in := new(AttachRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	out, err := srv.(CRDTServer).Attach(ctx, in)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func _CRDT_Detach_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error) (interface{}, error) {
+	in := new(DetachRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	out, err := srv.(CRDTServer).Detach(ctx, in)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func _CRDT_Subscribe_Handler(srv interface{}, stream grpc.ServerStream) error {
+	m := new(SubscribeRequest)
+	if err := stream.RecvMsg(m); err != nil {
+		return err
+	}
+	return srv.(CRDTServer).Subscribe(m, &cRDTSubscribeServer{stream})
+}
+
+type CRDT_SubscribeServer interface {
+	Send(*Notification) error
+	grpc.ServerStream
+}
+
+type cRDTSubscribeServer struct {
+	grpc.ServerStream
+}
+
+func (x *cRDTSubscribeServer) Send(m *Notification) error {
+	return x.ServerStream.SendMsg(m)
+}
+
+func _CRDT_Commit_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error) (interface{}, error) {
+	in := new(CommitRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	out, err := srv.(CRDTServer).Commit(ctx, in)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func _CRDT_Equals_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error) (interface{}, error) {
+	in := new(EqualsRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	out, err := srv.(CRDTServer).Equals(ctx, in)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func _CRDT_Merge_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error) (interface{}, error) {
+	in := new(MergeRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	out, err := srv.(CRDTServer).Merge(ctx, in)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func _CRDT_Clone_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error) (interface{}, error) {
+	in := new(CloneRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	out, err := srv.(CRDTServer).Clone(ctx, in)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func _CRDT_Acknowledge_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error) (interface{}, error) {
+	in := new(AcknowledgeRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	out, err := srv.(CRDTServer).Acknowledge(ctx, in)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func _CRDT_Compact_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error) (interface{}, error) {
+	in := new(CompactRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	out, err := srv.(CRDTServer).Compact(ctx, in)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func _CRDT_SupportedTypes_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error) (interface{}, error) {
+	in := new(EmptyMessage)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	out, err := srv.(CRDTServer).SupportedTypes(ctx, in)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func _CRDT_IsSupportedType_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error) (interface{}, error) {
+	in := new(TypeMessage)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	out, err := srv.(CRDTServer).IsSupportedType(ctx, in)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func _CRDT_SupportedStorageTypes_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error) (interface{}, error) {
+	in := new(EmptyMessage)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	out, err := srv.(CRDTServer).SupportedStorageTypes(ctx, in)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func _CRDT_IsSupportedStorageType_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error) (interface{}, error) {
+	in := new(TypeMessage)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	out, err := srv.(CRDTServer).IsSupportedStorageType(ctx, in)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func _CRDT_SupportedCryptoMethods_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error) (interface{}, error) {
+	in := new(EmptyMessage)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	out, err := srv.(CRDTServer).SupportedCryptoMethods(ctx, in)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func _CRDT_IsSupportedCryptoMethod_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error) (interface{}, error) {
+	in := new(TypeMessage)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	out, err := srv.(CRDTServer).IsSupportedCryptoMethod(ctx, in)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+var _CRDT_serviceDesc = grpc.ServiceDesc{
+	ServiceName: "crdt.CRDT",
+	HandlerType: (*CRDTServer)(nil),
+	Methods: []grpc.MethodDesc{
+		{
+			MethodName: "Create",
+			Handler:    _CRDT_Create_Handler,
+		},
+		{
+			MethodName: "Attach",
+			Handler:    _CRDT_Attach_Handler,
+		},
+		{
+			MethodName: "Detach",
+			Handler:    _CRDT_Detach_Handler,
+		},
+		{
+			MethodName: "Commit",
+			Handler:    _CRDT_Commit_Handler,
+		},
+		{
+			MethodName: "Equals",
+			Handler:    _CRDT_Equals_Handler,
+		},
+		{
+			MethodName: "Merge",
+			Handler:    _CRDT_Merge_Handler,
+		},
+		{
+			MethodName: "Clone",
+			Handler:    _CRDT_Clone_Handler,
+		},
+		{
+			MethodName: "Acknowledge",
+			Handler:    _CRDT_Acknowledge_Handler,
+		},
+		{
+			MethodName: "Compact",
+			Handler:    _CRDT_Compact_Handler,
+		},
+		{
+			MethodName: "SupportedTypes",
+			Handler:    _CRDT_SupportedTypes_Handler,
+		},
+		{
+			MethodName: "IsSupportedType",
+			Handler:    _CRDT_IsSupportedType_Handler,
+		},
+		{
+			MethodName: "SupportedStorageTypes",
+			Handler:    _CRDT_SupportedStorageTypes_Handler,
+		},
+		{
+			MethodName: "IsSupportedStorageType",
+			Handler:    _CRDT_IsSupportedStorageType_Handler,
+		},
+		{
+			MethodName: "SupportedCryptoMethods",
+			Handler:    _CRDT_SupportedCryptoMethods_Handler,
+		},
+		{
+			MethodName: "IsSupportedCryptoMethod",
+			Handler:    _CRDT_IsSupportedCryptoMethod_Handler,
+		},
+	},
+	Streams: []grpc.StreamDesc{
+		{
+			StreamName:    "Subscribe",
+			Handler:       _CRDT_Subscribe_Handler,
+			ServerStreams: true,
+		},
+	},
+}
+
+// Client API for GrowOnlySet service
+
+type GrowOnlySetClient interface {
+	List(ctx context.Context, in *SetListRequest, opts ...grpc.CallOption) (GrowOnlySet_ListClient, error)
+	Insert(ctx context.Context, in *SetInsertRequest, opts ...grpc.CallOption) (*SetInsertResponse, error)
+	Length(ctx context.Context, in *SetLengthRequest, opts ...grpc.CallOption) (*SetLengthResponse, error)
+	Contains(ctx context.Context, in *SetContainsRequest, opts ...grpc.CallOption) (*SetContainsResponse, error)
+	Union(ctx context.Context, in *SetAlgebraRequest, opts ...grpc.CallOption) (GrowOnlySet_UnionClient, error)
+	Intersect(ctx context.Context, in *SetAlgebraRequest, opts ...grpc.CallOption) (GrowOnlySet_IntersectClient, error)
+	Difference(ctx context.Context, in *SetAlgebraRequest, opts ...grpc.CallOption) (GrowOnlySet_DifferenceClient, error)
+	IsSubset(ctx context.Context, in *SetIsSubsetRequest, opts ...grpc.CallOption) (*SetIsSubsetResponse, error)
+	ListWithMetadata(ctx context.Context, in *SetListRequest, opts ...grpc.CallOption) (GrowOnlySet_ListWithMetadataClient, error)
+}
+
+type growOnlySetClient struct {
+	cc *grpc.ClientConn
+}
+
+func NewGrowOnlySetClient(cc *grpc.ClientConn) GrowOnlySetClient {
+	return &growOnlySetClient{cc}
+}
+
+func (c *growOnlySetClient) List(ctx context.Context, in *SetListRequest, opts ...grpc.CallOption) (GrowOnlySet_ListClient, error) {
+	stream, err := grpc.NewClientStream(ctx, &_GrowOnlySet_serviceDesc.Streams[0], c.cc, "/crdt.GrowOnlySet/List", opts...)
+	if err != nil {
+		return nil, err
+	}
+	x := &growOnlySetListClient{stream}
+	if err := x.ClientStream.SendMsg(in); err != nil {
+		return nil, err
+	}
+	if err := x.ClientStream.CloseSend(); err != nil {
+		return nil, err
+	}
+	return x, nil
+}
+
+type GrowOnlySet_ListClient interface {
+	Recv() (*ResourceObject, error)
+	grpc.ClientStream
+}
+
+type growOnlySetListClient struct {
+	grpc.ClientStream
+}
+
+func (x *growOnlySetListClient) Recv() (*ResourceObject, error) {
+	m := new(ResourceObject)
+	if err := x.ClientStream.RecvMsg(m); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+func (c *growOnlySetClient) Insert(ctx context.Context, in *SetInsertRequest, opts ...grpc.CallOption) (*SetInsertResponse, error) {
+	out := new(SetInsertResponse)
+	err := grpc.Invoke(ctx, "/crdt.GrowOnlySet/Insert", in, out, c.cc, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *growOnlySetClient) Length(ctx context.Context, in *SetLengthRequest, opts ...grpc.CallOption) (*SetLengthResponse, error) {
+	out := new(SetLengthResponse)
+	err := grpc.Invoke(ctx, "/crdt.GrowOnlySet/Length", in, out, c.cc, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *growOnlySetClient) Contains(ctx context.Context, in *SetContainsRequest, opts ...grpc.CallOption) (*SetContainsResponse, error) {
+	out := new(SetContainsResponse)
+	err := grpc.Invoke(ctx, "/crdt.GrowOnlySet/Contains", in, out, c.cc, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *growOnlySetClient) Union(ctx context.Context, in *SetAlgebraRequest, opts ...grpc.CallOption) (GrowOnlySet_UnionClient, error) {
+	stream, err := grpc.NewClientStream(ctx, &_GrowOnlySet_serviceDesc.Streams[1], c.cc, "/crdt.GrowOnlySet/Union", opts...)
+	if err != nil {
+		return nil, err
+	}
+	x := &growOnlySetUnionClient{stream}
+	if err := x.ClientStream.SendMsg(in); err != nil {
+		return nil, err
+	}
+	if err := x.ClientStream.CloseSend(); err != nil {
+		return nil, err
+	}
+	return x, nil
+}
+
+type GrowOnlySet_UnionClient interface {
+	Recv() (*SetAlgebraResponse, error)
+	grpc.ClientStream
+}
+
+type growOnlySetUnionClient struct {
+	grpc.ClientStream
+}
+
+func (x *growOnlySetUnionClient) Recv() (*SetAlgebraResponse, error) {
+	m := new(SetAlgebraResponse)
+	if err := x.ClientStream.RecvMsg(m); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+func (c *growOnlySetClient) Intersect(ctx context.Context, in *SetAlgebraRequest, opts ...grpc.CallOption) (GrowOnlySet_IntersectClient, error) {
+	stream, err := grpc.NewClientStream(ctx, &_GrowOnlySet_serviceDesc.Streams[2], c.cc, "/crdt.GrowOnlySet/Intersect", opts...)
+	if err != nil {
+		return nil, err
+	}
+	x := &growOnlySetIntersectClient{stream}
+	if err := x.ClientStream.SendMsg(in); err != nil {
+		return nil, err
+	}
+	if err := x.ClientStream.CloseSend(); err != nil {
+		return nil, err
+	}
+	return x, nil
+}
+
+type GrowOnlySet_IntersectClient interface {
+	Recv() (*SetAlgebraResponse, error)
+	grpc.ClientStream
+}
+
+type growOnlySetIntersectClient struct {
+	grpc.ClientStream
+}
+
+func (x *growOnlySetIntersectClient) Recv() (*SetAlgebraResponse, error) {
+	m := new(SetAlgebraResponse)
+	if err := x.ClientStream.RecvMsg(m); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+func (c *growOnlySetClient) Difference(ctx context.Context, in *SetAlgebraRequest, opts ...grpc.CallOption) (GrowOnlySet_DifferenceClient, error) {
+	stream, err := grpc.NewClientStream(ctx, &_GrowOnlySet_serviceDesc.Streams[3], c.cc, "/crdt.GrowOnlySet/Difference", opts...)
+	if err != nil {
+		return nil, err
+	}
+	x := &growOnlySetDifferenceClient{stream}
+	if err := x.ClientStream.SendMsg(in); err != nil {
+		return nil, err
+	}
+	if err := x.ClientStream.CloseSend(); err != nil {
+		return nil, err
+	}
+	return x, nil
+}
+
+type GrowOnlySet_DifferenceClient interface {
+	Recv() (*SetAlgebraResponse, error)
+	grpc.ClientStream
+}
+
+type growOnlySetDifferenceClient struct {
+	grpc.ClientStream
+}
+
+func (x *growOnlySetDifferenceClient) Recv() (*SetAlgebraResponse, error) {
+	m := new(SetAlgebraResponse)
+	if err := x.ClientStream.RecvMsg(m); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+func (c *growOnlySetClient) IsSubset(ctx context.Context, in *SetIsSubsetRequest, opts ...grpc.CallOption) (*SetIsSubsetResponse, error) {
+	out := new(SetIsSubsetResponse)
+	err := grpc.Invoke(ctx, "/crdt.GrowOnlySet/IsSubset", in, out, c.cc, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *growOnlySetClient) ListWithMetadata(ctx context.Context, in *SetListRequest, opts ...grpc.CallOption) (GrowOnlySet_ListWithMetadataClient, error) {
+	stream, err := grpc.NewClientStream(ctx, &_GrowOnlySet_serviceDesc.Streams[4], c.cc, "/crdt.GrowOnlySet/ListWithMetadata", opts...)
+	if err != nil {
+		return nil, err
+	}
+	x := &growOnlySetListWithMetadataClient{stream}
+	if err := x.ClientStream.SendMsg(in); err != nil {
+		return nil, err
+	}
+	if err := x.ClientStream.CloseSend(); err != nil {
+		return nil, err
+	}
+	return x, nil
+}
+
+type GrowOnlySet_ListWithMetadataClient interface {
+	Recv() (*SetListWithMetadataResponse, error)
+	grpc.ClientStream
+}
+
+type growOnlySetListWithMetadataClient struct {
+	grpc.ClientStream
+}
+
+func (x *growOnlySetListWithMetadataClient) Recv() (*SetListWithMetadataResponse, error) {
+	m := new(SetListWithMetadataResponse)
+	if err := x.ClientStream.RecvMsg(m); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+// Server API for GrowOnlySet service
+
+type GrowOnlySetServer interface {
+	List(*SetListRequest, GrowOnlySet_ListServer) error
+	Insert(context.Context, *SetInsertRequest) (*SetInsertResponse, error)
+	Length(context.Context, *SetLengthRequest) (*SetLengthResponse, error)
+	Contains(context.Context, *SetContainsRequest) (*SetContainsResponse, error)
+	Union(*SetAlgebraRequest, GrowOnlySet_UnionServer) error
+	Intersect(*SetAlgebraRequest, GrowOnlySet_IntersectServer) error
+	Difference(*SetAlgebraRequest, GrowOnlySet_DifferenceServer) error
+	IsSubset(context.Context, *SetIsSubsetRequest) (*SetIsSubsetResponse, error)
+	ListWithMetadata(*SetListRequest, GrowOnlySet_ListWithMetadataServer) error
+}
+
+func RegisterGrowOnlySetServer(s *grpc.Server, srv GrowOnlySetServer) {
+	s.RegisterService(&_GrowOnlySet_serviceDesc, srv)
+}
+
+func _GrowOnlySet_List_Handler(srv interface{}, stream grpc.ServerStream) error {
+	m := new(SetListRequest)
+	if err := stream.RecvMsg(m); err != nil {
+		return err
+	}
+	return srv.(GrowOnlySetServer).List(m, &growOnlySetListServer{stream})
+}
+
+type GrowOnlySet_ListServer interface {
+	Send(*ResourceObject) error
+	grpc.ServerStream
+}
+
+type growOnlySetListServer struct {
+	grpc.ServerStream
+}
+
+func (x *growOnlySetListServer) Send(m *ResourceObject) error {
+	return x.ServerStream.SendMsg(m)
+}
+
+func _GrowOnlySet_Insert_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error) (interface{}, error) {
+	in := new(SetInsertRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	out, err := srv.(GrowOnlySetServer).Insert(ctx, in)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func _GrowOnlySet_Length_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error) (interface{}, error) {
+	in := new(SetLengthRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	out, err := srv.(GrowOnlySetServer).Length(ctx, in)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func _GrowOnlySet_Contains_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error) (interface{}, error) {
+	in := new(SetContainsRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	out, err := srv.(GrowOnlySetServer).Contains(ctx, in)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func _GrowOnlySet_Union_Handler(srv interface{}, stream grpc.ServerStream) error {
+	m := new(SetAlgebraRequest)
+	if err := stream.RecvMsg(m); err != nil {
+		return err
+	}
+	return srv.(GrowOnlySetServer).Union(m, &growOnlySetUnionServer{stream})
+}
+
+type GrowOnlySet_UnionServer interface {
+	Send(*SetAlgebraResponse) error
+	grpc.ServerStream
+}
+
+type growOnlySetUnionServer struct {
+	grpc.ServerStream
+}
+
+func (x *growOnlySetUnionServer) Send(m *SetAlgebraResponse) error {
+	return x.ServerStream.SendMsg(m)
+}
+
+func _GrowOnlySet_Intersect_Handler(srv interface{}, stream grpc.ServerStream) error {
+	m := new(SetAlgebraRequest)
+	if err := stream.RecvMsg(m); err != nil {
+		return err
+	}
+	return srv.(GrowOnlySetServer).Intersect(m, &growOnlySetIntersectServer{stream})
+}
+
+type GrowOnlySet_IntersectServer interface {
+	Send(*SetAlgebraResponse) error
+	grpc.ServerStream
+}
+
+type growOnlySetIntersectServer struct {
+	grpc.ServerStream
+}
+
+func (x *growOnlySetIntersectServer) Send(m *SetAlgebraResponse) error {
+	return x.ServerStream.SendMsg(m)
+}
+
+func _GrowOnlySet_Difference_Handler(srv interface{}, stream grpc.ServerStream) error {
+	m := new(SetAlgebraRequest)
+	if err := stream.RecvMsg(m); err != nil {
+		return err
+	}
+	return srv.(GrowOnlySetServer).Difference(m, &growOnlySetDifferenceServer{stream})
+}
+
+type GrowOnlySet_DifferenceServer interface {
+	Send(*SetAlgebraResponse) error
+	grpc.ServerStream
+}
+
+type growOnlySetDifferenceServer struct {
+	grpc.ServerStream
+}
+
+func (x *growOnlySetDifferenceServer) Send(m *SetAlgebraResponse) error {
+	return x.ServerStream.SendMsg(m)
+}
+
+func _GrowOnlySet_IsSubset_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error) (interface{}, error) {
+	in := new(SetIsSubsetRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	out, err := srv.(GrowOnlySetServer).IsSubset(ctx, in)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func _GrowOnlySet_ListWithMetadata_Handler(srv interface{}, stream grpc.ServerStream) error {
+	m := new(SetListRequest)
+	if err := stream.RecvMsg(m); err != nil {
+		return err
+	}
+	return srv.(GrowOnlySetServer).ListWithMetadata(m, &growOnlySetListWithMetadataServer{stream})
+}
+
+type GrowOnlySet_ListWithMetadataServer interface {
+	Send(*SetListWithMetadataResponse) error
+	grpc.ServerStream
+}
+
+type growOnlySetListWithMetadataServer struct {
+	grpc.ServerStream
+}
+
+func (x *growOnlySetListWithMetadataServer) Send(m *SetListWithMetadataResponse) error {
+	return x.ServerStream.SendMsg(m)
+}
+
+var _GrowOnlySet_serviceDesc = grpc.ServiceDesc{
+	ServiceName: "crdt.GrowOnlySet",
+	HandlerType: (*GrowOnlySetServer)(nil),
+	Methods: []grpc.MethodDesc{
+		{
+			MethodName: "Insert",
+			Handler:    _GrowOnlySet_Insert_Handler,
+		},
+		{
+			MethodName: "Length",
+			Handler:    _GrowOnlySet_Length_Handler,
+		},
+		{
+			MethodName: "Contains",
+			Handler:    _GrowOnlySet_Contains_Handler,
+		},
+		{
+			MethodName: "IsSubset",
+			Handler:    _GrowOnlySet_IsSubset_Handler,
+		},
+	},
+	Streams: []grpc.StreamDesc{
+		{
+			StreamName:    "List",
+			Handler:       _GrowOnlySet_List_Handler,
+			ServerStreams: true,
+		},
+		{
+			StreamName:    "Union",
+			Handler:       _GrowOnlySet_Union_Handler,
+			ServerStreams: true,
+		},
+		{
+			StreamName:    "Intersect",
+			Handler:       _GrowOnlySet_Intersect_Handler,
+			ServerStreams: true,
+		},
+		{
+			StreamName:    "Difference",
+			Handler:       _GrowOnlySet_Difference_Handler,
+			ServerStreams: true,
+		},
+		{
+			StreamName:    "ListWithMetadata",
+			Handler:       _GrowOnlySet_ListWithMetadata_Handler,
+			ServerStreams: true,
+		},
+	},
+}
+
+// Client API for TwoPhaseSet service
+
+type TwoPhaseSetClient interface {
+	List(ctx context.Context, in *SetListRequest, opts ...grpc.CallOption) (TwoPhaseSet_ListClient, error)
+	Insert(ctx context.Context, in *SetInsertRequest, opts ...grpc.CallOption) (*SetInsertResponse, error)
+	Remove(ctx context.Context, in *SetRemoveRequest, opts ...grpc.CallOption) (*SetRemoveResponse, error)
+	Length(ctx context.Context, in *SetLengthRequest, opts ...grpc.CallOption) (*SetLengthResponse, error)
+	Contains(ctx context.Context, in *SetContainsRequest, opts ...grpc.CallOption) (*SetContainsResponse, error)
+	Union(ctx context.Context, in *SetAlgebraRequest, opts ...grpc.CallOption) (TwoPhaseSet_UnionClient, error)
+	Intersect(ctx context.Context, in *SetAlgebraRequest, opts ...grpc.CallOption) (TwoPhaseSet_IntersectClient, error)
+	Difference(ctx context.Context, in *SetAlgebraRequest, opts ...grpc.CallOption) (TwoPhaseSet_DifferenceClient, error)
+	IsSubset(ctx context.Context, in *SetIsSubsetRequest, opts ...grpc.CallOption) (*SetIsSubsetResponse, error)
+	ListWithMetadata(ctx context.Context, in *SetListRequest, opts ...grpc.CallOption) (TwoPhaseSet_ListWithMetadataClient, error)
+}
+
+type twoPhaseSetClient struct {
+	cc *grpc.ClientConn
+}
+
+func NewTwoPhaseSetClient(cc *grpc.ClientConn) TwoPhaseSetClient {
+	return &twoPhaseSetClient{cc}
+}
+
+func (c *twoPhaseSetClient) List(ctx context.Context, in *SetListRequest, opts ...grpc.CallOption) (TwoPhaseSet_ListClient, error) {
+	stream, err := grpc.NewClientStream(ctx, &_TwoPhaseSet_serviceDesc.Streams[0], c.cc, "/crdt.TwoPhaseSet/List", opts...)
+	if err != nil {
+		return nil, err
+	}
+	x := &twoPhaseSetListClient{stream}
+	if err := x.ClientStream.SendMsg(in); err != nil {
+		return nil, err
+	}
+	if err := x.ClientStream.CloseSend(); err != nil {
+		return nil, err
+	}
+	return x, nil
+}
+
+type TwoPhaseSet_ListClient interface {
+	Recv() (*ResourceObject, error)
+	grpc.ClientStream
+}
+
+type twoPhaseSetListClient struct {
+	grpc.ClientStream
+}
+
+func (x *twoPhaseSetListClient) Recv() (*ResourceObject, error) {
+	m := new(ResourceObject)
+	if err := x.ClientStream.RecvMsg(m); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+func (c *twoPhaseSetClient) Insert(ctx context.Context, in *SetInsertRequest, opts ...grpc.CallOption) (*SetInsertResponse, error) {
+	out := new(SetInsertResponse)
+	err := grpc.Invoke(ctx, "/crdt.TwoPhaseSet/Insert", in, out, c.cc, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *twoPhaseSetClient) Remove(ctx context.Context, in *SetRemoveRequest, opts ...grpc.CallOption) (*SetRemoveResponse, error) {
+	out := new(SetRemoveResponse)
+	err := grpc.Invoke(ctx, "/crdt.TwoPhaseSet/Remove", in, out, c.cc, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *twoPhaseSetClient) Length(ctx context.Context, in *SetLengthRequest, opts ...grpc.CallOption) (*SetLengthResponse, error) {
+	out := new(SetLengthResponse)
+	err := grpc.Invoke(ctx, "/crdt.TwoPhaseSet/Length", in, out, c.cc, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *twoPhaseSetClient) Contains(ctx context.Context, in *SetContainsRequest, opts ...grpc.CallOption) (*SetContainsResponse, error) {
+	out := new(SetContainsResponse)
+	err := grpc.Invoke(ctx, "/crdt.TwoPhaseSet/Contains", in, out, c.cc, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *twoPhaseSetClient) Union(ctx context.Context, in *SetAlgebraRequest, opts ...grpc.CallOption) (TwoPhaseSet_UnionClient, error) {
+	stream, err := grpc.NewClientStream(ctx, &_TwoPhaseSet_serviceDesc.Streams[1], c.cc, "/crdt.TwoPhaseSet/Union", opts...)
+	if err != nil {
+		return nil, err
+	}
+	x := &twoPhaseSetUnionClient{stream}
+	if err := x.ClientStream.SendMsg(in); err != nil {
+		return nil, err
+	}
+	if err := x.ClientStream.CloseSend(); err != nil {
+		return nil, err
+	}
+	return x, nil
+}
+
+type TwoPhaseSet_UnionClient interface {
+	Recv() (*SetAlgebraResponse, error)
+	grpc.ClientStream
+}
+
+type twoPhaseSetUnionClient struct {
+	grpc.ClientStream
+}
+
+func (x *twoPhaseSetUnionClient) Recv() (*SetAlgebraResponse, error) {
+	m := new(SetAlgebraResponse)
+	if err := x.ClientStream.RecvMsg(m); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+func (c *twoPhaseSetClient) Intersect(ctx context.Context, in *SetAlgebraRequest, opts ...grpc.CallOption) (TwoPhaseSet_IntersectClient, error) {
+	stream, err := grpc.NewClientStream(ctx, &_TwoPhaseSet_serviceDesc.Streams[2], c.cc, "/crdt.TwoPhaseSet/Intersect", opts...)
+	if err != nil {
+		return nil, err
+	}
+	x := &twoPhaseSetIntersectClient{stream}
+	if err := x.ClientStream.SendMsg(in); err != nil {
+		return nil, err
+	}
+	if err := x.ClientStream.CloseSend(); err != nil {
+		return nil, err
+	}
+	return x, nil
+}
+
+type TwoPhaseSet_IntersectClient interface {
+	Recv() (*SetAlgebraResponse, error)
+	grpc.ClientStream
+}
+
+type twoPhaseSetIntersectClient struct {
+	grpc.ClientStream
+}
+
+func (x *twoPhaseSetIntersectClient) Recv() (*SetAlgebraResponse, error) {
+	m := new(SetAlgebraResponse)
+	if err := x.ClientStream.RecvMsg(m); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+func (c *twoPhaseSetClient) Difference(ctx context.Context, in *SetAlgebraRequest, opts ...grpc.CallOption) (TwoPhaseSet_DifferenceClient, error) {
+	stream, err := grpc.NewClientStream(ctx, &_TwoPhaseSet_serviceDesc.Streams[3], c.cc, "/crdt.TwoPhaseSet/Difference", opts...)
+	if err != nil {
+		return nil, err
+	}
+	x := &twoPhaseSetDifferenceClient{stream}
+	if err := x.ClientStream.SendMsg(in); err != nil {
+		return nil, err
+	}
+	if err := x.ClientStream.CloseSend(); err != nil {
+		return nil, err
+	}
+	return x, nil
+}
+
+type TwoPhaseSet_DifferenceClient interface {
+	Recv() (*SetAlgebraResponse, error)
+	grpc.ClientStream
+}
+
+type twoPhaseSetDifferenceClient struct {
+	grpc.ClientStream
+}
+
+func (x *twoPhaseSetDifferenceClient) Recv() (*SetAlgebraResponse, error) {
+	m := new(SetAlgebraResponse)
+	if err := x.ClientStream.RecvMsg(m); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+func (c *twoPhaseSetClient) IsSubset(ctx context.Context, in *SetIsSubsetRequest, opts ...grpc.CallOption) (*SetIsSubsetResponse, error) {
+	out := new(SetIsSubsetResponse)
+	err := grpc.Invoke(ctx, "/crdt.TwoPhaseSet/IsSubset", in, out, c.cc, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *twoPhaseSetClient) ListWithMetadata(ctx context.Context, in *SetListRequest, opts ...grpc.CallOption) (TwoPhaseSet_ListWithMetadataClient, error) {
+	stream, err := grpc.NewClientStream(ctx, &_TwoPhaseSet_serviceDesc.Streams[4], c.cc, "/crdt.TwoPhaseSet/ListWithMetadata", opts...)
+	if err != nil {
+		return nil, err
+	}
+	x := &twoPhaseSetListWithMetadataClient{stream}
+	if err := x.ClientStream.SendMsg(in); err != nil {
+		return nil, err
+	}
+	if err := x.ClientStream.CloseSend(); err != nil {
+		return nil, err
+	}
+	return x, nil
+}
+
+type TwoPhaseSet_ListWithMetadataClient interface {
+	Recv() (*SetListWithMetadataResponse, error)
+	grpc.ClientStream
+}
+
+type twoPhaseSetListWithMetadataClient struct {
+	grpc.ClientStream
+}
+
+func (x *twoPhaseSetListWithMetadataClient) Recv() (*SetListWithMetadataResponse, error) {
+	m := new(SetListWithMetadataResponse)
+	if err := x.ClientStream.RecvMsg(m); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+// Server API for TwoPhaseSet service
+
+type TwoPhaseSetServer interface {
+	List(*SetListRequest, TwoPhaseSet_ListServer) error
+	Insert(context.Context, *SetInsertRequest) (*SetInsertResponse, error)
+	Remove(context.Context, *SetRemoveRequest) (*SetRemoveResponse, error)
+	Length(context.Context, *SetLengthRequest) (*SetLengthResponse, error)
+	Contains(context.Context, *SetContainsRequest) (*SetContainsResponse, error)
+	Union(*SetAlgebraRequest, TwoPhaseSet_UnionServer) error
+	Intersect(*SetAlgebraRequest, TwoPhaseSet_IntersectServer) error
+	Difference(*SetAlgebraRequest, TwoPhaseSet_DifferenceServer) error
+	IsSubset(context.Context, *SetIsSubsetRequest) (*SetIsSubsetResponse, error)
+	ListWithMetadata(*SetListRequest, TwoPhaseSet_ListWithMetadataServer) error
+}
+
+func RegisterTwoPhaseSetServer(s *grpc.Server, srv TwoPhaseSetServer) {
+	s.RegisterService(&_TwoPhaseSet_serviceDesc, srv)
+}
+
+func _TwoPhaseSet_List_Handler(srv interface{}, stream grpc.ServerStream) error {
+	m := new(SetListRequest)
+	if err := stream.RecvMsg(m); err != nil {
+		return err
+	}
+	return srv.(TwoPhaseSetServer).List(m, &twoPhaseSetListServer{stream})
+}
+
+type TwoPhaseSet_ListServer interface {
+	Send(*ResourceObject) error
+	grpc.ServerStream
+}
+
+type twoPhaseSetListServer struct {
+	grpc.ServerStream
+}
+
+func (x *twoPhaseSetListServer) Send(m *ResourceObject) error {
+	return x.ServerStream.SendMsg(m)
+}
+
+func _TwoPhaseSet_Insert_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error) (interface{}, error) {
+	in := new(SetInsertRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	out, err := srv.(TwoPhaseSetServer).Insert(ctx, in)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func _TwoPhaseSet_Remove_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error) (interface{}, error) {
+	in := new(SetRemoveRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	out, err := srv.(TwoPhaseSetServer).Remove(ctx, in)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func _TwoPhaseSet_Length_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error) (interface{}, error) {
+	in := new(SetLengthRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	out, err := srv.(TwoPhaseSetServer).Length(ctx, in)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func _TwoPhaseSet_Contains_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error) (interface{}, error) {
+	in := new(SetContainsRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	out, err := srv.(TwoPhaseSetServer).Contains(ctx, in)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func _TwoPhaseSet_Union_Handler(srv interface{}, stream grpc.ServerStream) error {
+	m := new(SetAlgebraRequest)
+	if err := stream.RecvMsg(m); err != nil {
+		return err
+	}
+	return srv.(TwoPhaseSetServer).Union(m, &twoPhaseSetUnionServer{stream})
+}
+
+type TwoPhaseSet_UnionServer interface {
+	Send(*SetAlgebraResponse) error
+	grpc.ServerStream
+}
+
+type twoPhaseSetUnionServer struct {
+	grpc.ServerStream
+}
+
+func (x *twoPhaseSetUnionServer) Send(m *SetAlgebraResponse) error {
+	return x.ServerStream.SendMsg(m)
+}
+
+func _TwoPhaseSet_Intersect_Handler(srv interface{}, stream grpc.ServerStream) error {
+	m := new(SetAlgebraRequest)
+	if err := stream.RecvMsg(m); err != nil {
+		return err
+	}
+	return srv.(TwoPhaseSetServer).Intersect(m, &twoPhaseSetIntersectServer{stream})
+}
+
+type TwoPhaseSet_IntersectServer interface {
+	Send(*SetAlgebraResponse) error
+	grpc.ServerStream
+}
+
+type twoPhaseSetIntersectServer struct {
+	grpc.ServerStream
+}
+
+func (x *twoPhaseSetIntersectServer) Send(m *SetAlgebraResponse) error {
+	return x.ServerStream.SendMsg(m)
+}
+
+func _TwoPhaseSet_Difference_Handler(srv interface{}, stream grpc.ServerStream) error {
+	m := new(SetAlgebraRequest)
+	if err := stream.RecvMsg(m); err != nil {
+		return err
+	}
+	return srv.(TwoPhaseSetServer).Difference(m, &twoPhaseSetDifferenceServer{stream})
+}
+
+type TwoPhaseSet_DifferenceServer interface {
+	Send(*SetAlgebraResponse) error
+	grpc.ServerStream
+}
+
+type twoPhaseSetDifferenceServer struct {
+	grpc.ServerStream
+}
+
+func (x *twoPhaseSetDifferenceServer) Send(m *SetAlgebraResponse) error {
+	return x.ServerStream.SendMsg(m)
+}
+
+func _TwoPhaseSet_IsSubset_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error) (interface{}, error) {
+	in := new(SetIsSubsetRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	out, err := srv.(TwoPhaseSetServer).IsSubset(ctx, in)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func _TwoPhaseSet_ListWithMetadata_Handler(srv interface{}, stream grpc.ServerStream) error {
+	m := new(SetListRequest)
+	if err := stream.RecvMsg(m); err != nil {
+		return err
+	}
+	return srv.(TwoPhaseSetServer).ListWithMetadata(m, &twoPhaseSetListWithMetadataServer{stream})
+}
+
+type TwoPhaseSet_ListWithMetadataServer interface {
+	Send(*SetListWithMetadataResponse) error
+	grpc.ServerStream
+}
+
+type twoPhaseSetListWithMetadataServer struct {
+	grpc.ServerStream
+}
+
+func (x *twoPhaseSetListWithMetadataServer) Send(m *SetListWithMetadataResponse) error {
+	return x.ServerStream.SendMsg(m)
+}
+
+var _TwoPhaseSet_serviceDesc = grpc.ServiceDesc{
+	ServiceName: "crdt.TwoPhaseSet",
+	HandlerType: (*TwoPhaseSetServer)(nil),
+	Methods: []grpc.MethodDesc{
+		{
+			MethodName: "Insert",
+			Handler:    _TwoPhaseSet_Insert_Handler,
+		},
+		{
+			MethodName: "Remove",
+			Handler:    _TwoPhaseSet_Remove_Handler,
+		},
+		{
+			MethodName: "Length",
+			Handler:    _TwoPhaseSet_Length_Handler,
+		},
+		{
+			MethodName: "Contains",
+			Handler:    _TwoPhaseSet_Contains_Handler,
+		},
+		{
+			MethodName: "IsSubset",
+			Handler:    _TwoPhaseSet_IsSubset_Handler,
+		},
+	},
+	Streams: []grpc.StreamDesc{
+		{
+			StreamName:    "List",
+			Handler:       _TwoPhaseSet_List_Handler,
+			ServerStreams: true,
+		},
+		{
+			StreamName:    "Union",
+			Handler:       _TwoPhaseSet_Union_Handler,
+			ServerStreams: true,
+		},
+		{
+			StreamName:    "Intersect",
+			Handler:       _TwoPhaseSet_Intersect_Handler,
+			ServerStreams: true,
+		},
+		{
+			StreamName:    "Difference",
+			Handler:       _TwoPhaseSet_Difference_Handler,
+			ServerStreams: true,
+		},
+		{
+			StreamName:    "ListWithMetadata",
+			Handler:       _TwoPhaseSet_ListWithMetadata_Handler,
+			ServerStreams: true,
+		},
+	},
+}
+
+// Client API for ObserveRemoveSet service
+
+type ObserveRemoveSetClient interface {
+	List(ctx context.Context, in *SetListRequest, opts ...grpc.CallOption) (ObserveRemoveSet_ListClient, error)
+	Insert(ctx context.Context, in *SetInsertRequest, opts ...grpc.CallOption) (*SetInsertResponse, error)
+	Remove(ctx context.Context, in *SetRemoveRequest, opts ...grpc.CallOption) (*SetRemoveResponse, error)
+	Length(ctx context.Context, in *SetLengthRequest, opts ...grpc.CallOption) (*SetLengthResponse, error)
+	Contains(ctx context.Context, in *SetContainsRequest, opts ...grpc.CallOption) (*SetContainsResponse, error)
+	Union(ctx context.Context, in *SetAlgebraRequest, opts ...grpc.CallOption) (ObserveRemoveSet_UnionClient, error)
+	Intersect(ctx context.Context, in *SetAlgebraRequest, opts ...grpc.CallOption) (ObserveRemoveSet_IntersectClient, error)
+	Difference(ctx context.Context, in *SetAlgebraRequest, opts ...grpc.CallOption) (ObserveRemoveSet_DifferenceClient, error)
+	IsSubset(ctx context.Context, in *SetIsSubsetRequest, opts ...grpc.CallOption) (*SetIsSubsetResponse, error)
+	ListWithMetadata(ctx context.Context, in *SetListRequest, opts ...grpc.CallOption) (ObserveRemoveSet_ListWithMetadataClient, error)
+}
+
+type observeRemoveSetClient struct {
+	cc *grpc.ClientConn
+}
+
+func NewObserveRemoveSetClient(cc *grpc.ClientConn) ObserveRemoveSetClient {
+	return &observeRemoveSetClient{cc}
+}
+
+func (c *observeRemoveSetClient) List(ctx context.Context, in *SetListRequest, opts ...grpc.CallOption) (ObserveRemoveSet_ListClient, error) {
+	stream, err := grpc.NewClientStream(ctx, &_ObserveRemoveSet_serviceDesc.Streams[0], c.cc, "/crdt.ObserveRemoveSet/List", opts...)
+	if err != nil {
+		return nil, err
+	}
+	x := &observeRemoveSetListClient{stream}
+	if err := x.ClientStream.SendMsg(in); err != nil {
+		return nil, err
+	}
+	if err := x.ClientStream.CloseSend(); err != nil {
+		return nil, err
+	}
+	return x, nil
+}
+
+type ObserveRemoveSet_ListClient interface {
+	Recv() (*ResourceObject, error)
+	grpc.ClientStream
+}
+
+type observeRemoveSetListClient struct {
+	grpc.ClientStream
+}
+
+func (x *observeRemoveSetListClient) Recv() (*ResourceObject, error) {
+	m := new(ResourceObject)
+	if err := x.ClientStream.RecvMsg(m); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+func (c *observeRemoveSetClient) Insert(ctx context.Context, in *SetInsertRequest, opts ...grpc.CallOption) (*SetInsertResponse, error) {
+	out := new(SetInsertResponse)
+	err := grpc.Invoke(ctx, "/crdt.ObserveRemoveSet/Insert", in, out, c.cc, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *observeRemoveSetClient) Remove(ctx context.Context, in *SetRemoveRequest, opts ...grpc.CallOption) (*SetRemoveResponse, error) {
+	out := new(SetRemoveResponse)
+	err := grpc.Invoke(ctx, "/crdt.ObserveRemoveSet/Remove", in, out, c.cc, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *observeRemoveSetClient) Length(ctx context.Context, in *SetLengthRequest, opts ...grpc.CallOption) (*SetLengthResponse, error) {
+	out := new(SetLengthResponse)
+	err := grpc.Invoke(ctx, "/crdt.ObserveRemoveSet/Length", in, out, c.cc, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *observeRemoveSetClient) Contains(ctx context.Context, in *SetContainsRequest, opts ...grpc.CallOption) (*SetContainsResponse, error) {
+	out := new(SetContainsResponse)
+	err := grpc.Invoke(ctx, "/crdt.ObserveRemoveSet/Contains", in, out, c.cc, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *observeRemoveSetClient) Union(ctx context.Context, in *SetAlgebraRequest, opts ...grpc.CallOption) (ObserveRemoveSet_UnionClient, error) {
+	stream, err := grpc.NewClientStream(ctx, &_ObserveRemoveSet_serviceDesc.Streams[1], c.cc, "/crdt.ObserveRemoveSet/Union", opts...)
+	if err != nil {
+		return nil, err
+	}
+	x := &observeRemoveSetUnionClient{stream}
+	if err := x.ClientStream.SendMsg(in); err != nil {
+		return nil, err
+	}
+	if err := x.ClientStream.CloseSend(); err != nil {
+		return nil, err
+	}
+	return x, nil
+}
+
+type ObserveRemoveSet_UnionClient interface {
+	Recv() (*SetAlgebraResponse, error)
+	grpc.ClientStream
+}
+
+type observeRemoveSetUnionClient struct {
+	grpc.ClientStream
+}
+
+func (x *observeRemoveSetUnionClient) Recv() (*SetAlgebraResponse, error) {
+	m := new(SetAlgebraResponse)
+	if err := x.ClientStream.RecvMsg(m); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+func (c *observeRemoveSetClient) Intersect(ctx context.Context, in *SetAlgebraRequest, opts ...grpc.CallOption) (ObserveRemoveSet_IntersectClient, error) {
+	stream, err := grpc.NewClientStream(ctx, &_ObserveRemoveSet_serviceDesc.Streams[2], c.cc, "/crdt.ObserveRemoveSet/Intersect", opts...)
+	if err != nil {
+		return nil, err
+	}
+	x := &observeRemoveSetIntersectClient{stream}
+	if err := x.ClientStream.SendMsg(in); err != nil {
+		return nil, err
+	}
+	if err := x.ClientStream.CloseSend(); err != nil {
+		return nil, err
+	}
+	return x, nil
+}
+
+type ObserveRemoveSet_IntersectClient interface {
+	Recv() (*SetAlgebraResponse, error)
+	grpc.ClientStream
+}
+
+type observeRemoveSetIntersectClient struct {
+	grpc.ClientStream
+}
+
+func (x *observeRemoveSetIntersectClient) Recv() (*SetAlgebraResponse, error) {
+	m := new(SetAlgebraResponse)
+	if err := x.ClientStream.RecvMsg(m); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+func (c *observeRemoveSetClient) Difference(ctx context.Context, in *SetAlgebraRequest, opts ...grpc.CallOption) (ObserveRemoveSet_DifferenceClient, error) {
+	stream, err := grpc.NewClientStream(ctx, &_ObserveRemoveSet_serviceDesc.Streams[3], c.cc, "/crdt.ObserveRemoveSet/Difference", opts...)
+	if err != nil {
+		return nil, err
+	}
+	x := &observeRemoveSetDifferenceClient{stream}
+	if err := x.ClientStream.SendMsg(in); err != nil {
+		return nil, err
+	}
+	if err := x.ClientStream.CloseSend(); err != nil {
+		return nil, err
+	}
+	return x, nil
+}
+
+type ObserveRemoveSet_DifferenceClient interface {
+	Recv() (*SetAlgebraResponse, error)
+	grpc.ClientStream
+}
+
+type observeRemoveSetDifferenceClient struct {
+	grpc.ClientStream
+}
+
+func (x *observeRemoveSetDifferenceClient) Recv() (*SetAlgebraResponse, error) {
+	m := new(SetAlgebraResponse)
+	if err := x.ClientStream.RecvMsg(m); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+func (c *observeRemoveSetClient) IsSubset(ctx context.Context, in *SetIsSubsetRequest, opts ...grpc.CallOption) (*SetIsSubsetResponse, error) {
+	out := new(SetIsSubsetResponse)
+	err := grpc.Invoke(ctx, "/crdt.ObserveRemoveSet/IsSubset", in, out, c.cc, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *observeRemoveSetClient) ListWithMetadata(ctx context.Context, in *SetListRequest, opts ...grpc.CallOption) (ObserveRemoveSet_ListWithMetadataClient, error) {
+	stream, err := grpc.NewClientStream(ctx, &_ObserveRemoveSet_serviceDesc.Streams[4], c.cc, "/crdt.ObserveRemoveSet/ListWithMetadata", opts...)
+	if err != nil {
+		return nil, err
+	}
+	x := &observeRemoveSetListWithMetadataClient{stream}
+	if err := x.ClientStream.SendMsg(in); err != nil {
+		return nil, err
+	}
+	if err := x.ClientStream.CloseSend(); err != nil {
+		return nil, err
+	}
+	return x, nil
+}
+
+type ObserveRemoveSet_ListWithMetadataClient interface {
+	Recv() (*SetListWithMetadataResponse, error)
+	grpc.ClientStream
+}
+
+type observeRemoveSetListWithMetadataClient struct {
+	grpc.ClientStream
+}
+
+func (x *observeRemoveSetListWithMetadataClient) Recv() (*SetListWithMetadataResponse, error) {
+	m := new(SetListWithMetadataResponse)
+	if err := x.ClientStream.RecvMsg(m); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+// Server API for ObserveRemoveSet service
+
+type ObserveRemoveSetServer interface {
+	List(*SetListRequest, ObserveRemoveSet_ListServer) error
+	Insert(context.Context, *SetInsertRequest) (*SetInsertResponse, error)
+	Remove(context.Context, *SetRemoveRequest) (*SetRemoveResponse, error)
+	Length(context.Context, *SetLengthRequest) (*SetLengthResponse, error)
+	Contains(context.Context, *SetContainsRequest) (*SetContainsResponse, error)
+	Union(*SetAlgebraRequest, ObserveRemoveSet_UnionServer) error
+	Intersect(*SetAlgebraRequest, ObserveRemoveSet_IntersectServer) error
+	Difference(*SetAlgebraRequest, ObserveRemoveSet_DifferenceServer) error
+	IsSubset(context.Context, *SetIsSubsetRequest) (*SetIsSubsetResponse, error)
+	ListWithMetadata(*SetListRequest, ObserveRemoveSet_ListWithMetadataServer) error
+}
+
+func RegisterObserveRemoveSetServer(s *grpc.Server, srv ObserveRemoveSetServer) {
+	s.RegisterService(&_ObserveRemoveSet_serviceDesc, srv)
+}
+
+func _ObserveRemoveSet_List_Handler(srv interface{}, stream grpc.ServerStream) error {
+	m := new(SetListRequest)
+	if err := stream.RecvMsg(m); err != nil {
+		return err
+	}
+	return srv.(ObserveRemoveSetServer).List(m, &observeRemoveSetListServer{stream})
+}
+
+type ObserveRemoveSet_ListServer interface {
+	Send(*ResourceObject) error
+	grpc.ServerStream
+}
+
+type observeRemoveSetListServer struct {
+	grpc.ServerStream
+}
+
+func (x *observeRemoveSetListServer) Send(m *ResourceObject) error {
+	return x.ServerStream.SendMsg(m)
+}
+
+func _ObserveRemoveSet_Insert_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error) (interface{}, error) {
+	in := new(SetInsertRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	out, err := srv.(ObserveRemoveSetServer).Insert(ctx, in)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func _ObserveRemoveSet_Remove_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error) (interface{}, error) {
+	in := new(SetRemoveRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	out, err := srv.(ObserveRemoveSetServer).Remove(ctx, in)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func _ObserveRemoveSet_Length_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error) (interface{}, error) {
+	in := new(SetLengthRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	out, err := srv.(ObserveRemoveSetServer).Length(ctx, in)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func _ObserveRemoveSet_Contains_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error) (interface{}, error) {
+	in := new(SetContainsRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	out, err := srv.(ObserveRemoveSetServer).Contains(ctx, in)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func _ObserveRemoveSet_Union_Handler(srv interface{}, stream grpc.ServerStream) error {
+	m := new(SetAlgebraRequest)
+	if err := stream.RecvMsg(m); err != nil {
+		return err
+	}
+	return srv.(ObserveRemoveSetServer).Union(m, &observeRemoveSetUnionServer{stream})
+}
+
+type ObserveRemoveSet_UnionServer interface {
+	Send(*SetAlgebraResponse) error
+	grpc.ServerStream
+}
+
+type observeRemoveSetUnionServer struct {
+	grpc.ServerStream
+}
+
+func (x *observeRemoveSetUnionServer) Send(m *SetAlgebraResponse) error {
+	return x.ServerStream.SendMsg(m)
+}
+
+func _ObserveRemoveSet_Intersect_Handler(srv interface{}, stream grpc.ServerStream) error {
+	m := new(SetAlgebraRequest)
+	if err := stream.RecvMsg(m); err != nil {
+		return err
+	}
+	return srv.(ObserveRemoveSetServer).Intersect(m, &observeRemoveSetIntersectServer{stream})
+}
+
+type ObserveRemoveSet_IntersectServer interface {
+	Send(*SetAlgebraResponse) error
+	grpc.ServerStream
+}
+
+type observeRemoveSetIntersectServer struct {
+	grpc.ServerStream
+}
+
+func (x *observeRemoveSetIntersectServer) Send(m *SetAlgebraResponse) error {
+	return x.ServerStream.SendMsg(m)
+}
+
+func _ObserveRemoveSet_Difference_Handler(srv interface{}, stream grpc.ServerStream) error {
+	m := new(SetAlgebraRequest)
+	if err := stream.RecvMsg(m); err != nil {
+		return err
+	}
+	return srv.(ObserveRemoveSetServer).Difference(m, &observeRemoveSetDifferenceServer{stream})
+}
+
+type ObserveRemoveSet_DifferenceServer interface {
+	Send(*SetAlgebraResponse) error
+	grpc.ServerStream
+}
+
+type observeRemoveSetDifferenceServer struct {
+	grpc.ServerStream
+}
+
+func (x *observeRemoveSetDifferenceServer) Send(m *SetAlgebraResponse) error {
+	return x.ServerStream.SendMsg(m)
+}
+
+func _ObserveRemoveSet_IsSubset_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error) (interface{}, error) {
+	in := new(SetIsSubsetRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	out, err := srv.(ObserveRemoveSetServer).IsSubset(ctx, in)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func _ObserveRemoveSet_ListWithMetadata_Handler(srv interface{}, stream grpc.ServerStream) error {
+	m := new(SetListRequest)
+	if err := stream.RecvMsg(m); err != nil {
+		return err
+	}
+	return srv.(ObserveRemoveSetServer).ListWithMetadata(m, &observeRemoveSetListWithMetadataServer{stream})
+}
+
+type ObserveRemoveSet_ListWithMetadataServer interface {
+	Send(*SetListWithMetadataResponse) error
+	grpc.ServerStream
+}
+
+type observeRemoveSetListWithMetadataServer struct {
+	grpc.ServerStream
+}
+
+func (x *observeRemoveSetListWithMetadataServer) Send(m *SetListWithMetadataResponse) error {
+	return x.ServerStream.SendMsg(m)
+}
+
+var _ObserveRemoveSet_serviceDesc = grpc.ServiceDesc{
+	ServiceName: "crdt.ObserveRemoveSet",
+	HandlerType: (*ObserveRemoveSetServer)(nil),
+	Methods: []grpc.MethodDesc{
+		{
+			MethodName: "Insert",
+			Handler:    _ObserveRemoveSet_Insert_Handler,
+		},
+		{
+			MethodName: "Remove",
+			Handler:    _ObserveRemoveSet_Remove_Handler,
+		},
+		{
+			MethodName: "Length",
+			Handler:    _ObserveRemoveSet_Length_Handler,
+		},
+		{
+			MethodName: "Contains",
+			Handler:    _ObserveRemoveSet_Contains_Handler,
+		},
+		{
+			MethodName: "IsSubset",
+			Handler:    _ObserveRemoveSet_IsSubset_Handler,
+		},
+	},
+	Streams: []grpc.StreamDesc{
+		{
+			StreamName:    "List",
+			Handler:       _ObserveRemoveSet_List_Handler,
+			ServerStreams: true,
+		},
+		{
+			StreamName:    "Union",
+			Handler:       _ObserveRemoveSet_Union_Handler,
+			ServerStreams: true,
+		},
+		{
+			StreamName:    "Intersect",
+			Handler:       _ObserveRemoveSet_Intersect_Handler,
+			ServerStreams: true,
+		},
+		{
+			StreamName:    "Difference",
+			Handler:       _ObserveRemoveSet_Difference_Handler,
+			ServerStreams: true,
+		},
+		{
+			StreamName:    "ListWithMetadata",
+			Handler:       _ObserveRemoveSet_ListWithMetadata_Handler,
+			ServerStreams: true,
+		},
+	},
+}
+
+// Client API for LastWriterWinsSet service
+
+type LastWriterWinsSetClient interface {
+	List(ctx context.Context, in *SetListRequest, opts ...grpc.CallOption) (LastWriterWinsSet_ListClient, error)
+	Insert(ctx context.Context, in *SetInsertRequest, opts ...grpc.CallOption) (*SetInsertResponse, error)
+	Remove(ctx context.Context, in *SetRemoveRequest, opts ...grpc.CallOption) (*SetRemoveResponse, error)
+	Length(ctx context.Context, in *SetLengthRequest, opts ...grpc.CallOption) (*SetLengthResponse, error)
+	Contains(ctx context.Context, in *SetContainsRequest, opts ...grpc.CallOption) (*SetContainsResponse, error)
+	Union(ctx context.Context, in *SetAlgebraRequest, opts ...grpc.CallOption) (LastWriterWinsSet_UnionClient, error)
+	Intersect(ctx context.Context, in *SetAlgebraRequest, opts ...grpc.CallOption) (LastWriterWinsSet_IntersectClient, error)
+	Difference(ctx context.Context, in *SetAlgebraRequest, opts ...grpc.CallOption) (LastWriterWinsSet_DifferenceClient, error)
+	IsSubset(ctx context.Context, in *SetIsSubsetRequest, opts ...grpc.CallOption) (*SetIsSubsetResponse, error)
+	ListWithMetadata(ctx context.Context, in *SetListRequest, opts ...grpc.CallOption) (LastWriterWinsSet_ListWithMetadataClient, error)
+}
+
+type lastWriterWinsSetClient struct {
+	cc *grpc.ClientConn
+}
+
+func NewLastWriterWinsSetClient(cc *grpc.ClientConn) LastWriterWinsSetClient {
+	return &lastWriterWinsSetClient{cc}
+}
+
+func (c *lastWriterWinsSetClient) List(ctx context.Context, in *SetListRequest, opts ...grpc.CallOption) (LastWriterWinsSet_ListClient, error) {
+	stream, err := grpc.NewClientStream(ctx, &_LastWriterWinsSet_serviceDesc.Streams[0], c.cc, "/crdt.LastWriterWinsSet/List", opts...)
+	if err != nil {
+		return nil, err
+	}
+	x := &lastWriterWinsSetListClient{stream}
+	if err := x.ClientStream.SendMsg(in); err != nil {
+		return nil, err
+	}
+	if err := x.ClientStream.CloseSend(); err != nil {
+		return nil, err
+	}
+	return x, nil
+}
+
+type LastWriterWinsSet_ListClient interface {
+	Recv() (*ResourceObject, error)
+	grpc.ClientStream
+}
+
+type lastWriterWinsSetListClient struct {
+	grpc.ClientStream
+}
+
+func (x *lastWriterWinsSetListClient) Recv() (*ResourceObject, error) {
+	m := new(ResourceObject)
+	if err := x.ClientStream.RecvMsg(m); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+func (c *lastWriterWinsSetClient) Insert(ctx context.Context, in *SetInsertRequest, opts ...grpc.CallOption) (*SetInsertResponse, error) {
+	out := new(SetInsertResponse)
+	err := grpc.Invoke(ctx, "/crdt.LastWriterWinsSet/Insert", in, out, c.cc, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *lastWriterWinsSetClient) Remove(ctx context.Context, in *SetRemoveRequest, opts ...grpc.CallOption) (*SetRemoveResponse, error) {
+	out := new(SetRemoveResponse)
+	err := grpc.Invoke(ctx, "/crdt.LastWriterWinsSet/Remove", in, out, c.cc, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *lastWriterWinsSetClient) Length(ctx context.Context, in *SetLengthRequest, opts ...grpc.CallOption) (*SetLengthResponse, error) {
+	out := new(SetLengthResponse)
+	err := grpc.Invoke(ctx, "/crdt.LastWriterWinsSet/Length", in, out, c.cc, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *lastWriterWinsSetClient) Contains(ctx context.Context, in *SetContainsRequest, opts ...grpc.CallOption) (*SetContainsResponse, error) {
+	out := new(SetContainsResponse)
+	err := grpc.Invoke(ctx, "/crdt.LastWriterWinsSet/Contains", in, out, c.cc, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *lastWriterWinsSetClient) Union(ctx context.Context, in *SetAlgebraRequest, opts ...grpc.CallOption) (LastWriterWinsSet_UnionClient, error) {
+	stream, err := grpc.NewClientStream(ctx, &_LastWriterWinsSet_serviceDesc.Streams[1], c.cc, "/crdt.LastWriterWinsSet/Union", opts...)
+	if err != nil {
+		return nil, err
+	}
+	x := &lastWriterWinsSetUnionClient{stream}
+	if err := x.ClientStream.SendMsg(in); err != nil {
+		return nil, err
+	}
+	if err := x.ClientStream.CloseSend(); err != nil {
+		return nil, err
+	}
+	return x, nil
+}
+
+type LastWriterWinsSet_UnionClient interface {
+	Recv() (*SetAlgebraResponse, error)
+	grpc.ClientStream
+}
+
+type lastWriterWinsSetUnionClient struct {
+	grpc.ClientStream
+}
+
+func (x *lastWriterWinsSetUnionClient) Recv() (*SetAlgebraResponse, error) {
+	m := new(SetAlgebraResponse)
+	if err := x.ClientStream.RecvMsg(m); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+func (c *lastWriterWinsSetClient) Intersect(ctx context.Context, in *SetAlgebraRequest, opts ...grpc.CallOption) (LastWriterWinsSet_IntersectClient, error) {
+	stream, err := grpc.NewClientStream(ctx, &_LastWriterWinsSet_serviceDesc.Streams[2], c.cc, "/crdt.LastWriterWinsSet/Intersect", opts...)
+	if err != nil {
+		return nil, err
+	}
+	x := &lastWriterWinsSetIntersectClient{stream}
+	if err := x.ClientStream.SendMsg(in); err != nil {
+		return nil, err
+	}
+	if err := x.ClientStream.CloseSend(); err != nil {
+		return nil, err
+	}
+	return x, nil
+}
+
+type LastWriterWinsSet_IntersectClient interface {
+	Recv() (*SetAlgebraResponse, error)
+	grpc.ClientStream
+}
+
+type lastWriterWinsSetIntersectClient struct {
+	grpc.ClientStream
+}
+
+func (x *lastWriterWinsSetIntersectClient) Recv() (*SetAlgebraResponse, error) {
+	m := new(SetAlgebraResponse)
+	if err := x.ClientStream.RecvMsg(m); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+func (c *lastWriterWinsSetClient) Difference(ctx context.Context, in *SetAlgebraRequest, opts ...grpc.CallOption) (LastWriterWinsSet_DifferenceClient, error) {
+	stream, err := grpc.NewClientStream(ctx, &_LastWriterWinsSet_serviceDesc.Streams[3], c.cc, "/crdt.LastWriterWinsSet/Difference", opts...)
+	if err != nil {
+		return nil, err
+	}
+	x := &lastWriterWinsSetDifferenceClient{stream}
+	if err := x.ClientStream.SendMsg(in); err != nil {
+		return nil, err
+	}
+	if err := x.ClientStream.CloseSend(); err != nil {
+		return nil, err
+	}
+	return x, nil
+}
+
+type LastWriterWinsSet_DifferenceClient interface {
+	Recv() (*SetAlgebraResponse, error)
+	grpc.ClientStream
+}
+
+type lastWriterWinsSetDifferenceClient struct {
+	grpc.ClientStream
+}
+
+func (x *lastWriterWinsSetDifferenceClient) Recv() (*SetAlgebraResponse, error) {
+	m := new(SetAlgebraResponse)
+	if err := x.ClientStream.RecvMsg(m); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+func (c *lastWriterWinsSetClient) IsSubset(ctx context.Context, in *SetIsSubsetRequest, opts ...grpc.CallOption) (*SetIsSubsetResponse, error) {
+	out := new(SetIsSubsetResponse)
+	err := grpc.Invoke(ctx, "/crdt.LastWriterWinsSet/IsSubset", in, out, c.cc, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *lastWriterWinsSetClient) ListWithMetadata(ctx context.Context, in *SetListRequest, opts ...grpc.CallOption) (LastWriterWinsSet_ListWithMetadataClient, error) {
+	stream, err := grpc.NewClientStream(ctx, &_LastWriterWinsSet_serviceDesc.Streams[4], c.cc, "/crdt.LastWriterWinsSet/ListWithMetadata", opts...)
+	if err != nil {
+		return nil, err
+	}
+	x := &lastWriterWinsSetListWithMetadataClient{stream}
+	if err := x.ClientStream.SendMsg(in); err != nil {
+		return nil, err
+	}
+	if err := x.ClientStream.CloseSend(); err != nil {
+		return nil, err
+	}
+	return x, nil
+}
+
+type LastWriterWinsSet_ListWithMetadataClient interface {
+	Recv() (*SetListWithMetadataResponse, error)
+	grpc.ClientStream
+}
+
+type lastWriterWinsSetListWithMetadataClient struct {
+	grpc.ClientStream
+}
+
+func (x *lastWriterWinsSetListWithMetadataClient) Recv() (*SetListWithMetadataResponse, error) {
+	m := new(SetListWithMetadataResponse)
+	if err := x.ClientStream.RecvMsg(m); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+// Server API for LastWriterWinsSet service
+
+type LastWriterWinsSetServer interface {
+	List(*SetListRequest, LastWriterWinsSet_ListServer) error
+	Insert(context.Context, *SetInsertRequest) (*SetInsertResponse, error)
+	Remove(context.Context, *SetRemoveRequest) (*SetRemoveResponse, error)
+	Length(context.Context, *SetLengthRequest) (*SetLengthResponse, error)
+	Contains(context.Context, *SetContainsRequest) (*SetContainsResponse, error)
+	Union(*SetAlgebraRequest, LastWriterWinsSet_UnionServer) error
+	Intersect(*SetAlgebraRequest, LastWriterWinsSet_IntersectServer) error
+	Difference(*SetAlgebraRequest, LastWriterWinsSet_DifferenceServer) error
+	IsSubset(context.Context, *SetIsSubsetRequest) (*SetIsSubsetResponse, error)
+	ListWithMetadata(*SetListRequest, LastWriterWinsSet_ListWithMetadataServer) error
+}
+
+func RegisterLastWriterWinsSetServer(s *grpc.Server, srv LastWriterWinsSetServer) {
+	s.RegisterService(&_LastWriterWinsSet_serviceDesc, srv)
+}
+
+func _LastWriterWinsSet_List_Handler(srv interface{}, stream grpc.ServerStream) error {
+	m := new(SetListRequest)
+	if err := stream.RecvMsg(m); err != nil {
+		return err
+	}
+	return srv.(LastWriterWinsSetServer).List(m, &lastWriterWinsSetListServer{stream})
+}
+
+type LastWriterWinsSet_ListServer interface {
+	Send(*ResourceObject) error
+	grpc.ServerStream
+}
+
+type lastWriterWinsSetListServer struct {
+	grpc.ServerStream
+}
+
+func (x *lastWriterWinsSetListServer) Send(m *ResourceObject) error {
+	return x.ServerStream.SendMsg(m)
+}
+
+func _LastWriterWinsSet_Insert_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error) (interface{}, error) {
+	in := new(SetInsertRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	out, err := srv.(LastWriterWinsSetServer).Insert(ctx, in)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func _LastWriterWinsSet_Remove_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error) (interface{}, error) {
+	in := new(SetRemoveRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	out, err := srv.(LastWriterWinsSetServer).Remove(ctx, in)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func _LastWriterWinsSet_Length_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error) (interface{}, error) {
+	in := new(SetLengthRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	out, err := srv.(LastWriterWinsSetServer).Length(ctx, in)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func _LastWriterWinsSet_Contains_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error) (interface{}, error) {
+	in := new(SetContainsRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	out, err := srv.(LastWriterWinsSetServer).Contains(ctx, in)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func _LastWriterWinsSet_Union_Handler(srv interface{}, stream grpc.ServerStream) error {
+	m := new(SetAlgebraRequest)
+	if err := stream.RecvMsg(m); err != nil {
+		return err
+	}
+	return srv.(LastWriterWinsSetServer).Union(m, &lastWriterWinsSetUnionServer{stream})
+}
+
+type LastWriterWinsSet_UnionServer interface {
+	Send(*SetAlgebraResponse) error
+	grpc.ServerStream
+}
+
+type lastWriterWinsSetUnionServer struct {
+	grpc.ServerStream
+}
+
+func (x *lastWriterWinsSetUnionServer) Send(m *SetAlgebraResponse) error {
+	return x.ServerStream.SendMsg(m)
+}
+
+func _LastWriterWinsSet_Intersect_Handler(srv interface{}, stream grpc.ServerStream) error {
+	m := new(SetAlgebraRequest)
+	if err := stream.RecvMsg(m); err != nil {
+		return err
+	}
+	return srv.(LastWriterWinsSetServer).Intersect(m, &lastWriterWinsSetIntersectServer{stream})
+}
+
+type LastWriterWinsSet_IntersectServer interface {
+	Send(*SetAlgebraResponse) error
+	grpc.ServerStream
+}
+
+type lastWriterWinsSetIntersectServer struct {
+	grpc.ServerStream
+}
+
+func (x *lastWriterWinsSetIntersectServer) Send(m *SetAlgebraResponse) error {
+	return x.ServerStream.SendMsg(m)
+}
+
+func _LastWriterWinsSet_Difference_Handler(srv interface{}, stream grpc.ServerStream) error {
+	m := new(SetAlgebraRequest)
+	if err := stream.RecvMsg(m); err != nil {
+		return err
+	}
+	return srv.(LastWriterWinsSetServer).Difference(m, &lastWriterWinsSetDifferenceServer{stream})
+}
+
+type LastWriterWinsSet_DifferenceServer interface {
+	Send(*SetAlgebraResponse) error
+	grpc.ServerStream
+}
+
+type lastWriterWinsSetDifferenceServer struct {
+	grpc.ServerStream
+}
+
+func (x *lastWriterWinsSetDifferenceServer) Send(m *SetAlgebraResponse) error {
+	return x.ServerStream.SendMsg(m)
+}
+
+func _LastWriterWinsSet_IsSubset_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error) (interface{}, error) {
+	in := new(SetIsSubsetRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	out, err := srv.(LastWriterWinsSetServer).IsSubset(ctx, in)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func _LastWriterWinsSet_ListWithMetadata_Handler(srv interface{}, stream grpc.ServerStream) error {
+	m := new(SetListRequest)
+	if err := stream.RecvMsg(m); err != nil {
+		return err
+	}
+	return srv.(LastWriterWinsSetServer).ListWithMetadata(m, &lastWriterWinsSetListWithMetadataServer{stream})
+}
+
+type LastWriterWinsSet_ListWithMetadataServer interface {
+	Send(*SetListWithMetadataResponse) error
+	grpc.ServerStream
+}
+
+type lastWriterWinsSetListWithMetadataServer struct {
+	grpc.ServerStream
+}
+
+func (x *lastWriterWinsSetListWithMetadataServer) Send(m *SetListWithMetadataResponse) error {
+	return x.ServerStream.SendMsg(m)
+}
+
+var _LastWriterWinsSet_serviceDesc = grpc.ServiceDesc{
+	ServiceName: "crdt.LastWriterWinsSet",
+	HandlerType: (*LastWriterWinsSetServer)(nil),
+	Methods: []grpc.MethodDesc{
+		{
+			MethodName: "Insert",
+			Handler:    _LastWriterWinsSet_Insert_Handler,
+		},
+		{
+			MethodName: "Remove",
+			Handler:    _LastWriterWinsSet_Remove_Handler,
+		},
+		{
+			MethodName: "Length",
+			Handler:    _LastWriterWinsSet_Length_Handler,
+		},
+		{
+			MethodName: "Contains",
+			Handler:    _LastWriterWinsSet_Contains_Handler,
+		},
+		{
+			MethodName: "IsSubset",
+			Handler:    _LastWriterWinsSet_IsSubset_Handler,
+		},
+	},
+	Streams: []grpc.StreamDesc{
+		{
+			StreamName:    "List",
+			Handler:       _LastWriterWinsSet_List_Handler,
+			ServerStreams: true,
+		},
+		{
+			StreamName:    "Union",
+			Handler:       _LastWriterWinsSet_Union_Handler,
+			ServerStreams: true,
+		},
+		{
+			StreamName:    "Intersect",
+			Handler:       _LastWriterWinsSet_Intersect_Handler,
+			ServerStreams: true,
+		},
+		{
+			StreamName:    "Difference",
+			Handler:       _LastWriterWinsSet_Difference_Handler,
+			ServerStreams: true,
+		},
+		{
+			StreamName:    "ListWithMetadata",
+			Handler:       _LastWriterWinsSet_ListWithMetadata_Handler,
+			ServerStreams: true,
+		},
+	},
+}
+
+// Client API for Counter service
+
+type CounterClient interface {
+	Increment(ctx context.Context, in *CounterIncrementRequest, opts ...grpc.CallOption) (*CounterIncrementResponse, error)
+	Decrement(ctx context.Context, in *CounterDecrementRequest, opts ...grpc.CallOption) (*CounterDecrementResponse, error)
+	Value(ctx context.Context, in *CounterValueRequest, opts ...grpc.CallOption) (*CounterValueResponse, error)
+	// Bounded counters only.
+	Transfer(ctx context.Context, in *CounterTransferRequest, opts ...grpc.CallOption) (*CounterTransferResponse, error)
+	Rights(ctx context.Context, in *CounterRightsRequest, opts ...grpc.CallOption) (*CounterRightsResponse, error)
+}
+
+type counterClient struct {
+	cc *grpc.ClientConn
+}
+
+func NewCounterClient(cc *grpc.ClientConn) CounterClient {
+	return &counterClient{cc}
+}
+
+func (c *counterClient) Increment(ctx context.Context, in *CounterIncrementRequest, opts ...grpc.CallOption) (*CounterIncrementResponse, error) {
+	out := new(CounterIncrementResponse)
+	err := grpc.Invoke(ctx, "/crdt.Counter/Increment", in, out, c.cc, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *counterClient) Decrement(ctx context.Context, in *CounterDecrementRequest, opts ...grpc.CallOption) (*CounterDecrementResponse, error) {
+	out := new(CounterDecrementResponse)
+	err := grpc.Invoke(ctx, "/crdt.Counter/Decrement", in, out, c.cc, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *counterClient) Value(ctx context.Context, in *CounterValueRequest, opts ...grpc.CallOption) (*CounterValueResponse, error) {
+	out := new(CounterValueResponse)
+	err := grpc.Invoke(ctx, "/crdt.Counter/Value", in, out, c.cc, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *counterClient) Transfer(ctx context.Context, in *CounterTransferRequest, opts ...grpc.CallOption) (*CounterTransferResponse, error) {
+	out := new(CounterTransferResponse)
+	err := grpc.Invoke(ctx, "/crdt.Counter/Transfer", in, out, c.cc, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *counterClient) Rights(ctx context.Context, in *CounterRightsRequest, opts ...grpc.CallOption) (*CounterRightsResponse, error) {
+	out := new(CounterRightsResponse)
+	err := grpc.Invoke(ctx, "/crdt.Counter/Rights", in, out, c.cc, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// Server API for Counter service
+
+type CounterServer interface {
+	Increment(context.Context, *CounterIncrementRequest) (*CounterIncrementResponse, error)
+	Decrement(context.Context, *CounterDecrementRequest) (*CounterDecrementResponse, error)
+	Value(context.Context, *CounterValueRequest) (*CounterValueResponse, error)
+	// Bounded counters only.
+	Transfer(context.Context, *CounterTransferRequest) (*CounterTransferResponse, error)
+	Rights(context.Context, *CounterRightsRequest) (*CounterRightsResponse, error)
+}
+
+func RegisterCounterServer(s *grpc.Server, srv CounterServer) {
+	s.RegisterService(&_Counter_serviceDesc, srv)
+}
+
+func _Counter_Increment_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error) (interface{}, error) {
+	in := new(CounterIncrementRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	out, err := srv.(CounterServer).Increment(ctx, in)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func _Counter_Decrement_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error) (interface{}, error) {
+	in := new(CounterDecrementRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	out, err := srv.(CounterServer).Decrement(ctx, in)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func _Counter_Value_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error) (interface{}, error) {
+	in := new(CounterValueRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	out, err := srv.(CounterServer).Value(ctx, in)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func _Counter_Transfer_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error) (interface{}, error) {
+	in := new(CounterTransferRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	out, err := srv.(CounterServer).Transfer(ctx, in)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func _Counter_Rights_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error) (interface{}, error) {
+	in := new(CounterRightsRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	out, err := srv.(CounterServer).Rights(ctx, in)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+var _Counter_serviceDesc = grpc.ServiceDesc{
+	ServiceName: "crdt.Counter",
+	HandlerType: (*CounterServer)(nil),
+	Methods: []grpc.MethodDesc{
+		{
+			MethodName: "Increment",
+			Handler:    _Counter_Increment_Handler,
+		},
+		{
+			MethodName: "Decrement",
+			Handler:    _Counter_Decrement_Handler,
+		},
+		{
+			MethodName: "Value",
+			Handler:    _Counter_Value_Handler,
+		},
+		{
+			MethodName: "Transfer",
+			Handler:    _Counter_Transfer_Handler,
+		},
+		{
+			MethodName: "Rights",
+			Handler:    _Counter_Rights_Handler,
+		},
+	},
+	Streams: []grpc.StreamDesc{},
+}
+
+// Client API for LastWriterWinsRegister service
+
+type LastWriterWinsRegisterClient interface {
+	Set(ctx context.Context, in *RegisterSetRequest, opts ...grpc.CallOption) (*RegisterSetResponse, error)
+	Get(ctx context.Context, in *RegisterGetRequest, opts ...grpc.CallOption) (*RegisterGetResponse, error)
+}
+
+type lastWriterWinsRegisterClient struct {
+	cc *grpc.ClientConn
+}
+
+func NewLastWriterWinsRegisterClient(cc *grpc.ClientConn) LastWriterWinsRegisterClient {
+	return &lastWriterWinsRegisterClient{cc}
+}
+
+func (c *lastWriterWinsRegisterClient) Set(ctx context.Context, in *RegisterSetRequest, opts ...grpc.CallOption) (*RegisterSetResponse, error) {
+	out := new(RegisterSetResponse)
+	err := grpc.Invoke(ctx, "/crdt.LastWriterWinsRegister/Set", in, out, c.cc, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *lastWriterWinsRegisterClient) Get(ctx context.Context, in *RegisterGetRequest, opts ...grpc.CallOption) (*RegisterGetResponse, error) {
+	out := new(RegisterGetResponse)
+	err := grpc.Invoke(ctx, "/crdt.LastWriterWinsRegister/Get", in, out, c.cc, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// Server API for LastWriterWinsRegister service
+
+type LastWriterWinsRegisterServer interface {
+	Set(context.Context, *RegisterSetRequest) (*RegisterSetResponse, error)
+	Get(context.Context, *RegisterGetRequest) (*RegisterGetResponse, error)
+}
+
+func RegisterLastWriterWinsRegisterServer(s *grpc.Server, srv LastWriterWinsRegisterServer) {
+	s.RegisterService(&_LastWriterWinsRegister_serviceDesc, srv)
+}
+
+func _LastWriterWinsRegister_Set_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error) (interface{}, error) {
+	in := new(RegisterSetRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	out, err := srv.(LastWriterWinsRegisterServer).Set(ctx, in)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func _LastWriterWinsRegister_Get_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error) (interface{}, error) {
+	in := new(RegisterGetRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	out, err := srv.(LastWriterWinsRegisterServer).Get(ctx, in)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+var _LastWriterWinsRegister_serviceDesc = grpc.ServiceDesc{
+	ServiceName: "crdt.LastWriterWinsRegister",
+	HandlerType: (*LastWriterWinsRegisterServer)(nil),
+	Methods: []grpc.MethodDesc{
+		{
+			MethodName: "Set",
+			Handler:    _LastWriterWinsRegister_Set_Handler,
+		},
+		{
+			MethodName: "Get",
+			Handler:    _LastWriterWinsRegister_Get_Handler,
+		},
+	},
+	Streams: []grpc.StreamDesc{},
+}
+
+// Client API for MultiValueRegister service
+
+type MultiValueRegisterClient interface {
+	Set(ctx context.Context, in *RegisterSetRequest, opts ...grpc.CallOption) (*RegisterSetResponse, error)
+	Get(ctx context.Context, in *RegisterGetRequest, opts ...grpc.CallOption) (*MultiValueRegisterGetResponse, error)
+}
+
+type multiValueRegisterClient struct {
+	cc *grpc.ClientConn
+}
+
+func NewMultiValueRegisterClient(cc *grpc.ClientConn) MultiValueRegisterClient {
+	return &multiValueRegisterClient{cc}
+}
+
+func (c *multiValueRegisterClient) Set(ctx context.Context, in *RegisterSetRequest, opts ...grpc.CallOption) (*RegisterSetResponse, error) {
+	out := new(RegisterSetResponse)
+	err := grpc.Invoke(ctx, "/crdt.MultiValueRegister/Set", in, out, c.cc, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *multiValueRegisterClient) Get(ctx context.Context, in *RegisterGetRequest, opts ...grpc.CallOption) (*MultiValueRegisterGetResponse, error) {
+	out := new(MultiValueRegisterGetResponse)
+	err := grpc.Invoke(ctx, "/crdt.MultiValueRegister/Get", in, out, c.cc, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// Server API for MultiValueRegister service
+
+type MultiValueRegisterServer interface {
+	Set(context.Context, *RegisterSetRequest) (*RegisterSetResponse, error)
+	Get(context.Context, *RegisterGetRequest) (*MultiValueRegisterGetResponse, error)
 }
 
-func _CRDT_Create_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error) (interface{}, error) {
-	in := new(CreateRequest)
+func RegisterMultiValueRegisterServer(s *grpc.Server, srv MultiValueRegisterServer) {
+	s.RegisterService(&_MultiValueRegister_serviceDesc, srv)
+}
+
+func _MultiValueRegister_Set_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error) (interface{}, error) {
+	in := new(RegisterSetRequest)
 	if err := dec(in); err != nil {
 		return nil, err
 	}
-	out, err := srv.(CRDTServer).Create(ctx, in)
+	out, err := srv.(MultiValueRegisterServer).Set(ctx, in)
 	if err != nil {
 		return nil, err
 	}
 	return out, nil
 }
 
-func _CRDT_Attach_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error) (interface{}, error) {
-	in := new(AttachRequest)
+func _MultiValueRegister_Get_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error) (interface{}, error) {
+	in := new(RegisterGetRequest)
 	if err := dec(in); err != nil {
 		return nil, err
 	}
-	out, err := srv.(CRDTServer).Attach(ctx, in)
+	out, err := srv.(MultiValueRegisterServer).Get(ctx, in)
 	if err != nil {
 		return nil, err
 	}
 	return out, nil
 }
 
-func _CRDT_Detach_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error) (interface{}, error) {
-	in := new(DetachRequest)
-	if err := dec(in); err != nil {
+var _MultiValueRegister_serviceDesc = grpc.ServiceDesc{
+	ServiceName: "crdt.MultiValueRegister",
+	HandlerType: (*MultiValueRegisterServer)(nil),
+	Methods: []grpc.MethodDesc{
+		{
+			MethodName: "Set",
+			Handler:    _MultiValueRegister_Set_Handler,
+		},
+		{
+			MethodName: "Get",
+			Handler:    _MultiValueRegister_Get_Handler,
+		},
+	},
+	Streams: []grpc.StreamDesc{},
+}
+
+// Client API for ObserveRemoveMap service
+
+type ObserveRemoveMapClient interface {
+	Put(ctx context.Context, in *MapPutRequest, opts ...grpc.CallOption) (*MapPutResponse, error)
+	Get(ctx context.Context, in *MapGetRequest, opts ...grpc.CallOption) (*MapGetResponse, error)
+	Remove(ctx context.Context, in *MapRemoveRequest, opts ...grpc.CallOption) (*MapRemoveResponse, error)
+	Keys(ctx context.Context, in *MapKeysRequest, opts ...grpc.CallOption) (*MapKeysResponse, error)
+}
+
+type observeRemoveMapClient struct {
+	cc *grpc.ClientConn
+}
+
+func NewObserveRemoveMapClient(cc *grpc.ClientConn) ObserveRemoveMapClient {
+	return &observeRemoveMapClient{cc}
+}
+
+func (c *observeRemoveMapClient) Put(ctx context.Context, in *MapPutRequest, opts ...grpc.CallOption) (*MapPutResponse, error) {
+	out := new(MapPutResponse)
+	err := grpc.Invoke(ctx, "/crdt.ObserveRemoveMap/Put", in, out, c.cc, opts...)
+	if err != nil {
 		return nil, err
 	}
-	out, err := srv.(CRDTServer).Detach(ctx, in)
+	return out, nil
+}
+
+func (c *observeRemoveMapClient) Get(ctx context.Context, in *MapGetRequest, opts ...grpc.CallOption) (*MapGetResponse, error) {
+	out := new(MapGetResponse)
+	err := grpc.Invoke(ctx, "/crdt.ObserveRemoveMap/Get", in, out, c.cc, opts...)
 	if err != nil {
 		return nil, err
 	}
 	return out, nil
 }
 
-func _CRDT_Subscribe_Handler(srv interface{}, stream grpc.ServerStream) error {
-	m := new(SubscribeRequest)
-	if err := stream.RecvMsg(m); err != nil {
-		return err
+func (c *observeRemoveMapClient) Remove(ctx context.Context, in *MapRemoveRequest, opts ...grpc.CallOption) (*MapRemoveResponse, error) {
+	out := new(MapRemoveResponse)
+	err := grpc.Invoke(ctx, "/crdt.ObserveRemoveMap/Remove", in, out, c.cc, opts...)
+	if err != nil {
+		return nil, err
 	}
-	return srv.(CRDTServer).Subscribe(m, &cRDTSubscribeServer{stream})
+	return out, nil
 }
 
-type CRDT_SubscribeServer interface {
-	Send(*Notification) error
-	grpc.ServerStream
+func (c *observeRemoveMapClient) Keys(ctx context.Context, in *MapKeysRequest, opts ...grpc.CallOption) (*MapKeysResponse, error) {
+	out := new(MapKeysResponse)
+	err := grpc.Invoke(ctx, "/crdt.ObserveRemoveMap/Keys", in, out, c.cc, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
 }
 
-type cRDTSubscribeServer struct {
-	grpc.ServerStream
+// Server API for ObserveRemoveMap service
+
+type ObserveRemoveMapServer interface {
+	Put(context.Context, *MapPutRequest) (*MapPutResponse, error)
+	Get(context.Context, *MapGetRequest) (*MapGetResponse, error)
+	Remove(context.Context, *MapRemoveRequest) (*MapRemoveResponse, error)
+	Keys(context.Context, *MapKeysRequest) (*MapKeysResponse, error)
 }
 
-func (x *cRDTSubscribeServer) Send(m *Notification) error {
-	return x.ServerStream.SendMsg(m)
+func RegisterObserveRemoveMapServer(s *grpc.Server, srv ObserveRemoveMapServer) {
+	s.RegisterService(&_ObserveRemoveMap_serviceDesc, srv)
 }
 
-func _CRDT_Commit_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error) (interface{}, error) {
-	in := new(CommitRequest)
+func _ObserveRemoveMap_Put_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error) (interface{}, error) {
+	in := new(MapPutRequest)
 	if err := dec(in); err != nil {
 		return nil, err
 	}
-	out, err := srv.(CRDTServer).Commit(ctx, in)
+	out, err := srv.(ObserveRemoveMapServer).Put(ctx, in)
 	if err != nil {
 		return nil, err
 	}
 	return out, nil
 }
 
-func _CRDT_Equals_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error) (interface{}, error) {
-	in := new(EqualsRequest)
+func _ObserveRemoveMap_Get_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error) (interface{}, error) {
+	in := new(MapGetRequest)
 	if err := dec(in); err != nil {
 		return nil, err
 	}
-	out, err := srv.(CRDTServer).Equals(ctx, in)
+	out, err := srv.(ObserveRemoveMapServer).Get(ctx, in)
 	if err != nil {
 		return nil, err
 	}
 	return out, nil
 }
 
-func _CRDT_Merge_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error) (interface{}, error) {
-	in := new(MergeRequest)
+func _ObserveRemoveMap_Remove_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error) (interface{}, error) {
+	in := new(MapRemoveRequest)
 	if err := dec(in); err != nil {
 		return nil, err
 	}
-	out, err := srv.(CRDTServer).Merge(ctx, in)
+	out, err := srv.(ObserveRemoveMapServer).Remove(ctx, in)
 	if err != nil {
 		return nil, err
 	}
 	return out, nil
 }
 
-func _CRDT_Clone_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error) (interface{}, error) {
-	in := new(CloneRequest)
+func _ObserveRemoveMap_Keys_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error) (interface{}, error) {
+	in := new(MapKeysRequest)
 	if err := dec(in); err != nil {
 		return nil, err
 	}
-	out, err := srv.(CRDTServer).Clone(ctx, in)
+	out, err := srv.(ObserveRemoveMapServer).Keys(ctx, in)
 	if err != nil {
 		return nil, err
 	}
 	return out, nil
 }
 
-func _CRDT_SupportedTypes_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error) (interface{}, error) {
-	in := new(EmptyMessage)
-	if err := dec(in); err != nil {
+var _ObserveRemoveMap_serviceDesc = grpc.ServiceDesc{
+	ServiceName: "crdt.ObserveRemoveMap",
+	HandlerType: (*ObserveRemoveMapServer)(nil),
+	Methods: []grpc.MethodDesc{
+		{
+			MethodName: "Put",
+			Handler:    _ObserveRemoveMap_Put_Handler,
+		},
+		{
+			MethodName: "Get",
+			Handler:    _ObserveRemoveMap_Get_Handler,
+		},
+		{
+			MethodName: "Remove",
+			Handler:    _ObserveRemoveMap_Remove_Handler,
+		},
+		{
+			MethodName: "Keys",
+			Handler:    _ObserveRemoveMap_Keys_Handler,
+		},
+	},
+	Streams: []grpc.StreamDesc{},
+}
+
+// Client API for Sequence service
+
+type SequenceClient interface {
+	InsertAt(ctx context.Context, in *SequenceInsertRequest, opts ...grpc.CallOption) (*SequenceInsertResponse, error)
+	DeleteAt(ctx context.Context, in *SequenceDeleteRequest, opts ...grpc.CallOption) (*SequenceDeleteResponse, error)
+	Slice(ctx context.Context, in *SequenceSliceRequest, opts ...grpc.CallOption) (*SequenceSliceResponse, error)
+	Text(ctx context.Context, in *SequenceTextRequest, opts ...grpc.CallOption) (*SequenceTextResponse, error)
+}
+
+type sequenceClient struct {
+	cc *grpc.ClientConn
+}
+
+func NewSequenceClient(cc *grpc.ClientConn) SequenceClient {
+	return &sequenceClient{cc}
+}
+
+func (c *sequenceClient) InsertAt(ctx context.Context, in *SequenceInsertRequest, opts ...grpc.CallOption) (*SequenceInsertResponse, error) {
+	out := new(SequenceInsertResponse)
+	err := grpc.Invoke(ctx, "/crdt.Sequence/InsertAt", in, out, c.cc, opts...)
+	if err != nil {
 		return nil, err
 	}
-	out, err := srv.(CRDTServer).SupportedTypes(ctx, in)
+	return out, nil
+}
+
+func (c *sequenceClient) DeleteAt(ctx context.Context, in *SequenceDeleteRequest, opts ...grpc.CallOption) (*SequenceDeleteResponse, error) {
+	out := new(SequenceDeleteResponse)
+	err := grpc.Invoke(ctx, "/crdt.Sequence/DeleteAt", in, out, c.cc, opts...)
 	if err != nil {
 		return nil, err
 	}
 	return out, nil
 }
 
-func _CRDT_IsSupportedType_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error) (interface{}, error) {
-	in := new(TypeMessage)
-	if err := dec(in); err != nil {
+func (c *sequenceClient) Slice(ctx context.Context, in *SequenceSliceRequest, opts ...grpc.CallOption) (*SequenceSliceResponse, error) {
+	out := new(SequenceSliceResponse)
+	err := grpc.Invoke(ctx, "/crdt.Sequence/Slice", in, out, c.cc, opts...)
+	if err != nil {
 		return nil, err
 	}
-	out, err := srv.(CRDTServer).IsSupportedType(ctx, in)
+	return out, nil
+}
+
+func (c *sequenceClient) Text(ctx context.Context, in *SequenceTextRequest, opts ...grpc.CallOption) (*SequenceTextResponse, error) {
+	out := new(SequenceTextResponse)
+	err := grpc.Invoke(ctx, "/crdt.Sequence/Text", in, out, c.cc, opts...)
 	if err != nil {
 		return nil, err
 	}
 	return out, nil
 }
 
-func _CRDT_SupportedStorageTypes_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error) (interface{}, error) {
-	in := new(EmptyMessage)
+// Server API for Sequence service
+
+type SequenceServer interface {
+	InsertAt(context.Context, *SequenceInsertRequest) (*SequenceInsertResponse, error)
+	DeleteAt(context.Context, *SequenceDeleteRequest) (*SequenceDeleteResponse, error)
+	Slice(context.Context, *SequenceSliceRequest) (*SequenceSliceResponse, error)
+	Text(context.Context, *SequenceTextRequest) (*SequenceTextResponse, error)
+}
+
+func RegisterSequenceServer(s *grpc.Server, srv SequenceServer) {
+	s.RegisterService(&_Sequence_serviceDesc, srv)
+}
+
+func _Sequence_InsertAt_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error) (interface{}, error) {
+	in := new(SequenceInsertRequest)
 	if err := dec(in); err != nil {
 		return nil, err
 	}
-	out, err := srv.(CRDTServer).SupportedStorageTypes(ctx, in)
+	out, err := srv.(SequenceServer).InsertAt(ctx, in)
 	if err != nil {
 		return nil, err
 	}
 	return out, nil
 }
 
-func _CRDT_IsSupportedStorageType_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error) (interface{}, error) {
-	in := new(TypeMessage)
+func _Sequence_DeleteAt_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error) (interface{}, error) {
+	in := new(SequenceDeleteRequest)
 	if err := dec(in); err != nil {
 		return nil, err
 	}
-	out, err := srv.(CRDTServer).IsSupportedStorageType(ctx, in)
+	out, err := srv.(SequenceServer).DeleteAt(ctx, in)
 	if err != nil {
 		return nil, err
 	}
 	return out, nil
 }
 
-func _CRDT_SupportedCryptoMethods_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error) (interface{}, error) {
-	in := new(EmptyMessage)
+func _Sequence_Slice_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error) (interface{}, error) {
+	in := new(SequenceSliceRequest)
 	if err := dec(in); err != nil {
 		return nil, err
 	}
-	out, err := srv.(CRDTServer).SupportedCryptoMethods(ctx, in)
+	out, err := srv.(SequenceServer).Slice(ctx, in)
 	if err != nil {
 		return nil, err
 	}
 	return out, nil
 }
 
-func _CRDT_IsSupportedCryptoMethod_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error) (interface{}, error) {
-	in := new(TypeMessage)
+func _Sequence_Text_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error) (interface{}, error) {
+	in := new(SequenceTextRequest)
 	if err := dec(in); err != nil {
 		return nil, err
 	}
-	out, err := srv.(CRDTServer).IsSupportedCryptoMethod(ctx, in)
+	out, err := srv.(SequenceServer).Text(ctx, in)
 	if err != nil {
 		return nil, err
 	}
 	return out, nil
 }
 
-var _CRDT_serviceDesc = grpc.ServiceDesc{
-	ServiceName: "crdt.CRDT",
-	HandlerType: (*CRDTServer)(nil),
+var _Sequence_serviceDesc = grpc.ServiceDesc{
+	ServiceName: "crdt.Sequence",
+	HandlerType: (*SequenceServer)(nil),
 	Methods: []grpc.MethodDesc{
 		{
-			MethodName: "Create",
-			Handler:    _CRDT_Create_Handler,
-		},
-		{
-			MethodName: "Attach",
-			Handler:    _CRDT_Attach_Handler,
-		},
-		{
-			MethodName: "Detach",
-			Handler:    _CRDT_Detach_Handler,
-		},
-		{
-			MethodName: "Commit",
-			Handler:    _CRDT_Commit_Handler,
-		},
-		{
-			MethodName: "Equals",
-			Handler:    _CRDT_Equals_Handler,
-		},
-		{
-			MethodName: "Merge",
-			Handler:    _CRDT_Merge_Handler,
-		},
-		{
-			MethodName: "Clone",
-			Handler:    _CRDT_Clone_Handler,
-		},
-		{
-			MethodName: "SupportedTypes",
-			Handler:    _CRDT_SupportedTypes_Handler,
-		},
-		{
-			MethodName: "IsSupportedType",
-			Handler:    _CRDT_IsSupportedType_Handler,
-		},
-		{
-			MethodName: "SupportedStorageTypes",
-			Handler:    _CRDT_SupportedStorageTypes_Handler,
-		},
-		{
-			MethodName: "IsSupportedStorageType",
-			Handler:    _CRDT_IsSupportedStorageType_Handler,
+			MethodName: "InsertAt",
+			Handler:    _Sequence_InsertAt_Handler,
 		},
 		{
-			MethodName: "SupportedCryptoMethods",
-			Handler:    _CRDT_SupportedCryptoMethods_Handler,
+			MethodName: "DeleteAt",
+			Handler:    _Sequence_DeleteAt_Handler,
 		},
 		{
-			MethodName: "IsSupportedCryptoMethod",
-			Handler:    _CRDT_IsSupportedCryptoMethod_Handler,
+			MethodName: "Slice",
+			Handler:    _Sequence_Slice_Handler,
 		},
-	},
-	Streams: []grpc.StreamDesc{
 		{
-			StreamName:    "Subscribe",
-			Handler:       _CRDT_Subscribe_Handler,
-			ServerStreams: true,
+			MethodName: "Text",
+			Handler:    _Sequence_Text_Handler,
 		},
 	},
+	Streams: []grpc.StreamDesc{},
 }
 
-// Client API for GrowOnlySet service
+// Client API for Flag service
 
-type GrowOnlySetClient interface {
-	List(ctx context.Context, in *SetListRequest, opts ...grpc.CallOption) (GrowOnlySet_ListClient, error)
-	Insert(ctx context.Context, in *SetInsertRequest, opts ...grpc.CallOption) (*SetInsertResponse, error)
-	Length(ctx context.Context, in *SetLengthRequest, opts ...grpc.CallOption) (*SetLengthResponse, error)
-	Contains(ctx context.Context, in *SetContainsRequest, opts ...grpc.CallOption) (*SetContainsResponse, error)
+type FlagClient interface {
+	Enable(ctx context.Context, in *FlagEnableRequest, opts ...grpc.CallOption) (*FlagEnableResponse, error)
+	Disable(ctx context.Context, in *FlagDisableRequest, opts ...grpc.CallOption) (*FlagDisableResponse, error)
+	Value(ctx context.Context, in *FlagValueRequest, opts ...grpc.CallOption) (*FlagValueResponse, error)
 }
 
-type growOnlySetClient struct {
+type flagClient struct {
 	cc *grpc.ClientConn
 }
 
-func NewGrowOnlySetClient(cc *grpc.ClientConn) GrowOnlySetClient {
-	return &growOnlySetClient{cc}
+func NewFlagClient(cc *grpc.ClientConn) FlagClient {
+	return &flagClient{cc}
 }
 
-func (c *growOnlySetClient) List(ctx context.Context, in *SetListRequest, opts ...grpc.CallOption) (GrowOnlySet_ListClient, error) {
-	stream, err := grpc.NewClientStream(ctx, &_GrowOnlySet_serviceDesc.Streams[0], c.cc, "/crdt.GrowOnlySet/List", opts...)
+func (c *flagClient) Enable(ctx context.Context, in *FlagEnableRequest, opts ...grpc.CallOption) (*FlagEnableResponse, error) {
+	out := new(FlagEnableResponse)
+	err := grpc.Invoke(ctx, "/crdt.Flag/Enable", in, out, c.cc, opts...)
 	if err != nil {
 		return nil, err
 	}
-	x := &growOnlySetListClient{stream}
-	if err := x.ClientStream.SendMsg(in); err != nil {
+	return out, nil
+}
+
+func (c *flagClient) Disable(ctx context.Context, in *FlagDisableRequest, opts ...grpc.CallOption) (*FlagDisableResponse, error) {
+	out := new(FlagDisableResponse)
+	err := grpc.Invoke(ctx, "/crdt.Flag/Disable", in, out, c.cc, opts...)
+	if err != nil {
 		return nil, err
 	}
-	if err := x.ClientStream.CloseSend(); err != nil {
+	return out, nil
+}
+
+func (c *flagClient) Value(ctx context.Context, in *FlagValueRequest, opts ...grpc.CallOption) (*FlagValueResponse, error) {
+	out := new(FlagValueResponse)
+	err := grpc.Invoke(ctx, "/crdt.Flag/Value", in, out, c.cc, opts...)
+	if err != nil {
 		return nil, err
 	}
-	return x, nil
+	return out, nil
 }
 
-type GrowOnlySet_ListClient interface {
-	Recv() (*ResourceObject, error)
-	grpc.ClientStream
+// Server API for Flag service
+
+type FlagServer interface {
+	Enable(context.Context, *FlagEnableRequest) (*FlagEnableResponse, error)
+	Disable(context.Context, *FlagDisableRequest) (*FlagDisableResponse, error)
+	Value(context.Context, *FlagValueRequest) (*FlagValueResponse, error)
+}
+
+func RegisterFlagServer(s *grpc.Server, srv FlagServer) {
+	s.RegisterService(&_Flag_serviceDesc, srv)
+}
+
+func _Flag_Enable_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error) (interface{}, error) {
+	in := new(FlagEnableRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	out, err := srv.(FlagServer).Enable(ctx, in)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func _Flag_Disable_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error) (interface{}, error) {
+	in := new(FlagDisableRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	out, err := srv.(FlagServer).Disable(ctx, in)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func _Flag_Value_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error) (interface{}, error) {
+	in := new(FlagValueRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	out, err := srv.(FlagServer).Value(ctx, in)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+var _Flag_serviceDesc = grpc.ServiceDesc{
+	ServiceName: "crdt.Flag",
+	HandlerType: (*FlagServer)(nil),
+	Methods: []grpc.MethodDesc{
+		{
+			MethodName: "Enable",
+			Handler:    _Flag_Enable_Handler,
+		},
+		{
+			MethodName: "Disable",
+			Handler:    _Flag_Disable_Handler,
+		},
+		{
+			MethodName: "Value",
+			Handler:    _Flag_Value_Handler,
+		},
+	},
+	Streams: []grpc.StreamDesc{},
+}
+
+// Client API for Graph service
+
+type GraphClient interface {
+	AddVertex(ctx context.Context, in *GraphAddVertexRequest, opts ...grpc.CallOption) (*GraphAddVertexResponse, error)
+	RemoveVertex(ctx context.Context, in *GraphRemoveVertexRequest, opts ...grpc.CallOption) (*GraphRemoveVertexResponse, error)
+	AddEdge(ctx context.Context, in *GraphAddEdgeRequest, opts ...grpc.CallOption) (*GraphAddEdgeResponse, error)
+	RemoveEdge(ctx context.Context, in *GraphRemoveEdgeRequest, opts ...grpc.CallOption) (*GraphRemoveEdgeResponse, error)
+	Neighbours(ctx context.Context, in *GraphNeighboursRequest, opts ...grpc.CallOption) (*GraphNeighboursResponse, error)
+	Vertices(ctx context.Context, in *GraphVerticesRequest, opts ...grpc.CallOption) (*GraphVerticesResponse, error)
+}
+
+type graphClient struct {
+	cc *grpc.ClientConn
+}
+
+func NewGraphClient(cc *grpc.ClientConn) GraphClient {
+	return &graphClient{cc}
+}
+
+func (c *graphClient) AddVertex(ctx context.Context, in *GraphAddVertexRequest, opts ...grpc.CallOption) (*GraphAddVertexResponse, error) {
+	out := new(GraphAddVertexResponse)
+	err := grpc.Invoke(ctx, "/crdt.Graph/AddVertex", in, out, c.cc, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
 }
 
-type growOnlySetListClient struct {
-	grpc.ClientStream
+func (c *graphClient) RemoveVertex(ctx context.Context, in *GraphRemoveVertexRequest, opts ...grpc.CallOption) (*GraphRemoveVertexResponse, error) {
+	out := new(GraphRemoveVertexResponse)
+	err := grpc.Invoke(ctx, "/crdt.Graph/RemoveVertex", in, out, c.cc, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
 }
 
-func (x *growOnlySetListClient) Recv() (*ResourceObject, error) {
-	m := new(ResourceObject)
-	if err := x.ClientStream.RecvMsg(m); err != nil {
+func (c *graphClient) AddEdge(ctx context.Context, in *GraphAddEdgeRequest, opts ...grpc.CallOption) (*GraphAddEdgeResponse, error) {
+	out := new(GraphAddEdgeResponse)
+	err := grpc.Invoke(ctx, "/crdt.Graph/AddEdge", in, out, c.cc, opts...)
+	if err != nil {
 		return nil, err
 	}
-	return m, nil
+	return out, nil
 }
 
-func (c *growOnlySetClient) Insert(ctx context.Context, in *SetInsertRequest, opts ...grpc.CallOption) (*SetInsertResponse, error) {
-	out := new(SetInsertResponse)
-	err := grpc.Invoke(ctx, "/crdt.GrowOnlySet/Insert", in, out, c.cc, opts...)
+func (c *graphClient) RemoveEdge(ctx context.Context, in *GraphRemoveEdgeRequest, opts ...grpc.CallOption) (*GraphRemoveEdgeResponse, error) {
+	out := new(GraphRemoveEdgeResponse)
+	err := grpc.Invoke(ctx, "/crdt.Graph/RemoveEdge", in, out, c.cc, opts...)
 	if err != nil {
 		return nil, err
 	}
 	return out, nil
 }
 
-func (c *growOnlySetClient) Length(ctx context.Context, in *SetLengthRequest, opts ...grpc.CallOption) (*SetLengthResponse, error) {
-	out := new(SetLengthResponse)
-	err := grpc.Invoke(ctx, "/crdt.GrowOnlySet/Length", in, out, c.cc, opts...)
+func (c *graphClient) Neighbours(ctx context.Context, in *GraphNeighboursRequest, opts ...grpc.CallOption) (*GraphNeighboursResponse, error) {
+	out := new(GraphNeighboursResponse)
+	err := grpc.Invoke(ctx, "/crdt.Graph/Neighbours", in, out, c.cc, opts...)
 	if err != nil {
 		return nil, err
 	}
 	return out, nil
 }
 
-func (c *growOnlySetClient) Contains(ctx context.Context, in *SetContainsRequest, opts ...grpc.CallOption) (*SetContainsResponse, error) {
-	out := new(SetContainsResponse)
-	err := grpc.Invoke(ctx, "/crdt.GrowOnlySet/Contains", in, out, c.cc, opts...)
+func (c *graphClient) Vertices(ctx context.Context, in *GraphVerticesRequest, opts ...grpc.CallOption) (*GraphVerticesResponse, error) {
+	out := new(GraphVerticesResponse)
+	err := grpc.Invoke(ctx, "/crdt.Graph/Vertices", in, out, c.cc, opts...)
 	if err != nil {
 		return nil, err
 	}
 	return out, nil
 }
 
-// Server API for GrowOnlySet service
+// Server API for Graph service
 
-type GrowOnlySetServer interface {
-	List(*SetListRequest, GrowOnlySet_ListServer) error
-	Insert(context.Context, *SetInsertRequest) (*SetInsertResponse, error)
-	Length(context.Context, *SetLengthRequest) (*SetLengthResponse, error)
-	Contains(context.Context, *SetContainsRequest) (*SetContainsResponse, error)
+type GraphServer interface {
+	AddVertex(context.Context, *GraphAddVertexRequest) (*GraphAddVertexResponse, error)
+	RemoveVertex(context.Context, *GraphRemoveVertexRequest) (*GraphRemoveVertexResponse, error)
+	AddEdge(context.Context, *GraphAddEdgeRequest) (*GraphAddEdgeResponse, error)
+	RemoveEdge(context.Context, *GraphRemoveEdgeRequest) (*GraphRemoveEdgeResponse, error)
+	Neighbours(context.Context, *GraphNeighboursRequest) (*GraphNeighboursResponse, error)
+	Vertices(context.Context, *GraphVerticesRequest) (*GraphVerticesResponse, error)
 }
 
-func RegisterGrowOnlySetServer(s *grpc.Server, srv GrowOnlySetServer) {
-	s.RegisterService(&_GrowOnlySet_serviceDesc, srv)
+func RegisterGraphServer(s *grpc.Server, srv GraphServer) {
+	s.RegisterService(&_Graph_serviceDesc, srv)
 }
 
-func _GrowOnlySet_List_Handler(srv interface{}, stream grpc.ServerStream) error {
-	m := new(SetListRequest)
-	if err := stream.RecvMsg(m); err != nil {
-		return err
+func _Graph_AddVertex_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error) (interface{}, error) {
+	in := new(GraphAddVertexRequest)
+	if err := dec(in); err != nil {
+		return nil, err
 	}
-	return srv.(GrowOnlySetServer).List(m, &growOnlySetListServer{stream})
-}
-
-type GrowOnlySet_ListServer interface {
-	Send(*ResourceObject) error
-	grpc.ServerStream
+	out, err := srv.(GraphServer).AddVertex(ctx, in)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
 }
 
-type growOnlySetListServer struct {
-	grpc.ServerStream
+func _Graph_RemoveVertex_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error) (interface{}, error) {
+	in := new(GraphRemoveVertexRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	out, err := srv.(GraphServer).RemoveVertex(ctx, in)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
 }
 
-func (x *growOnlySetListServer) Send(m *ResourceObject) error {
-	return x.ServerStream.SendMsg(m)
+func _Graph_AddEdge_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error) (interface{}, error) {
+	in := new(GraphAddEdgeRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	out, err := srv.(GraphServer).AddEdge(ctx, in)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
 }
 
-func _GrowOnlySet_Insert_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error) (interface{}, error) {
-	in := new(SetInsertRequest)
+func _Graph_RemoveEdge_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error) (interface{}, error) {
+	in := new(GraphRemoveEdgeRequest)
 	if err := dec(in); err != nil {
 		return nil, err
 	}
-	out, err := srv.(GrowOnlySetServer).Insert(ctx, in)
+	out, err := srv.(GraphServer).RemoveEdge(ctx, in)
 	if err != nil {
 		return nil, err
 	}
 	return out, nil
 }
 
-func _GrowOnlySet_Length_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error) (interface{}, error) {
-	in := new(SetLengthRequest)
+func _Graph_Neighbours_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error) (interface{}, error) {
+	in := new(GraphNeighboursRequest)
 	if err := dec(in); err != nil {
 		return nil, err
 	}
-	out, err := srv.(GrowOnlySetServer).Length(ctx, in)
+	out, err := srv.(GraphServer).Neighbours(ctx, in)
 	if err != nil {
 		return nil, err
 	}
 	return out, nil
 }
 
-func _GrowOnlySet_Contains_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error) (interface{}, error) {
-	in := new(SetContainsRequest)
+func _Graph_Vertices_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error) (interface{}, error) {
+	in := new(GraphVerticesRequest)
 	if err := dec(in); err != nil {
 		return nil, err
 	}
-	out, err := srv.(GrowOnlySetServer).Contains(ctx, in)
+	out, err := srv.(GraphServer).Vertices(ctx, in)
 	if err != nil {
 		return nil, err
 	}
 	return out, nil
 }
 
-var _GrowOnlySet_serviceDesc = grpc.ServiceDesc{
-	ServiceName: "crdt.GrowOnlySet",
-	HandlerType: (*GrowOnlySetServer)(nil),
+var _Graph_serviceDesc = grpc.ServiceDesc{
+	ServiceName: "crdt.Graph",
+	HandlerType: (*GraphServer)(nil),
 	Methods: []grpc.MethodDesc{
 		{
-			MethodName: "Insert",
-			Handler:    _GrowOnlySet_Insert_Handler,
+			MethodName: "AddVertex",
+			Handler:    _Graph_AddVertex_Handler,
 		},
 		{
-			MethodName: "Length",
-			Handler:    _GrowOnlySet_Length_Handler,
+			MethodName: "RemoveVertex",
+			Handler:    _Graph_RemoveVertex_Handler,
 		},
 		{
-			MethodName: "Contains",
-			Handler:    _GrowOnlySet_Contains_Handler,
+			MethodName: "AddEdge",
+			Handler:    _Graph_AddEdge_Handler,
 		},
-	},
-	Streams: []grpc.StreamDesc{
 		{
-			StreamName:    "List",
-			Handler:       _GrowOnlySet_List_Handler,
-			ServerStreams: true,
+			MethodName: "RemoveEdge",
+			Handler:    _Graph_RemoveEdge_Handler,
+		},
+		{
+			MethodName: "Neighbours",
+			Handler:    _Graph_Neighbours_Handler,
+		},
+		{
+			MethodName: "Vertices",
+			Handler:    _Graph_Vertices_Handler,
 		},
 	},
+	Streams: []grpc.StreamDesc{},
 }
 
-// Client API for TwoPhaseSet service
+// Client API for Tree service
 
-type TwoPhaseSetClient interface {
-	List(ctx context.Context, in *SetListRequest, opts ...grpc.CallOption) (TwoPhaseSet_ListClient, error)
-	Insert(ctx context.Context, in *SetInsertRequest, opts ...grpc.CallOption) (*SetInsertResponse, error)
-	Remove(ctx context.Context, in *SetRemoveRequest, opts ...grpc.CallOption) (*SetRemoveResponse, error)
-	Length(ctx context.Context, in *SetLengthRequest, opts ...grpc.CallOption) (*SetLengthResponse, error)
-	Contains(ctx context.Context, in *SetContainsRequest, opts ...grpc.CallOption) (*SetContainsResponse, error)
+type TreeClient interface {
+	Create(ctx context.Context, in *TreeCreateRequest, opts ...grpc.CallOption) (*TreeCreateResponse, error)
+	Move(ctx context.Context, in *TreeMoveRequest, opts ...grpc.CallOption) (*TreeMoveResponse, error)
+	Delete(ctx context.Context, in *TreeDeleteRequest, opts ...grpc.CallOption) (*TreeDeleteResponse, error)
+	Parent(ctx context.Context, in *TreeParentRequest, opts ...grpc.CallOption) (*TreeParentResponse, error)
+	Children(ctx context.Context, in *TreeChildrenRequest, opts ...grpc.CallOption) (*TreeChildrenResponse, error)
 }
 
-type twoPhaseSetClient struct {
+type treeClient struct {
 	cc *grpc.ClientConn
 }
 
-func NewTwoPhaseSetClient(cc *grpc.ClientConn) TwoPhaseSetClient {
-	return &twoPhaseSetClient{cc}
+func NewTreeClient(cc *grpc.ClientConn) TreeClient {
+	return &treeClient{cc}
 }
 
-func (c *twoPhaseSetClient) List(ctx context.Context, in *SetListRequest, opts ...grpc.CallOption) (TwoPhaseSet_ListClient, error) {
-	stream, err := grpc.NewClientStream(ctx, &_TwoPhaseSet_serviceDesc.Streams[0], c.cc, "/crdt.TwoPhaseSet/List", opts...)
+func (c *treeClient) Create(ctx context.Context, in *TreeCreateRequest, opts ...grpc.CallOption) (*TreeCreateResponse, error) {
+	out := new(TreeCreateResponse)
+	err := grpc.Invoke(ctx, "/crdt.Tree/Create", in, out, c.cc, opts...)
 	if err != nil {
 		return nil, err
 	}
-	x := &twoPhaseSetListClient{stream}
-	if err := x.ClientStream.SendMsg(in); err != nil {
+	return out, nil
+}
+
+func (c *treeClient) Move(ctx context.Context, in *TreeMoveRequest, opts ...grpc.CallOption) (*TreeMoveResponse, error) {
+	out := new(TreeMoveResponse)
+	err := grpc.Invoke(ctx, "/crdt.Tree/Move", in, out, c.cc, opts...)
+	if err != nil {
 		return nil, err
 	}
-	if err := x.ClientStream.CloseSend(); err != nil {
+	return out, nil
+}
+
+func (c *treeClient) Delete(ctx context.Context, in *TreeDeleteRequest, opts ...grpc.CallOption) (*TreeDeleteResponse, error) {
+	out := new(TreeDeleteResponse)
+	err := grpc.Invoke(ctx, "/crdt.Tree/Delete", in, out, c.cc, opts...)
+	if err != nil {
 		return nil, err
 	}
-	return x, nil
+	return out, nil
 }
 
-type TwoPhaseSet_ListClient interface {
-	Recv() (*ResourceObject, error)
-	grpc.ClientStream
+func (c *treeClient) Parent(ctx context.Context, in *TreeParentRequest, opts ...grpc.CallOption) (*TreeParentResponse, error) {
+	out := new(TreeParentResponse)
+	err := grpc.Invoke(ctx, "/crdt.Tree/Parent", in, out, c.cc, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
 }
 
-type twoPhaseSetListClient struct {
-	grpc.ClientStream
+func (c *treeClient) Children(ctx context.Context, in *TreeChildrenRequest, opts ...grpc.CallOption) (*TreeChildrenResponse, error) {
+	out := new(TreeChildrenResponse)
+	err := grpc.Invoke(ctx, "/crdt.Tree/Children", in, out, c.cc, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
 }
 
-func (x *twoPhaseSetListClient) Recv() (*ResourceObject, error) {
-	m := new(ResourceObject)
-	if err := x.ClientStream.RecvMsg(m); err != nil {
+// Server API for Tree service
+
+type TreeServer interface {
+	Create(context.Context, *TreeCreateRequest) (*TreeCreateResponse, error)
+	Move(context.Context, *TreeMoveRequest) (*TreeMoveResponse, error)
+	Delete(context.Context, *TreeDeleteRequest) (*TreeDeleteResponse, error)
+	Parent(context.Context, *TreeParentRequest) (*TreeParentResponse, error)
+	Children(context.Context, *TreeChildrenRequest) (*TreeChildrenResponse, error)
+}
+
+func RegisterTreeServer(s *grpc.Server, srv TreeServer) {
+	s.RegisterService(&_Tree_serviceDesc, srv)
+}
+
+func _Tree_Create_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error) (interface{}, error) {
+	in := new(TreeCreateRequest)
+	if err := dec(in); err != nil {
 		return nil, err
 	}
-	return m, nil
+	out, err := srv.(TreeServer).Create(ctx, in)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
 }
 
-func (c *twoPhaseSetClient) Insert(ctx context.Context, in *SetInsertRequest, opts ...grpc.CallOption) (*SetInsertResponse, error) {
-	out := new(SetInsertResponse)
-	err := grpc.Invoke(ctx, "/crdt.TwoPhaseSet/Insert", in, out, c.cc, opts...)
+func _Tree_Move_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error) (interface{}, error) {
+	in := new(TreeMoveRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	out, err := srv.(TreeServer).Move(ctx, in)
 	if err != nil {
 		return nil, err
 	}
 	return out, nil
 }
 
-func (c *twoPhaseSetClient) Remove(ctx context.Context, in *SetRemoveRequest, opts ...grpc.CallOption) (*SetRemoveResponse, error) {
-	out := new(SetRemoveResponse)
-	err := grpc.Invoke(ctx, "/crdt.TwoPhaseSet/Remove", in, out, c.cc, opts...)
+func _Tree_Delete_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error) (interface{}, error) {
+	in := new(TreeDeleteRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	out, err := srv.(TreeServer).Delete(ctx, in)
 	if err != nil {
 		return nil, err
 	}
 	return out, nil
 }
 
-func (c *twoPhaseSetClient) Length(ctx context.Context, in *SetLengthRequest, opts ...grpc.CallOption) (*SetLengthResponse, error) {
-	out := new(SetLengthResponse)
-	err := grpc.Invoke(ctx, "/crdt.TwoPhaseSet/Length", in, out, c.cc, opts...)
+func _Tree_Parent_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error) (interface{}, error) {
+	in := new(TreeParentRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	out, err := srv.(TreeServer).Parent(ctx, in)
 	if err != nil {
 		return nil, err
 	}
 	return out, nil
 }
 
-func (c *twoPhaseSetClient) Contains(ctx context.Context, in *SetContainsRequest, opts ...grpc.CallOption) (*SetContainsResponse, error) {
-	out := new(SetContainsResponse)
-	err := grpc.Invoke(ctx, "/crdt.TwoPhaseSet/Contains", in, out, c.cc, opts...)
+func _Tree_Children_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error) (interface{}, error) {
+	in := new(TreeChildrenRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	out, err := srv.(TreeServer).Children(ctx, in)
 	if err != nil {
 		return nil, err
 	}
 	return out, nil
 }
 
-// Server API for TwoPhaseSet service
+var _Tree_serviceDesc = grpc.ServiceDesc{
+	ServiceName: "crdt.Tree",
+	HandlerType: (*TreeServer)(nil),
+	Methods: []grpc.MethodDesc{
+		{
+			MethodName: "Create",
+			Handler:    _Tree_Create_Handler,
+		},
+		{
+			MethodName: "Move",
+			Handler:    _Tree_Move_Handler,
+		},
+		{
+			MethodName: "Delete",
+			Handler:    _Tree_Delete_Handler,
+		},
+		{
+			MethodName: "Parent",
+			Handler:    _Tree_Parent_Handler,
+		},
+		{
+			MethodName: "Children",
+			Handler:    _Tree_Children_Handler,
+		},
+	},
+	Streams: []grpc.StreamDesc{},
+}
 
-type TwoPhaseSetServer interface {
-	List(*SetListRequest, TwoPhaseSet_ListServer) error
-	Insert(context.Context, *SetInsertRequest) (*SetInsertResponse, error)
-	Remove(context.Context, *SetRemoveRequest) (*SetRemoveResponse, error)
-	Length(context.Context, *SetLengthRequest) (*SetLengthResponse, error)
-	Contains(context.Context, *SetContainsRequest) (*SetContainsResponse, error)
+// Client API for Document service
+
+type DocumentClient interface {
+	Get(ctx context.Context, in *DocumentGetRequest, opts ...grpc.CallOption) (*DocumentGetResponse, error)
+	Set(ctx context.Context, in *DocumentSetRequest, opts ...grpc.CallOption) (*DocumentSetResponse, error)
+	Insert(ctx context.Context, in *DocumentInsertRequest, opts ...grpc.CallOption) (*DocumentInsertResponse, error)
+	Delete(ctx context.Context, in *DocumentDeleteRequest, opts ...grpc.CallOption) (*DocumentDeleteResponse, error)
 }
 
-func RegisterTwoPhaseSetServer(s *grpc.Server, srv TwoPhaseSetServer) {
-	s.RegisterService(&_TwoPhaseSet_serviceDesc, srv)
+type documentClient struct {
+	cc *grpc.ClientConn
 }
 
-func _TwoPhaseSet_List_Handler(srv interface{}, stream grpc.ServerStream) error {
-	m := new(SetListRequest)
-	if err := stream.RecvMsg(m); err != nil {
-		return err
+func NewDocumentClient(cc *grpc.ClientConn) DocumentClient {
+	return &documentClient{cc}
+}
+
+func (c *documentClient) Get(ctx context.Context, in *DocumentGetRequest, opts ...grpc.CallOption) (*DocumentGetResponse, error) {
+	out := new(DocumentGetResponse)
+	err := grpc.Invoke(ctx, "/crdt.Document/Get", in, out, c.cc, opts...)
+	if err != nil {
+		return nil, err
 	}
-	return srv.(TwoPhaseSetServer).List(m, &twoPhaseSetListServer{stream})
+	return out, nil
 }
 
-type TwoPhaseSet_ListServer interface {
-	Send(*ResourceObject) error
-	grpc.ServerStream
+func (c *documentClient) Set(ctx context.Context, in *DocumentSetRequest, opts ...grpc.CallOption) (*DocumentSetResponse, error) {
+	out := new(DocumentSetResponse)
+	err := grpc.Invoke(ctx, "/crdt.Document/Set", in, out, c.cc, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
 }
 
-type twoPhaseSetListServer struct {
-	grpc.ServerStream
+func (c *documentClient) Insert(ctx context.Context, in *DocumentInsertRequest, opts ...grpc.CallOption) (*DocumentInsertResponse, error) {
+	out := new(DocumentInsertResponse)
+	err := grpc.Invoke(ctx, "/crdt.Document/Insert", in, out, c.cc, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
 }
 
-func (x *twoPhaseSetListServer) Send(m *ResourceObject) error {
-	return x.ServerStream.SendMsg(m)
+func (c *documentClient) Delete(ctx context.Context, in *DocumentDeleteRequest, opts ...grpc.CallOption) (*DocumentDeleteResponse, error) {
+	out := new(DocumentDeleteResponse)
+	err := grpc.Invoke(ctx, "/crdt.Document/Delete", in, out, c.cc, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
 }
 
-func _TwoPhaseSet_Insert_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error) (interface{}, error) {
-	in := new(SetInsertRequest)
+// Server API for Document service
+
+type DocumentServer interface {
+	Get(context.Context, *DocumentGetRequest) (*DocumentGetResponse, error)
+	Set(context.Context, *DocumentSetRequest) (*DocumentSetResponse, error)
+	Insert(context.Context, *DocumentInsertRequest) (*DocumentInsertResponse, error)
+	Delete(context.Context, *DocumentDeleteRequest) (*DocumentDeleteResponse, error)
+}
+
+func RegisterDocumentServer(s *grpc.Server, srv DocumentServer) {
+	s.RegisterService(&_Document_serviceDesc, srv)
+}
+
+func _Document_Get_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error) (interface{}, error) {
+	in := new(DocumentGetRequest)
 	if err := dec(in); err != nil {
 		return nil, err
 	}
-	out, err := srv.(TwoPhaseSetServer).Insert(ctx, in)
+	out, err := srv.(DocumentServer).Get(ctx, in)
 	if err != nil {
 		return nil, err
 	}
 	return out, nil
 }
 
-func _TwoPhaseSet_Remove_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error) (interface{}, error) {
-	in := new(SetRemoveRequest)
+func _Document_Set_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error) (interface{}, error) {
+	in := new(DocumentSetRequest)
 	if err := dec(in); err != nil {
 		return nil, err
 	}
-	out, err := srv.(TwoPhaseSetServer).Remove(ctx, in)
+	out, err := srv.(DocumentServer).Set(ctx, in)
 	if err != nil {
 		return nil, err
 	}
 	return out, nil
 }
 
-func _TwoPhaseSet_Length_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error) (interface{}, error) {
-	in := new(SetLengthRequest)
+func _Document_Insert_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error) (interface{}, error) {
+	in := new(DocumentInsertRequest)
 	if err := dec(in); err != nil {
 		return nil, err
 	}
-	out, err := srv.(TwoPhaseSetServer).Length(ctx, in)
+	out, err := srv.(DocumentServer).Insert(ctx, in)
 	if err != nil {
 		return nil, err
 	}
 	return out, nil
 }
 
-func _TwoPhaseSet_Contains_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error) (interface{}, error) {
-	in := new(SetContainsRequest)
+func _Document_Delete_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error) (interface{}, error) {
+	in := new(DocumentDeleteRequest)
 	if err := dec(in); err != nil {
 		return nil, err
 	}
-	out, err := srv.(TwoPhaseSetServer).Contains(ctx, in)
+	out, err := srv.(DocumentServer).Delete(ctx, in)
 	if err != nil {
 		return nil, err
 	}
 	return out, nil
 }
 
-var _TwoPhaseSet_serviceDesc = grpc.ServiceDesc{
-	ServiceName: "crdt.TwoPhaseSet",
-	HandlerType: (*TwoPhaseSetServer)(nil),
+var _Document_serviceDesc = grpc.ServiceDesc{
+	ServiceName: "crdt.Document",
+	HandlerType: (*DocumentServer)(nil),
 	Methods: []grpc.MethodDesc{
 		{
-			MethodName: "Insert",
-			Handler:    _TwoPhaseSet_Insert_Handler,
-		},
-		{
-			MethodName: "Remove",
-			Handler:    _TwoPhaseSet_Remove_Handler,
+			MethodName: "Get",
+			Handler:    _Document_Get_Handler,
 		},
 		{
-			MethodName: "Length",
-			Handler:    _TwoPhaseSet_Length_Handler,
+			MethodName: "Set",
+			Handler:    _Document_Set_Handler,
 		},
 		{
-			MethodName: "Contains",
-			Handler:    _TwoPhaseSet_Contains_Handler,
+			MethodName: "Insert",
+			Handler:    _Document_Insert_Handler,
 		},
-	},
-	Streams: []grpc.StreamDesc{
 		{
-			StreamName:    "List",
-			Handler:       _TwoPhaseSet_List_Handler,
-			ServerStreams: true,
+			MethodName: "Delete",
+			Handler:    _Document_Delete_Handler,
 		},
 	},
+	Streams: []grpc.StreamDesc{},
 }
@@ -0,0 +1,246 @@
+/*
+ * Copyright (c) 2015 Tom Swindell (t.swindell@rubyx.co.uk)
+ *
+ * Permission is hereby granted, free of charge, to any person obtaining a copy
+ * of this software and associated documentation files (the "Software"), to deal
+ * in the Software without restriction, including without limitation the rights
+ * to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+ * copies of the Software, and to permit persons to whom the Software is
+ * furnished to do so, subject to the following conditions:
+ *
+ * The above copyright notice and this permission notice shall be included in
+ * all copies or substantial portions of the Software.
+ *
+ * THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+ * IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+ * FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+ * AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+ * LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+ * OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+ * SOFTWARE.
+ *
+ */
+
+package register
+
+import (
+    "bytes"
+    "encoding/binary"
+    "sync"
+
+    "github.com/tswindell/go-crdt/sets"
+)
+
+type dots map[set.Dot]struct{}
+
+// Keeps the dots of a which are in b, or which b has not yet observed.
+func mergeDots(a, b dots, bContext *set.DotContext) dots {
+    result := make(dots)
+    for d := range a {
+        if _, found := b[d]; found || !bContext.Contains(d) { result[d] = struct{}{} }
+    }
+    return result
+}
+
+func equalDots(a, b dots) bool {
+    if len(a) != len(b) { return false }
+
+    for d := range a {
+        if _, found := b[d]; !found { return false }
+    }
+    return true
+}
+
+func serializeDots(buff *bytes.Buffer, in dots) error {
+    binary.Write(buff, binary.LittleEndian, uint32(len(in)))
+    for d := range in {
+        if e := set.WriteString(buff, d.ReplicaId); e != nil { return e }
+        binary.Write(buff, binary.LittleEndian, d.Counter)
+    }
+    return nil
+}
+
+func deserializeDots(buff *bytes.Buffer) (dots, error) {
+    var count uint32
+    if e := binary.Read(buff, binary.LittleEndian, &count); e != nil { return nil, e }
+
+    result := make(dots)
+    for i := uint32(0); i < count; i++ {
+        var d set.Dot
+        var e error
+
+        if d.ReplicaId, e = set.ReadString(buff); e != nil { return nil, e }
+        if e := binary.Read(buff, binary.LittleEndian, &d.Counter); e != nil { return nil, e }
+        result[d] = struct{}{}
+    }
+    return result, nil
+}
+
+// The flagState type is shared by both flag types. Every enable or disable
+// is tagged with a dot and replaces the enables and disables it observed, so
+// after a merge only the operations not observed by a later one remain. The
+// flag types differ only in how they read concurrent enables and disables.
+type flagState struct {
+    sync.RWMutex
+    context  *set.DotContext
+    enables   dots
+    disables  dots
+}
+
+func newFlagState() *flagState {
+    return &flagState{context: set.NewDotContext(), enables: make(dots), disables: make(dots)}
+}
+
+func (f *flagState) Enable(replicaId string) {
+    f.Lock()
+    defer f.Unlock()
+
+    f.enables = dots{f.context.Next(replicaId): struct{}{}}
+    f.disables = make(dots)
+}
+
+func (f *flagState) Disable(replicaId string) {
+    f.Lock()
+    defer f.Unlock()
+
+    f.disables = dots{f.context.Next(replicaId): struct{}{}}
+    f.enables = make(dots)
+}
+
+func (f *flagState) equals(other *flagState) bool {
+    in := other.clone()
+
+    f.RLock()
+    defer f.RUnlock()
+
+    return f.context.Equals(in.context) &&
+           equalDots(f.enables, in.enables) &&
+           equalDots(f.disables, in.disables)
+}
+
+func (f *flagState) clone() *flagState {
+    f.RLock()
+    defer f.RUnlock()
+
+    result := newFlagState()
+    result.context = f.context.Clone()
+    for d := range f.enables { result.enables[d] = struct{}{} }
+    for d := range f.disables { result.disables[d] = struct{}{} }
+    return result
+}
+
+func (f *flagState) merge(other *flagState) {
+    // Snapshot the other flag first, so two flags merging into each other
+    // can never deadlock.
+    in := other.clone()
+
+    f.Lock()
+    defer f.Unlock()
+
+    enables := mergeDots(f.enables, in.enables, in.context)
+    for d := range mergeDots(in.enables, f.enables, f.context) { enables[d] = struct{}{} }
+
+    disables := mergeDots(f.disables, in.disables, in.context)
+    for d := range mergeDots(in.disables, f.disables, f.context) { disables[d] = struct{}{} }
+
+    f.enables, f.disables = enables, disables
+    f.context.Merge(in.context)
+}
+
+func (f *flagState) serialize(buff *bytes.Buffer, magic []byte) error {
+    f.RLock()
+    defer f.RUnlock()
+
+    buff.Write(magic)
+    if e := f.context.Serialize(buff); e != nil { return e }
+    if e := serializeDots(buff, f.enables); e != nil { return e }
+    return serializeDots(buff, f.disables)
+}
+
+func (f *flagState) deserialize(buff *bytes.Buffer, magic []byte) error {
+    if e := set.ReadHeader(buff, magic); e != nil { return e }
+
+    in := newFlagState()
+    if e := in.context.Deserialize(buff); e != nil { return e }
+
+    var e error
+    if in.enables, e = deserializeDots(buff); e != nil { return e }
+    if in.disables, e = deserializeDots(buff); e != nil { return e }
+
+    f.merge(in)
+    return nil
+}
+
+
+// Common Go representation of an enable-wins flag. The flag starts disabled,
+// and an enable concurrent with a disable wins.
+type EWFlag struct {
+    *flagState
+}
+
+func NewEWFlag() *EWFlag {
+    return &EWFlag{newFlagState()}
+}
+
+func (f *EWFlag) Value() bool {
+    f.RLock()
+    defer f.RUnlock()
+
+    return len(f.enables) > 0
+}
+
+func (f *EWFlag) Equals(other *EWFlag) bool { return f.equals(other.flagState) }
+
+func (f *EWFlag) Merge(other *EWFlag) { f.merge(other.flagState) }
+
+func (f *EWFlag) Clone() *EWFlag {
+    return &EWFlag{f.clone()}
+}
+
+var EWFLAG_HEADER_MAGIC = []byte{'c', 'r', 'd', 't', ':', 'e', 'w', 'f', 'l', 'a', 'g', 0x00}
+
+func (f *EWFlag) Serialize(buff *bytes.Buffer) error {
+    return f.serialize(buff, EWFLAG_HEADER_MAGIC)
+}
+
+// Deserialize merges the serialized state into this flag.
+func (f *EWFlag) Deserialize(buff *bytes.Buffer) error {
+    return f.deserialize(buff, EWFLAG_HEADER_MAGIC)
+}
+
+
+// Common Go representation of a disable-wins flag. The flag starts disabled,
+// and a disable concurrent with an enable wins.
+type DWFlag struct {
+    *flagState
+}
+
+func NewDWFlag() *DWFlag {
+    return &DWFlag{newFlagState()}
+}
+
+func (f *DWFlag) Value() bool {
+    f.RLock()
+    defer f.RUnlock()
+
+    return len(f.enables) > 0 && len(f.disables) == 0
+}
+
+func (f *DWFlag) Equals(other *DWFlag) bool { return f.equals(other.flagState) }
+
+func (f *DWFlag) Merge(other *DWFlag) { f.merge(other.flagState) }
+
+func (f *DWFlag) Clone() *DWFlag {
+    return &DWFlag{f.clone()}
+}
+
+var DWFLAG_HEADER_MAGIC = []byte{'c', 'r', 'd', 't', ':', 'd', 'w', 'f', 'l', 'a', 'g', 0x00}
+
+func (f *DWFlag) Serialize(buff *bytes.Buffer) error {
+    return f.serialize(buff, DWFLAG_HEADER_MAGIC)
+}
+
+// Deserialize merges the serialized state into this flag.
+func (f *DWFlag) Deserialize(buff *bytes.Buffer) error {
+    return f.deserialize(buff, DWFLAG_HEADER_MAGIC)
+}
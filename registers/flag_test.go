@@ -0,0 +1,141 @@
+/*
+ * Copyright (c) 2015 Tom Swindell (t.swindell@rubyx.co.uk)
+ *
+ * Permission is hereby granted, free of charge, to any person obtaining a copy
+ * of this software and associated documentation files (the "Software"), to deal
+ * in the Software without restriction, including without limitation the rights
+ * to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+ * copies of the Software, and to permit persons to whom the Software is
+ * furnished to do so, subject to the following conditions:
+ *
+ * The above copyright notice and this permission notice shall be included in
+ * all copies or substantial portions of the Software.
+ *
+ * THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+ * IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+ * FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+ * AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+ * LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+ * OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+ * SOFTWARE.
+ *
+ */
+
+package register
+
+import "testing"
+import "bytes"
+
+func TestNewFlags(t *testing.T) {
+    if NewEWFlag().Value() || NewDWFlag().Value() {
+        t.Error("New flags should be disabled!")
+    }
+}
+
+func TestEWFlagEnableDisable(t *testing.T) {
+    a := NewEWFlag()
+
+    a.Enable("a")
+    if !a.Value() {
+        t.Error("Flag should be enabled!")
+    }
+
+    a.Disable("a")
+    if a.Value() {
+        t.Error("Flag should be disabled!")
+    }
+}
+
+func TestEWFlagConcurrent(t *testing.T) {
+    a := NewEWFlag()
+    a.Enable("a")
+
+    b := a.Clone()
+
+    a.Disable("a")
+    b.Enable("b")
+
+    c := a.Clone()
+    c.Merge(b)
+    d := b.Clone()
+    d.Merge(a)
+
+    if !c.Equals(d) {
+        t.Error("Merge should be commutative!")
+    }
+
+    if !c.Value() {
+        t.Error("Concurrent enable should win!")
+    }
+
+    // A disable which observed the enable does win.
+    c.Disable("a")
+    d.Merge(c)
+    if d.Value() {
+        t.Error("Observed enable should be disabled!")
+    }
+}
+
+func TestDWFlagConcurrent(t *testing.T) {
+    a := NewDWFlag()
+    a.Enable("a")
+
+    b := a.Clone()
+
+    a.Disable("a")
+    b.Enable("b")
+
+    c := a.Clone()
+    c.Merge(b)
+    d := b.Clone()
+    d.Merge(a)
+
+    if !c.Equals(d) {
+        t.Error("Merge should be commutative!")
+    }
+
+    if c.Value() {
+        t.Error("Concurrent disable should win!")
+    }
+
+    // An enable which observed the disable does win.
+    c.Enable("a")
+    d.Merge(c)
+    if !d.Value() {
+        t.Error("Observed disable should be enabled!")
+    }
+
+    d.Merge(d.Clone())
+    if !d.Equals(c) {
+        t.Error("Merge should be idempotent!")
+    }
+}
+
+func TestFlagSerialize(t *testing.T) {
+    a := NewDWFlag()
+    b := NewDWFlag()
+
+    a.Enable("a")
+    b.Disable("b")
+    a.Merge(b)
+
+    buff := new(bytes.Buffer)
+    if e := a.Serialize(buff); e != nil {
+        t.Fatal(e)
+    }
+
+    c := NewDWFlag()
+    if e := c.Deserialize(buff); e != nil {
+        t.Fatal(e)
+    }
+
+    if !a.Equals(c) || c.Value() {
+        t.Error("Deserialized flag should equal original!")
+    }
+
+    buff.Reset()
+    a.Serialize(buff)
+    if e := NewEWFlag().Deserialize(buff); e == nil {
+        t.Error("Expected header error!")
+    }
+}
@@ -0,0 +1,152 @@
+/*
+ * Copyright (c) 2015 Tom Swindell (t.swindell@rubyx.co.uk)
+ *
+ * Permission is hereby granted, free of charge, to any person obtaining a copy
+ * of this software and associated documentation files (the "Software"), to deal
+ * in the Software without restriction, including without limitation the rights
+ * to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+ * copies of the Software, and to permit persons to whom the Software is
+ * furnished to do so, subject to the following conditions:
+ *
+ * The above copyright notice and this permission notice shall be included in
+ * all copies or substantial portions of the Software.
+ *
+ * THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+ * IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+ * FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+ * AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+ * LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+ * OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+ * SOFTWARE.
+ *
+ */
+
+package register
+
+import (
+    "bytes"
+    "encoding/binary"
+    "sync"
+
+    "github.com/tswindell/go-crdt/sets"
+)
+
+// Common Go representation of a last-writer-wins register. Each write carries
+// a timestamp and the id of the replica that made it, the write with the
+// highest timestamp wins and replica ids break ties.
+type LWWRegister struct {
+    sync.RWMutex
+    clock     set.Clock
+    value     []byte
+    timestamp int64
+    replicaId string
+}
+
+func NewLWWRegister(clock set.Clock) *LWWRegister {
+    return &LWWRegister{clock: clock}
+}
+
+// The caller must hold the lock.
+func (r *LWWRegister) newer(ts int64, replicaId string) bool {
+    return ts > r.timestamp || (ts == r.timestamp && replicaId > r.replicaId)
+}
+
+// The Set() method writes value. A local write always supersedes the value it
+// replaces, even if the clock has gone backwards.
+func (r *LWWRegister) Set(replicaId string, value []byte) {
+    r.Lock()
+    defer r.Unlock()
+
+    ts := r.clock.Now()
+    if ts <= r.timestamp { ts = r.timestamp + 1 }
+
+    r.value, r.timestamp, r.replicaId = value, ts, replicaId
+}
+
+// The SetAt() method writes value with an explicit timestamp. Returns false if
+// the current value is newer.
+func (r *LWWRegister) SetAt(replicaId string, value []byte, ts int64) bool {
+    r.Lock()
+    defer r.Unlock()
+
+    if !r.newer(ts, replicaId) { return false }
+
+    r.value, r.timestamp, r.replicaId = value, ts, replicaId
+    return true
+}
+
+func (r *LWWRegister) Get() []byte {
+    r.RLock()
+    defer r.RUnlock()
+
+    return r.value
+}
+
+func (r *LWWRegister) Timestamp() int64 {
+    r.RLock()
+    defer r.RUnlock()
+
+    return r.timestamp
+}
+
+func (r *LWWRegister) Equals(other *LWWRegister) bool {
+    in := other.Clone()
+
+    r.RLock()
+    defer r.RUnlock()
+
+    return r.timestamp == in.timestamp &&
+           r.replicaId == in.replicaId &&
+           bytes.Equal(r.value, in.value)
+}
+
+func (r *LWWRegister) Merge(other *LWWRegister) {
+    other.RLock()
+    value, ts, replicaId := other.value, other.timestamp, other.replicaId
+    other.RUnlock()
+
+    r.SetAt(replicaId, value, ts)
+}
+
+func (r *LWWRegister) Clone() *LWWRegister {
+    r.RLock()
+    defer r.RUnlock()
+
+    return &LWWRegister{
+               clock: r.clock,
+               value: r.value,
+               timestamp: r.timestamp,
+               replicaId: r.replicaId,
+           }
+}
+
+var LWWREGISTER_HEADER_MAGIC = []byte{'c', 'r', 'd', 't', ':', 'l', 'w', 'w', 'r', 'e', 'g', 0x00}
+
+func (r *LWWRegister) Serialize(buff *bytes.Buffer) error {
+    r.RLock()
+    defer r.RUnlock()
+
+    buff.Write(LWWREGISTER_HEADER_MAGIC)
+    binary.Write(buff, binary.LittleEndian, r.timestamp)
+    if e := set.WriteString(buff, r.replicaId); e != nil { return e }
+    set.WriteValue(buff, r.value)
+
+    return nil
+}
+
+// Deserialize merges the serialized state into this register.
+func (r *LWWRegister) Deserialize(buff *bytes.Buffer) error {
+    if e := set.ReadHeader(buff, LWWREGISTER_HEADER_MAGIC); e != nil { return e }
+
+    var ts int64
+    if e := binary.Read(buff, binary.LittleEndian, &ts); e != nil { return e }
+
+    replicaId, e := set.ReadString(buff)
+    if e != nil { return e }
+
+    value, e := set.ReadValue(buff)
+    if e != nil { return e }
+
+    r.SetAt(replicaId, value, ts)
+    return nil
+}
@@ -0,0 +1,123 @@
+/*
+ * Copyright (c) 2015 Tom Swindell (t.swindell@rubyx.co.uk)
+ *
+ * Permission is hereby granted, free of charge, to any person obtaining a copy
+ * of this software and associated documentation files (the "Software"), to deal
+ * in the Software without restriction, including without limitation the rights
+ * to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+ * copies of the Software, and to permit persons to whom the Software is
+ * furnished to do so, subject to the following conditions:
+ *
+ * The above copyright notice and this permission notice shall be included in
+ * all copies or substantial portions of the Software.
+ *
+ * THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+ * IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+ * FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+ * AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+ * LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+ * OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+ * SOFTWARE.
+ *
+ */
+
+package register
+
+import "testing"
+import "bytes"
+
+// Deterministic clock, advancing by one on each reading.
+type tickClock struct { now int64 }
+
+func (c *tickClock) Now() int64 { c.now++; return c.now }
+
+func TestNewLWWRegister(t *testing.T) {
+    a := NewLWWRegister(&tickClock{})
+    if a.Get() != nil {
+        t.Error("New register should have no value!")
+    }
+}
+
+func TestLWWRegisterSet(t *testing.T) {
+    a := NewLWWRegister(&tickClock{})
+
+    a.Set("a", []byte("one"))
+    a.Set("a", []byte("two"))
+
+    if !bytes.Equal(a.Get(), []byte("two")) {
+        t.Errorf("Expected two, got %s", a.Get())
+    }
+}
+
+func TestLWWRegisterClockSkew(t *testing.T) {
+    a := NewLWWRegister(&tickClock{})
+
+    a.SetAt("a", []byte("one"), 100)
+    a.Set("a", []byte("two"))
+
+    if !bytes.Equal(a.Get(), []byte("two")) {
+        t.Error("Local write should supersede value despite clock skew!")
+    }
+}
+
+func TestLWWRegisterSetAt(t *testing.T) {
+    a := NewLWWRegister(&tickClock{})
+
+    a.SetAt("a", []byte("one"), 10)
+
+    if a.SetAt("a", []byte("two"), 5) {
+        t.Error("Older write should not supersede newer value!")
+    }
+
+    if !a.SetAt("b", []byte("three"), 10) {
+        t.Error("Higher replica id should break timestamp tie!")
+    }
+
+    if !bytes.Equal(a.Get(), []byte("three")) {
+        t.Errorf("Expected three, got %s", a.Get())
+    }
+}
+
+func TestLWWRegisterMerge(t *testing.T) {
+    a := NewLWWRegister(&tickClock{})
+    b := NewLWWRegister(&tickClock{})
+
+    a.SetAt("a", []byte("one"), 1)
+    b.SetAt("b", []byte("two"), 2)
+
+    c := a.Clone()
+    c.Merge(b)
+    d := b.Clone()
+    d.Merge(a)
+
+    if !c.Equals(d) {
+        t.Error("Merge should be commutative!")
+    }
+
+    if !bytes.Equal(c.Get(), []byte("two")) {
+        t.Errorf("Expected two, got %s", c.Get())
+    }
+}
+
+func TestLWWRegisterSerialize(t *testing.T) {
+    a := NewLWWRegister(&tickClock{})
+    a.Set("a", []byte("one"))
+
+    buff := new(bytes.Buffer)
+    if e := a.Serialize(buff); e != nil {
+        t.Fatal(e)
+    }
+
+    b := NewLWWRegister(&tickClock{})
+    if e := b.Deserialize(buff); e != nil {
+        t.Fatal(e)
+    }
+
+    if !a.Equals(b) {
+        t.Error("Deserialized register should equal original!")
+    }
+
+    if e := b.Deserialize(bytes.NewBufferString("crdt:gset")); e == nil {
+        t.Error("Expected header error!")
+    }
+}
@@ -0,0 +1,164 @@
+/*
+ * Copyright (c) 2015 Tom Swindell (t.swindell@rubyx.co.uk)
+ *
+ * Permission is hereby granted, free of charge, to any person obtaining a copy
+ * of this software and associated documentation files (the "Software"), to deal
+ * in the Software without restriction, including without limitation the rights
+ * to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+ * copies of the Software, and to permit persons to whom the Software is
+ * furnished to do so, subject to the following conditions:
+ *
+ * The above copyright notice and this permission notice shall be included in
+ * all copies or substantial portions of the Software.
+ *
+ * THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+ * IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+ * FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+ * AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+ * LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+ * OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+ * SOFTWARE.
+ *
+ */
+
+package register
+
+import (
+    "bytes"
+    "encoding/binary"
+    "sync"
+
+    "github.com/tswindell/go-crdt/sets"
+)
+
+// A value paired with the version it was written at.
+type mvEntry struct {
+    value   []byte
+    version set.VectorClock
+}
+
+// Common Go representation of a multi-value register. A write supersedes
+// every value its replica has observed, concurrent writes are all kept and
+// returned by Get() until a later write supersedes them.
+type MVRegister struct {
+    sync.RWMutex
+    entries []mvEntry
+}
+
+func NewMVRegister() *MVRegister {
+    return new(MVRegister)
+}
+
+func (r *MVRegister) Set(replicaId string, value []byte) {
+    r.Lock()
+    defer r.Unlock()
+
+    version := set.NewVectorClock()
+    for _, entry := range r.entries { version.Merge(entry.version) }
+    version.Increment(replicaId)
+
+    r.entries = []mvEntry{{value: value, version: version}}
+}
+
+// The Get() method returns all concurrent values, or an empty slice when
+// the register has never been written.
+func (r *MVRegister) Get() [][]byte {
+    r.RLock()
+    defer r.RUnlock()
+
+    result := make([][]byte, 0, len(r.entries))
+    for _, entry := range r.entries { result = append(result, entry.value) }
+    return result
+}
+
+// The caller must hold the lock.
+func (r *MVRegister) insert(in mvEntry) {
+    entries := make([]mvEntry, 0, len(r.entries) + 1)
+    for _, entry := range r.entries {
+        if entry.version.Descends(in.version) { return }
+        if in.version.Compare(entry.version) != set.CLOCK_AFTER { entries = append(entries, entry) }
+    }
+    r.entries = append(entries, in)
+}
+
+func (r *MVRegister) Equals(other *MVRegister) bool {
+    in := other.Clone()
+
+    r.RLock()
+    defer r.RUnlock()
+
+    if len(r.entries) != len(in.entries) { return false }
+
+    for _, a := range r.entries {
+        found := false
+        for _, b := range in.entries {
+            if a.version.Equals(b.version) && bytes.Equal(a.value, b.value) {
+                found = true
+                break
+            }
+        }
+        if !found { return false }
+    }
+    return true
+}
+
+func (r *MVRegister) Merge(other *MVRegister) {
+    // Snapshot the other register first, so two registers merging into each
+    // other can never deadlock.
+    in := other.Clone()
+
+    r.Lock()
+    defer r.Unlock()
+
+    for _, entry := range in.entries { r.insert(entry) }
+}
+
+func (r *MVRegister) Clone() *MVRegister {
+    r.RLock()
+    defer r.RUnlock()
+
+    result := NewMVRegister()
+    for _, entry := range r.entries {
+        result.entries = append(result.entries, mvEntry{value: entry.value, version: entry.version.Clone()})
+    }
+    return result
+}
+
+var MVREGISTER_HEADER_MAGIC = []byte{'c', 'r', 'd', 't', ':', 'm', 'v', 'r', 'e', 'g', 0x00}
+
+func (r *MVRegister) Serialize(buff *bytes.Buffer) error {
+    r.RLock()
+    defer r.RUnlock()
+
+    buff.Write(MVREGISTER_HEADER_MAGIC)
+    binary.Write(buff, binary.LittleEndian, uint32(len(r.entries)))
+
+    for _, entry := range r.entries {
+        set.WriteValue(buff, entry.value)
+        if e := entry.version.Serialize(buff); e != nil { return e }
+    }
+
+    return nil
+}
+
+// Deserialize merges the serialized state into this register.
+func (r *MVRegister) Deserialize(buff *bytes.Buffer) error {
+    if e := set.ReadHeader(buff, MVREGISTER_HEADER_MAGIC); e != nil { return e }
+
+    var count uint32
+    if e := binary.Read(buff, binary.LittleEndian, &count); e != nil { return e }
+
+    in := NewMVRegister()
+    for i := uint32(0); i < count; i++ {
+        value, e := set.ReadValue(buff)
+        if e != nil { return e }
+
+        version := set.NewVectorClock()
+        if e := version.Deserialize(buff); e != nil { return e }
+
+        in.entries = append(in.entries, mvEntry{value: value, version: version})
+    }
+
+    r.Merge(in)
+    return nil
+}
@@ -0,0 +1,128 @@
+/*
+ * Copyright (c) 2015 Tom Swindell (t.swindell@rubyx.co.uk)
+ *
+ * Permission is hereby granted, free of charge, to any person obtaining a copy
+ * of this software and associated documentation files (the "Software"), to deal
+ * in the Software without restriction, including without limitation the rights
+ * to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+ * copies of the Software, and to permit persons to whom the Software is
+ * furnished to do so, subject to the following conditions:
+ *
+ * The above copyright notice and this permission notice shall be included in
+ * all copies or substantial portions of the Software.
+ *
+ * THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+ * IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+ * FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+ * AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+ * LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+ * OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+ * SOFTWARE.
+ *
+ */
+
+package register
+
+import "testing"
+import "bytes"
+
+func hasValue(values [][]byte, value string) bool {
+    for _, v := range values {
+        if bytes.Equal(v, []byte(value)) { return true }
+    }
+    return false
+}
+
+func TestNewMVRegister(t *testing.T) {
+    a := NewMVRegister()
+    if len(a.Get()) != 0 {
+        t.Error("New register should have no values!")
+    }
+}
+
+func TestMVRegisterSet(t *testing.T) {
+    a := NewMVRegister()
+
+    a.Set("a", []byte("one"))
+    a.Set("a", []byte("two"))
+
+    values := a.Get()
+    if len(values) != 1 || !hasValue(values, "two") {
+        t.Errorf("Expected [two], got %q", values)
+    }
+}
+
+func TestMVRegisterConcurrent(t *testing.T) {
+    a := NewMVRegister()
+    b := NewMVRegister()
+
+    a.Set("a", []byte("one"))
+    b.Set("b", []byte("two"))
+
+    a.Merge(b)
+
+    values := a.Get()
+    if len(values) != 2 || !hasValue(values, "one") || !hasValue(values, "two") {
+        t.Errorf("Expected both concurrent values, got %q", values)
+    }
+
+    // A write after observing both supersedes them.
+    a.Set("a", []byte("three"))
+    b.Merge(a)
+
+    values = b.Get()
+    if len(values) != 1 || !hasValue(values, "three") {
+        t.Errorf("Expected [three], got %q", values)
+    }
+}
+
+func TestMVRegisterMerge(t *testing.T) {
+    a := NewMVRegister()
+    b := NewMVRegister()
+
+    a.Set("a", []byte("one"))
+    b.Merge(a)
+    b.Set("b", []byte("two"))
+
+    c := a.Clone()
+    c.Merge(b)
+    d := b.Clone()
+    d.Merge(a)
+
+    if !c.Equals(d) {
+        t.Error("Merge should be commutative!")
+    }
+
+    values := c.Get()
+    if len(values) != 1 || !hasValue(values, "two") {
+        t.Errorf("Expected [two], got %q", values)
+    }
+
+    c.Merge(c.Clone())
+    if !c.Equals(d) {
+        t.Error("Merge should be idempotent!")
+    }
+}
+
+func TestMVRegisterSerialize(t *testing.T) {
+    a := NewMVRegister()
+    b := NewMVRegister()
+
+    a.Set("a", []byte("one"))
+    b.Set("b", []byte("two"))
+    a.Merge(b)
+
+    buff := new(bytes.Buffer)
+    if e := a.Serialize(buff); e != nil {
+        t.Fatal(e)
+    }
+
+    c := NewMVRegister()
+    if e := c.Deserialize(buff); e != nil {
+        t.Fatal(e)
+    }
+
+    if !a.Equals(c) {
+        t.Error("Deserialized register should equal original!")
+    }
+}
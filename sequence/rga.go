@@ -0,0 +1,321 @@
+/*
+ * Copyright (c) 2015 Tom Swindell (t.swindell@rubyx.co.uk)
+ *
+ * Permission is hereby granted, free of charge, to any person obtaining a copy
+ * of this software and associated documentation files (the "Software"), to deal
+ * in the Software without restriction, including without limitation the rights
+ * to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+ * copies of the Software, and to permit persons to whom the Software is
+ * furnished to do so, subject to the following conditions:
+ *
+ * The above copyright notice and this permission notice shall be included in
+ * all copies or substantial portions of the Software.
+ *
+ * THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+ * IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+ * FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+ * AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+ * LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+ * OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+ * SOFTWARE.
+ *
+ */
+
+package sequence
+
+import (
+    "bytes"
+    "encoding/binary"
+    "fmt"
+    "sync"
+
+    "github.com/tswindell/go-crdt/sets"
+)
+
+// The Id type uniquely identifies an element of a sequence, by a Lamport
+// sequence number and the replica that inserted it.
+type Id struct {
+    Seq       uint64
+    ReplicaId string
+}
+
+// The zero Id refers to the head of the sequence.
+var HEAD = Id{}
+
+func (a Id) Less(b Id) bool {
+    return a.Seq < b.Seq || (a.Seq == b.Seq && a.ReplicaId < b.ReplicaId)
+}
+
+type element struct {
+    id      Id
+    origin  Id // Element this was inserted after.
+    value   []byte
+    deleted bool
+}
+
+// Common Go representation of a replicated growable array. Each element
+// remembers the element it was inserted after, and concurrent inserts after
+// the same element are ordered by Id, newest first. Deleted elements are kept
+// as tombstones so later inserts can still find their origin.
+type RGA struct {
+    sync.RWMutex
+    seq      uint64 // Highest sequence number observed.
+    elements []*element
+    index    map[Id]*element
+}
+
+func NewRGA() *RGA {
+    s := new(RGA)
+    s.index = make(map[Id]*element)
+    return s
+}
+
+// Returns the offset of id within elements, -1 for HEAD.
+// The caller must hold the lock.
+func (s *RGA) offsetOf(id Id) int {
+    if id == HEAD { return -1 }
+
+    for i, e := range s.elements {
+        if e.id == id { return i }
+    }
+    return -1
+}
+
+// Returns the offset within elements of the visible element at pos, or the
+// length of elements when pos is one past the last visible element.
+// The caller must hold the lock.
+func (s *RGA) offsetAt(pos int) int {
+    for i, e := range s.elements {
+        if e.deleted { continue }
+        if pos == 0 { return i }
+        pos--
+    }
+    return len(s.elements)
+}
+
+// Inserts e after its origin, skipping past any newer elements inserted
+// after the same origin. Returns false if e is already known, or its origin
+// is not. The caller must hold the lock.
+func (s *RGA) integrate(e *element) bool {
+    if _, found := s.index[e.id]; found { return false }
+    if _, found := s.index[e.origin]; !found && e.origin != HEAD { return false }
+
+    i := s.offsetOf(e.origin) + 1
+    for i < len(s.elements) && e.id.Less(s.elements[i].id) { i++ }
+
+    s.elements = append(s.elements, nil)
+    copy(s.elements[i + 1:], s.elements[i:])
+    s.elements[i] = e
+    s.index[e.id] = e
+
+    if e.id.Seq > s.seq { s.seq = e.id.Seq }
+    return true
+}
+
+// The caller must hold the lock.
+func (s *RGA) length() int {
+    length := 0
+    for _, e := range s.elements {
+        if !e.deleted { length++ }
+    }
+    return length
+}
+
+// The InsertAt() method inserts values consecutively, starting at pos. Returns
+// false if pos is beyond the end of the sequence.
+func (s *RGA) InsertAt(replicaId string, pos int, values ...[]byte) bool {
+    s.Lock()
+    defer s.Unlock()
+
+    if pos < 0 || pos > s.length() { return false }
+
+    origin := HEAD
+    if pos > 0 { origin = s.elements[s.offsetAt(pos - 1)].id }
+
+    for _, value := range values {
+        e := &element{id: Id{s.seq + 1, replicaId}, origin: origin, value: value}
+        s.integrate(e)
+        origin = e.id
+    }
+    return true
+}
+
+// The DeleteAt() method deletes count elements starting at pos, returning
+// their values. Returns false if the range is beyond the end of the sequence.
+func (s *RGA) DeleteAt(pos int, count int) ([][]byte, bool) {
+    s.Lock()
+    defer s.Unlock()
+
+    if pos < 0 || count < 0 || pos + count > s.length() { return nil, false }
+
+    results := make([][]byte, 0, count)
+    for i := s.offsetAt(pos); len(results) < count; i++ {
+        e := s.elements[i]
+        if e.deleted { continue }
+
+        e.deleted = true
+        results = append(results, e.value)
+    }
+    return results, true
+}
+
+func (s *RGA) Length() int {
+    s.RLock()
+    defer s.RUnlock()
+
+    return s.length()
+}
+
+// The Slice() method returns the values from start up to, but not including,
+// end. Returns false if the range is invalid.
+func (s *RGA) Slice(start, end int) ([][]byte, bool) {
+    s.RLock()
+    defer s.RUnlock()
+
+    if start < 0 || end < start || end > s.length() { return nil, false }
+
+    results := make([][]byte, 0, end - start)
+    for i := s.offsetAt(start); len(results) < end - start; i++ {
+        if !s.elements[i].deleted { results = append(results, s.elements[i].value) }
+    }
+    return results, true
+}
+
+func (s *RGA) ToSlice() [][]byte {
+    s.RLock()
+    defer s.RUnlock()
+
+    results := make([][]byte, 0, len(s.elements))
+    for _, e := range s.elements {
+        if !e.deleted { results = append(results, e.value) }
+    }
+    return results
+}
+
+// The Text() method returns the concatenation of all values.
+func (s *RGA) Text() string {
+    return string(bytes.Join(s.ToSlice(), nil))
+}
+
+func (s *RGA) Equals(other *RGA) bool {
+    in := other.Clone()
+
+    s.RLock()
+    defer s.RUnlock()
+
+    if len(s.elements) != len(in.elements) { return false }
+
+    for i, a := range s.elements {
+        b := in.elements[i]
+        if a.id != b.id || a.deleted != b.deleted || !bytes.Equal(a.value, b.value) { return false }
+    }
+    return true
+}
+
+// The caller must hold the lock.
+func (s *RGA) merge(in *RGA) error {
+    // Origins always precede the elements inserted after them, so elements
+    // can be integrated in order.
+    for _, e := range in.elements {
+        if current, found := s.index[e.id]; found {
+            if e.deleted { current.deleted = true }
+            continue
+        }
+
+        c := *e
+        if !s.integrate(&c) { return fmt.Errorf("unknown origin") }
+    }
+    return nil
+}
+
+func (s *RGA) Merge(other *RGA) {
+    // Snapshot the other sequence first, so two sequences merging into each
+    // other can never deadlock.
+    in := other.Clone()
+
+    s.Lock()
+    defer s.Unlock()
+
+    s.merge(in)
+}
+
+func (s *RGA) Clone() *RGA {
+    s.RLock()
+    defer s.RUnlock()
+
+    result := NewRGA()
+    result.seq = s.seq
+    for _, e := range s.elements {
+        c := *e
+        result.elements = append(result.elements, &c)
+        result.index[c.id] = &c
+    }
+    return result
+}
+
+var RGA_HEADER_MAGIC = []byte{'c', 'r', 'd', 't', ':', 'r', 'g', 'a', 0x00}
+
+func writeId(buff *bytes.Buffer, id Id) error {
+    binary.Write(buff, binary.LittleEndian, id.Seq)
+    return set.WriteString(buff, id.ReplicaId)
+}
+
+func readId(buff *bytes.Buffer) (Id, error) {
+    var id Id
+    if e := binary.Read(buff, binary.LittleEndian, &id.Seq); e != nil { return id, e }
+
+    var e error
+    id.ReplicaId, e = set.ReadString(buff)
+    return id, e
+}
+
+func (s *RGA) Serialize(buff *bytes.Buffer) error {
+    s.RLock()
+    defer s.RUnlock()
+
+    buff.Write(RGA_HEADER_MAGIC)
+    binary.Write(buff, binary.LittleEndian, uint32(len(s.elements)))
+
+    for _, e := range s.elements {
+        if e := writeId(buff, e.id); e != nil { return e }
+        if e := writeId(buff, e.origin); e != nil { return e }
+
+        deleted := byte(0)
+        if e.deleted { deleted = 1 }
+        buff.WriteByte(deleted)
+
+        set.WriteValue(buff, e.value)
+    }
+
+    return nil
+}
+
+// Deserialize merges the serialized state into this sequence.
+func (s *RGA) Deserialize(buff *bytes.Buffer) error {
+    if e := set.ReadHeader(buff, RGA_HEADER_MAGIC); e != nil { return e }
+
+    var count uint32
+    if e := binary.Read(buff, binary.LittleEndian, &count); e != nil { return e }
+
+    in := NewRGA()
+    for i := uint32(0); i < count; i++ {
+        var e error
+        c := new(element)
+
+        if c.id, e = readId(buff); e != nil { return e }
+        if c.origin, e = readId(buff); e != nil { return e }
+
+        deleted, e := buff.ReadByte()
+        if e != nil { return e }
+        c.deleted = deleted != 0
+
+        if c.value, e = set.ReadValue(buff); e != nil { return e }
+
+        if c.id == HEAD || !in.integrate(c) { return fmt.Errorf("invalid format") }
+    }
+
+    s.Lock()
+    defer s.Unlock()
+
+    return s.merge(in)
+}
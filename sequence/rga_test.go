@@ -0,0 +1,163 @@
+/*
+ * Copyright (c) 2015 Tom Swindell (t.swindell@rubyx.co.uk)
+ *
+ * Permission is hereby granted, free of charge, to any person obtaining a copy
+ * of this software and associated documentation files (the "Software"), to deal
+ * in the Software without restriction, including without limitation the rights
+ * to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+ * copies of the Software, and to permit persons to whom the Software is
+ * furnished to do so, subject to the following conditions:
+ *
+ * The above copyright notice and this permission notice shall be included in
+ * all copies or substantial portions of the Software.
+ *
+ * THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+ * IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+ * FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+ * AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+ * LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+ * OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+ * SOFTWARE.
+ *
+ */
+
+package sequence
+
+import "testing"
+import "bytes"
+
+func TestNewRGA(t *testing.T) {
+    a := NewRGA()
+    if a.Length() != 0 || a.Text() != "" {
+        t.Error("New sequence should be empty!")
+    }
+}
+
+func TestRGAInsertAt(t *testing.T) {
+    a := NewRGA()
+
+    a.InsertAt("a", 0, []byte("h"), []byte("o"))
+    a.InsertAt("a", 1, []byte("ell"))
+    a.InsertAt("a", 3, []byte("!"))
+
+    if a.Text() != "hello!" {
+        t.Errorf("Expected hello!, got %s", a.Text())
+    }
+
+    if a.InsertAt("a", 5, []byte("?")) {
+        t.Error("Insert beyond end of sequence should fail!")
+    }
+}
+
+func TestRGADeleteAt(t *testing.T) {
+    a := NewRGA()
+    a.InsertAt("a", 0, []byte("a"), []byte("b"), []byte("c"), []byte("d"))
+
+    values, ok := a.DeleteAt(1, 2)
+    if !ok || len(values) != 2 || string(values[0]) != "b" || string(values[1]) != "c" {
+        t.Errorf("Unexpected deleted values: %q", values)
+    }
+
+    if a.Text() != "ad" {
+        t.Errorf("Expected ad, got %s", a.Text())
+    }
+
+    if _, ok := a.DeleteAt(1, 2); ok {
+        t.Error("Delete beyond end of sequence should fail!")
+    }
+
+    // Inserting after a deleted element's neighbour still works.
+    a.InsertAt("a", 1, []byte("x"))
+    if a.Text() != "axd" {
+        t.Errorf("Expected axd, got %s", a.Text())
+    }
+}
+
+func TestRGASlice(t *testing.T) {
+    a := NewRGA()
+    a.InsertAt("a", 0, []byte("a"), []byte("b"), []byte("c"))
+    a.DeleteAt(0, 1)
+
+    values, ok := a.Slice(1, 2)
+    if !ok || len(values) != 1 || string(values[0]) != "c" {
+        t.Errorf("Unexpected slice: %q", values)
+    }
+
+    if _, ok := a.Slice(1, 3); ok {
+        t.Error("Slice beyond end of sequence should fail!")
+    }
+}
+
+func TestRGAConcurrentInsert(t *testing.T) {
+    a := NewRGA()
+    a.InsertAt("a", 0, []byte("a"), []byte("c"))
+
+    b := a.Clone()
+
+    a.InsertAt("a", 1, []byte("1"))
+    b.InsertAt("b", 1, []byte("2"))
+    b.InsertAt("b", 2, []byte("3"))
+
+    c := a.Clone()
+    c.Merge(b)
+    d := b.Clone()
+    d.Merge(a)
+
+    if !c.Equals(d) || c.Text() != d.Text() {
+        t.Errorf("Merge should be commutative! %s != %s", c.Text(), d.Text())
+    }
+
+    if c.Text() != "a231c" {
+        t.Errorf("Expected a231c, got %s", c.Text())
+    }
+
+    c.Merge(c.Clone())
+    if !c.Equals(d) {
+        t.Error("Merge should be idempotent!")
+    }
+}
+
+func TestRGAConcurrentDelete(t *testing.T) {
+    a := NewRGA()
+    a.InsertAt("a", 0, []byte("a"), []byte("b"), []byte("c"))
+
+    b := a.Clone()
+
+    a.DeleteAt(1, 1)
+    b.InsertAt("b", 2, []byte("x"))
+
+    a.Merge(b)
+    b.Merge(a)
+
+    if a.Text() != "axc" || !a.Equals(b) {
+        t.Errorf("Expected axc, got %s and %s", a.Text(), b.Text())
+    }
+}
+
+func TestRGASerialize(t *testing.T) {
+    a := NewRGA()
+    b := NewRGA()
+
+    a.InsertAt("a", 0, []byte("a"), []byte("b"))
+    b.InsertAt("b", 0, []byte("c"))
+    a.Merge(b)
+    a.DeleteAt(0, 1)
+
+    buff := new(bytes.Buffer)
+    if e := a.Serialize(buff); e != nil {
+        t.Fatal(e)
+    }
+
+    c := NewRGA()
+    if e := c.Deserialize(buff); e != nil {
+        t.Fatal(e)
+    }
+
+    if !a.Equals(c) {
+        t.Errorf("Deserialized sequence should equal original! %s != %s", a.Text(), c.Text())
+    }
+
+    if e := c.Deserialize(bytes.NewBufferString("crdt:gset")); e == nil {
+        t.Error("Expected header error!")
+    }
+}
@@ -0,0 +1,112 @@
+/*
+ * Copyright (c) 2015 Tom Swindell (t.swindell@rubyx.co.uk)
+ *
+ * Permission is hereby granted, free of charge, to any person obtaining a copy
+ * of this software and associated documentation files (the "Software"), to deal
+ * in the Software without restriction, including without limitation the rights
+ * to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+ * copies of the Software, and to permit persons to whom the Software is
+ * furnished to do so, subject to the following conditions:
+ *
+ * The above copyright notice and this permission notice shall be included in
+ * all copies or substantial portions of the Software.
+ *
+ * THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+ * IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+ * FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+ * AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+ * LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+ * OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+ * SOFTWARE.
+ *
+ */
+
+package set
+
+import "iter"
+
+// The Readable interface is all the set algebra needs of an operand, and is
+// implemented by every set type of this package. Operands need not be of the
+// same type, so an ORSet may be compared against a GSet and so on.
+type Readable[T comparable] interface {
+    Contains(T) bool
+    Iterate() iter.Seq[T]
+}
+
+// The Union function returns a view of the elements in either a or b. Each
+// element is yielded once, a snapshot of a followed by those elements of a
+// snapshot of b not already yielded.
+func Union[T comparable](a, b Readable[T]) iter.Seq[T] {
+    return func(yield func(T) bool) {
+        seen := make(map[T]struct{})
+        for i := range a.Iterate() {
+            seen[i] = struct{}{}
+            if !yield(i) { return }
+        }
+        for i := range b.Iterate() {
+            if _, found := seen[i]; found { continue }
+            if !yield(i) { return }
+        }
+    }
+}
+
+// The Intersect function returns a view of the elements of a snapshot of a
+// which b contains at the time each of them is reached.
+func Intersect[T comparable](a, b Readable[T]) iter.Seq[T] {
+    return func(yield func(T) bool) {
+        for i := range a.Iterate() {
+            if !b.Contains(i) { continue }
+            if !yield(i) { return }
+        }
+    }
+}
+
+// The Difference function returns a view of the elements of a snapshot of a
+// which b does not contain at the time each of them is reached.
+func Difference[T comparable](a, b Readable[T]) iter.Seq[T] {
+    return func(yield func(T) bool) {
+        for i := range a.Iterate() {
+            if b.Contains(i) { continue }
+            if !yield(i) { return }
+        }
+    }
+}
+
+// The IsSubset function returns true if b contains every element of a.
+func IsSubset[T comparable](a, b Readable[T]) bool {
+    for i := range a.Iterate() {
+        if !b.Contains(i) { return false }
+    }
+    return true
+}
+
+
+func (s Set) Union(other Readable[interface{}]) iter.Seq[interface{}] { return Union[interface{}](s, other) }
+func (s Set) Intersect(other Readable[interface{}]) iter.Seq[interface{}] { return Intersect[interface{}](s, other) }
+func (s Set) Difference(other Readable[interface{}]) iter.Seq[interface{}] { return Difference[interface{}](s, other) }
+func (s Set) IsSubset(other Readable[interface{}]) bool { return IsSubset[interface{}](s, other) }
+
+func (s *GSet[T]) Union(other Readable[T]) iter.Seq[T] { return Union[T](s, other) }
+func (s *GSet[T]) Intersect(other Readable[T]) iter.Seq[T] { return Intersect[T](s, other) }
+func (s *GSet[T]) Difference(other Readable[T]) iter.Seq[T] { return Difference[T](s, other) }
+func (s *GSet[T]) IsSubset(other Readable[T]) bool { return IsSubset[T](s, other) }
+
+func (s *TwoPhase[T]) Union(other Readable[T]) iter.Seq[T] { return Union[T](s, other) }
+func (s *TwoPhase[T]) Intersect(other Readable[T]) iter.Seq[T] { return Intersect[T](s, other) }
+func (s *TwoPhase[T]) Difference(other Readable[T]) iter.Seq[T] { return Difference[T](s, other) }
+func (s *TwoPhase[T]) IsSubset(other Readable[T]) bool { return IsSubset[T](s, other) }
+
+func (s *ORSet[T]) Union(other Readable[T]) iter.Seq[T] { return Union[T](s, other) }
+func (s *ORSet[T]) Intersect(other Readable[T]) iter.Seq[T] { return Intersect[T](s, other) }
+func (s *ORSet[T]) Difference(other Readable[T]) iter.Seq[T] { return Difference[T](s, other) }
+func (s *ORSet[T]) IsSubset(other Readable[T]) bool { return IsSubset[T](s, other) }
+
+func (s *LWWSet[T]) Union(other Readable[T]) iter.Seq[T] { return Union[T](s, other) }
+func (s *LWWSet[T]) Intersect(other Readable[T]) iter.Seq[T] { return Intersect[T](s, other) }
+func (s *LWWSet[T]) Difference(other Readable[T]) iter.Seq[T] { return Difference[T](s, other) }
+func (s *LWWSet[T]) IsSubset(other Readable[T]) bool { return IsSubset[T](s, other) }
+
+func (s *DiskGSet[T]) Union(other Readable[T]) iter.Seq[T] { return Union[T](s, other) }
+func (s *DiskGSet[T]) Intersect(other Readable[T]) iter.Seq[T] { return Intersect[T](s, other) }
+func (s *DiskGSet[T]) Difference(other Readable[T]) iter.Seq[T] { return Difference[T](s, other) }
+func (s *DiskGSet[T]) IsSubset(other Readable[T]) bool { return IsSubset[T](s, other) }
@@ -0,0 +1,111 @@
+/*
+ * Copyright (c) 2015 Tom Swindell (t.swindell@rubyx.co.uk)
+ *
+ * Permission is hereby granted, free of charge, to any person obtaining a copy
+ * of this software and associated documentation files (the "Software"), to deal
+ * in the Software without restriction, including without limitation the rights
+ * to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+ * copies of the Software, and to permit persons to whom the Software is
+ * furnished to do so, subject to the following conditions:
+ *
+ * The above copyright notice and this permission notice shall be included in
+ * all copies or substantial portions of the Software.
+ *
+ * THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+ * IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+ * FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+ * AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+ * LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+ * OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+ * SOFTWARE.
+ *
+ */
+
+package set
+
+import "testing"
+import "sort"
+
+func sortedInts(seq func(func(int) bool)) []int {
+    var result []int
+    for i := range seq { result = append(result, i) }
+    sort.Ints(result)
+    return result
+}
+
+func equalInts(a, b []int) bool {
+    if len(a) != len(b) { return false }
+    for i := range a {
+        if a[i] != b[i] { return false }
+    }
+    return true
+}
+
+func TestSetAlgebra(t *testing.T) {
+    a := NewGSet[int]()
+    b := NewORSet[int]()
+
+    for i := 1; i <= 6; i++ { a.Insert(i) }
+    for i := 4; i <= 9; i++ { b.Insert(i) }
+    b.Remove(9)
+
+    if r := sortedInts(a.Union(b)); !equalInts(r, []int{1, 2, 3, 4, 5, 6, 7, 8}) {
+        t.Errorf("Unexpected union %v", r)
+    }
+
+    if r := sortedInts(a.Intersect(b)); !equalInts(r, []int{4, 5, 6}) {
+        t.Errorf("Unexpected intersection %v", r)
+    }
+
+    if r := sortedInts(a.Difference(b)); !equalInts(r, []int{1, 2, 3}) {
+        t.Errorf("Unexpected difference %v", r)
+    }
+
+    if r := sortedInts(b.Difference(a)); !equalInts(r, []int{7, 8}) {
+        t.Errorf("Unexpected difference %v", r)
+    }
+}
+
+func TestSetAlgebraIsSubset(t *testing.T) {
+    a := New2P[int]()
+    b := NewLWWSet[int](&tickClock{}, LWW_ADD_WINS)
+
+    for i := 1; i <= 3; i++ { a.Insert(i) }
+    for i := 1; i <= 5; i++ { b.Insert(i) }
+
+    if !a.IsSubset(b) || b.IsSubset(a) {
+        t.Error("Expected a to be a proper subset of b!")
+    }
+
+    b.Remove(2)
+
+    if a.IsSubset(b) {
+        t.Error("a should not be a subset of b after removing 2 from b!")
+    }
+
+    a.Remove(2)
+
+    if !a.IsSubset(b) {
+        t.Error("a should be a subset of b after removing 2 from both!")
+    }
+
+    if !New2P[int]().IsSubset(a) {
+        t.Error("The empty set should be a subset of any set!")
+    }
+}
+
+func TestSetAlgebraEarlyExit(t *testing.T) {
+    a := Set{}
+    b := Set{}
+
+    for i := 0; i < 10; i++ { a.Insert(i); b.Insert(i + 10) }
+
+    n := 0
+    for range a.Union(b) {
+        if n++; n == 15 { break }
+    }
+
+    if n != 15 {
+        t.Errorf("Expected to stop after 15 elements, got %d", n)
+    }
+}
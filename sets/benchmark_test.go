@@ -0,0 +1,241 @@
+/*
+ * Copyright (c) 2015 Tom Swindell (t.swindell@rubyx.co.uk)
+ *
+ * Permission is hereby granted, free of charge, to any person obtaining a copy
+ * of this software and associated documentation files (the "Software"), to deal
+ * in the Software without restriction, including without limitation the rights
+ * to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+ * copies of the Software, and to permit persons to whom the Software is
+ * furnished to do so, subject to the following conditions:
+ *
+ * The above copyright notice and this permission notice shall be included in
+ * all copies or substantial portions of the Software.
+ *
+ * THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+ * IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+ * FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+ * AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+ * LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+ * OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+ * SOFTWARE.
+ *
+ */
+
+package set
+
+import "testing"
+import "bytes"
+import "encoding/base64"
+import "fmt"
+import "io"
+import "math/rand"
+import "runtime"
+import "sync"
+
+// Benchmarks compare sets of untyped, base64 encoded elements, as SetResource
+// used to hold, with sets of raw strings.
+
+const BENCHMARK_SET_SIZE = 10000
+
+func benchmarkElements() [][]byte {
+    result := make([][]byte, BENCHMARK_SET_SIZE)
+    for i := range result {
+        result[i] = []byte(fmt.Sprintf("element-%08d", i))
+    }
+    return result
+}
+
+func BenchmarkGSetInsertBase64(b *testing.B) {
+    elements := benchmarkElements()
+    b.ReportAllocs()
+
+    for n := 0; n < b.N; n++ {
+        s := NewGSet[interface{}]()
+        for _, e := range elements { s.Insert(base64.StdEncoding.EncodeToString(e)) }
+    }
+}
+
+func BenchmarkGSetInsertString(b *testing.B) {
+    elements := benchmarkElements()
+    b.ReportAllocs()
+
+    for n := 0; n < b.N; n++ {
+        s := NewGSet[string]()
+        for _, e := range elements { s.Insert(string(e)) }
+    }
+}
+
+func BenchmarkGSetContainsBase64(b *testing.B) {
+    elements := benchmarkElements()
+    s := NewGSet[interface{}]()
+    for _, e := range elements { s.Insert(base64.StdEncoding.EncodeToString(e)) }
+
+    b.ReportAllocs()
+    b.ResetTimer()
+
+    for n := 0; n < b.N; n++ {
+        s.Contains(base64.StdEncoding.EncodeToString(elements[n % len(elements)]))
+    }
+}
+
+func BenchmarkGSetContainsString(b *testing.B) {
+    elements := benchmarkElements()
+    s := NewGSet[string]()
+    for _, e := range elements { s.Insert(string(e)) }
+
+    b.ReportAllocs()
+    b.ResetTimer()
+
+    for n := 0; n < b.N; n++ {
+        s.Contains(string(elements[n % len(elements)]))
+    }
+}
+
+func BenchmarkGSetSerializeBase64(b *testing.B) {
+    s := NewGSet[interface{}]()
+    for _, e := range benchmarkElements() { s.Insert(base64.StdEncoding.EncodeToString(e)) }
+
+    b.ReportAllocs()
+    b.ResetTimer()
+
+    for n := 0; n < b.N; n++ {
+        s.Serialize(new(bytes.Buffer))
+    }
+}
+
+func BenchmarkGSetSerializeString(b *testing.B) {
+    s := NewGSet[string]()
+    for _, e := range benchmarkElements() { s.Insert(string(e)) }
+
+    b.ReportAllocs()
+    b.ResetTimer()
+
+    for n := 0; n < b.N; n++ {
+        s.Serialize(new(bytes.Buffer))
+    }
+}
+
+func BenchmarkGSetDeserializeBase64(b *testing.B) {
+    s := NewGSet[interface{}]()
+    for _, e := range benchmarkElements() { s.Insert(base64.StdEncoding.EncodeToString(e)) }
+
+    buff := new(bytes.Buffer)
+    s.Serialize(buff)
+    data := buff.Bytes()
+
+    b.ReportAllocs()
+    b.ResetTimer()
+
+    for n := 0; n < b.N; n++ {
+        NewGSet[interface{}]().Deserialize(bytes.NewBuffer(data))
+    }
+}
+
+func BenchmarkGSetDeserializeString(b *testing.B) {
+    s := NewGSet[string]()
+    for _, e := range benchmarkElements() { s.Insert(string(e)) }
+
+    buff := new(bytes.Buffer)
+    s.Serialize(buff)
+    data := buff.Bytes()
+
+    b.ReportAllocs()
+    b.ResetTimer()
+
+    for n := 0; n < b.N; n++ {
+        NewGSet[string]().Deserialize(bytes.NewBuffer(data))
+    }
+}
+
+
+// Benchmarks of disk-backed sets hold BENCHMARK_LARGE_SET_SIZE elements, and
+// need a few GB of disk in the temporary directory. The set is built once and
+// shared, taking a few minutes; -short skips them.
+
+const BENCHMARK_LARGE_SET_SIZE = 10000000
+
+var largeSet struct {
+    sync.Once
+    set *DiskGSet[string]
+}
+
+func largeElement(i int) string { return fmt.Sprintf("element-%08d", i) }
+
+// Fills a disk-backed set in dir, reporting the heap in use once it is full.
+func buildLargeSet(b *testing.B, dir string) *DiskGSet[string] {
+    s := NewDiskGSet[string](dir)
+    for i := 0; i < BENCHMARK_LARGE_SET_SIZE; i++ { s.Insert(largeElement(i)) }
+    if e := s.Err(); e != nil { b.Fatal(e) }
+
+    var stats runtime.MemStats
+    runtime.GC()
+    runtime.ReadMemStats(&stats)
+    b.ReportMetric(float64(stats.HeapInuse) / (1 << 20), "heap-MB")
+
+    return s
+}
+
+func sharedLargeSet(b *testing.B) *DiskGSet[string] {
+    if testing.Short() { b.Skip("skipping large set benchmark in short mode") }
+
+    largeSet.Do(func() { largeSet.set = buildLargeSet(b, b.TempDir()) })
+    if largeSet.set == nil { b.Fatal("failed to build large set") }
+
+    return largeSet.set
+}
+
+func BenchmarkDiskGSetInsert10M(b *testing.B) {
+    if testing.Short() { b.Skip("skipping large set benchmark in short mode") }
+
+    for n := 0; n < b.N; n++ { buildLargeSet(b, b.TempDir()).Close() }
+
+    b.ReportMetric(float64(b.Elapsed().Nanoseconds()) / float64(b.N * BENCHMARK_LARGE_SET_SIZE), "ns/element")
+}
+
+func BenchmarkDiskGSetContains10M(b *testing.B) {
+    s := sharedLargeSet(b)
+    r := rand.New(rand.NewSource(1))
+
+    b.ReportAllocs()
+    b.ResetTimer()
+
+    for n := 0; n < b.N; n++ {
+        if !s.Contains(largeElement(r.Intn(BENCHMARK_LARGE_SET_SIZE))) { b.Fatal("missing element") }
+    }
+}
+
+func BenchmarkDiskGSetContainsMissing10M(b *testing.B) {
+    s := sharedLargeSet(b)
+    r := rand.New(rand.NewSource(1))
+
+    b.ReportAllocs()
+    b.ResetTimer()
+
+    for n := 0; n < b.N; n++ {
+        if s.Contains(largeElement(BENCHMARK_LARGE_SET_SIZE + r.Intn(BENCHMARK_LARGE_SET_SIZE))) { b.Fatal("unexpected element") }
+    }
+}
+
+func BenchmarkDiskGSetIterate10M(b *testing.B) {
+    s := sharedLargeSet(b)
+
+    b.ReportAllocs()
+    b.ResetTimer()
+
+    for n := 0; n < b.N; n++ {
+        count := 0
+        for range s.Iterate() { count++ }
+        if count != BENCHMARK_LARGE_SET_SIZE { b.Fatalf("iterated %d elements", count) }
+    }
+}
+
+func BenchmarkDiskGSetSerialize10M(b *testing.B) {
+    s := sharedLargeSet(b)
+
+    b.ReportAllocs()
+    b.ResetTimer()
+
+    for n := 0; n < b.N; n++ {
+        if e := s.Serialize(io.Discard); e != nil { b.Fatal(e) }
+    }
+}
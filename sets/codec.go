@@ -0,0 +1,67 @@
+/*
+ * Copyright (c) 2015 Tom Swindell (t.swindell@rubyx.co.uk)
+ *
+ * Permission is hereby granted, free of charge, to any person obtaining a copy
+ * of this software and associated documentation files (the "Software"), to deal
+ * in the Software without restriction, including without limitation the rights
+ * to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+ * copies of the Software, and to permit persons to whom the Software is
+ * furnished to do so, subject to the following conditions:
+ *
+ * The above copyright notice and this permission notice shall be included in
+ * all copies or substantial portions of the Software.
+ *
+ * THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+ * IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+ * FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+ * AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+ * LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+ * OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+ * SOFTWARE.
+ *
+ */
+
+package set
+
+import (
+    "encoding/base64"
+    "fmt"
+)
+
+// The Codec interface converts set elements to and from the raw bytes written
+// for each element of a serialized set.
+type Codec[T comparable] interface {
+    Encode(item T) ([]byte, error)
+    Decode(data []byte) (T, error)
+}
+
+// The StringCodec type stores string elements as their raw bytes.
+type StringCodec struct{}
+
+func (StringCodec) Encode(item string) ([]byte, error) { return []byte(item), nil }
+func (StringCodec) Decode(data []byte) (string, error) { return string(data), nil }
+
+// The Base64Codec type stores untyped elements holding base64 encoded strings,
+// the representation used by sets before they were typed, as the bytes they
+// encode. Sets using either codec serialize identically.
+type Base64Codec struct{}
+
+func (Base64Codec) Encode(item interface{}) ([]byte, error) {
+    s, ok := item.(string)
+    if !ok { return nil, fmt.Errorf("element is not a base64 string") }
+
+    return base64.StdEncoding.DecodeString(s)
+}
+
+func (Base64Codec) Decode(data []byte) (interface{}, error) {
+    return base64.StdEncoding.EncodeToString(data), nil
+}
+
+// The DefaultCodec function returns StringCodec for string elements and
+// Base64Codec for untyped elements. Other element types have no default, and
+// sets of them can only be serialized when created with a codec.
+func DefaultCodec[T comparable]() Codec[T] {
+    if codec, ok := interface{}(StringCodec{}).(Codec[T]); ok { return codec }
+    if codec, ok := interface{}(Base64Codec{}).(Codec[T]); ok { return codec }
+    return nil
+}
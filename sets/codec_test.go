@@ -0,0 +1,123 @@
+/*
+ * Copyright (c) 2015 Tom Swindell (t.swindell@rubyx.co.uk)
+ *
+ * Permission is hereby granted, free of charge, to any person obtaining a copy
+ * of this software and associated documentation files (the "Software"), to deal
+ * in the Software without restriction, including without limitation the rights
+ * to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+ * copies of the Software, and to permit persons to whom the Software is
+ * furnished to do so, subject to the following conditions:
+ *
+ * The above copyright notice and this permission notice shall be included in
+ * all copies or substantial portions of the Software.
+ *
+ * THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+ * IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+ * FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+ * AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+ * LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+ * OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+ * SOFTWARE.
+ *
+ */
+
+package set
+
+import "testing"
+import "bytes"
+import "encoding/binary"
+import "fmt"
+
+// Codec storing uint32 elements as 4 little endian bytes.
+type uint32Codec struct{}
+
+func (uint32Codec) Encode(item uint32) ([]byte, error) {
+    data := make([]byte, 4)
+    binary.LittleEndian.PutUint32(data, item)
+    return data, nil
+}
+
+func (uint32Codec) Decode(data []byte) (uint32, error) {
+    if len(data) != 4 { return 0, fmt.Errorf("invalid length") }
+    return binary.LittleEndian.Uint32(data), nil
+}
+
+func TestDefaultCodec(t *testing.T) {
+    if _, ok := DefaultCodec[string]().(StringCodec); !ok {
+        t.Error("Expected StringCodec for string elements!")
+    }
+
+    if _, ok := DefaultCodec[interface{}]().(Base64Codec); !ok {
+        t.Error("Expected Base64Codec for untyped elements!")
+    }
+
+    if DefaultCodec[uint32]() != nil {
+        t.Error("Expected no default codec for uint32 elements!")
+    }
+}
+
+func TestCodecCompatibility(t *testing.T) {
+    a := NewGSet[interface{}]()
+    b := NewGSet[string]()
+
+    a.Insert(b64("hello"))
+    b.Insert("hello")
+
+    abuff := new(bytes.Buffer)
+    bbuff := new(bytes.Buffer)
+    a.Serialize(abuff)
+    b.Serialize(bbuff)
+
+    if !bytes.Equal(abuff.Bytes(), bbuff.Bytes()) {
+        t.Fatal("Base64 and string sets should serialize identically!")
+    }
+
+    c := New2P[string]()
+    if e := c.added.Deserialize(abuff); e != nil {
+        t.Fatal(e)
+    }
+
+    if !c.Contains("hello") {
+        t.Error("String set should read untyped set data!")
+    }
+}
+
+func TestCustomCodec(t *testing.T) {
+    a := NewORSetWithCodec[uint32](uint32Codec{})
+    a.Insert(7)
+    a.Insert(42)
+
+    buff := new(bytes.Buffer)
+    if e := a.Serialize(buff); e != nil {
+        t.Fatal(e)
+    }
+
+    b := NewORSetWithCodec[uint32](uint32Codec{})
+    if e := b.Deserialize(buff); e != nil {
+        t.Fatal(e)
+    }
+
+    if !a.Equals(b) || !b.Contains(42) {
+        t.Error("Deserialized set should equal original!")
+    }
+}
+
+func TestMissingCodec(t *testing.T) {
+    a := NewGSet[uint32]()
+    a.Insert(1)
+
+    if e := a.Serialize(new(bytes.Buffer)); e == nil {
+        t.Error("Expected error serializing without a codec!")
+    }
+
+    if e := NewGSet[interface{}]().Serialize(new(bytes.Buffer)); e != nil {
+        t.Fatal(e)
+    }
+
+    b := NewLWWSet[interface{}](&tickClock{}, LWW_ADD_WINS)
+    b.Insert(1)
+
+    if e := b.Serialize(new(bytes.Buffer)); e == nil {
+        t.Error("Expected error serializing element Base64Codec can not encode!")
+    }
+}
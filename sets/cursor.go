@@ -0,0 +1,52 @@
+/*
+ * Copyright (c) 2015 Tom Swindell (t.swindell@rubyx.co.uk)
+ *
+ * Permission is hereby granted, free of charge, to any person obtaining a copy
+ * of this software and associated documentation files (the "Software"), to deal
+ * in the Software without restriction, including without limitation the rights
+ * to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+ * copies of the Software, and to permit persons to whom the Software is
+ * furnished to do so, subject to the following conditions:
+ *
+ * The above copyright notice and this permission notice shall be included in
+ * all copies or substantial portions of the Software.
+ *
+ * THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+ * IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+ * FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+ * AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+ * LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+ * OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+ * SOFTWARE.
+ *
+ */
+
+package set
+
+import "iter"
+
+// The Cursor type pulls elements one at a time from a sequence, for callers
+// which cannot consume it in a single range loop. A cursor must be closed when
+// abandoned before the end of its sequence.
+type Cursor[T any] struct {
+    next func() (T, bool)
+    stop func()
+}
+
+// The NewCursor function returns a cursor positioned before the first element
+// of seq.
+func NewCursor[T any](seq iter.Seq[T]) *Cursor[T] {
+    next, stop := iter.Pull(seq)
+    return &Cursor[T]{next, stop}
+}
+
+// The Next() method returns the next element, or false once the sequence is
+// exhausted or the cursor closed.
+func (c *Cursor[T]) Next() (T, bool) {
+    return c.next()
+}
+
+// The Close() method releases the sequence. It is safe to call more than once.
+func (c *Cursor[T]) Close() {
+    c.stop()
+}
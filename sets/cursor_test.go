@@ -0,0 +1,95 @@
+/*
+ * Copyright (c) 2015 Tom Swindell (t.swindell@rubyx.co.uk)
+ *
+ * Permission is hereby granted, free of charge, to any person obtaining a copy
+ * of this software and associated documentation files (the "Software"), to deal
+ * in the Software without restriction, including without limitation the rights
+ * to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+ * copies of the Software, and to permit persons to whom the Software is
+ * furnished to do so, subject to the following conditions:
+ *
+ * The above copyright notice and this permission notice shall be included in
+ * all copies or substantial portions of the Software.
+ *
+ * THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+ * IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+ * FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+ * AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+ * LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+ * OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+ * SOFTWARE.
+ *
+ */
+
+package set
+
+import "testing"
+import "iter"
+
+// Breaks out of seq after the first element, returning how many were seen.
+func takeOne[T any](seq iter.Seq[T]) int {
+    n := 0
+    for range seq { n++; break }
+    return n
+}
+
+func TestIterateEarlyExit(t *testing.T) {
+    g := NewGSet[interface{}]()
+    p := New2P[interface{}]()
+    o := NewORSet[interface{}]()
+    l := NewLWWSet[interface{}](&tickClock{}, LWW_ADD_WINS)
+    s := Set{}
+
+    for i := 0; i < 10; i++ { g.Insert(i); p.Insert(i); o.Insert(i); l.Insert(i); s.Insert(i) }
+
+    for _, seq := range []iter.Seq[interface{}]{g.Iterate(), p.Iterate(), o.Iterate(), l.Iterate(), s.Iterate()} {
+        if n := takeOne(seq); n != 1 { t.Errorf("Expected to stop after 1 element, got %d", n) }
+    }
+
+    // Breaking out must not leave a lock held.
+    if !g.Insert(10) || !p.Insert(10) || !o.Insert(10) || !l.Insert(10) {
+        t.Error("Insert failed after early exit from Iterate!")
+    }
+}
+
+func TestIterateModifyInLoop(t *testing.T) {
+    a := NewGSet[interface{}]()
+    for i := 0; i < 10; i++ { a.Insert(i) }
+
+    n := 0
+    for i := range a.Iterate() {
+        a.Insert(i.(int) + 10)
+        n++
+    }
+
+    if n != 10 { t.Errorf("Expected to iterate the 10 elements present, got %d", n) }
+    if a.Length() != 20 { t.Errorf("Expected length of 20, got %d", a.Length()) }
+}
+
+func TestCursor(t *testing.T) {
+    a := New2P[interface{}]()
+    for i := 0; i < 3; i++ { a.Insert(i) }
+    a.Remove(1)
+
+    c := NewCursor(a.Iterate())
+    defer c.Close()
+
+    seen := Set{}
+    for v, ok := c.Next(); ok; v, ok = c.Next() { seen.Insert(v) }
+
+    if !seen.Equals(a.ToSet()) { t.Error("Cursor did not yield the set contents!") }
+    if _, ok := c.Next(); ok { t.Error("Exhausted cursor returned an element!") }
+}
+
+func TestCursorClose(t *testing.T) {
+    a := NewORSet[interface{}]()
+    for i := 0; i < 3; i++ { a.Insert(i) }
+
+    c := NewCursor(a.Iterate())
+    if _, ok := c.Next(); !ok { t.Error("Expected an element from cursor!") }
+
+    c.Close()
+    c.Close()
+
+    if _, ok := c.Next(); ok { t.Error("Closed cursor returned an element!") }
+}
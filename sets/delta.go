@@ -0,0 +1,99 @@
+/*
+ * Copyright (c) 2015 Tom Swindell (t.swindell@rubyx.co.uk)
+ *
+ * Permission is hereby granted, free of charge, to any person obtaining a copy
+ * of this software and associated documentation files (the "Software"), to deal
+ * in the Software without restriction, including without limitation the rights
+ * to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+ * copies of the Software, and to permit persons to whom the Software is
+ * furnished to do so, subject to the following conditions:
+ *
+ * The above copyright notice and this permission notice shall be included in
+ * all copies or substantial portions of the Software.
+ *
+ * THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+ * IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+ * FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+ * AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+ * LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+ * OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+ * SOFTWARE.
+ *
+ */
+
+package set
+
+import (
+    "bytes"
+    "encoding/binary"
+    "fmt"
+)
+
+// The Marker type identifies a point in the local history of a delta-state
+// set, as the number of inserts into each of its grow-only components. The
+// zero Marker is the start of history. Markers are only meaningful to the set
+// instance that issued them, and do not survive serialization.
+type Marker []uint64
+
+// Returns component i of the marker, zero when the marker is shorter.
+func (m Marker) get(i int) uint64 {
+    if i >= len(m) { return 0 }
+    return m[i]
+}
+
+func (m Marker) Serialize(buff *bytes.Buffer) {
+    binary.Write(buff, binary.LittleEndian, uint32(len(m)))
+    for _, n := range m { binary.Write(buff, binary.LittleEndian, n) }
+}
+
+func (m Marker) Bytes() []byte {
+    buff := new(bytes.Buffer)
+    m.Serialize(buff)
+    return buff.Bytes()
+}
+
+func readMarker(buff *bytes.Buffer) (Marker, error) {
+    var count uint32
+    if e := binary.Read(buff, binary.LittleEndian, &count); e != nil { return nil, e }
+    if uint64(count) * 8 > uint64(buff.Len()) { return nil, fmt.Errorf("invalid format") }
+
+    m := make(Marker, count)
+    for i := range m {
+        if e := binary.Read(buff, binary.LittleEndian, &m[i]); e != nil { return nil, e }
+    }
+    return m, nil
+}
+
+// The ParseMarker() function decodes a marker from Bytes(), an empty slice
+// decodes to the zero Marker.
+func ParseMarker(data []byte) (Marker, error) {
+    if len(data) == 0 { return nil, nil }
+    return readMarker(bytes.NewBuffer(data))
+}
+
+// The DeltaState interface is implemented by sets which can ship only the
+// changes made since a marker, rather than their whole state.
+type DeltaState interface {
+    Marker() Marker
+
+    SerializeDelta(*bytes.Buffer, Marker) (Marker, error)
+    DeserializeDelta(*bytes.Buffer) (Marker, error)
+}
+
+// A serialized delta is the delta header, the marker it was taken since, the
+// marker it reaches, followed by the delta itself serialized as a set of the
+// same type.
+var DELTA_HEADER_MAGIC = []byte{'c', 'r', 'd', 't', ':', 'd', 'e', 'l', 't', 'a', 0x00}
+
+func writeDeltaHeader(buff *bytes.Buffer, since, next Marker) {
+    buff.Write(DELTA_HEADER_MAGIC)
+    since.Serialize(buff)
+    next.Serialize(buff)
+}
+
+// Reads a delta header, returning the marker the delta reaches.
+func readDeltaHeader(buff *bytes.Buffer) (Marker, error) {
+    if e := ReadHeader(buff, DELTA_HEADER_MAGIC); e != nil { return nil, e }
+    if _, e := readMarker(buff); e != nil { return nil, e }
+    return readMarker(buff)
+}
@@ -0,0 +1,161 @@
+/*
+ * Copyright (c) 2015 Tom Swindell (t.swindell@rubyx.co.uk)
+ *
+ * Permission is hereby granted, free of charge, to any person obtaining a copy
+ * of this software and associated documentation files (the "Software"), to deal
+ * in the Software without restriction, including without limitation the rights
+ * to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+ * copies of the Software, and to permit persons to whom the Software is
+ * furnished to do so, subject to the following conditions:
+ *
+ * The above copyright notice and this permission notice shall be included in
+ * all copies or substantial portions of the Software.
+ *
+ * THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+ * IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+ * FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+ * AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+ * LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+ * OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+ * SOFTWARE.
+ *
+ */
+
+package set
+
+import "testing"
+import "bytes"
+import "encoding/base64"
+
+func b64(s string) string { return base64.StdEncoding.EncodeToString([]byte(s)) }
+
+func TestGSetDeltaSince(t *testing.T) {
+    a := NewGSet[interface{}]()
+    a.Insert(1)
+    a.Insert(2)
+
+    delta, marker := a.DeltaSince(nil)
+    if delta.Length() != 2 {
+        t.Errorf("Delta since zero marker should hold everything, got %d", delta.Length())
+    }
+
+    a.Insert(3)
+    a.Insert(1)
+
+    delta, marker = a.DeltaSince(marker)
+    if delta.Length() != 1 || !delta.Contains(3) {
+        t.Errorf("Expected delta of {3}, got %v", delta.ToSlice())
+    }
+
+    delta, marker = a.DeltaSince(marker)
+    if delta.Length() != 0 {
+        t.Error("Expected empty delta!")
+    }
+
+    delta, _ = a.DeltaSince(Marker{100})
+    if delta.Length() != 3 {
+        t.Error("Delta since unknown marker should hold everything!")
+    }
+}
+
+func TestGSetApplyDelta(t *testing.T) {
+    a := NewGSet[interface{}]()
+    b := NewGSet[interface{}]()
+
+    a.Insert(1)
+    delta, marker := a.DeltaSince(nil)
+    b.ApplyDelta(delta)
+
+    a.Insert(2)
+    b.Insert(3)
+    delta, _ = a.DeltaSince(marker)
+    b.ApplyDelta(delta)
+
+    // Elements merged in are part of the history, so propagate onwards.
+    c := NewGSet[interface{}]()
+    delta, _ = b.DeltaSince(nil)
+    c.ApplyDelta(delta)
+
+    if c.Length() != 3 || !c.Contains(1) || !c.Contains(2) || !c.Contains(3) {
+        t.Errorf("Unexpected contents: %v", c.ToSlice())
+    }
+}
+
+func TestGSetSerializeDelta(t *testing.T) {
+    a := NewGSet[interface{}]()
+    b := NewGSet[interface{}]()
+
+    a.Insert(b64("a"))
+    buff := new(bytes.Buffer)
+    marker, e := a.SerializeDelta(buff, nil)
+    if e != nil { t.Fatal(e) }
+
+    a.Insert(b64("b"))
+    if _, e := a.SerializeDelta(buff, marker); e != nil { t.Fatal(e) }
+
+    for i := 0; i < 2; i++ {
+        if _, e := b.DeserializeDelta(buff); e != nil { t.Fatal(e) }
+    }
+
+    if !a.Equals(b) {
+        t.Error("Applied deltas should reproduce the original set!")
+    }
+
+    if _, e := b.DeserializeDelta(bytes.NewBufferString("crdt:gset")); e == nil {
+        t.Error("Expected header error!")
+    }
+}
+
+func Test2PDeltaSince(t *testing.T) {
+    a := New2P[interface{}]()
+    a.Insert(1)
+    a.Insert(2)
+
+    _, marker := a.DeltaSince(nil)
+
+    a.Remove(1)
+    a.Insert(3)
+
+    delta, marker := a.DeltaSince(marker)
+    if delta.added.Length() != 1 || !delta.added.Contains(3) ||
+       delta.removed.Length() != 1 || !delta.removed.Contains(1) {
+        t.Errorf("Unexpected delta: %v, %v", delta.added.ToSlice(), delta.removed.ToSlice())
+    }
+
+    b := New2P[interface{}]()
+    b.Insert(1)
+    b.ApplyDelta(delta)
+
+    if b.Contains(1) || !b.Contains(3) {
+        t.Error("Remove should be applied from delta!")
+    }
+}
+
+func Test2PSerializeDelta(t *testing.T) {
+    a := New2P[interface{}]()
+    b := New2P[interface{}]()
+
+    a.Insert(b64("a"))
+    a.Insert(b64("b"))
+
+    buff := new(bytes.Buffer)
+    marker, e := a.SerializeDelta(buff, nil)
+    if e != nil { t.Fatal(e) }
+
+    a.Remove(b64("a"))
+    next, e := a.SerializeDelta(buff, marker)
+    if e != nil { t.Fatal(e) }
+
+    for i := 0; i < 2; i++ {
+        if _, e := b.DeserializeDelta(buff); e != nil { t.Fatal(e) }
+    }
+
+    if !a.Equals(b) {
+        t.Error("Applied deltas should reproduce the original set!")
+    }
+
+    parsed, e := ParseMarker(next.Bytes())
+    if e != nil || len(parsed) != 2 || parsed[0] != 2 || parsed[1] != 1 {
+        t.Errorf("Marker round trip failed: %v, %v", parsed, e)
+    }
+}
@@ -0,0 +1,566 @@
+/*
+ * Copyright (c) 2015 Tom Swindell (t.swindell@rubyx.co.uk)
+ *
+ * Permission is hereby granted, free of charge, to any person obtaining a copy
+ * of this software and associated documentation files (the "Software"), to deal
+ * in the Software without restriction, including without limitation the rights
+ * to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+ * copies of the Software, and to permit persons to whom the Software is
+ * furnished to do so, subject to the following conditions:
+ *
+ * The above copyright notice and this permission notice shall be included in
+ * all copies or substantial portions of the Software.
+ *
+ * THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+ * IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+ * FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+ * AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+ * LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+ * OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+ * SOFTWARE.
+ *
+ */
+
+package set
+
+import (
+    "bufio"
+    "bytes"
+    "encoding/binary"
+    "fmt"
+    "hash/maphash"
+    "io"
+    "iter"
+    "os"
+    "runtime"
+    "sync"
+)
+
+// Tuning of disk-backed sets.
+const (
+    DISKGSET_INITIAL_SLOTS       = 1024    // Slots of a new index, a power of two.
+    DISKGSET_SLOT_SIZE           = 16      // Element hash and log offset plus one.
+    DISKGSET_PENDING_SIZE        = 1 << 20 // Bytes of log buffered before writing.
+    DISKGSET_CHECKPOINT_INTERVAL = 4096    // Elements between log checkpoints.
+)
+
+// Grow only set keeping its elements on disk, for sets too large to be held in
+// memory. Elements are appended to a log in insertion order, each as the record
+// the v2 format writes for it, and found through an open addressing hash index
+// of the log held in a second file, mapped into memory where the platform
+// allows so that the kernel pages it in and out as needed. The heap only holds
+// the log buffer and the offset of every DISKGSET_CHECKPOINT_INTERVAL-th
+// element, kept to find where a delta starts.
+//
+// The files are created in dir on first use and removed as soon as they are
+// open, so they last only as long as the set. A DiskGSet serializes exactly as
+// a GSet does, and either reads the state of the other.
+//
+// Insert and Contains can not report I/O errors, so the first one is kept: the
+// set stops changing, and Err, Serialize and Deserialize return it. A DiskGSet
+// is safe for concurrent use, as a GSet is.
+type DiskGSet[T comparable] struct {
+    sync.RWMutex
+    codec       Codec[T]
+    dir         string
+    seed        maphash.Seed
+    log         *os.File
+    index       *slotTable
+    flushed     int64    // Length of the log written to its file.
+    pending     []byte   // Records appended to the log after flushed.
+    length      uint64   // Length of the insertion history.
+    checkpoints []int64  // Log offset of every DISKGSET_CHECKPOINT_INTERVAL-th element.
+    unknown     sections // Sections of a newer format, kept to write back out.
+    err         error
+}
+
+// The NewDiskGSet function returns a set serialized by the DefaultCodec of T,
+// keeping its files in dir.
+func NewDiskGSet[T comparable](dir string) *DiskGSet[T] {
+    return NewDiskGSetWithCodec(dir, DefaultCodec[T]())
+}
+
+func NewDiskGSetWithCodec[T comparable](dir string, codec Codec[T]) *DiskGSet[T] {
+    return &DiskGSet[T]{codec: codec, dir: dir, seed: maphash.MakeSeed()}
+}
+
+// Creates a file in dir which is removed at once, and so only lasts as long as
+// it is open.
+func createUnlinked(dir string) (*os.File, error) {
+    if e := os.MkdirAll(dir, 0700); e != nil { return nil, e }
+
+    f, e := os.CreateTemp(dir, "crdt-")
+    if e != nil { return nil, e }
+
+    if e := os.Remove(f.Name()); e != nil {
+        f.Close()
+        return nil, e
+    }
+    return f, nil
+}
+
+// The slotTable type holds the slots of an index in a file, mapped into memory
+// when mapFile supports the platform and read and written through the file
+// otherwise. Each slot holds the hash of an element and its log offset plus
+// one, zero marking an empty slot.
+type slotTable struct {
+    file  *os.File
+    data  []byte // Mapping of file, nil when not mapped.
+    slots uint64 // A power of two.
+}
+
+// Creates a table of empty slots, sized without writing it. The table is closed
+// once garbage collected, as the mapping would otherwise outlive it.
+func createSlotTable(dir string, slots uint64) (*slotTable, error) {
+    f, e := createUnlinked(dir)
+    if e != nil { return nil, e }
+
+    size := int64(slots * DISKGSET_SLOT_SIZE)
+    if e := f.Truncate(size); e != nil { f.Close(); return nil, e }
+
+    data, e := mapFile(f, size)
+    if e != nil { f.Close(); return nil, e }
+
+    t := &slotTable{f, data, slots}
+    runtime.SetFinalizer(t, (*slotTable).close)
+    return t, nil
+}
+
+func (t *slotTable) get(slot uint64, entry []byte) error {
+    if t.data != nil { copy(entry, t.data[slot * DISKGSET_SLOT_SIZE:]); return nil }
+
+    _, e := t.file.ReadAt(entry, int64(slot * DISKGSET_SLOT_SIZE))
+    return e
+}
+
+func (t *slotTable) set(slot uint64, entry []byte) error {
+    if t.data != nil { copy(t.data[slot * DISKGSET_SLOT_SIZE:], entry); return nil }
+
+    _, e := t.file.WriteAt(entry, int64(slot * DISKGSET_SLOT_SIZE))
+    return e
+}
+
+func (t *slotTable) close() error {
+    runtime.SetFinalizer(t, nil)
+
+    var e error
+    if t.data != nil { e = unmapFile(t.data); t.data = nil }
+    if e2 := t.file.Close(); e == nil { e = e2 }
+    return e
+}
+
+// Probes the table from the home slot of hash, passing each occupied slot to
+// visit until it returns true or an empty slot is reached. Returns the slot
+// probing stopped at, and whether visit stopped it.
+func (t *slotTable) probe(hash uint64, visit func(entry []byte) (bool, error)) (uint64, bool, error) {
+    entry := make([]byte, DISKGSET_SLOT_SIZE)
+    mask := t.slots - 1
+
+    for i, n := hash & mask, uint64(0); n < t.slots; i, n = (i + 1) & mask, n + 1 {
+        if e := t.get(i, entry); e != nil { return 0, false, e }
+        if binary.LittleEndian.Uint64(entry[8:]) == 0 { return i, false, nil }
+        if visit == nil { continue }
+
+        found, e := visit(entry)
+        if e != nil { return 0, false, e }
+        if found { return i, true, nil }
+    }
+    return 0, false, fmt.Errorf("index full")
+}
+
+// Opens the files of the set on first use. The caller must hold the lock.
+func (s *DiskGSet[T]) open() error {
+    if s.err != nil || s.log != nil { return s.err }
+
+    log, e := createUnlinked(s.dir)
+    if e != nil { s.err = e; return e }
+
+    index, e := createSlotTable(s.dir, DISKGSET_INITIAL_SLOTS)
+    if e != nil { log.Close(); s.err = e; return e }
+
+    s.log, s.index = log, index
+    return nil
+}
+
+// Returns the log record of item, its uvarint length and raw data from codec.
+func (s *DiskGSet[T]) record(item T) ([]byte, error) {
+    if s.codec == nil { return nil, fmt.Errorf("no codec for element type") }
+
+    data, e := s.codec.Encode(item)
+    if e != nil { return nil, e }
+
+    return append(binary.AppendUvarint(nil, uint64(len(data))), data...), nil
+}
+
+// Returns true if the log holds record at offset. The caller must hold the
+// lock.
+func (s *DiskGSet[T]) recordAt(offset int64, record []byte) (bool, error) {
+    if offset >= s.flushed { return bytes.HasPrefix(s.pending[offset - s.flushed:], record), nil }
+
+    data := make([]byte, len(record))
+    if _, e := s.log.ReadAt(data, offset); e != nil && e != io.EOF { return false, e }
+    return bytes.Equal(data, record), nil
+}
+
+// Finds record in the index, returning whether it is present and otherwise the
+// slot it belongs in. The caller must hold the lock.
+func (s *DiskGSet[T]) find(record []byte, hash uint64) (uint64, bool, error) {
+    return s.index.probe(hash, func(entry []byte) (bool, error) {
+        if binary.LittleEndian.Uint64(entry) != hash { return false, nil }
+        return s.recordAt(int64(binary.LittleEndian.Uint64(entry[8:]) - 1), record)
+    })
+}
+
+// Writes the buffered records to the log. The caller must hold the lock.
+func (s *DiskGSet[T]) flush() error {
+    if len(s.pending) == 0 { return nil }
+    if _, e := s.log.WriteAt(s.pending, s.flushed); e != nil { return e }
+
+    s.flushed += int64(len(s.pending))
+    s.pending = s.pending[:0]
+    return nil
+}
+
+// Doubles the slots of the index, once it is half full. The caller must hold
+// the lock.
+func (s *DiskGSet[T]) grow() error {
+    index, e := createSlotTable(s.dir, s.index.slots * 2)
+    if e != nil { return e }
+
+    entry := make([]byte, DISKGSET_SLOT_SIZE)
+    for i := uint64(0); i < s.index.slots; i++ {
+        e := s.index.get(i, entry)
+        if e == nil && binary.LittleEndian.Uint64(entry[8:]) != 0 {
+            var slot uint64
+            slot, _, e = index.probe(binary.LittleEndian.Uint64(entry), nil)
+            if e == nil { e = index.set(slot, entry) }
+        }
+        if e != nil { index.close(); return e }
+    }
+
+    s.index.close()
+    s.index = index
+    return nil
+}
+
+// Appends record to the log and index unless already present. The caller must
+// hold the lock.
+func (s *DiskGSet[T]) insert(record []byte) bool {
+    if s.open() != nil { return false }
+
+    hash := maphash.Bytes(s.seed, record)
+    slot, found, e := s.find(record, hash)
+    if e != nil || found { s.err = e; return false }
+
+    offset := s.flushed + int64(len(s.pending))
+    if s.length % DISKGSET_CHECKPOINT_INTERVAL == 0 { s.checkpoints = append(s.checkpoints, offset) }
+    s.pending = append(s.pending, record...)
+    s.length++
+
+    entry := make([]byte, DISKGSET_SLOT_SIZE)
+    binary.LittleEndian.PutUint64(entry, hash)
+    binary.LittleEndian.PutUint64(entry[8:], uint64(offset) + 1)
+    e = s.index.set(slot, entry)
+
+    if e == nil && len(s.pending) >= DISKGSET_PENDING_SIZE { e = s.flush() }
+    if e == nil && s.length * 2 > s.index.slots { e = s.grow() }
+    if e != nil { s.err = e }
+
+    return true
+}
+
+func (s *DiskGSet[T]) Insert(item T) bool {
+    record, e := s.record(item)
+    if e != nil { return false }
+
+    s.Lock()
+    defer s.Unlock()
+
+    return s.insert(record)
+}
+
+func (s *DiskGSet[T]) Contains(item T) bool {
+    record, e := s.record(item)
+    if e != nil { return false }
+
+    s.RLock()
+    defer s.RUnlock()
+
+    if s.log == nil || s.err != nil { return false }
+
+    _, found, e := s.find(record, maphash.Bytes(s.seed, record))
+    return e == nil && found
+}
+
+func (s *DiskGSet[T]) Length() int {
+    s.RLock()
+    defer s.RUnlock()
+
+    return int(s.length)
+}
+
+// The Err() method returns the I/O error which stopped the set, if any.
+func (s *DiskGSet[T]) Err() error {
+    s.RLock()
+    defer s.RUnlock()
+
+    return s.err
+}
+
+// The Close() method releases the files of the set, which can not be used
+// afterwards. Sets which are not closed release them once garbage collected.
+func (s *DiskGSet[T]) Close() error {
+    s.Lock()
+    defer s.Unlock()
+
+    if s.err == nil { s.err = fmt.Errorf("set closed") }
+    if s.log == nil { return nil }
+
+    e := s.log.Close()
+    if e2 := s.index.close(); e == nil { e = e2 }
+    s.log, s.index, s.pending = nil, nil, nil
+    return e
+}
+
+// The diskSnapshot type holds the extent of the log at one point of its
+// history. The log is append only, so the records it covers never change.
+type diskSnapshot struct {
+    log     *os.File
+    end     int64    // Length of the log.
+    length  uint64   // Length of the insertion history.
+    unknown sections
+}
+
+// Flushes the log, returning a snapshot of the set. The caller must hold the
+// lock.
+func (s *DiskGSet[T]) snapshot() (diskSnapshot, error) {
+    if s.err != nil { return diskSnapshot{}, s.err }
+    if s.log == nil { return diskSnapshot{unknown: s.unknown}, nil }
+    if e := s.flush(); e != nil { s.err = e; return diskSnapshot{}, e }
+
+    return diskSnapshot{s.log, s.flushed, s.length, s.unknown}, nil
+}
+
+func (s *DiskGSet[T]) takeSnapshot() (diskSnapshot, error) {
+    s.Lock()
+    defer s.Unlock()
+
+    return s.snapshot()
+}
+
+// Reads each record of a log, passing visit the record and the element data it
+// holds. Both are only valid until visit returns.
+func scanRecords(r io.Reader, visit func(record, data []byte) error) error {
+    in := bufio.NewReaderSize(r, 1 << 16)
+    var record []byte
+
+    for {
+        l, e := binary.ReadUvarint(in)
+        if e == io.EOF { return nil }
+        if e != nil { return e }
+        if l > 1 << 32 { return fmt.Errorf("invalid format") }
+
+        record = binary.AppendUvarint(record[:0], l)
+        prefix := len(record)
+        record = append(record, make([]byte, l)...)
+        if _, e := io.ReadFull(in, record[prefix:]); e != nil { return e }
+
+        if e := visit(record, record[prefix:]); e != nil { return e }
+    }
+}
+
+// Reads the records of the snapshot from offset from, skipping the first skip
+// records read.
+func (d diskSnapshot) scan(from int64, skip uint64, visit func(record, data []byte) error) error {
+    if d.end <= from { return nil }
+
+    return scanRecords(io.NewSectionReader(d.log, from, d.end - from), func(record, data []byte) error {
+        if skip > 0 { skip--; return nil }
+        return visit(record, data)
+    })
+}
+
+// The Iterate() method ranges over a snapshot of the set read back from disk, so
+// the lock is never held while the loop body runs and the loop may modify the
+// set. Elements are visited in insertion order.
+func (s *DiskGSet[T]) Iterate() iter.Seq[T] {
+    snapshot, e := s.takeSnapshot()
+
+    return func(yield func(T) bool) {
+        if e != nil { return }
+
+        stop := fmt.Errorf("stop")
+        snapshot.scan(0, 0, func(record, data []byte) error {
+            item, e := s.codec.Decode(data)
+            if e != nil || !yield(item) { return stop }
+            return nil
+        })
+    }
+}
+
+func (s *DiskGSet[T]) Equals(other *DiskGSet[T]) bool {
+    if s == other { return true }
+    if s.Length() != other.Length() { return false }
+
+    for i := range s.Iterate() {
+        if !other.Contains(i) { return false }
+    }
+    return true
+}
+
+// Inserts the records of a snapshot, which must not be of this set, into the
+// set.
+func (s *DiskGSet[T]) merge(snapshot diskSnapshot) error {
+    s.Lock()
+    defer s.Unlock()
+
+    if e := s.open(); e != nil { return e }
+
+    e := snapshot.scan(0, 0, func(record, data []byte) error {
+        s.insert(record)
+        return s.err
+    })
+    if e != nil { return e }
+
+    s.unknown = s.unknown.union(snapshot.unknown)
+    return nil
+}
+
+func (s *DiskGSet[T]) Merge(other *DiskGSet[T]) {
+    if s == other { return }
+
+    // Snapshot the other set first, so two sets merging into each other can
+    // never deadlock.
+    snapshot, e := other.takeSnapshot()
+    if e != nil { return }
+
+    s.merge(snapshot)
+}
+
+func (s *DiskGSet[T]) Clone() *DiskGSet[T] {
+    result := NewDiskGSetWithCodec(s.dir, s.codec)
+    result.Merge(s)
+    if e := s.Err(); e != nil { result.err = e }
+
+    return result
+}
+
+// Writes a GSet container of the records of snapshot from offset from,
+// skipping the first skip records read.
+func (d diskSnapshot) write(w io.Writer, from int64, skip uint64) error {
+    return writeContainer(w, GSET_HEADER_MAGIC, []sectionWriter{{GSET_SECTION_ELEMENTS, func(emit func([]byte) error) error {
+        return d.scan(from, skip, func(record, data []byte) error { return emit(record) })
+    }}}, d.unknown)
+}
+
+// The Serialize() method streams a snapshot of the set to w in the format of a
+// GSet, without holding the lock while writing.
+func (s *DiskGSet[T]) Serialize(w io.Writer) error {
+    snapshot, e := s.takeSnapshot()
+    if e != nil { return e }
+
+    return snapshot.write(w, 0, 0)
+}
+
+// Deserialize merges the serialized state of a GSet, in either format, into
+// this set. Elements are staged on disk until all of them have been read, and
+// nothing is merged unless all of it is read successfully.
+func (s *DiskGSet[T]) Deserialize(r io.Reader) error {
+    if s.codec == nil { return fmt.Errorf("no codec for element type") }
+
+    stage, e := createUnlinked(s.dir)
+    if e != nil { return e }
+    defer stage.Close()
+
+    out := bufio.NewWriterSize(stage, 1 << 16)
+
+    v2, e := readFormat(r, GSET_HEADER_MAGIC)
+    if e != nil { return e }
+
+    var unknown sections
+    if v2 {
+        unknown, e = readContainer(r, GSET_HEADER_MAGIC, map[uint64]sectionReader{
+            GSET_SECTION_ELEMENTS: func(in *limitedReader) error {
+                for in.n > 0 {
+                    data, e := readBytes(in)
+                    if e != nil { return e }
+                    if _, e := s.codec.Decode(data); e != nil { return e }
+                    if e := writeBytes(out, data); e != nil { return e }
+                }
+                return nil
+            },
+        })
+    } else {
+        e = s.stageV1(r, out)
+    }
+    if e != nil { return e }
+    if e := out.Flush(); e != nil { return e }
+
+    end, e := stage.Seek(0, io.SeekCurrent)
+    if e != nil { return e }
+
+    return s.merge(diskSnapshot{log: stage, end: end, unknown: unknown})
+}
+
+// Stages the v1 format after its header, a uint32 count followed by each
+// element as read by readElement.
+func (s *DiskGSet[T]) stageV1(r io.Reader, out io.Writer) error {
+    var sizeof uint32
+    if e := binary.Read(r, binary.LittleEndian, &sizeof); e != nil {
+        return e
+    }
+
+    for i := uint32(0); i < sizeof; i++ {
+        item, e := readElement(r, s.codec)
+        if e != nil { return e }
+        if e := writeElementV2(out, s.codec, item); e != nil { return e }
+    }
+    return nil
+}
+
+func (s *DiskGSet[T]) Marker() Marker {
+    s.RLock()
+    defer s.RUnlock()
+
+    return Marker{s.length}
+}
+
+// Returns the log offset to read the elements inserted after position n of
+// the history from, and the records to skip there. A position beyond the end
+// of history, such as one from before a restore, returns every element. The
+// caller must hold the lock.
+func (s *DiskGSet[T]) since(n uint64) (int64, uint64) {
+    if n > s.length { n = 0 }
+    if n == s.length { return s.flushed + int64(len(s.pending)), 0 }
+
+    k := n / DISKGSET_CHECKPOINT_INTERVAL
+    return s.checkpoints[k], n - k * DISKGSET_CHECKPOINT_INTERVAL
+}
+
+// The SerializeDelta() method writes the elements inserted since marker as a
+// GSet delta, and returns the marker to request the next delta from.
+func (s *DiskGSet[T]) SerializeDelta(buff *bytes.Buffer, marker Marker) (Marker, error) {
+    s.Lock()
+    snapshot, e := s.snapshot()
+    from, skip := s.since(marker.get(0))
+    s.Unlock()
+    if e != nil { return nil, e }
+
+    // Deltas carry only elements, as those of a GSet do.
+    snapshot.unknown = nil
+
+    next := Marker{snapshot.length}
+    writeDeltaHeader(buff, marker, next)
+    if e := snapshot.write(buff, from, skip); e != nil { return nil, e }
+    return next, nil
+}
+
+// DeserializeDelta applies a serialized delta, returning the marker it reaches
+// in the history of the set which produced it.
+func (s *DiskGSet[T]) DeserializeDelta(buff *bytes.Buffer) (Marker, error) {
+    next, e := readDeltaHeader(buff)
+    if e != nil { return nil, e }
+
+    if e := s.Deserialize(buff); e != nil { return nil, e }
+    return next, nil
+}
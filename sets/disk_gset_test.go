@@ -0,0 +1,215 @@
+/*
+ * Copyright (c) 2015 Tom Swindell (t.swindell@rubyx.co.uk)
+ *
+ * Permission is hereby granted, free of charge, to any person obtaining a copy
+ * of this software and associated documentation files (the "Software"), to deal
+ * in the Software without restriction, including without limitation the rights
+ * to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+ * copies of the Software, and to permit persons to whom the Software is
+ * furnished to do so, subject to the following conditions:
+ *
+ * The above copyright notice and this permission notice shall be included in
+ * all copies or substantial portions of the Software.
+ *
+ * THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+ * IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+ * FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+ * AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+ * LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+ * OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+ * SOFTWARE.
+ *
+ */
+
+package set
+
+import "testing"
+import "bytes"
+import "fmt"
+import "os"
+import "path/filepath"
+import "sync"
+
+func TestDiskGSetInsert(t *testing.T) {
+    a := NewDiskGSet[string](t.TempDir())
+
+    if a.Contains("1") || a.Length() != 0 {
+        t.Error("New set should be empty!")
+    }
+
+    if !a.Insert("1") || !a.Insert("2") {
+        t.Error("Failed to insert into set!")
+    }
+
+    if a.Insert("1") {
+        t.Error("Insert returned success when attempting to insert twice!")
+    }
+
+    if !a.Contains("1") || !a.Contains("2") || a.Contains("3") || a.Length() != 2 {
+        t.Error("Unexpected contents after insert!")
+    }
+
+    if a.Err() != nil { t.Error(a.Err()) }
+}
+
+func TestDiskGSetGrow(t *testing.T) {
+    a := NewDiskGSet[string](t.TempDir())
+    n := DISKGSET_INITIAL_SLOTS * 4 + DISKGSET_CHECKPOINT_INTERVAL
+
+    for i := 0; i < n; i++ {
+        if !a.Insert(fmt.Sprintf("element-%d", i)) { t.Fatalf("Failed to insert element %d: %v", i, a.Err()) }
+    }
+
+    if a.Length() != n { t.Errorf("Expected length of %d, got %d", n, a.Length()) }
+
+    for i := 0; i < n; i++ {
+        if !a.Contains(fmt.Sprintf("element-%d", i)) { t.Fatalf("Missing element %d after growing index", i) }
+    }
+
+    i := 0
+    for v := range a.Iterate() {
+        if v != fmt.Sprintf("element-%d", i) { t.Fatalf("Expected insertion order, got %s at %d", v, i) }
+        i++
+    }
+    if i != n { t.Errorf("Expected to iterate %d elements, got %d", n, i) }
+}
+
+func TestDiskGSetFilesRemoved(t *testing.T) {
+    dir := t.TempDir()
+    a := NewDiskGSet[string](dir)
+    a.Insert("1")
+
+    if entries, _ := os.ReadDir(dir); len(entries) != 0 {
+        t.Errorf("Expected no files left in directory, got %d", len(entries))
+    }
+
+    if e := a.Close(); e != nil { t.Error(e) }
+    if a.Insert("2") || a.Err() == nil { t.Error("Expected closed set to fail!") }
+}
+
+func TestDiskGSetInvalidDirectory(t *testing.T) {
+    file := filepath.Join(t.TempDir(), "file")
+    os.WriteFile(file, nil, 0600)
+
+    a := NewDiskGSet[string](file)
+    if a.Insert("1") || a.Err() == nil { t.Error("Expected insert into invalid directory to fail!") }
+    if e := a.Serialize(new(bytes.Buffer)); e == nil { t.Error("Expected serialize to return error!") }
+}
+
+func TestDiskGSetEqualsMergeClone(t *testing.T) {
+    a := NewDiskGSet[string](t.TempDir())
+    b := NewDiskGSet[string](t.TempDir())
+
+    for i := 0; i < 10; i++ { a.Insert(fmt.Sprint(i)) }
+    for i := 5; i < 15; i++ { b.Insert(fmt.Sprint(i)) }
+
+    if a.Equals(b) { t.Error("a should not equal b!") }
+
+    c := a.Clone()
+    if !a.Equals(c) { t.Error("Expected clone to equal a!") }
+
+    a.Merge(b)
+    b.Merge(a)
+
+    if !a.Equals(b) || a.Length() != 15 { t.Error("Equals failed after merge!") }
+    if c.Length() != 10 { t.Error("Clone should not change with a!") }
+}
+
+func TestDiskGSetSerialize(t *testing.T) {
+    a := NewDiskGSet[string](t.TempDir())
+    for i := 0; i < 100; i++ { a.Insert(fmt.Sprint(i)) }
+
+    out := &bytes.Buffer{}
+    if e := a.Serialize(out); e != nil { t.Fatal(e) }
+
+    // A DiskGSet serializes exactly as a GSet does.
+    b := NewGSet[string]()
+    if e := b.Deserialize(bytes.NewBuffer(out.Bytes())); e != nil { t.Fatal(e) }
+
+    expected := &bytes.Buffer{}
+    if e := b.Serialize(expected); e != nil { t.Fatal(e) }
+    if !bytes.Equal(out.Bytes(), expected.Bytes()) { t.Error("Expected identical serialization to GSet!") }
+
+    c := NewDiskGSet[string](t.TempDir())
+    if e := c.Deserialize(bytes.NewBuffer(expected.Bytes())); e != nil { t.Fatal(e) }
+    if !a.Equals(c) { t.Error("Match failed") }
+}
+
+func TestDiskGSetDeserializeV1(t *testing.T) {
+    a := NewDiskGSet[string](t.TempDir())
+    if e := a.Deserialize(bytes.NewBuffer(gsetV1("a", "b", "c"))); e != nil { t.Fatal(e) }
+    if a.Length() != 3 || !a.Contains("b") { t.Error("Unexpected contents from v1 data!") }
+}
+
+func TestDiskGSetDeserializeInvalid(t *testing.T) {
+    a := NewGSet[string]()
+    for i := 0; i < 10; i++ { a.Insert(fmt.Sprint(i)) }
+
+    out := &bytes.Buffer{}
+    a.Serialize(out)
+
+    data := out.Bytes()
+    data[len(data) - 1] ^= 0xff
+
+    b := NewDiskGSet[string](t.TempDir())
+    b.Insert("x")
+
+    if e := b.Deserialize(bytes.NewBuffer(data)); e == nil { t.Error("Expected checksum failure!") }
+    if b.Length() != 1 { t.Error("Nothing should be merged from invalid data!") }
+}
+
+func TestDiskGSetDelta(t *testing.T) {
+    a := NewDiskGSet[string](t.TempDir())
+    b := NewDiskGSet[string](t.TempDir())
+    n := DISKGSET_CHECKPOINT_INTERVAL + 10
+
+    for i := 0; i < n; i++ { a.Insert(fmt.Sprint(i)) }
+    marker := Marker{uint64(n - 5)}
+
+    buff := new(bytes.Buffer)
+    next, e := a.SerializeDelta(buff, marker)
+    if e != nil { t.Fatal(e) }
+    if next.get(0) != uint64(n) { t.Errorf("Unexpected next marker %v", next) }
+
+    if _, e := b.DeserializeDelta(buff); e != nil { t.Fatal(e) }
+    if b.Length() != 5 || !b.Contains(fmt.Sprint(n - 1)) || b.Contains(fmt.Sprint(n - 6)) {
+        t.Errorf("Unexpected delta contents, length %d", b.Length())
+    }
+
+    // A GSet reads deltas of a DiskGSet.
+    buff.Reset()
+    a.SerializeDelta(buff, nil)
+
+    c := NewGSet[string]()
+    if _, e := c.DeserializeDelta(buff); e != nil { t.Fatal(e) }
+    if c.Length() != n { t.Errorf("Expected full delta of %d elements, got %d", n, c.Length()) }
+
+    buff.Reset()
+    a.SerializeDelta(buff, next)
+    if _, e := b.DeserializeDelta(buff); e != nil || b.Length() != 5 { t.Error("Expected empty delta!") }
+}
+
+func TestDiskGSetConcurrent(t *testing.T) {
+    a := NewDiskGSet[string](t.TempDir())
+    b := NewDiskGSet[string](t.TempDir())
+
+    var wg sync.WaitGroup
+    for w := 0; w < 4; w++ {
+        wg.Add(1)
+        go func(w int) {
+            defer wg.Done()
+            for i := 0; i < 500; i++ {
+                a.Insert(fmt.Sprint(i))
+                b.Insert(fmt.Sprint(w * 500 + i))
+                a.Contains(fmt.Sprint(i))
+                if i % 100 == 0 { a.Merge(b); b.Merge(a) }
+            }
+        }(w)
+    }
+    wg.Wait()
+
+    a.Merge(b)
+    b.Merge(a)
+
+    if !a.Equals(b) || a.Length() != 2000 { t.Errorf("Expected equal sets of 2000, got %d", a.Length()) }
+}
@@ -0,0 +1,157 @@
+/*
+ * Copyright (c) 2015 Tom Swindell (t.swindell@rubyx.co.uk)
+ *
+ * Permission is hereby granted, free of charge, to any person obtaining a copy
+ * of this software and associated documentation files (the "Software"), to deal
+ * in the Software without restriction, including without limitation the rights
+ * to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+ * copies of the Software, and to permit persons to whom the Software is
+ * furnished to do so, subject to the following conditions:
+ *
+ * The above copyright notice and this permission notice shall be included in
+ * all copies or substantial portions of the Software.
+ *
+ * THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+ * IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+ * FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+ * AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+ * LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+ * OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+ * SOFTWARE.
+ *
+ */
+
+package set
+
+import (
+    "bytes"
+    "encoding/binary"
+    "sort"
+)
+
+// The Dot type identifies a single event, the counter'th event of a replica.
+type Dot struct {
+    ReplicaId string
+    Counter   uint64
+}
+
+// Common Go representation of a dot context, the set of events a replica has
+// observed. Contiguous events are summarised by a vector clock, and events
+// received out of order are kept in a dot cloud until the gap before them is
+// filled. Not safe for concurrent use.
+type DotContext struct {
+    clock VectorClock
+    cloud map[Dot]struct{}
+}
+
+func NewDotContext() *DotContext {
+    return &DotContext{clock: NewVectorClock(), cloud: make(map[Dot]struct{})}
+}
+
+func (c *DotContext) Contains(d Dot) bool {
+    if d.Counter <= c.clock[d.ReplicaId] { return true }
+
+    _, found := c.cloud[d]
+    return found
+}
+
+// The Next() method returns and records a new dot for replicaId.
+func (c *DotContext) Next(replicaId string) Dot {
+    d := Dot{replicaId, c.clock.Increment(replicaId)}
+    c.compact()
+    return d
+}
+
+// The Add() method records an observed dot.
+func (c *DotContext) Add(d Dot) {
+    if c.Contains(d) { return }
+
+    c.cloud[d] = struct{}{}
+    c.compact()
+}
+
+// Folds dots which continue the vector clock into it.
+func (c *DotContext) compact() {
+    for changed := true; changed; {
+        changed = false
+        for d := range c.cloud {
+            n := c.clock[d.ReplicaId]
+            if d.Counter == n + 1 {
+                c.clock[d.ReplicaId] = d.Counter
+                changed = true
+            }
+            if d.Counter <= c.clock[d.ReplicaId] { delete(c.cloud, d) }
+        }
+    }
+}
+
+// The Clock() method returns a copy of the contiguous part of the context.
+func (c *DotContext) Clock() VectorClock {
+    return c.clock.Clone()
+}
+
+func (c *DotContext) Merge(other *DotContext) {
+    c.clock.Merge(other.clock)
+    for d := range other.cloud { c.cloud[d] = struct{}{} }
+    c.compact()
+}
+
+func (c *DotContext) Equals(other *DotContext) bool {
+    if !c.clock.Equals(other.clock) || len(c.cloud) != len(other.cloud) { return false }
+
+    for d := range c.cloud {
+        if _, found := other.cloud[d]; !found { return false }
+    }
+    return true
+}
+
+func (c *DotContext) Clone() *DotContext {
+    result := NewDotContext()
+    result.Merge(c)
+    return result
+}
+
+var DOTCONTEXT_HEADER_MAGIC = []byte{'c', 'r', 'd', 't', ':', 'd', 'o', 't', 'c', 't', 'x', 0x00}
+
+func (c *DotContext) Serialize(buff *bytes.Buffer) error {
+    buff.Write(DOTCONTEXT_HEADER_MAGIC)
+    if e := c.clock.Serialize(buff); e != nil { return e }
+
+    dots := make([]Dot, 0, len(c.cloud))
+    for d := range c.cloud { dots = append(dots, d) }
+    sort.Slice(dots, func(i, j int) bool {
+        if dots[i].ReplicaId != dots[j].ReplicaId { return dots[i].ReplicaId < dots[j].ReplicaId }
+        return dots[i].Counter < dots[j].Counter
+    })
+
+    binary.Write(buff, binary.LittleEndian, uint32(len(dots)))
+    for _, d := range dots {
+        if e := WriteString(buff, d.ReplicaId); e != nil { return e }
+        binary.Write(buff, binary.LittleEndian, d.Counter)
+    }
+
+    return nil
+}
+
+// Deserialize merges the serialized context into c.
+func (c *DotContext) Deserialize(buff *bytes.Buffer) error {
+    if e := ReadHeader(buff, DOTCONTEXT_HEADER_MAGIC); e != nil { return e }
+
+    in := NewDotContext()
+    if e := in.clock.Deserialize(buff); e != nil { return e }
+
+    var sizeof uint32
+    if e := binary.Read(buff, binary.LittleEndian, &sizeof); e != nil { return e }
+
+    for i := uint32(0); i < sizeof; i++ {
+        var d Dot
+        var e error
+
+        if d.ReplicaId, e = ReadString(buff); e != nil { return e }
+        if e := binary.Read(buff, binary.LittleEndian, &d.Counter); e != nil { return e }
+        in.cloud[d] = struct{}{}
+    }
+
+    c.Merge(in)
+    return nil
+}
@@ -0,0 +1,116 @@
+/*
+ * Copyright (c) 2015 Tom Swindell (t.swindell@rubyx.co.uk)
+ *
+ * Permission is hereby granted, free of charge, to any person obtaining a copy
+ * of this software and associated documentation files (the "Software"), to deal
+ * in the Software without restriction, including without limitation the rights
+ * to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+ * copies of the Software, and to permit persons to whom the Software is
+ * furnished to do so, subject to the following conditions:
+ *
+ * The above copyright notice and this permission notice shall be included in
+ * all copies or substantial portions of the Software.
+ *
+ * THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+ * IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+ * FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+ * AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+ * LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+ * OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+ * SOFTWARE.
+ *
+ */
+
+package set
+
+import "testing"
+import "bytes"
+
+func TestDotContextNext(t *testing.T) {
+    c := NewDotContext()
+
+    d1 := c.Next("a")
+    d2 := c.Next("a")
+
+    if d1 != (Dot{"a", 1}) || d2 != (Dot{"a", 2}) {
+        t.Errorf("Unexpected dots %v, %v", d1, d2)
+    }
+
+    if !c.Contains(d1) || !c.Contains(d2) || c.Contains(Dot{"a", 3}) {
+        t.Error("Contains check failed!")
+    }
+}
+
+func TestDotContextAddOutOfOrder(t *testing.T) {
+    c := NewDotContext()
+
+    c.Add(Dot{"a", 3})
+    c.Add(Dot{"a", 2})
+
+    if c.Contains(Dot{"a", 1}) || !c.Contains(Dot{"a", 2}) || !c.Contains(Dot{"a", 3}) {
+        t.Error("Contains check failed!")
+    }
+
+    if c.Clock().Get("a") != 0 {
+        t.Error("Clock should not advance past a gap!")
+    }
+
+    c.Add(Dot{"a", 1})
+
+    if c.Clock().Get("a") != 3 || len(c.cloud) != 0 {
+        t.Error("Filling the gap should compact the dot cloud!")
+    }
+}
+
+func TestDotContextMerge(t *testing.T) {
+    a := NewDotContext()
+    b := NewDotContext()
+
+    a.Next("a")
+    a.Add(Dot{"b", 2})
+    b.Next("b")
+    b.Add(Dot{"a", 3})
+
+    ab := a.Clone()
+    ab.Merge(b)
+    ba := b.Clone()
+    ba.Merge(a)
+
+    if !ab.Equals(ba) {
+        t.Error("Merge should be commutative!")
+    }
+
+    if ab.Clock().Get("b") != 2 || ab.Clock().Get("a") != 1 || !ab.Contains(Dot{"a", 3}) || ab.Contains(Dot{"a", 2}) {
+        t.Error("Unexpected merged context!")
+    }
+
+    aa := ab.Clone()
+    aa.Merge(ab)
+    if !aa.Equals(ab) {
+        t.Error("Merge should be idempotent!")
+    }
+}
+
+func TestDotContextSerialize(t *testing.T) {
+    a := NewDotContext()
+    a.Next("a")
+    a.Add(Dot{"b", 4})
+
+    buff := new(bytes.Buffer)
+    if e := a.Serialize(buff); e != nil {
+        t.Fatal(e)
+    }
+
+    b := NewDotContext()
+    if e := b.Deserialize(buff); e != nil {
+        t.Fatal(e)
+    }
+
+    if !a.Equals(b) {
+        t.Error("Deserialized context should equal original!")
+    }
+
+    if e := b.Deserialize(bytes.NewBufferString("crdt:vclock")); e == nil {
+        t.Error("Expected header error!")
+    }
+}
@@ -0,0 +1,347 @@
+/*
+ * Copyright (c) 2015 Tom Swindell (t.swindell@rubyx.co.uk)
+ *
+ * Permission is hereby granted, free of charge, to any person obtaining a copy
+ * of this software and associated documentation files (the "Software"), to deal
+ * in the Software without restriction, including without limitation the rights
+ * to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+ * copies of the Software, and to permit persons to whom the Software is
+ * furnished to do so, subject to the following conditions:
+ *
+ * The above copyright notice and this permission notice shall be included in
+ * all copies or substantial portions of the Software.
+ *
+ * THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+ * IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+ * FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+ * AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+ * LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+ * OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+ * SOFTWARE.
+ *
+ */
+
+package set
+
+import (
+    "bufio"
+    "bytes"
+    "encoding/binary"
+    "fmt"
+    "hash"
+    "hash/crc32"
+    "io"
+    "sort"
+)
+
+// Version 2 of the serialization format wraps the state of a set in a self
+// describing container:
+//
+//   magic     FORMAT_MAGIC
+//   version   uvarint, FORMAT_VERSION
+//   type      uvarint length and type name, the v1 header without its NUL
+//   length    uvarint length of the sections which follow
+//   sections  each a uvarint tag, uvarint length and the section data
+//   checksum  uint32 crc32 of everything above, little endian
+//
+// Sections are specific to each type. Readers keep sections with tags they do
+// not know, and write them back out unchanged, so fields added by a newer
+// replica survive a round trip through an older one.
+//
+// Containers are written and read as streams. The writer sizes each section
+// before writing it, and the reader never reads past the checksum when given
+// an io.ByteReader, so containers can be embedded in other data.
+//
+// Version 1 blobs start with the type header itself, "crdt:" rather than the
+// NUL of FORMAT_MAGIC, and are still read by every set.
+var FORMAT_MAGIC = []byte{'c', 'r', 'd', 't', 0x00}
+
+const FORMAT_VERSION = 2
+
+// The section type holds one tagged section of a v2 container.
+type section struct {
+    tag  uint64
+    data []byte
+}
+
+// The sections type holds the sections of a container in the order written.
+type sections []section
+
+// Returns the union of both lists, keeping the first of any identical pair.
+func (s sections) union(other sections) sections {
+    result := append(sections{}, s...)
+    for _, i := range other {
+        found := false
+        for _, j := range result {
+            if i.tag == j.tag && bytes.Equal(i.data, j.data) { found = true; break }
+        }
+        if !found { result = append(result, i) }
+    }
+    sort.SliceStable(result, func(a, b int) bool { return result[a].tag < result[b].tag })
+    return result
+}
+
+// The records type emits the records of a section in order, returning the
+// first error from emit. It is called twice, first to size the section and
+// then to write it, and must emit the same records both times.
+type records func(emit func([]byte) error) error
+
+// The sectionWriter type streams one tagged section of a container.
+type sectionWriter struct {
+    tag     uint64
+    records records
+}
+
+// Returns the records encoding each of items with codec.
+func elementRecords[T comparable](codec Codec[T], items []T) records {
+    return func(emit func([]byte) error) error {
+        scratch := new(bytes.Buffer)
+        for _, i := range items {
+            scratch.Reset()
+            if e := writeElementV2(scratch, codec, i); e != nil { return e }
+            if e := emit(scratch.Bytes()); e != nil { return e }
+        }
+        return nil
+    }
+}
+
+func writeUvarint(w io.Writer, v uint64) error {
+    _, e := w.Write(binary.AppendUvarint(nil, v))
+    return e
+}
+
+func uvarintLen(v uint64) uint64 {
+    return uint64(len(binary.AppendUvarint(nil, v)))
+}
+
+// The byteReader interface is what the container reader needs from its input
+// to read varints without reading ahead.
+type byteReader interface {
+    io.Reader
+    io.ByteReader
+}
+
+func readUvarint(r io.ByteReader) (uint64, error) {
+    v, e := binary.ReadUvarint(r)
+    if e == io.EOF || e == io.ErrUnexpectedEOF { e = fmt.Errorf("data too small") }
+    return v, e
+}
+
+// Writes data prefixed with its uvarint length.
+func writeBytes(w io.Writer, data []byte) error {
+    if e := writeUvarint(w, uint64(len(data))); e != nil { return e }
+    _, e := w.Write(data)
+    return e
+}
+
+// Reads exactly n bytes, growing the result as the data arrives so a corrupt
+// length can not force a huge allocation.
+func readN(r io.Reader, n uint64) ([]byte, error) {
+    data, e := io.ReadAll(io.LimitReader(r, int64(n)))
+    if e != nil { return nil, e }
+    if uint64(len(data)) != n { return nil, fmt.Errorf("data too small") }
+    return data, nil
+}
+
+func readBytes(r byteReader) ([]byte, error) {
+    l, e := readUvarint(r)
+    if e != nil { return nil, e }
+    if l > 1 << 62 { return nil, fmt.Errorf("invalid format") }
+
+    return readN(r, l)
+}
+
+// Writes a set element as its uvarint length and the raw data from codec.
+func writeElementV2[T comparable](w io.Writer, codec Codec[T], item T) error {
+    if codec == nil { return fmt.Errorf("no codec for element type") }
+
+    data, e := codec.Encode(item)
+    if e != nil { return e }
+
+    return writeBytes(w, data)
+}
+
+func readElementV2[T comparable](r byteReader, codec Codec[T]) (T, error) {
+    var item T
+    if codec == nil { return item, fmt.Errorf("no codec for element type") }
+
+    data, e := readBytes(r)
+    if e != nil { return item, e }
+
+    return codec.Decode(data)
+}
+
+// Reads every element in a section.
+func readElements[T comparable](in *limitedReader, codec Codec[T]) ([]T, error) {
+    var items []T
+    for in.n > 0 {
+        item, e := readElementV2(in, codec)
+        if e != nil { return nil, e }
+        items = append(items, item)
+    }
+    return items, nil
+}
+
+// Reads the start of a serialized set of the type identified by its v1 header
+// magic. Returns true when a v2 container follows, and false when it was the
+// v1 header itself.
+func readFormat(r io.Reader, magic []byte) (bool, error) {
+    head := make([]byte, len(FORMAT_MAGIC))
+    if _, e := io.ReadFull(r, head); e != nil { return false, fmt.Errorf("data too small") }
+
+    if bytes.Equal(head, FORMAT_MAGIC) { return true, nil }
+    if !bytes.HasPrefix(magic, head) { return false, fmt.Errorf("invalid header") }
+
+    rest := make([]byte, len(magic) - len(head))
+    if _, e := io.ReadFull(r, rest); e != nil { return false, fmt.Errorf("data too small") }
+    if !bytes.Equal(rest, magic[len(head):]) { return false, fmt.Errorf("invalid header") }
+
+    return false, nil
+}
+
+// Writes a v2 container for the type identified by its v1 header magic. The
+// known sections are written in order, followed by any unknown sections to
+// preserve. Nothing is buffered beyond a single record.
+func writeContainer(w io.Writer, magic []byte, known []sectionWriter, unknown sections) error {
+    writers := append([]sectionWriter{}, known...)
+    for _, u := range unknown {
+        data := u.data
+        writers = append(writers, sectionWriter{u.tag, func(emit func([]byte) error) error { return emit(data) }})
+    }
+
+    sizes := make([]uint64, len(writers))
+    length := uint64(0)
+    for i, s := range writers {
+        e := s.records(func(r []byte) error { sizes[i] += uint64(len(r)); return nil })
+        if e != nil { return e }
+
+        length += uvarintLen(s.tag) + uvarintLen(sizes[i]) + sizes[i]
+    }
+
+    checksum := crc32.NewIEEE()
+    out := bufio.NewWriter(io.MultiWriter(w, checksum))
+
+    out.Write(FORMAT_MAGIC)
+    writeUvarint(out, FORMAT_VERSION)
+    writeBytes(out, bytes.TrimRight(magic, "\x00"))
+    writeUvarint(out, length)
+
+    for i, s := range writers {
+        writeUvarint(out, s.tag)
+        writeUvarint(out, sizes[i])
+
+        written := uint64(0)
+        e := s.records(func(r []byte) error {
+            written += uint64(len(r))
+            _, e := out.Write(r)
+            return e
+        })
+        if e != nil { return e }
+        if written != sizes[i] { return fmt.Errorf("section %d changed while writing", s.tag) }
+    }
+
+    if e := out.Flush(); e != nil { return e }
+    return binary.Write(w, binary.LittleEndian, checksum.Sum32())
+}
+
+// The checksumReader type reads a byte at a time when its input can not, so
+// it never reads past the end of a container, and checksums all it reads.
+type checksumReader struct {
+    r        io.Reader
+    checksum hash.Hash32
+}
+
+func (c *checksumReader) Read(p []byte) (int, error) {
+    n, e := c.r.Read(p)
+    c.checksum.Write(p[:n])
+    return n, e
+}
+
+func (c *checksumReader) ReadByte() (byte, error) {
+    var b [1]byte
+    if br, ok := c.r.(io.ByteReader); ok {
+        v, e := br.ReadByte()
+        if e != nil { return 0, e }
+        b[0] = v
+    } else if _, e := io.ReadFull(c.r, b[:]); e != nil {
+        return 0, e
+    }
+    c.checksum.Write(b[:])
+    return b[0], nil
+}
+
+// The limitedReader type reads at most n more bytes of a container.
+type limitedReader struct {
+    r byteReader
+    n uint64
+}
+
+func (l *limitedReader) Read(p []byte) (int, error) {
+    if l.n == 0 { return 0, io.EOF }
+    if uint64(len(p)) > l.n { p = p[:l.n] }
+
+    n, e := l.r.Read(p)
+    l.n -= uint64(n)
+    return n, e
+}
+
+func (l *limitedReader) ReadByte() (byte, error) {
+    if l.n == 0 { return 0, io.EOF }
+
+    b, e := l.r.ReadByte()
+    if e == nil { l.n-- }
+    return b, e
+}
+
+// The sectionReader type reads a known section, consuming all of in.
+type sectionReader func(in *limitedReader) error
+
+// Reads the rest of a v2 container, after readFormat, for the type identified
+// by its v1 header magic. Known sections are passed to their reader and the
+// unknown sections returned. The checksum is only verified once the whole
+// container has been read, so readers must not apply what they decode until
+// readContainer returns.
+func readContainer(r io.Reader, magic []byte, known map[uint64]sectionReader) (sections, error) {
+    in := &checksumReader{r, crc32.NewIEEE()}
+    in.checksum.Write(FORMAT_MAGIC)
+
+    version, e := readUvarint(in)
+    if e != nil { return nil, e }
+    if version != FORMAT_VERSION { return nil, fmt.Errorf("unsupported format version %d", version) }
+
+    typeId, e := readBytes(in)
+    if e != nil { return nil, e }
+    if !bytes.Equal(typeId, bytes.TrimRight(magic, "\x00")) { return nil, fmt.Errorf("invalid header") }
+
+    length, e := readUvarint(in)
+    if e != nil { return nil, e }
+
+    var unknown sections
+    payload := &limitedReader{in, length}
+    for payload.n > 0 {
+        tag, e := readUvarint(payload)
+        if e != nil { return nil, e }
+
+        size, e := readUvarint(payload)
+        if e != nil { return nil, e }
+        if size > payload.n { return nil, fmt.Errorf("invalid format") }
+
+        data := &limitedReader{payload, size}
+        if read, found := known[tag]; found {
+            if e := read(data); e != nil { return nil, e }
+            if data.n != 0 { return nil, fmt.Errorf("invalid format") }
+        } else {
+            v, e := readN(data, size)
+            if e != nil { return nil, e }
+            unknown = append(unknown, section{tag, v})
+        }
+    }
+
+    expected := in.checksum.Sum32()
+
+    var checksum uint32
+    if e := binary.Read(r, binary.LittleEndian, &checksum); e != nil { return nil, fmt.Errorf("data too small") }
+    if checksum != expected { return nil, fmt.Errorf("crc32 failure") }
+
+    return unknown, nil
+}
@@ -0,0 +1,323 @@
+/*
+ * Copyright (c) 2015 Tom Swindell (t.swindell@rubyx.co.uk)
+ *
+ * Permission is hereby granted, free of charge, to any person obtaining a copy
+ * of this software and associated documentation files (the "Software"), to deal
+ * in the Software without restriction, including without limitation the rights
+ * to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+ * copies of the Software, and to permit persons to whom the Software is
+ * furnished to do so, subject to the following conditions:
+ *
+ * The above copyright notice and this permission notice shall be included in
+ * all copies or substantial portions of the Software.
+ *
+ * THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+ * IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+ * FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+ * AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+ * LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+ * OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+ * SOFTWARE.
+ *
+ */
+
+package set
+
+import "testing"
+import "bytes"
+import "encoding/binary"
+import "hash/crc32"
+import "fmt"
+import "io"
+import "runtime"
+import "testing/iotest"
+
+// Writes a set element in the v1 layout, read by readElement.
+func writeElement[T comparable](buff *bytes.Buffer, codec Codec[T], item T) {
+    data, _ := codec.Encode(item)
+
+    binary.Write(buff, binary.LittleEndian, uint64(len(data)))
+    binary.Write(buff, binary.LittleEndian, crc32.ChecksumIEEE(data))
+    buff.Write(data)
+}
+
+// Returns a v1 GSet blob holding items.
+func gsetV1(items ...string) []byte {
+    buff := new(bytes.Buffer)
+    buff.Write(GSET_HEADER_MAGIC)
+    binary.Write(buff, binary.LittleEndian, uint32(len(items)))
+    for _, i := range items { writeElement[string](buff, StringCodec{}, i) }
+    return buff.Bytes()
+}
+
+// Checks s serializes to a v2 container which reads back into empty.
+func checkMigrated[S interface{ Serialize(io.Writer) error; Deserialize(io.Reader) error }](t *testing.T, s, empty S, equals func(S, S) bool) {
+    out := new(bytes.Buffer)
+    if e := s.Serialize(out); e != nil { t.Fatal(e) }
+
+    if !bytes.HasPrefix(out.Bytes(), FORMAT_MAGIC) { t.Fatal("Expected a v2 container!") }
+
+    if e := empty.Deserialize(out); e != nil { t.Fatal(e) }
+    if !equals(s, empty) { t.Error("Migrated set does not match original!") }
+    if out.Len() != 0 { t.Errorf("Expected container to be consumed, %d bytes left", out.Len()) }
+}
+
+func TestFormatV1GSet(t *testing.T) {
+    a := NewGSet[string]()
+    if e := a.Deserialize(bytes.NewBuffer(gsetV1("a", "b", "c"))); e != nil { t.Fatal(e) }
+
+    if a.Length() != 3 || !a.Contains("b") { t.Error("Unexpected contents from v1 data!") }
+
+    checkMigrated(t, a, NewGSet[string](), (*GSet[string]).Equals)
+}
+
+func TestFormatV1TwoPhase(t *testing.T) {
+    buff := new(bytes.Buffer)
+    buff.Write(TWOPHASESET_HEADER_MAGIC)
+    buff.Write(gsetV1("a", "b", "c"))
+    buff.Write(gsetV1("b"))
+
+    a := New2P[string]()
+    if e := a.Deserialize(buff); e != nil { t.Fatal(e) }
+
+    if a.Length() != 2 || a.Contains("b") { t.Error("Unexpected contents from v1 data!") }
+
+    checkMigrated(t, a, New2P[string](), (*TwoPhase[string]).Equals)
+}
+
+// Returns a v1 ORSet blob holding "a", and "b" inserted and removed.
+func orsetV1() []byte {
+    tag := bytes.Repeat([]byte{7}, ORSET_TAG_SIZE)
+
+    buff := new(bytes.Buffer)
+    buff.Write(ORSET_HEADER_MAGIC)
+    binary.Write(buff, binary.LittleEndian, uint32(2))
+    for i, item := range []string{"a", "b"} {
+        writeElement[string](buff, StringCodec{}, item)
+        binary.Write(buff, binary.LittleEndian, uint32(1))
+        buff.Write(tag)
+        binary.Write(buff, binary.LittleEndian, uint32(i))
+        if i == 1 { buff.Write(tag) }
+    }
+    return buff.Bytes()
+}
+
+// Returns a v1 LWWSet blob holding "a" inserted before its removal, and "b".
+func lwwsetV1() []byte {
+    buff := new(bytes.Buffer)
+    buff.Write(LWWSET_HEADER_MAGIC)
+    binary.Write(buff, binary.LittleEndian, uint32(2))
+    writeElement[string](buff, StringCodec{}, "a")
+    binary.Write(buff, binary.LittleEndian, int64(10))
+    writeElement[string](buff, StringCodec{}, "b")
+    binary.Write(buff, binary.LittleEndian, int64(-10))
+    binary.Write(buff, binary.LittleEndian, uint32(1))
+    writeElement[string](buff, StringCodec{}, "a")
+    binary.Write(buff, binary.LittleEndian, int64(20))
+    return buff.Bytes()
+}
+
+func TestFormatV1ORSet(t *testing.T) {
+    a := NewORSet[string]()
+    if e := a.Deserialize(bytes.NewBuffer(orsetV1())); e != nil { t.Fatal(e) }
+
+    if !a.Contains("a") || a.Contains("b") { t.Error("Unexpected contents from v1 data!") }
+
+    checkMigrated(t, a, NewORSet[string](), (*ORSet[string]).Equals)
+}
+
+func TestFormatV1LWWSet(t *testing.T) {
+    a := NewLWWSet[string](&tickClock{}, LWW_ADD_WINS)
+    if e := a.Deserialize(bytes.NewBuffer(lwwsetV1())); e != nil { t.Fatal(e) }
+
+    if a.Contains("a") || !a.Contains("b") { t.Error("Unexpected contents from v1 data!") }
+
+    checkMigrated(t, a, NewLWWSet[string](&tickClock{}, LWW_ADD_WINS), (*LWWSet[string]).Equals)
+}
+
+func TestFormatV1Truncated(t *testing.T) {
+    data := gsetV1("a", "b")
+
+    for i := 0; i < len(data); i++ {
+        if e := NewGSet[string]().Deserialize(bytes.NewBuffer(data[:i])); e == nil {
+            t.Errorf("Expected error reading v1 data truncated to %d bytes!", i)
+        }
+    }
+}
+
+// Truncated v1 data must be refused without merging the part read before it.
+func TestFormatV1TruncatedORSet(t *testing.T) {
+    data := orsetV1()
+
+    for i := 0; i < len(data); i++ {
+        a := NewORSet[string]()
+        if e := a.Deserialize(bytes.NewBuffer(data[:i])); e == nil {
+            t.Errorf("Expected error reading v1 data truncated to %d bytes!", i)
+        }
+        if !a.Equals(NewORSet[string]()) { t.Errorf("Data truncated to %d bytes modified the set!", i) }
+    }
+}
+
+func TestFormatV1TruncatedLWWSet(t *testing.T) {
+    data := lwwsetV1()
+
+    for i := 0; i < len(data); i++ {
+        a := NewLWWSet[string](&tickClock{}, LWW_ADD_WINS)
+        if e := a.Deserialize(bytes.NewBuffer(data[:i])); e == nil {
+            t.Errorf("Expected error reading v1 data truncated to %d bytes!", i)
+        }
+        if !a.Equals(NewLWWSet[string](&tickClock{}, LWW_ADD_WINS)) {
+            t.Errorf("Data truncated to %d bytes modified the set!", i)
+        }
+    }
+}
+
+// Lengths read from corrupt data must not be allocated up front.
+func TestFormatHugeLength(t *testing.T) {
+    v1 := new(bytes.Buffer)
+    v1.Write(GSET_HEADER_MAGIC)
+    binary.Write(v1, binary.LittleEndian, uint32(1))
+    binary.Write(v1, binary.LittleEndian, uint64(1 << 40))
+    binary.Write(v1, binary.LittleEndian, uint32(0))
+    v1.WriteString("short")
+
+    v2 := new(bytes.Buffer)
+    v2.Write(FORMAT_MAGIC)
+    v2.Write(binary.AppendUvarint(nil, FORMAT_VERSION))
+    writeBytes(v2, []byte("crdt:gset"))
+    v2.Write(binary.AppendUvarint(nil, 1 << 40))
+    v2.Write(binary.AppendUvarint(nil, GSET_SECTION_ELEMENTS))
+    v2.Write(binary.AppendUvarint(nil, 1 << 39))
+    v2.Write(binary.AppendUvarint(nil, 1 << 38))
+    v2.WriteString("short")
+
+    for _, data := range [][]byte{v1.Bytes(), v2.Bytes()} {
+        var before, after runtime.MemStats
+        runtime.ReadMemStats(&before)
+
+        if e := NewGSet[string]().Deserialize(bytes.NewReader(data)); e == nil {
+            t.Error("Expected error reading truncated element!")
+        }
+
+        runtime.ReadMemStats(&after)
+        if n := after.TotalAlloc - before.TotalAlloc; n > 1 << 20 {
+            t.Errorf("Corrupt length allocated %d bytes!", n)
+        }
+    }
+}
+
+func TestFormatKeepsHistory(t *testing.T) {
+    a := NewGSet[string]()
+    for _, i := range []string{"c", "a", "b"} { a.Insert(i) }
+
+    buff := new(bytes.Buffer)
+    a.Serialize(buff)
+
+    b := NewGSet[string]()
+    b.Deserialize(buff)
+
+    for i, seq := range a.contents {
+        if b.contents[i] != seq { t.Errorf("Expected %s at position %d, got %d", i, seq, b.contents[i]) }
+    }
+}
+
+func TestFormatChecksum(t *testing.T) {
+    a := New2P[string]()
+    a.Insert("hello")
+
+    buff := new(bytes.Buffer)
+    a.Serialize(buff)
+    data := buff.Bytes()
+
+    for i := len(FORMAT_MAGIC); i < len(data); i++ {
+        corrupt := bytes.Clone(data)
+        corrupt[i] ^= 0x01
+
+        if e := New2P[string]().Deserialize(bytes.NewBuffer(corrupt)); e == nil {
+            t.Errorf("Expected error reading data corrupted at byte %d!", i)
+        }
+    }
+
+    for i := 0; i < len(data); i++ {
+        if e := New2P[string]().Deserialize(bytes.NewBuffer(data[:i])); e == nil {
+            t.Errorf("Expected error reading data truncated to %d bytes!", i)
+        }
+    }
+}
+
+func TestFormatWrongType(t *testing.T) {
+    a := NewGSet[string]()
+    a.Insert("hello")
+
+    buff := new(bytes.Buffer)
+    a.Serialize(buff)
+
+    if e := NewORSet[string]().Deserialize(buff); e == nil {
+        t.Error("Expected error reading GSet data into an ORSet!")
+    }
+}
+
+func TestFormatVersion(t *testing.T) {
+    buff := new(bytes.Buffer)
+    if e := writeContainer(buff, GSET_HEADER_MAGIC, nil, nil); e != nil { t.Fatal(e) }
+
+    data := buff.Bytes()
+    data[len(FORMAT_MAGIC)] = FORMAT_VERSION + 1
+
+    if e := NewGSet[string]().Deserialize(bytes.NewBuffer(data)); e == nil {
+        t.Error("Expected error reading an unsupported format version!")
+    }
+}
+
+func TestFormatUnknownSections(t *testing.T) {
+    elements := sectionWriter{GSET_SECTION_ELEMENTS, elementRecords[string](StringCodec{}, []string{"hello"})}
+
+    future := section{99, []byte("from the future")}
+
+    buff := new(bytes.Buffer)
+    if e := writeContainer(buff, GSET_HEADER_MAGIC, []sectionWriter{elements}, sections{future}); e != nil { t.Fatal(e) }
+
+    a := NewGSet[string]()
+    if e := a.Deserialize(buff); e != nil { t.Fatal(e) }
+    if !a.Contains("hello") { t.Error("Expected known section to be read!") }
+
+    // Unknown sections survive clones, merges and a round trip.
+    b := NewGSet[string]()
+    b.Merge(a.Clone())
+
+    out := new(bytes.Buffer)
+    if e := b.Serialize(out); e != nil { t.Fatal(e) }
+
+    if _, e := readFormat(out, GSET_HEADER_MAGIC); e != nil { t.Fatal(e) }
+
+    unknown, e := readContainer(out, GSET_HEADER_MAGIC, map[uint64]sectionReader{
+        GSET_SECTION_ELEMENTS: func(in *limitedReader) error { _, e := readN(in, in.n); return e },
+    })
+    if e != nil { t.Fatal(e) }
+
+    if len(unknown) != 1 { t.Fatal("Unknown section was not preserved once!") }
+    if unknown[0].tag != 99 || !bytes.Equal(unknown[0].data, future.data) { t.Error("Unknown section was not preserved!") }
+}
+
+func TestFormatStreamed(t *testing.T) {
+    a := New2P[string]()
+    b := NewORSet[string]()
+    for i := 0; i < 100; i++ { a.Insert(fmt.Sprint(i)); b.Insert(fmt.Sprint(i)) }
+    a.Remove("7")
+    b.Remove("7")
+
+    buff := new(bytes.Buffer)
+    if e := a.Serialize(buff); e != nil { t.Fatal(e) }
+    if e := b.Serialize(buff); e != nil { t.Fatal(e) }
+
+    // Neither set may read past its own container, even from a plain reader.
+    in := iotest.HalfReader(struct{ io.Reader }{buff})
+
+    c := New2P[string]()
+    if e := c.Deserialize(in); e != nil { t.Fatal(e) }
+    if !a.Equals(c) { t.Error("Streamed TwoPhase does not match original!") }
+
+    d := NewORSet[string]()
+    if e := d.Deserialize(in); e != nil { t.Fatal(e) }
+    if !b.Equals(d) { t.Error("Streamed ORSet does not match original!") }
+}
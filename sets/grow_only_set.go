@@ -25,35 +25,93 @@ package set
 
 import (
     "bytes"
-    "encoding/base64"
     "encoding/binary"
     "fmt"
-    "hash/crc32"
     "io"
+    "iter"
+    "sort"
     "sync"
 )
 
-// Common Go representation of a grow only set.
-type GSet struct {
+// Common Go representation of a grow only set. Each element maps to its
+// position in the insertion history of this set, numbered from one. Elements
+// are serialized by codec.
+//
+// A GSet must be used through the pointer returned by NewGSet, and is then
+// safe for concurrent use: every method holds the lock of the set for its
+// duration. Methods taking another set snapshot it first, so two sets may be
+// merged or compared with each other concurrently from both sides.
+type GSet[T comparable] struct {
     sync.RWMutex
-    contents map[interface{}]struct{}
+    codec    Codec[T]
+    contents map[T]uint64
+    length   uint64        // Length of the insertion history.
+    metadata provenance[T] // Metadata of the elements inserted with any.
+    unknown  sections      // Sections of a newer format, kept to write back out.
 }
 
-func NewGSet() GSet {
-    return GSet{contents: make(map[interface{}]struct{})}
+// The NewGSet function returns a set serialized by the DefaultCodec of T.
+func NewGSet[T comparable]() *GSet[T] {
+    return NewGSetWithCodec(DefaultCodec[T]())
 }
 
-func (s GSet) Insert(item interface{}) bool {
+func NewGSetWithCodec[T comparable](codec Codec[T]) *GSet[T] {
+    return &GSet[T]{codec: codec, contents: make(map[T]uint64), metadata: make(provenance[T])}
+}
+
+// Appends item to the insertion history unless already present. The caller
+// must hold the lock.
+func (s *GSet[T]) insert(item T) bool {
+    if _, found := s.contents[item]; found { return false }
+    s.length++
+    s.contents[item] = s.length
+
+    return true
+}
+
+func (s *GSet[T]) Insert(item T) bool {
     s.Lock()
     defer s.Unlock()
 
-    _, found := s.contents[item]
-    s.contents[item] = struct{}{}
+    return s.insert(item)
+}
+
+// The InsertWithMetadata() method inserts item, recording md as its metadata.
+// Returns false, recording nothing, when item is already present.
+func (s *GSet[T]) InsertWithMetadata(item T, md Metadata) bool {
+    s.Lock()
+    defer s.Unlock()
 
-    return !found
+    if !s.insert(item) { return false }
+    s.metadata[item] = md.clone()
+    return true
+}
+
+func (s *GSet[T]) Metadata(item T) (Metadata, bool) {
+    s.RLock()
+    defer s.RUnlock()
+
+    md, found := s.metadata[item]
+    return md, found
 }
 
-func (s GSet) Contains(item interface{}) bool {
+// Returns a snapshot of the metadata of the set.
+func (s *GSet[T]) provenance() provenance[T] {
+    s.RLock()
+    defer s.RUnlock()
+
+    return s.metadata.clone()
+}
+
+// Records the metadata of those elements of metadata the set contains. The
+// caller must hold the lock.
+func (s *GSet[T]) annotate(metadata provenance[T]) {
+    for i, md := range metadata {
+        if _, found := s.contents[i]; found { s.metadata.update(i, md) }
+    }
+}
+
+func (s *GSet[T]) Contains(item T) bool {
     s.RLock()
     defer s.RUnlock()
 
@@ -62,65 +120,79 @@ func (s GSet) Contains(item interface{}) bool {
     return found
 }
 
-func (s GSet) Length() int {
+func (s *GSet[T]) Length() int {
     s.RLock()
     defer s.RUnlock()
 
     return len(s.contents)
 }
 
-func (s GSet) Equals(other GSet) bool {
+func (s *GSet[T]) Equals(other *GSet[T]) bool {
+    in := other.Clone()
+
     s.RLock()
     defer s.RUnlock()
 
-    if len(s.contents) != other.Length() {
+    if len(s.contents) != len(in.contents) {
         return false
     }
 
     for i := range s.contents {
-        if !other.Contains(i) {
+        if _, found := in.contents[i]; !found {
             return false
         }
     }
-    return true
+    return s.metadata.equals(in.metadata)
 }
 
-func (s GSet) Clone() GSet {
+func (s *GSet[T]) Clone() *GSet[T] {
     s.RLock()
     defer s.RUnlock()
 
-    result := NewGSet()
-    for i := range s.contents {
-        result.Insert(i)
+    result := NewGSetWithCodec(s.codec)
+    for i, n := range s.contents {
+        result.contents[i] = n
     }
+    result.length = s.length
+    result.metadata = s.metadata.clone()
+    result.unknown = s.unknown
 
     return result
 }
 
-func (s GSet) Merge(other GSet) {
-    for i := range other.Iterate() {
-        s.Insert(i)
+func (s *GSet[T]) Merge(other *GSet[T]) {
+    // Snapshot the other set first, so two sets merging into each other
+    // can never deadlock.
+    in := other.Clone()
+
+    s.Lock()
+    defer s.Unlock()
+
+    for i := range in.contents {
+        s.insert(i)
     }
+    s.metadata.merge(in.metadata)
+    s.unknown = s.unknown.union(in.unknown)
 }
 
-func (s GSet) Iterate() <-chan interface{} {
-    ch := make(chan interface{})
-    go func() {
-        s.RLock()
-        defer s.RUnlock()
-
-        for i := range s.contents { ch <- i }
+// The Iterate() method ranges over a snapshot of the set, so the lock is never
+// held while the loop body runs and the loop may modify the set.
+func (s *GSet[T]) Iterate() iter.Seq[T] {
+    return iterate(s.ToSlice())
+}
 
-        close(ch)
-    }()
-    return ch
+// The IterateWithMetadata() method ranges over a snapshot of the set, as
+// Iterate() does, yielding each element with its metadata.
+func (s *GSet[T]) IterateWithMetadata() iter.Seq2[T, Metadata] {
+    metadata := s.provenance()
+    return iterateWithMetadata(s.ToSlice(), metadata)
 }
 
-func (s GSet) ToSlice() []interface{} {
+func (s *GSet[T]) ToSlice() []T {
     s.RLock()
     defer s.RUnlock()
 
-    result := make([]interface{}, 0, len(s.contents))
+    result := make([]T, 0, len(s.contents))
     for i := range s.contents { result = append(result, i) }
 
     return result
@@ -128,58 +200,175 @@ func (s GSet) ToSlice() []interface{} {
 
 var GSET_HEADER_MAGIC = []byte{'c', 'r', 'd', 't', ':', 'g', 's', 'e', 't', 0x00}
 
-func (s GSet) Serialize(buff *bytes.Buffer) error {
+// Sections of a v2 GSet container.
+const (
+    GSET_SECTION_ELEMENTS = 1 // Elements in insertion order.
+    GSET_SECTION_METADATA = 2 // Each element with its metadata, when any has.
+)
+
+// Returns a snapshot of the elements in insertion order.
+func (s *GSet[T]) history() []T {
     s.RLock()
     defer s.RUnlock()
 
-    buff.Write(GSET_HEADER_MAGIC)
-    binary.Write(buff, binary.LittleEndian, uint32(len(s.contents)))
+    if s.length == uint64(len(s.contents)) {
+        history := make([]T, len(s.contents))
+        for i, seq := range s.contents { history[seq - 1] = i }
+        return history
+    }
 
-    for i := range s.contents {
-        data, e := base64.StdEncoding.DecodeString(i.(string))
-        if e != nil { return nil }
+    // Forgotten elements leave gaps in the history.
+    history := make([]T, 0, len(s.contents))
+    for i := range s.contents { history = append(history, i) }
+    sort.Slice(history, func(a, b int) bool { return s.contents[history[a]] < s.contents[history[b]] })
+    return history
+}
 
-        binary.Write(buff, binary.LittleEndian, uint64(len(data)))
-        binary.Write(buff, binary.LittleEndian, crc32.ChecksumIEEE(data))
-        buff.Write(data)
-    }
+// Returns the section writing the elements in insertion order, so that a
+// restored set keeps its history.
+func (s *GSet[T]) elements(tag uint64) sectionWriter {
+    return sectionWriter{tag, elementRecords(s.codec, s.history())}
+}
 
-    return nil
+// The Serialize() method streams a snapshot of the set to w, without holding
+// the lock while writing.
+func (s *GSet[T]) Serialize(w io.Writer) error {
+    s.RLock()
+    unknown := s.unknown
+    s.RUnlock()
+
+    known := append([]sectionWriter{s.elements(GSET_SECTION_ELEMENTS)},
+                    s.provenance().sections(GSET_SECTION_METADATA, s.codec)...)
+    return writeContainer(w, GSET_HEADER_MAGIC, known, unknown)
 }
 
-func (s GSet) Deserialize(buff *bytes.Buffer) error {
-    if buff.Len() < len(GSET_HEADER_MAGIC) { return fmt.Errorf("data too small") }
+// Deserialize merges the serialized state, in either format, into this set.
+// Nothing is merged unless all of it is read successfully.
+func (s *GSet[T]) Deserialize(r io.Reader) error {
+    if s.codec == nil { return fmt.Errorf("no codec for element type") }
+
+    v2, e := readFormat(r, GSET_HEADER_MAGIC)
+    if e != nil { return e }
+    if !v2 { return s.deserializeV1(r) }
+
+    var items []T
+    metadata := make(provenance[T])
+    unknown, e := readContainer(r, GSET_HEADER_MAGIC, map[uint64]sectionReader{
+        GSET_SECTION_ELEMENTS: func(in *limitedReader) (e error) {
+            items, e = readElements(in, s.codec)
+            return
+        },
+        GSET_SECTION_METADATA: metadata.reader(s.codec),
+    })
+    if e != nil { return e }
+
+    s.Lock()
+    defer s.Unlock()
 
-    header := make([]byte, len(GSET_HEADER_MAGIC))
-    _, e := buff.Read(header)
-    if e != nil || !bytes.Equal(header, GSET_HEADER_MAGIC) { return fmt.Errorf("invalid header") }
+    for _, i := range items { s.insert(i) }
+    s.annotate(metadata)
+    s.unknown = s.unknown.union(unknown)
+    return nil
+}
 
+// Reads the v1 format after its header, a uint32 count followed by each
+// element as read by readElement.
+func (s *GSet[T]) deserializeV1(r io.Reader) error {
     var sizeof uint32
-    if e := binary.Read(buff, binary.LittleEndian, &sizeof); e != nil {
+    if e := binary.Read(r, binary.LittleEndian, &sizeof); e != nil {
         return e
     }
 
+    var items []T
     for i := uint32(0); i < sizeof; i++ {
-        var datl uint64
-        var datc uint32
+        item, e := readElement(r, s.codec)
+        if e != nil { return e }
+        items = append(items, item)
+    }
 
-        if e := binary.Read(buff, binary.LittleEndian, &datl); e != nil {
-            if e == io.EOF { return nil }
-        }
-        if e := binary.Read(buff, binary.LittleEndian, &datc); e != nil {
-            return e
-        }
+    s.Lock()
+    defer s.Unlock()
 
-        object := make([]byte, datl)
-        if l, e := buff.Read(object); e != nil || l != int(datl) {
-            return fmt.Errorf("invalid format")
-        }
+    for _, i := range items { s.insert(i) }
+    return nil
+}
 
-        if datc != crc32.ChecksumIEEE(object) { return fmt.Errorf("crc32 failure") }
 
-        s.Insert(base64.StdEncoding.EncodeToString(object))
+// Removes item, keeping the positions of the remaining history so that markers
+// stay valid. Only sets built on a GSet may forget elements, once it is safe
+// for them to do so. The caller must hold the lock.
+func (s *GSet[T]) forget(item T) {
+    delete(s.contents, item)
+    delete(s.metadata, item)
+}
+
+// Returns how many elements at or below position n of the history are still
+// held.
+func (s *GSet[T]) heldTo(n uint64) uint64 {
+    s.RLock()
+    defer s.RUnlock()
+
+    count := uint64(0)
+    for _, seq := range s.contents {
+        if seq <= n { count++ }
     }
+    return count
+}
 
-    return nil
+func (s *GSet[T]) Marker() Marker {
+    s.RLock()
+    defer s.RUnlock()
+
+    return Marker{s.length}
+}
+
+// Returns the elements inserted after position n of the history. A position
+// beyond the end of history, such as one from before a restore, returns every
+// element.
+func (s *GSet[T]) since(n uint64) *GSet[T] {
+    s.RLock()
+    defer s.RUnlock()
+
+    if n > s.length { n = 0 }
+
+    result := NewGSetWithCodec(s.codec)
+    for i, seq := range s.contents {
+        if seq <= n { continue }
+
+        result.insert(i)
+        if md, found := s.metadata[i]; found { result.metadata[i] = md }
+    }
+    return result
 }
 
+// The DeltaSince() method returns the elements inserted since marker, and the
+// marker to request the next delta from.
+func (s *GSet[T]) DeltaSince(marker Marker) (*GSet[T], Marker) {
+    next := s.Marker()
+    return s.since(marker.get(0)), next
+}
+
+func (s *GSet[T]) ApplyDelta(delta *GSet[T]) {
+    s.Merge(delta)
+}
+
+func (s *GSet[T]) SerializeDelta(buff *bytes.Buffer, marker Marker) (Marker, error) {
+    delta, next := s.DeltaSince(marker)
+
+    writeDeltaHeader(buff, marker, next)
+    if e := delta.Serialize(buff); e != nil { return nil, e }
+    return next, nil
+}
+
+// DeserializeDelta applies a serialized delta, returning the marker it reaches
+// in the history of the set which produced it.
+func (s *GSet[T]) DeserializeDelta(buff *bytes.Buffer) (Marker, error) {
+    next, e := readDeltaHeader(buff)
+    if e != nil { return nil, e }
+
+    delta := NewGSetWithCodec(s.codec)
+    if e := delta.Deserialize(buff); e != nil { return nil, e }
+
+    s.ApplyDelta(delta)
+    return next, nil
+}
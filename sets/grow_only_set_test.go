@@ -27,16 +27,17 @@ import "testing"
 import "bytes"
 import "encoding/base64"
 import "crypto/rand"
+import "sync"
 
 func TestGSetNew(t *testing.T) {
-    a := NewGSet()
+    a := NewGSet[interface{}]()
     if a.Length() != 0 {
         t.Error("New Set should have 0 length!")
     }
 }
 
 func TestGSetInsert(t *testing.T) {
-    a := NewGSet()
+    a := NewGSet[interface{}]()
 
     if !a.Insert(1) {
         t.Error("Failed to insert 1 into Set!")
@@ -56,7 +57,7 @@ func TestGSetInsert(t *testing.T) {
 }
 
 func TestGSetContains(t *testing.T) {
-    a := NewGSet()
+    a := NewGSet[interface{}]()
 
     if a.Contains(1) {
         t.Error("Failed contains check in empty set!")
@@ -72,7 +73,7 @@ func TestGSetContains(t *testing.T) {
 }
 
 func TestGSetLength(t *testing.T) {
-    a := NewGSet()
+    a := NewGSet[interface{}]()
 
     for i := 1; i <= 10; i++ {
         a.Insert(i)
@@ -84,9 +85,9 @@ func TestGSetLength(t *testing.T) {
 
 
 func TestGSetEquals(t *testing.T) {
-    a := NewGSet()
-    b := NewGSet()
-    c := NewGSet()
+    a := NewGSet[interface{}]()
+    b := NewGSet[interface{}]()
+    c := NewGSet[interface{}]()
 
     for i := 1; i <= 10; i++ { a.Insert(i); b.Insert(i) }
     for i := 1; i <= 5; i++ { c.Insert(i) }
@@ -101,7 +102,7 @@ func TestGSetEquals(t *testing.T) {
 }
 
 func TestGSetClone(t *testing.T) {
-    a := NewGSet()
+    a := NewGSet[interface{}]()
 
     for i := 1; i <= 10; i++ {a.Insert(i)}
 
@@ -117,9 +118,9 @@ func TestGSetClone(t *testing.T) {
 }
 
 func TestGSetMerge(t *testing.T) {
-    a := NewGSet()
-    b := NewGSet()
-    c := NewGSet()
+    a := NewGSet[interface{}]()
+    b := NewGSet[interface{}]()
+    c := NewGSet[interface{}]()
 
     for i := 1; i <= 10; i++ {
         a.Insert(i)
@@ -138,8 +139,8 @@ func TestGSetMerge(t *testing.T) {
 }
 
 func TestGSetSerialize(t *testing.T) {
-    a := NewGSet()
-    b := NewGSet()
+    a := NewGSet[interface{}]()
+    b := NewGSet[interface{}]()
 
     for i := 0; i < 10; i++ {
         data := make([]byte, 4)
@@ -156,3 +157,85 @@ func TestGSetSerialize(t *testing.T) {
     if !a.Equals(b) { t.Error("Match failed") }
 }
 
+
+func TestGSetConcurrentInsert(t *testing.T) {
+    a := NewGSet[interface{}]()
+
+    var wg sync.WaitGroup
+    for w := 0; w < 8; w++ {
+        wg.Add(1)
+        go func(w int) {
+            defer wg.Done()
+            for i := 0; i < 500; i++ {
+                a.Insert(i)
+                a.Contains(i)
+                a.Length()
+            }
+        }(w)
+    }
+    wg.Wait()
+
+    if a.Length() != 500 {
+        t.Errorf("Expected length of 500, got %d", a.Length())
+    }
+    if m := a.Marker().get(0); m != 500 {
+        t.Errorf("Expected marker at 500, got %d", m)
+    }
+}
+
+func TestGSetConcurrentMerge(t *testing.T) {
+    a := NewGSet[interface{}]()
+    b := NewGSet[interface{}]()
+
+    for i := 0; i < 100; i++ { a.Insert(i); b.Insert(i + 100) }
+
+    var wg sync.WaitGroup
+    for w := 0; w < 4; w++ {
+        wg.Add(3)
+        go func() { defer wg.Done(); a.Merge(b); a.Equals(b) }()
+        go func() { defer wg.Done(); b.Merge(a); b.Equals(a) }()
+        go func() { defer wg.Done(); a.Merge(a); a.Serialize(&bytes.Buffer{}) }()
+    }
+    wg.Wait()
+
+    if !a.Equals(b) || a.Length() != 200 {
+        t.Error("Equals failed after concurrent merge!")
+    }
+}
+
+// Checks malformed input is refused without merging anything, and that any
+// input accepted round trips.
+func FuzzGSetDeserialize(f *testing.F) {
+    a := NewGSet[string]()
+    a.Insert("alpha")
+    a.Insert("beta")
+
+    out := new(bytes.Buffer)
+    a.Serialize(out)
+    f.Add(out.Bytes())
+
+    a.InsertWithMetadata("gamma", Metadata{Timestamp: 1, ReplicaId: "r", Labels: map[string]string{"k": "v"}})
+
+    out = new(bytes.Buffer)
+    a.Serialize(out)
+    f.Add(out.Bytes())
+
+    f.Add(gsetV1("alpha", "beta"))
+    f.Add(gsetV1())
+    f.Add(GSET_HEADER_MAGIC)
+
+    f.Fuzz(func(t *testing.T, data []byte) {
+        b := NewGSet[string]()
+        if e := b.Deserialize(bytes.NewReader(data)); e != nil {
+            if b.Length() != 0 { t.Error("Failed deserialize modified the set!") }
+            return
+        }
+
+        out := new(bytes.Buffer)
+        if e := b.Serialize(out); e != nil { t.Fatal(e) }
+
+        c := NewGSet[string]()
+        if e := c.Deserialize(out); e != nil { t.Fatal(e) }
+        if !b.Equals(c) { t.Error("Round trip does not match!") }
+    })
+}
@@ -0,0 +1,214 @@
+/*
+ * Copyright (c) 2015 Tom Swindell (t.swindell@rubyx.co.uk)
+ *
+ * Permission is hereby granted, free of charge, to any person obtaining a copy
+ * of this software and associated documentation files (the "Software"), to deal
+ * in the Software without restriction, including without limitation the rights
+ * to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+ * copies of the Software, and to permit persons to whom the Software is
+ * furnished to do so, subject to the following conditions:
+ *
+ * The above copyright notice and this permission notice shall be included in
+ * all copies or substantial portions of the Software.
+ *
+ * THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+ * IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+ * FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+ * AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+ * LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+ * OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+ * SOFTWARE.
+ *
+ */
+package set
+
+import (
+    "fmt"
+    "math/rand"
+)
+
+// The LawSubject type describes a CRDT for CheckLaws as functions over its
+// replica state S, so that any type converging by merge can be checked,
+// whether or not it lives in this package.
+type LawSubject[S any] struct {
+    // Returns an empty replica state, for simulated replica number replica.
+    New func(replica int) S
+
+    // Applies one randomly chosen operation to s, as simulated replica number
+    // replica.
+    Operate func(r *rand.Rand, s S, replica int) error
+
+    // Merges b into a, leaving b untouched.
+    Merge func(a, b S) error
+
+    Clone func(s S) (S, error)
+
+    Equals func(a, b S) (bool, error)
+
+    // Serializes s, and restores serialized state into an empty replica. Round
+    // trips are not checked when either is nil.
+    Serialize   func(s S) ([]byte, error)
+    Deserialize func(data []byte, replica int) (S, error)
+}
+
+// The LawConfig type controls the histories generated by CheckLaws, zero fields
+// take the defaults below.
+type LawConfig struct {
+    Seed       int64 // Seeds the generator, zero picks a seed at random.
+    Histories  int   // Number of independent histories generated.
+    Replicas   int   // Number of simulated replicas in each history.
+    Operations int   // Number of operations and merges in each history.
+}
+
+const (
+    LAW_DEFAULT_HISTORIES  = 50
+    LAW_DEFAULT_REPLICAS   = 3
+    LAW_DEFAULT_OPERATIONS = 40
+)
+
+// The LawError type reports a law violated by a subject, with the seed and
+// history which reproduce it.
+type LawError struct {
+    Law     string
+    Seed    int64
+    History int
+    Err     error // Set when the subject failed outright, rather than a law.
+}
+
+func (e *LawError) Error() string {
+    if e.Err != nil {
+        return fmt.Sprintf("%s: %v (seed %d, history %d)", e.Law, e.Err, e.Seed, e.History)
+    }
+    return fmt.Sprintf("%s violated (seed %d, history %d)", e.Law, e.Seed, e.History)
+}
+
+func (e *LawError) Unwrap() error { return e.Err }
+
+// Runs the checks of one history, each failing check stopping the history with
+// a LawError.
+type lawRun[S any] struct {
+    subject LawSubject[S]
+    seed    int64
+    history int
+}
+
+func (l *lawRun[S]) fail(law string, e error) error {
+    return &LawError{Law: law, Seed: l.seed, History: l.history, Err: e}
+}
+
+// Returns a merged into a copy of b, without modifying either.
+func (l *lawRun[S]) merged(a, b S) (S, error) {
+    result, e := l.subject.Clone(a)
+    if e != nil { return result, e }
+    if e := l.subject.Merge(result, b); e != nil { return result, e }
+    return result, nil
+}
+
+func (l *lawRun[S]) equal(law string, a, b S) error {
+    ok, e := l.subject.Equals(a, b)
+    if e != nil { return l.fail(law, e) }
+    if !ok { return l.fail(law, nil) }
+    return nil
+}
+
+// Generates the replicas of a history, each operation applied to a random
+// replica interleaved with random merges between them, so that the replicas
+// end up sharing part of their histories as real replicas do.
+func (l *lawRun[S]) generate(r *rand.Rand, config LawConfig) ([]S, error) {
+    replicas := make([]S, config.Replicas)
+    for i := range replicas { replicas[i] = l.subject.New(i) }
+
+    for n := 0; n < config.Operations; n++ {
+        i := r.Intn(len(replicas))
+
+        if r.Intn(4) > 0 {
+            if e := l.subject.Operate(r, replicas[i], i); e != nil { return nil, l.fail("operate", e) }
+            continue
+        }
+
+        j := r.Intn(len(replicas))
+        if i == j { continue }
+
+        if e := l.subject.Merge(replicas[i], replicas[j]); e != nil { return nil, l.fail("merge", e) }
+    }
+    return replicas, nil
+}
+
+func (l *lawRun[S]) roundTrip(s S, replica int) error {
+    if l.subject.Serialize == nil || l.subject.Deserialize == nil { return nil }
+
+    data, e := l.subject.Serialize(s)
+    if e != nil { return l.fail("serialize", e) }
+
+    restored, e := l.subject.Deserialize(data, replica)
+    if e != nil { return l.fail("deserialize", e) }
+
+    return l.equal("round trip", s, restored)
+}
+
+func (l *lawRun[S]) check(r *rand.Rand, config LawConfig) error {
+    replicas, e := l.generate(r, config)
+    if e != nil { return e }
+
+    a := replicas[r.Intn(len(replicas))]
+    b := replicas[r.Intn(len(replicas))]
+    c := replicas[r.Intn(len(replicas))]
+
+    // Idempotence, a ⊔ a = a.
+    aa, e := l.merged(a, a)
+    if e != nil { return l.fail("merge", e) }
+    if e := l.equal("idempotence", aa, a); e != nil { return e }
+
+    // Commutativity, a ⊔ b = b ⊔ a.
+    ab, e := l.merged(a, b)
+    if e != nil { return l.fail("merge", e) }
+    ba, e := l.merged(b, a)
+    if e != nil { return l.fail("merge", e) }
+    if e := l.equal("commutativity", ab, ba); e != nil { return e }
+
+    // Associativity, (a ⊔ b) ⊔ c = a ⊔ (b ⊔ c).
+    abc, e := l.merged(ab, c)
+    if e != nil { return l.fail("merge", e) }
+    bc, e := l.merged(b, c)
+    if e != nil { return l.fail("merge", e) }
+    abc2, e := l.merged(a, bc)
+    if e != nil { return l.fail("merge", e) }
+    if e := l.equal("associativity", abc, abc2); e != nil { return e }
+
+    for i, s := range replicas {
+        if e := l.roundTrip(s, i); e != nil { return e }
+    }
+    if e := l.roundTrip(abc, 0); e != nil { return e }
+
+    // Convergence, every replica holds the same state once each has merged
+    // all the others.
+    for i := range replicas {
+        for j := range replicas {
+            if i == j { continue }
+            if e := l.subject.Merge(replicas[i], replicas[j]); e != nil { return l.fail("merge", e) }
+        }
+    }
+    for _, s := range replicas[1:] {
+        if e := l.equal("convergence", replicas[0], s); e != nil { return e }
+    }
+    return nil
+}
+
+// The CheckLaws function generates random operation histories across simulated
+// replicas of subject, and checks that Merge is idempotent, commutative and
+// associative over the resulting states, that replicas converge once they have
+// merged each other, and that serialized state restores to an equal replica.
+// The first violation found is returned as a *LawError.
+func CheckLaws[S any](subject LawSubject[S], config LawConfig) error {
+    if config.Seed == 0 { config.Seed = rand.Int63() }
+    if config.Histories <= 0 { config.Histories = LAW_DEFAULT_HISTORIES }
+    if config.Replicas <= 0 { config.Replicas = LAW_DEFAULT_REPLICAS }
+    if config.Operations <= 0 { config.Operations = LAW_DEFAULT_OPERATIONS }
+
+    r := rand.New(rand.NewSource(config.Seed))
+    for i := 0; i < config.Histories; i++ {
+        run := &lawRun[S]{subject: subject, seed: config.Seed, history: i}
+        if e := run.check(r, config); e != nil { return e }
+    }
+    return nil
+}
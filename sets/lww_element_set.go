@@ -0,0 +1,372 @@
+/*
+ * Copyright (c) 2015 Tom Swindell (t.swindell@rubyx.co.uk)
+ *
+ * Permission is hereby granted, free of charge, to any person obtaining a copy
+ * of this software and associated documentation files (the "Software"), to deal
+ * in the Software without restriction, including without limitation the rights
+ * to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+ * copies of the Software, and to permit persons to whom the Software is
+ * furnished to do so, subject to the following conditions:
+ *
+ * The above copyright notice and this permission notice shall be included in
+ * all copies or substantial portions of the Software.
+ *
+ * THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+ * IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+ * FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+ * AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+ * LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+ * OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+ * SOFTWARE.
+ *
+ */
+
+package set
+
+import (
+    "bytes"
+    "encoding/binary"
+    "fmt"
+    "io"
+    "iter"
+    "sync"
+    "time"
+)
+
+// The Clock interface supplies the timestamps LWWSet operations are ordered by.
+type Clock interface {
+    Now() int64
+}
+
+// The ClockFunc type adapts an ordinary function to the Clock interface.
+type ClockFunc func() int64
+
+func (f ClockFunc) Now() int64 { return f() }
+
+// Wall clock in nanoseconds since the unix epoch.
+var SystemClock = ClockFunc(func() int64 { return time.Now().UnixNano() })
+
+// The LWWBias type decides whether an insert or a remove wins when both carry
+// the same timestamp.
+type LWWBias int
+
+const (
+    LWW_ADD_WINS LWWBias = iota
+    LWW_REMOVE_WINS
+)
+
+// Timestamped half of an LWWSet, the largest timestamp seen for each element.
+type timestamps[T comparable] map[T]int64
+
+func (t timestamps[T]) update(item T, ts int64) {
+    if v, found := t[item]; !found || ts > v { t[item] = ts }
+}
+
+func (t timestamps[T]) equals(other timestamps[T]) bool {
+    if len(t) != len(other) { return false }
+    for i, ts := range t {
+        if v, found := other[i]; !found || v != ts { return false }
+    }
+    return true
+}
+
+// Common Go representation of a last-writer-wins element set. Like TwoPhase it
+// is made of an added and a removed half, but each element carries the time of
+// its latest insert and remove, so an element is contained when its insert is
+// newer than its remove and may be inserted again after removal.
+type LWWSet[T comparable] struct {
+    sync.RWMutex
+    codec   Codec[T]
+    clock   Clock
+    bias    LWWBias
+    added    timestamps[T]
+    removed  timestamps[T]
+    metadata provenance[T] // Metadata of the elements inserted with any.
+    unknown  sections      // Sections of a newer format, kept to write back out.
+}
+
+// The NewLWWSet function returns a set serialized by the DefaultCodec of T.
+func NewLWWSet[T comparable](clock Clock, bias LWWBias) *LWWSet[T] {
+    return NewLWWSetWithCodec(clock, bias, DefaultCodec[T]())
+}
+
+func NewLWWSetWithCodec[T comparable](clock Clock, bias LWWBias, codec Codec[T]) *LWWSet[T] {
+    s := new(LWWSet[T])
+    s.codec   = codec
+    s.clock   = clock
+    s.bias    = bias
+    s.added   = make(timestamps[T])
+    s.removed = make(timestamps[T])
+    s.metadata = make(provenance[T])
+    return s
+}
+
+func (s *LWWSet[T]) Bias() LWWBias { return s.bias }
+
+// The caller must hold the lock.
+func (s *LWWSet[T]) contains(item T) bool {
+    a, found := s.added[item]
+    if !found { return false }
+
+    r, found := s.removed[item]
+    if !found || a > r { return true }
+    if a < r { return false }
+
+    return s.bias == LWW_ADD_WINS
+}
+
+func (s *LWWSet[T]) Insert(item T) bool {
+    return s.InsertAt(item, s.clock.Now())
+}
+
+// The InsertAt() method inserts item with an explicit timestamp. Returns true if
+// the item was not contained before and is now.
+func (s *LWWSet[T]) InsertAt(item T, ts int64) bool {
+    s.Lock()
+    defer s.Unlock()
+
+    return s.insertAt(item, ts)
+}
+
+// The caller must hold the lock.
+func (s *LWWSet[T]) insertAt(item T, ts int64) bool {
+    was := s.contains(item)
+
+    // Always record ts, a later concurrent remove must not hide this insert.
+    s.added.update(item, ts)
+    return !was && s.contains(item)
+}
+
+// The InsertWithMetadata() method inserts item as Insert() does, recording md
+// as its metadata. An element inserted again after its removal keeps the
+// metadata of its first insert.
+func (s *LWWSet[T]) InsertWithMetadata(item T, md Metadata) bool {
+    ts := s.clock.Now()
+
+    s.Lock()
+    defer s.Unlock()
+
+    if !s.insertAt(item, ts) { return false }
+    s.metadata.update(item, md.clone())
+    return true
+}
+
+// The Metadata() method returns the metadata of item while it is contained.
+func (s *LWWSet[T]) Metadata(item T) (Metadata, bool) {
+    s.RLock()
+    defer s.RUnlock()
+
+    if !s.contains(item) { return Metadata{}, false }
+
+    md, found := s.metadata[item]
+    return md, found
+}
+
+func (s *LWWSet[T]) Remove(item T) bool {
+    return s.RemoveAt(item, s.clock.Now())
+}
+
+// The RemoveAt() method removes item with an explicit timestamp. Returns true if
+// the item was contained before and is not now.
+func (s *LWWSet[T]) RemoveAt(item T, ts int64) bool {
+    s.Lock()
+    defer s.Unlock()
+
+    was := s.contains(item)
+
+    // Always record ts, an earlier concurrent insert must not survive this remove.
+    s.removed.update(item, ts)
+    return was && !s.contains(item)
+}
+
+func (s *LWWSet[T]) Contains(item T) bool {
+    s.RLock()
+    defer s.RUnlock()
+
+    return s.contains(item)
+}
+
+func (s *LWWSet[T]) Length() int {
+    s.RLock()
+    defer s.RUnlock()
+
+    length := 0
+    for i := range s.added {
+        if s.contains(i) { length++ }
+    }
+    return length
+}
+
+func (s *LWWSet[T]) Equals(other *LWWSet[T]) bool {
+    in := other.Clone()
+
+    s.RLock()
+    defer s.RUnlock()
+
+    return s.added.equals(in.added) && s.removed.equals(in.removed) &&
+           s.metadata.equals(in.metadata)
+}
+
+func (s *LWWSet[T]) Merge(other *LWWSet[T]) {
+    in := other.Clone()
+
+    s.Lock()
+    defer s.Unlock()
+
+    for i, ts := range in.added { s.added.update(i, ts) }
+    for i, ts := range in.removed { s.removed.update(i, ts) }
+    s.metadata.merge(in.metadata)
+    s.unknown = s.unknown.union(in.unknown)
+}
+
+func (s *LWWSet[T]) Clone() *LWWSet[T] {
+    s.RLock()
+    defer s.RUnlock()
+
+    result := NewLWWSetWithCodec(s.clock, s.bias, s.codec)
+    for i, ts := range s.added { result.added[i] = ts }
+    for i, ts := range s.removed { result.removed[i] = ts }
+    result.metadata = s.metadata.clone()
+    result.unknown = s.unknown
+    return result
+}
+
+func (s *LWWSet[T]) Iterate() iter.Seq[T] {
+    return iterate(s.ToSlice())
+}
+
+func (s *LWWSet[T]) IterateWithMetadata() iter.Seq2[T, Metadata] {
+    s.RLock()
+    defer s.RUnlock()
+
+    return iterateWithMetadata(s.toSlice(), s.metadata.clone())
+}
+
+func (s *LWWSet[T]) ToSet() Set {
+    s.RLock()
+    defer s.RUnlock()
+
+    result := Set{}
+    for i := range s.added {
+        if s.contains(i) { result.Insert(i) }
+    }
+    return result
+}
+
+func (s *LWWSet[T]) ToSlice() []T {
+    s.RLock()
+    defer s.RUnlock()
+
+    return s.toSlice()
+}
+
+// The caller must hold the lock.
+func (s *LWWSet[T]) toSlice() []T {
+    result := make([]T, 0)
+    for i := range s.added {
+        if s.contains(i) { result = append(result, i) }
+    }
+    return result
+}
+
+var LWWSET_HEADER_MAGIC = []byte{'c', 'r', 'd', 't', ':', 'l', 'w', 'w', 's', 'e', 't', 0x00}
+
+// Sections of a v2 LWWSet container.
+const (
+    LWWSET_SECTION_ADDED    = 1 // Each element with its latest insert time.
+    LWWSET_SECTION_REMOVED  = 2 // Each element with its latest remove time.
+    LWWSET_SECTION_METADATA = 3 // Each element with its metadata, when any has.
+)
+
+// Returns the records encoding each element followed by its varint timestamp.
+func (t timestamps[T]) records(codec Codec[T]) records {
+    return func(emit func([]byte) error) error {
+        scratch := new(bytes.Buffer)
+        for i, ts := range t {
+            scratch.Reset()
+            if e := writeElementV2(scratch, codec, i); e != nil { return e }
+            scratch.Write(binary.AppendVarint(nil, ts))
+
+            if e := emit(scratch.Bytes()); e != nil { return e }
+        }
+        return nil
+    }
+}
+
+// Returns the reader of a section of records.
+func (t timestamps[T]) reader(codec Codec[T]) sectionReader {
+    return func(in *limitedReader) error {
+        for in.n > 0 {
+            item, e := readElementV2(in, codec)
+            if e != nil { return e }
+
+            ts, e := binary.ReadVarint(in)
+            if e != nil { return fmt.Errorf("invalid format") }
+
+            t.update(item, ts)
+        }
+        return nil
+    }
+}
+
+// Reads the v1 format, a uint32 count followed by each element as read by
+// readElement and its int64 timestamp.
+func (t timestamps[T]) deserializeV1(r io.Reader, codec Codec[T]) error {
+    var sizeof uint32
+    if e := binary.Read(r, binary.LittleEndian, &sizeof); e != nil { return e }
+
+    for i := uint32(0); i < sizeof; i++ {
+        item, e := readElement(r, codec)
+        if e != nil { return e }
+
+        var ts int64
+        if e := binary.Read(r, binary.LittleEndian, &ts); e != nil { return e }
+
+        t.update(item, ts)
+    }
+
+    return nil
+}
+
+// The Serialize() method streams a snapshot of the set to w, without holding
+// the lock while writing.
+func (s *LWWSet[T]) Serialize(w io.Writer) error {
+    snap := s.Clone()
+
+    known := append([]sectionWriter{
+                        {LWWSET_SECTION_ADDED, snap.added.records(s.codec)},
+                        {LWWSET_SECTION_REMOVED, snap.removed.records(s.codec)},
+                    }, snap.metadata.sections(LWWSET_SECTION_METADATA, s.codec)...)
+    return writeContainer(w, LWWSET_HEADER_MAGIC, known, snap.unknown)
+}
+
+// Deserialize merges the serialized state, in either format, into this set.
+// Nothing is merged unless all of it is read successfully.
+func (s *LWWSet[T]) Deserialize(r io.Reader) error {
+    v2, e := readFormat(r, LWWSET_HEADER_MAGIC)
+    if e != nil { return e }
+
+    result := NewLWWSetWithCodec(s.clock, s.bias, s.codec)
+
+    if !v2 {
+        if e := result.added.deserializeV1(r, s.codec); e != nil { return e }
+        if e := result.removed.deserializeV1(r, s.codec); e != nil { return e }
+
+        s.Merge(result)
+        return nil
+    }
+
+    result.unknown, e = readContainer(r, LWWSET_HEADER_MAGIC, map[uint64]sectionReader{
+        LWWSET_SECTION_ADDED:    result.added.reader(s.codec),
+        LWWSET_SECTION_REMOVED:  result.removed.reader(s.codec),
+        LWWSET_SECTION_METADATA: result.metadata.reader(s.codec),
+    })
+    if e != nil { return e }
+
+    for i := range result.metadata {
+        if _, found := result.added[i]; !found { delete(result.metadata, i) }
+    }
+
+    s.Merge(result)
+    return nil
+}
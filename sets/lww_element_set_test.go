@@ -0,0 +1,286 @@
+/*
+ * Copyright (c) 2015 Tom Swindell (t.swindell@rubyx.co.uk)
+ *
+ * Permission is hereby granted, free of charge, to any person obtaining a copy
+ * of this software and associated documentation files (the "Software"), to deal
+ * in the Software without restriction, including without limitation the rights
+ * to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+ * copies of the Software, and to permit persons to whom the Software is
+ * furnished to do so, subject to the following conditions:
+ *
+ * The above copyright notice and this permission notice shall be included in
+ * all copies or substantial portions of the Software.
+ *
+ * THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+ * IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+ * FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+ * AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+ * LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+ * OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+ * SOFTWARE.
+ *
+ */
+
+package set
+
+import "testing"
+import "bytes"
+import "encoding/base64"
+import "crypto/rand"
+import "sync"
+
+// Deterministic clock, advancing by one on each reading.
+type tickClock struct { now int64 }
+
+func (c *tickClock) Now() int64 { c.now++; return c.now }
+
+func TestNewLWWSet(t *testing.T) {
+    a := NewLWWSet[interface{}](&tickClock{}, LWW_ADD_WINS)
+    if a.Length() != 0 {
+        t.Error("New Set should have 0 length!")
+    }
+}
+
+func TestLWWSetInsert(t *testing.T) {
+    a := NewLWWSet[interface{}](&tickClock{}, LWW_ADD_WINS)
+
+    if !a.Insert(1) {
+        t.Error("Failed to insert 1 into Set!")
+    }
+
+    a.Insert(2)
+
+    if a.Insert(1) {
+        t.Error("Insert returned success when attempting to insert twice!")
+    }
+
+    a.Insert(3)
+
+    if a.Length() != 3 {
+        t.Error("Set length should == 3!")
+    }
+}
+
+func TestLWWSetRemoveAndReinsert(t *testing.T) {
+    a := NewLWWSet[interface{}](&tickClock{}, LWW_ADD_WINS)
+
+    a.Insert(1)
+    a.Insert(2)
+
+    if !a.Remove(2) || a.Contains(2) {
+        t.Error("Failed to remove from set!")
+    }
+
+    if a.Remove(2) {
+        t.Error("Remove returned success when attempting to remove twice!")
+    }
+
+    if !a.Insert(2) || !a.Contains(2) {
+        t.Error("Failed to re-insert removed element!")
+    }
+}
+
+func TestLWWSetStaleOperations(t *testing.T) {
+    a := NewLWWSet[interface{}](&tickClock{}, LWW_ADD_WINS)
+
+    a.InsertAt(1, 10)
+
+    if a.RemoveAt(1, 5) || !a.Contains(1) {
+        t.Error("Remove older than insert should not take effect!")
+    }
+
+    a.RemoveAt(1, 20)
+
+    if a.InsertAt(1, 15) || a.Contains(1) {
+        t.Error("Insert older than remove should not take effect!")
+    }
+}
+
+func TestLWWSetConcurrentRemoveAndReinsert(t *testing.T) {
+    a := NewLWWSet[interface{}](&tickClock{}, LWW_ADD_WINS)
+    a.InsertAt(1, 1)
+    b := a.Clone()
+
+    // a re-inserts an element it already contains, while b removes it in between.
+    b.RemoveAt(1, 3)
+    if a.InsertAt(1, 5) {
+        t.Error("Insert of a contained element should not report a change!")
+    }
+
+    a.Merge(b)
+    b.Merge(a)
+
+    if !a.Contains(1) || !b.Contains(1) {
+        t.Error("Re-insert newer than a concurrent remove should win!")
+    }
+
+    // a removes an element it no longer contains, while b re-inserts it in between.
+    a.RemoveAt(1, 6)
+    b.InsertAt(1, 7)
+    if a.RemoveAt(1, 9) {
+        t.Error("Remove of a missing element should not report a change!")
+    }
+
+    a.Merge(b)
+    b.Merge(a)
+
+    if a.Contains(1) || b.Contains(1) {
+        t.Error("Remove newer than a concurrent re-insert should win!")
+    }
+}
+
+func TestLWWSetBias(t *testing.T) {
+    a := NewLWWSet[interface{}](&tickClock{}, LWW_ADD_WINS)
+    a.InsertAt(1, 10)
+    a.RemoveAt(1, 10)
+
+    if !a.Contains(1) {
+        t.Error("Add-wins set should contain element on tied timestamps!")
+    }
+
+    b := NewLWWSet[interface{}](&tickClock{}, LWW_REMOVE_WINS)
+    b.InsertAt(1, 10)
+    b.RemoveAt(1, 10)
+
+    if b.Contains(1) {
+        t.Error("Remove-wins set should not contain element on tied timestamps!")
+    }
+}
+
+func TestLWWSetEquals(t *testing.T) {
+    a := NewLWWSet[interface{}](&tickClock{}, LWW_ADD_WINS)
+    b := NewLWWSet[interface{}](&tickClock{}, LWW_ADD_WINS)
+    c := NewLWWSet[interface{}](&tickClock{}, LWW_ADD_WINS)
+
+    for i := 1; i <= 10; i++ { a.Insert(i); b.Insert(i) }
+    for i := 1; i <= 5; i++ { c.Insert(i) }
+
+    if !a.Equals(b) {
+        t.Error("a equals b check failed!")
+    }
+
+    if a.Equals(c) {
+        t.Error("a equals c check failed!")
+    }
+}
+
+func TestLWWSetClone(t *testing.T) {
+    a := NewLWWSet[interface{}](&tickClock{}, LWW_ADD_WINS)
+
+    for i := 1; i <= 10; i++ {a.Insert(i)}
+
+    b := a.Clone()
+
+    if !a.Equals(b) {
+        t.Errorf("Expected a equals b!")
+    }
+
+    a.Insert(11)
+
+    if a.Equals(b) {
+        t.Errorf("Expected a not to equal b!")
+    }
+}
+
+func TestLWWSetMerge(t *testing.T) {
+    a := NewLWWSet[interface{}](&tickClock{}, LWW_ADD_WINS)
+    b := NewLWWSet[interface{}](&tickClock{}, LWW_ADD_WINS)
+
+    a.InsertAt(1, 1)
+    a.InsertAt(2, 1)
+    b.InsertAt(2, 2)
+    b.RemoveAt(2, 3)
+    b.InsertAt(3, 4)
+
+    a.Merge(b)
+    b.Merge(a)
+
+    if !a.Equals(b) {
+        t.Error("Equals failed after merge!")
+    }
+
+    if !a.Contains(1) || a.Contains(2) || !a.Contains(3) {
+        t.Error("Unexpected contents after merge!")
+    }
+}
+
+func TestLWWSetSerialize(t *testing.T) {
+    a := NewLWWSet[interface{}](&tickClock{}, LWW_ADD_WINS)
+    b := NewLWWSet[interface{}](&tickClock{}, LWW_ADD_WINS)
+
+    for i := 0; i < 10; i++ {
+        data := make([]byte, 4)
+        rand.Read(data)
+        a.Insert(base64.StdEncoding.EncodeToString(data))
+    }
+
+    for _, v := range a.ToSlice()[:5] { a.Remove(v) }
+
+    out := &bytes.Buffer{}
+    if e := a.Serialize(out); e != nil { t.Error(e) }
+
+    in := bytes.NewBuffer(out.Bytes())
+    if e := b.Deserialize(in); e != nil { t.Error(e) }
+
+    if !a.Equals(b) { t.Error("Match failed") }
+    if b.Length() != 5 { t.Errorf("Expected length of 5, got %d", b.Length()) }
+}
+
+// Two sets compared with each other from both sides, while being written, must
+// not deadlock.
+func TestLWWSetConcurrentEquals(t *testing.T) {
+    a := NewLWWSet[interface{}](SystemClock, LWW_ADD_WINS)
+    b := NewLWWSet[interface{}](SystemClock, LWW_ADD_WINS)
+
+    var wg sync.WaitGroup
+    for w := 0; w < 4; w++ {
+        wg.Add(2)
+        go func(w int) { defer wg.Done(); for i := 0; i < 100; i++ { a.Insert(w * 100 + i); a.Equals(b) } }(w)
+        go func(w int) { defer wg.Done(); for i := 0; i < 100; i++ { b.Insert(w * 100 + i); b.Equals(a) } }(w)
+    }
+    wg.Wait()
+
+    if a.Length() != 400 || b.Length() != 400 {
+        t.Error("Unexpected length after concurrent inserts!")
+    }
+}
+
+func TestLWWSetSerializeUnlocked(t *testing.T) {
+    a := NewLWWSet[string](SystemClock, LWW_ADD_WINS)
+    a.Insert("a")
+
+    if e := a.Serialize(insertingWriter{func() { a.Insert("b") }}); e != nil { t.Fatal(e) }
+    if !a.Contains("b") { t.Error("Expected insert while serializing!") }
+}
+
+// Checks malformed input is refused without merging anything, and that any
+// input accepted round trips.
+func FuzzLWWSetDeserialize(f *testing.F) {
+    a := NewLWWSet[string](&tickClock{}, LWW_ADD_WINS)
+    a.Insert("alpha")
+    a.Insert("beta")
+    a.Remove("alpha")
+    a.InsertWithMetadata("gamma", Metadata{Timestamp: 1, ReplicaId: "r", Labels: map[string]string{"k": "v"}})
+
+    out := new(bytes.Buffer)
+    a.Serialize(out)
+
+    f.Add(out.Bytes())
+    f.Add(lwwsetV1())
+    f.Add(LWWSET_HEADER_MAGIC)
+
+    f.Fuzz(func(t *testing.T, data []byte) {
+        b := NewLWWSet[string](&tickClock{}, LWW_ADD_WINS)
+        if e := b.Deserialize(bytes.NewReader(data)); e != nil {
+            if !b.Equals(NewLWWSet[string](&tickClock{}, LWW_ADD_WINS)) { t.Error("Failed deserialize modified the set!") }
+            return
+        }
+
+        out := new(bytes.Buffer)
+        if e := b.Serialize(out); e != nil { t.Fatal(e) }
+
+        c := NewLWWSet[string](&tickClock{}, LWW_ADD_WINS)
+        if e := c.Deserialize(out); e != nil { t.Fatal(e) }
+        if !b.Equals(c) { t.Error("Round trip does not match!") }
+    })
+}
@@ -0,0 +1,204 @@
+/*
+ * Copyright (c) 2015 Tom Swindell (t.swindell@rubyx.co.uk)
+ *
+ * Permission is hereby granted, free of charge, to any person obtaining a copy
+ * of this software and associated documentation files (the "Software"), to deal
+ * in the Software without restriction, including without limitation the rights
+ * to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+ * copies of the Software, and to permit persons to whom the Software is
+ * furnished to do so, subject to the following conditions:
+ *
+ * The above copyright notice and this permission notice shall be included in
+ * all copies or substantial portions of the Software.
+ *
+ * THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+ * IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+ * FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+ * AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+ * LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+ * OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+ * SOFTWARE.
+ *
+ */
+package set
+
+import (
+    "bytes"
+    "encoding/binary"
+    "fmt"
+    "iter"
+    "sort"
+)
+
+// The Metadata type records the provenance of an element, the time and the
+// replica of its first insert, along with any labels the caller attached.
+// Metadata is never modified once recorded.
+type Metadata struct {
+    Timestamp int64
+    ReplicaId string
+    Labels    map[string]string
+}
+
+// The MetadataSet interface is implemented by sets which keep the metadata of
+// their elements. Only elements inserted through InsertWithMetadata carry
+// metadata, and an element keeps that of its first insert, so that replicas
+// agree on it whatever order they merge in. Other elements report the zero
+// Metadata.
+type MetadataSet[T comparable] interface {
+    InsertWithMetadata(T, Metadata) bool
+    Metadata(T) (Metadata, bool)
+    IterateWithMetadata() iter.Seq2[T, Metadata]
+}
+
+// The IsZero() method returns true for the zero Metadata, which is reported
+// for elements inserted without any.
+func (m Metadata) IsZero() bool {
+    return m.Timestamp == 0 && m.ReplicaId == "" && len(m.Labels) == 0
+}
+
+// Returns a copy of m, so that the caller can not modify the labels recorded.
+func (m Metadata) clone() Metadata {
+    if m.Labels == nil { return m }
+
+    labels := make(map[string]string, len(m.Labels))
+    for k, v := range m.Labels { labels[k] = v }
+    m.Labels = labels
+    return m
+}
+
+// Writes the metadata as varint timestamp, replica id, and a uvarint count of
+// labels followed by each key and value, in key order so that equal metadata
+// encodes to the same bytes.
+func (m Metadata) encode(buff *bytes.Buffer) {
+    buff.Write(binary.AppendVarint(nil, m.Timestamp))
+    writeBytes(buff, []byte(m.ReplicaId))
+
+    keys := make([]string, 0, len(m.Labels))
+    for k := range m.Labels { keys = append(keys, k) }
+    sort.Strings(keys)
+
+    writeUvarint(buff, uint64(len(keys)))
+    for _, k := range keys {
+        writeBytes(buff, []byte(k))
+        writeBytes(buff, []byte(m.Labels[k]))
+    }
+}
+
+func (m Metadata) bytes() []byte {
+    buff := new(bytes.Buffer)
+    m.encode(buff)
+    return buff.Bytes()
+}
+
+func (m Metadata) Equals(other Metadata) bool {
+    return bytes.Equal(m.bytes(), other.bytes())
+}
+
+// Returns true when m orders before other, by timestamp, then replica id and
+// then labels, so that any two differing metadata are ordered.
+func (m Metadata) before(other Metadata) bool {
+    if m.Timestamp != other.Timestamp { return m.Timestamp < other.Timestamp }
+    if m.ReplicaId != other.ReplicaId { return m.ReplicaId < other.ReplicaId }
+    return bytes.Compare(m.bytes(), other.bytes()) < 0
+}
+
+func readMetadata(in *limitedReader) (Metadata, error) {
+    var m Metadata
+
+    ts, e := binary.ReadVarint(in)
+    if e != nil { return m, fmt.Errorf("invalid format") }
+    m.Timestamp = ts
+
+    replicaId, e := readBytes(in)
+    if e != nil { return m, e }
+    m.ReplicaId = string(replicaId)
+
+    count, e := readUvarint(in)
+    if e != nil { return m, e }
+    if count > in.n / 2 { return m, fmt.Errorf("invalid format") }
+    if count == 0 { return m, nil }
+
+    m.Labels = make(map[string]string, count)
+    for i := uint64(0); i < count; i++ {
+        k, e := readBytes(in)
+        if e != nil { return m, e }
+        v, e := readBytes(in)
+        if e != nil { return m, e }
+        m.Labels[string(k)] = string(v)
+    }
+    return m, nil
+}
+
+
+// The provenance type holds the metadata of each element of a set which has
+// any. Like Set it is not safe for concurrent use, the sets holding one guard
+// it with their own lock.
+type provenance[T comparable] map[T]Metadata
+
+// Records md for item, unless it already holds metadata of an earlier insert.
+func (p provenance[T]) update(item T, md Metadata) {
+    if v, found := p[item]; !found || md.before(v) { p[item] = md }
+}
+
+func (p provenance[T]) merge(other provenance[T]) {
+    for i, md := range other { p.update(i, md) }
+}
+
+func (p provenance[T]) equals(other provenance[T]) bool {
+    if len(p) != len(other) { return false }
+    for i, md := range p {
+        v, found := other[i]
+        if !found || !md.Equals(v) { return false }
+    }
+    return true
+}
+
+func (p provenance[T]) clone() provenance[T] {
+    result := make(provenance[T], len(p))
+    for i, md := range p { result[i] = md }
+    return result
+}
+
+// Returns the section writing the metadata of each element, or no section at
+// all when there is none, so that sets without metadata serialize as before.
+func (p provenance[T]) sections(tag uint64, codec Codec[T]) []sectionWriter {
+    if len(p) == 0 { return nil }
+
+    return []sectionWriter{{tag, func(emit func([]byte) error) error {
+        scratch := new(bytes.Buffer)
+        for i, md := range p {
+            scratch.Reset()
+            if e := writeElementV2(scratch, codec, i); e != nil { return e }
+            md.encode(scratch)
+
+            if e := emit(scratch.Bytes()); e != nil { return e }
+        }
+        return nil
+    }}}
+}
+
+// Returns the reader of a metadata section, recording each entry in p.
+func (p provenance[T]) reader(codec Codec[T]) sectionReader {
+    return func(in *limitedReader) error {
+        for in.n > 0 {
+            item, e := readElementV2(in, codec)
+            if e != nil { return e }
+
+            md, e := readMetadata(in)
+            if e != nil { return e }
+
+            p.update(item, md)
+        }
+        return nil
+    }
+}
+
+// Returns a sequence yielding each of items with its metadata, stopping as
+// soon as the consumer does.
+func iterateWithMetadata[T comparable](items []T, metadata provenance[T]) iter.Seq2[T, Metadata] {
+    return func(yield func(T, Metadata) bool) {
+        for _, i := range items {
+            if !yield(i, metadata[i]) { return }
+        }
+    }
+}
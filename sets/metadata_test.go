@@ -0,0 +1,229 @@
+/*
+ * Copyright (c) 2015 Tom Swindell (t.swindell@rubyx.co.uk)
+ *
+ * Permission is hereby granted, free of charge, to any person obtaining a copy
+ * of this software and associated documentation files (the "Software"), to deal
+ * in the Software without restriction, including without limitation the rights
+ * to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+ * copies of the Software, and to permit persons to whom the Software is
+ * furnished to do so, subject to the following conditions:
+ *
+ * The above copyright notice and this permission notice shall be included in
+ * all copies or substantial portions of the Software.
+ *
+ * THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+ * IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+ * FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+ * AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+ * LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+ * OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+ * SOFTWARE.
+ *
+ */
+package set
+
+import "testing"
+import "bytes"
+import "encoding/binary"
+import "hash/crc32"
+import "io"
+
+// Operations of the set types keeping metadata used below.
+type metadataTestSet interface {
+    MetadataSet[string]
+    Insert(string) bool
+    Serialize(io.Writer) error
+    Deserialize(io.Reader) error
+}
+
+// Constructors of each set type keeping metadata.
+var metadataTestSets = map[string]func() metadataTestSet{
+    "gset":   func() metadataTestSet { return NewGSet[string]() },
+    "2pset":  func() metadataTestSet { return New2P[string]() },
+    "orset":  func() metadataTestSet { return NewORSet[string]() },
+    "lwwset": func() metadataTestSet { return NewLWWSet[string](&tickClock{}, LWW_ADD_WINS) },
+}
+
+func TestMetadataInsert(t *testing.T) {
+    for name, create := range metadataTestSets {
+        s := create()
+        md := Metadata{Timestamp: 10, ReplicaId: "a", Labels: map[string]string{"user": "alice"}}
+        expected := md.clone()
+
+        if !s.InsertWithMetadata("x", md) { t.Errorf("%s: insert failed", name) }
+        md.Labels["user"] = "mallory"
+
+        if s.InsertWithMetadata("x", Metadata{Timestamp: 5, ReplicaId: "b"}) {
+            t.Errorf("%s: second insert succeeded", name)
+        }
+        s.Insert("y")
+
+        if v, found := s.Metadata("x"); !found || !v.Equals(expected) {
+            t.Errorf("%s: unexpected metadata %v", name, v)
+        }
+        if _, found := s.Metadata("y"); found {
+            t.Errorf("%s: element inserted without metadata has metadata", name)
+        }
+
+        count := 0
+        for i, v := range s.IterateWithMetadata() {
+            count++
+            if (i == "x") != v.Equals(expected) { t.Errorf("%s: unexpected metadata %v for %s", name, v, i) }
+        }
+        if count != 2 { t.Errorf("%s: expected 2 elements, got %d", name, count) }
+    }
+}
+
+func TestMetadataRemove(t *testing.T) {
+    md := Metadata{Timestamp: 1, ReplicaId: "a"}
+
+    for name, create := range metadataTestSets {
+        s, ok := create().(interface{ metadataTestSet; Remove(string) bool })
+        if !ok { continue }
+
+        s.InsertWithMetadata("x", md)
+        s.Remove("x")
+
+        if _, found := s.Metadata("x"); found { t.Errorf("%s: removed element has metadata", name) }
+        for i := range s.IterateWithMetadata() { t.Errorf("%s: unexpected element %s", name, i) }
+    }
+
+    // Inserted again, an element keeps the metadata of its first insert.
+    s := NewORSet[string]()
+    s.InsertWithMetadata("x", md)
+    s.Remove("x")
+    s.InsertWithMetadata("x", Metadata{Timestamp: 2, ReplicaId: "b"})
+
+    if v, found := s.Metadata("x"); !found || !v.Equals(md) {
+        t.Errorf("Unexpected metadata %v", v)
+    }
+}
+
+func TestMetadataMerge(t *testing.T) {
+    early := Metadata{Timestamp: 1, ReplicaId: "b"}
+    late := Metadata{Timestamp: 2, ReplicaId: "a"}
+
+    a := NewGSet[string]()
+    b := NewGSet[string]()
+    a.InsertWithMetadata("x", late)
+    b.InsertWithMetadata("x", early)
+    b.InsertWithMetadata("y", late)
+
+    ab := a.Clone()
+    ab.Merge(b)
+    ba := b.Clone()
+    ba.Merge(a)
+
+    if !ab.Equals(ba) { t.Error("Merged sets should be equal!") }
+    if v, _ := ab.Metadata("x"); !v.Equals(early) { t.Errorf("Expected the earliest metadata, got %v", v) }
+    if v, _ := ab.Metadata("y"); !v.Equals(late) { t.Errorf("Unexpected metadata %v", v) }
+
+    // Sets differing only in metadata are not equal.
+    c := NewGSet[string]()
+    c.InsertWithMetadata("x", early)
+    d := NewGSet[string]()
+    d.Insert("x")
+
+    if c.Equals(d) || d.Equals(c) { t.Error("Sets with differing metadata should not be equal!") }
+}
+
+func TestMetadataSerialize(t *testing.T) {
+    md := Metadata{Timestamp: -7, ReplicaId: "a", Labels: map[string]string{"k": "v", "": ""}}
+
+    for name, create := range metadataTestSets {
+        s := create()
+        s.InsertWithMetadata("x", md)
+        s.Insert("y")
+
+        buff := new(bytes.Buffer)
+        if e := s.Serialize(buff); e != nil { t.Fatalf("%s: %v", name, e) }
+
+        restored := create()
+        if e := restored.Deserialize(buff); e != nil { t.Fatalf("%s: %v", name, e) }
+
+        if v, found := restored.Metadata("x"); !found || !v.Equals(md) {
+            t.Errorf("%s: unexpected metadata %v", name, v)
+        }
+        if _, found := restored.Metadata("y"); found {
+            t.Errorf("%s: element inserted without metadata has metadata", name)
+        }
+    }
+}
+
+// Sets without metadata serialize without a metadata section, exactly as they
+// did before metadata was kept.
+func TestMetadataSerializeWithout(t *testing.T) {
+    s := NewGSet[string]()
+    s.Insert("x")
+
+    buff := new(bytes.Buffer)
+    if e := s.Serialize(buff); e != nil { t.Fatal(e) }
+
+    with := NewGSet[string]()
+    with.InsertWithMetadata("x", Metadata{ReplicaId: "a"})
+
+    other := new(bytes.Buffer)
+    if e := with.Serialize(other); e != nil { t.Fatal(e) }
+
+    if other.Len() <= buff.Len() { t.Error("Expected a metadata section to be written!") }
+
+    disk := NewDiskGSet[string](t.TempDir())
+    defer disk.Close()
+    disk.Insert("x")
+
+    expected := new(bytes.Buffer)
+    if e := disk.Serialize(expected); e != nil { t.Fatal(e) }
+    if !bytes.Equal(buff.Bytes(), expected.Bytes()) { t.Error("Serialized set without metadata has changed!") }
+}
+
+func TestMetadataDeserializeInvalid(t *testing.T) {
+    s := NewGSet[string]()
+    s.InsertWithMetadata("x", Metadata{ReplicaId: "a", Labels: map[string]string{"k": "v"}})
+
+    buff := new(bytes.Buffer)
+    if e := s.Serialize(buff); e != nil { t.Fatal(e) }
+    data := buff.Bytes()
+
+    // Corrupt the label count of the metadata, and fix up the checksum.
+    corrupt := append([]byte{}, data...)
+    at := bytes.Index(corrupt, []byte{1, 'k', 1, 'v'}) - 1
+    if at < 0 { t.Fatal("Label not found!") }
+    corrupt[at] = 0x7f
+
+    body := corrupt[:len(corrupt) - 4]
+    binary.LittleEndian.PutUint32(corrupt[len(body):], crc32.ChecksumIEEE(body))
+
+    if e := NewGSet[string]().Deserialize(bytes.NewBuffer(corrupt)); e == nil {
+        t.Error("Expected an error deserializing a corrupt label count!")
+    }
+}
+
+func TestMetadataDelta(t *testing.T) {
+    md := Metadata{Timestamp: 3, ReplicaId: "a"}
+
+    a := New2P[string]()
+    a.Insert("x")
+    marker := a.Marker()
+    a.InsertWithMetadata("y", md)
+
+    buff := new(bytes.Buffer)
+    if _, e := a.SerializeDelta(buff, marker); e != nil { t.Fatal(e) }
+
+    b := New2P[string]()
+    if _, e := b.DeserializeDelta(buff); e != nil { t.Fatal(e) }
+
+    if v, found := b.Metadata("y"); !found || !v.Equals(md) { t.Errorf("Unexpected metadata %v", v) }
+    if b.Contains("x") { t.Error("Delta should not hold elements from before marker!") }
+}
+
+func TestMetadataCompact(t *testing.T) {
+    s := New2P[string]()
+    s.InsertWithMetadata("x", Metadata{ReplicaId: "a"})
+    s.Remove("x")
+    s.Acknowledge("b", s.Marker())
+
+    if s.Compact([]string{"b"}) != 1 { t.Fatal("Expected the tombstone to be compacted!") }
+    if s.InsertWithMetadata("x", Metadata{ReplicaId: "c"}) { t.Fatal("Compacted element should stay removed!") }
+
+    if v, found := s.Metadata("x"); found { t.Errorf("Unexpected metadata %v", v) }
+}
@@ -0,0 +1,377 @@
+/*
+ * Copyright (c) 2015 Tom Swindell (t.swindell@rubyx.co.uk)
+ *
+ * Permission is hereby granted, free of charge, to any person obtaining a copy
+ * of this software and associated documentation files (the "Software"), to deal
+ * in the Software without restriction, including without limitation the rights
+ * to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+ * copies of the Software, and to permit persons to whom the Software is
+ * furnished to do so, subject to the following conditions:
+ *
+ * The above copyright notice and this permission notice shall be included in
+ * all copies or substantial portions of the Software.
+ *
+ * THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+ * IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+ * FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+ * AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+ * LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+ * OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+ * SOFTWARE.
+ *
+ */
+
+package set
+
+import (
+    "bytes"
+    "crypto/rand"
+    "encoding/binary"
+    "fmt"
+    "io"
+    "iter"
+    "sync"
+)
+
+// Size in bytes of the unique tag attached to each insert.
+const ORSET_TAG_SIZE = 16
+
+// The tags type holds the unique add-tags observed for a single element.
+type tags map[string]struct{}
+
+// Common Go representation of an observed-remove set. Each insert attaches a
+// unique tag to the element, and a remove tombstones only the tags that it
+// has observed, so an element can be inserted again after being removed and
+// a concurrent insert always survives a remove.
+type ORSet[T comparable] struct {
+    sync.RWMutex
+    codec   Codec[T]
+    added    map[T]tags
+    removed  map[T]tags
+    metadata provenance[T] // Metadata of the elements inserted with any.
+    unknown  sections      // Sections of a newer format, kept to write back out.
+}
+
+// The NewORSet function returns a set serialized by the DefaultCodec of T.
+func NewORSet[T comparable]() *ORSet[T] {
+    return NewORSetWithCodec(DefaultCodec[T]())
+}
+
+func NewORSetWithCodec[T comparable](codec Codec[T]) *ORSet[T] {
+    s := new(ORSet[T])
+    s.codec   = codec
+    s.added   = make(map[T]tags)
+    s.removed = make(map[T]tags)
+    s.metadata = make(provenance[T])
+    return s
+}
+
+func newTag() string {
+    tag := make([]byte, ORSET_TAG_SIZE)
+    rand.Read(tag)
+    return string(tag)
+}
+
+// Returns true when at least one add-tag of item has not been tombstoned.
+// The caller must hold the lock.
+func (s *ORSet[T]) contains(item T) bool {
+    for tag := range s.added[item] {
+        if _, found := s.removed[item][tag]; !found { return true }
+    }
+    return false
+}
+
+func addTag[T comparable](m map[T]tags, item T, tag string) {
+    t, found := m[item]
+    if !found {
+        t = make(tags)
+        m[item] = t
+    }
+    t[tag] = struct{}{}
+}
+
+func (s *ORSet[T]) Insert(item T) bool {
+    s.Lock()
+    defer s.Unlock()
+
+    if s.contains(item) { return false }
+
+    addTag(s.added, item, newTag())
+    return true
+}
+
+// The InsertWithMetadata() method inserts item as Insert() does, recording md
+// as its metadata. An element inserted again after its removal keeps the
+// metadata of its first insert.
+func (s *ORSet[T]) InsertWithMetadata(item T, md Metadata) bool {
+    s.Lock()
+    defer s.Unlock()
+
+    if s.contains(item) { return false }
+
+    addTag(s.added, item, newTag())
+    s.metadata.update(item, md.clone())
+    return true
+}
+
+// The Metadata() method returns the metadata of item while it is contained.
+func (s *ORSet[T]) Metadata(item T) (Metadata, bool) {
+    s.RLock()
+    defer s.RUnlock()
+
+    if !s.contains(item) { return Metadata{}, false }
+
+    md, found := s.metadata[item]
+    return md, found
+}
+
+func (s *ORSet[T]) Remove(item T) bool {
+    s.Lock()
+    defer s.Unlock()
+
+    if !s.contains(item) { return false }
+
+    for tag := range s.added[item] { addTag(s.removed, item, tag) }
+    return true
+}
+
+func (s *ORSet[T]) Contains(item T) bool {
+    s.RLock()
+    defer s.RUnlock()
+
+    return s.contains(item)
+}
+
+func (s *ORSet[T]) Length() int {
+    s.RLock()
+    defer s.RUnlock()
+
+    length := 0
+    for i := range s.added {
+        if s.contains(i) { length++ }
+    }
+    return length
+}
+
+func equalTags[T comparable](a, b map[T]tags) bool {
+    if len(a) != len(b) { return false }
+
+    for i, at := range a {
+        bt, found := b[i]
+        if !found || len(at) != len(bt) { return false }
+
+        for tag := range at {
+            if _, found := bt[tag]; !found { return false }
+        }
+    }
+    return true
+}
+
+func (s *ORSet[T]) Equals(other *ORSet[T]) bool {
+    in := other.Clone()
+
+    s.RLock()
+    defer s.RUnlock()
+
+    return equalTags(s.added, in.added) && equalTags(s.removed, in.removed) &&
+           s.metadata.equals(in.metadata)
+}
+
+func copyTags[T comparable](dst, src map[T]tags) {
+    for i, t := range src {
+        for tag := range t { addTag(dst, i, tag) }
+    }
+}
+
+func (s *ORSet[T]) Merge(other *ORSet[T]) {
+    // Snapshot the other set first, so two sets merging into each other
+    // can never deadlock.
+    in := other.Clone()
+
+    s.Lock()
+    defer s.Unlock()
+
+    copyTags(s.added, in.added)
+    copyTags(s.removed, in.removed)
+    s.metadata.merge(in.metadata)
+    s.unknown = s.unknown.union(in.unknown)
+}
+
+func (s *ORSet[T]) Clone() *ORSet[T] {
+    s.RLock()
+    defer s.RUnlock()
+
+    result := NewORSetWithCodec(s.codec)
+    copyTags(result.added, s.added)
+    copyTags(result.removed, s.removed)
+    result.metadata = s.metadata.clone()
+    result.unknown = s.unknown
+    return result
+}
+
+func (s *ORSet[T]) Iterate() iter.Seq[T] {
+    return iterate(s.ToSlice())
+}
+
+func (s *ORSet[T]) IterateWithMetadata() iter.Seq2[T, Metadata] {
+    s.RLock()
+    defer s.RUnlock()
+
+    return iterateWithMetadata(s.toSlice(), s.metadata.clone())
+}
+
+func (s *ORSet[T]) ToSet() Set {
+    s.RLock()
+    defer s.RUnlock()
+
+    result := Set{}
+    for i := range s.added {
+        if s.contains(i) { result.Insert(i) }
+    }
+    return result
+}
+
+func (s *ORSet[T]) ToSlice() []T {
+    s.RLock()
+    defer s.RUnlock()
+
+    return s.toSlice()
+}
+
+// The caller must hold the lock.
+func (s *ORSet[T]) toSlice() []T {
+    result := make([]T, 0)
+    for i := range s.added {
+        if s.contains(i) { result = append(result, i) }
+    }
+    return result
+}
+
+var ORSET_HEADER_MAGIC = []byte{'c', 'r', 'd', 't', ':', 'o', 'r', 's', 'e', 't', 0x00}
+
+func deserializeTags[T comparable](r io.Reader, m map[T]tags, item T) error {
+    var count uint32
+    if e := binary.Read(r, binary.LittleEndian, &count); e != nil { return e }
+
+    for i := uint32(0); i < count; i++ {
+        tag := make([]byte, ORSET_TAG_SIZE)
+        if _, e := io.ReadFull(r, tag); e != nil { return fmt.Errorf("invalid format") }
+        addTag(m, item, string(tag))
+    }
+    return nil
+}
+
+// Sections of a v2 ORSet container.
+const (
+    ORSET_SECTION_ELEMENTS = 1 // Each element with its add-tags and removed tags.
+    ORSET_SECTION_METADATA = 2 // Each element with its metadata, when any has.
+)
+
+// Writes a uvarint count followed by the raw tags.
+func writeTagsV2(buff *bytes.Buffer, t tags) {
+    writeUvarint(buff, uint64(len(t)))
+    for tag := range t { buff.WriteString(tag) }
+}
+
+func readTagsV2[T comparable](in *limitedReader, m map[T]tags, item T) error {
+    count, e := readUvarint(in)
+    if e != nil { return e }
+    if count > in.n / ORSET_TAG_SIZE { return fmt.Errorf("invalid format") }
+
+    tag := make([]byte, ORSET_TAG_SIZE)
+    for i := uint64(0); i < count; i++ {
+        if _, e := io.ReadFull(in, tag); e != nil { return fmt.Errorf("invalid format") }
+        addTag(m, item, string(tag))
+    }
+    return nil
+}
+
+// The Serialize() method streams a snapshot of the set to w, without holding
+// the lock while writing.
+func (s *ORSet[T]) Serialize(w io.Writer) error {
+    snap := s.Clone()
+
+    elements := func(emit func([]byte) error) error {
+        scratch := new(bytes.Buffer)
+        for i, t := range snap.added {
+            scratch.Reset()
+            if e := writeElementV2(scratch, s.codec, i); e != nil { return e }
+
+            writeTagsV2(scratch, t)
+            writeTagsV2(scratch, snap.removed[i])
+
+            if e := emit(scratch.Bytes()); e != nil { return e }
+        }
+
+        // Tombstones merged in without their inserts.
+        for i, t := range snap.removed {
+            if _, found := snap.added[i]; found { continue }
+
+            scratch.Reset()
+            if e := writeElementV2(scratch, s.codec, i); e != nil { return e }
+
+            writeTagsV2(scratch, nil)
+            writeTagsV2(scratch, t)
+
+            if e := emit(scratch.Bytes()); e != nil { return e }
+        }
+        return nil
+    }
+
+    known := append([]sectionWriter{{ORSET_SECTION_ELEMENTS, elements}},
+                    snap.metadata.sections(ORSET_SECTION_METADATA, s.codec)...)
+    return writeContainer(w, ORSET_HEADER_MAGIC, known, snap.unknown)
+}
+
+// Deserialize merges the serialized state, in either format, into this set.
+// Nothing is merged unless all of it is read successfully.
+func (s *ORSet[T]) Deserialize(r io.Reader) error {
+    v2, e := readFormat(r, ORSET_HEADER_MAGIC)
+    if e != nil { return e }
+    if !v2 { return s.deserializeV1(r) }
+
+    result := NewORSetWithCodec(s.codec)
+
+    result.unknown, e = readContainer(r, ORSET_HEADER_MAGIC, map[uint64]sectionReader{
+        ORSET_SECTION_ELEMENTS: func(in *limitedReader) error {
+            for in.n > 0 {
+                item, e := readElementV2(in, s.codec)
+                if e != nil { return e }
+
+                if e := readTagsV2(in, result.added, item); e != nil { return e }
+                if e := readTagsV2(in, result.removed, item); e != nil { return e }
+            }
+            return nil
+        },
+        ORSET_SECTION_METADATA: result.metadata.reader(s.codec),
+    })
+    if e != nil { return e }
+
+    for i := range result.metadata {
+        if _, found := result.added[i]; !found { delete(result.metadata, i) }
+    }
+    s.Merge(result)
+    return nil
+}
+
+// Reads the v1 format after its header, a uint32 count followed by each
+// element as read by readElement with a uint32 count and the raw data of each
+// of its tags.
+func (s *ORSet[T]) deserializeV1(r io.Reader) error {
+    var sizeof uint32
+    if e := binary.Read(r, binary.LittleEndian, &sizeof); e != nil {
+        return e
+    }
+
+    result := NewORSetWithCodec(s.codec)
+    for i := uint32(0); i < sizeof; i++ {
+        item, e := readElement(r, s.codec)
+        if e != nil { return e }
+
+        if e := deserializeTags(r, result.added, item); e != nil { return e }
+        if e := deserializeTags(r, result.removed, item); e != nil { return e }
+    }
+
+    s.Merge(result)
+    return nil
+}
@@ -0,0 +1,318 @@
+/*
+ * Copyright (c) 2015 Tom Swindell (t.swindell@rubyx.co.uk)
+ *
+ * Permission is hereby granted, free of charge, to any person obtaining a copy
+ * of this software and associated documentation files (the "Software"), to deal
+ * in the Software without restriction, including without limitation the rights
+ * to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+ * copies of the Software, and to permit persons to whom the Software is
+ * furnished to do so, subject to the following conditions:
+ *
+ * The above copyright notice and this permission notice shall be included in
+ * all copies or substantial portions of the Software.
+ *
+ * THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+ * IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+ * FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+ * AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+ * LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+ * OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+ * SOFTWARE.
+ *
+ */
+
+package set
+
+import "testing"
+import "bytes"
+import "encoding/base64"
+import "crypto/rand"
+import "sync"
+import "encoding/binary"
+import "fmt"
+import "time"
+
+func TestNewORSet(t *testing.T) {
+    a := NewORSet[interface{}]()
+    if a.Length() != 0 {
+        t.Error("New Set should have 0 length!")
+    }
+}
+
+func TestORSetInsert(t *testing.T) {
+    a := NewORSet[interface{}]()
+
+    if !a.Insert(1) {
+        t.Error("Failed to insert 1 into Set!")
+    }
+
+    a.Insert(2)
+
+    if a.Insert(1) {
+        t.Error("Insert returned success when attempting to insert twice!")
+    }
+
+    a.Insert(3)
+
+    if a.Length() != 3 {
+        t.Error("Set length should == 3!")
+    }
+}
+
+func TestORSetRemoveAndContains(t *testing.T) {
+    a := NewORSet[interface{}]()
+
+    a.Insert(1)
+    a.Insert(2)
+    a.Insert(3)
+
+    if !a.Contains(2) {
+        t.Error("Failed contains check in set!")
+    }
+
+    if !a.Remove(2) {
+        t.Error("Failed to remove from set!")
+    }
+
+    if a.Contains(2) {
+        t.Error("Contains returned true after remove!")
+    }
+
+    if a.Remove(2) {
+        t.Error("Remove returned success when attempting to remove twice!")
+    }
+}
+
+func TestORSetReinsert(t *testing.T) {
+    a := NewORSet[interface{}]()
+
+    a.Insert(1)
+    a.Remove(1)
+
+    if !a.Insert(1) {
+        t.Error("Failed to re-insert removed element!")
+    }
+
+    if !a.Contains(1) || a.Length() != 1 {
+        t.Error("Re-inserted element not contained in set!")
+    }
+}
+
+func TestORSetLength(t *testing.T) {
+    a := NewORSet[interface{}]()
+
+    for i := 1; i <= 10; i++ {
+        a.Insert(i)
+        if a.Length() != i {
+            t.Errorf("Length check failed after insert! Expecting %d got %d", i, a.Length())
+        }
+    }
+
+    for i := 10; i >= 1; i-- {
+        a.Remove(i)
+        if a.Length() != i-1 {
+            t.Errorf("Length check failed after remove! Expecting %d got %d", i, a.Length())
+        }
+    }
+}
+
+func TestORSetEquals(t *testing.T) {
+    a := NewORSet[interface{}]()
+    b := NewORSet[interface{}]()
+
+    for i := 1; i <= 10; i++ { a.Insert(i) }
+    b.Merge(a)
+
+    if !a.Equals(b) {
+        t.Error("a equals b check failed!")
+    }
+
+    // The same elements with different tags are distinct states.
+    c := NewORSet[interface{}]()
+    for i := 1; i <= 10; i++ { c.Insert(i) }
+
+    if a.Equals(c) {
+        t.Error("a equals c check failed!")
+    }
+}
+
+func TestORSetClone(t *testing.T) {
+    a := NewORSet[interface{}]()
+
+    for i := 1; i <= 10; i++ {a.Insert(i)}
+
+    b := a.Clone()
+
+    if !a.Equals(b) {
+        t.Errorf("Expected a equals b!")
+    }
+
+    a.Insert(11)
+
+    if a.Equals(b) {
+        t.Errorf("Expected a not to equal b!")
+    }
+}
+
+func TestORSetMerge(t *testing.T) {
+    a := NewORSet[interface{}]()
+    b := NewORSet[interface{}]()
+
+    for i := 1; i <= 10; i++ { a.Insert(i) }
+    for i := 11; i <= 20; i++ { b.Insert(i) }
+
+    a.Merge(b)
+
+    if a.Length() != 20 {
+        t.Errorf("Expected length of 20 after merge, got %d", a.Length())
+    }
+
+    b.Merge(a)
+
+    if !a.Equals(b) {
+        t.Error("Equals failed after merge!")
+    }
+}
+
+func TestORSetConcurrentInsertWins(t *testing.T) {
+    a := NewORSet[interface{}]()
+    a.Insert(1)
+
+    b := a.Clone()
+
+    // Concurrently remove on a, and re-insert on b.
+    a.Remove(1)
+    b.Remove(1)
+    b.Insert(1)
+
+    a.Merge(b)
+    b.Merge(a)
+
+    if !a.Contains(1) || !b.Contains(1) {
+        t.Error("Concurrent insert should survive remove!")
+    }
+
+    if !a.Equals(b) {
+        t.Error("Replicas failed to converge!")
+    }
+}
+
+func TestORSetSerialize(t *testing.T) {
+    a := NewORSet[interface{}]()
+    b := NewORSet[interface{}]()
+
+    for i := 0; i < 10; i++ {
+        data := make([]byte, 4)
+        rand.Read(data)
+        a.Insert(base64.StdEncoding.EncodeToString(data))
+    }
+
+    for _, v := range a.ToSlice()[:5] { a.Remove(v) }
+
+    out := &bytes.Buffer{}
+    if e := a.Serialize(out); e != nil { t.Error(e) }
+
+    in := bytes.NewBuffer(out.Bytes())
+    if e := b.Deserialize(in); e != nil { t.Error(e) }
+
+    if !a.Equals(b) { t.Error("Match failed") }
+    if b.Length() != 5 { t.Errorf("Expected length of 5, got %d", b.Length()) }
+}
+
+// Two sets compared with each other from both sides, while being written, must
+// not deadlock.
+func TestORSetConcurrentEquals(t *testing.T) {
+    a := NewORSet[interface{}]()
+    b := NewORSet[interface{}]()
+
+    var wg sync.WaitGroup
+    for w := 0; w < 4; w++ {
+        wg.Add(2)
+        go func(w int) { defer wg.Done(); for i := 0; i < 100; i++ { a.Insert(w * 100 + i); a.Equals(b) } }(w)
+        go func(w int) { defer wg.Done(); for i := 0; i < 100; i++ { b.Insert(w * 100 + i); b.Equals(a) } }(w)
+    }
+    wg.Wait()
+
+    if a.Length() != 400 || b.Length() != 400 {
+        t.Error("Unexpected length after concurrent inserts!")
+    }
+}
+
+// A writer which inserts into the set being serialized, failing if the insert
+// does not complete because the set is still locked.
+type insertingWriter struct {
+    insert func()
+}
+
+func (w insertingWriter) Write(p []byte) (int, error) {
+    done := make(chan struct{})
+    go func() { w.insert(); close(done) }()
+
+    select {
+    case <-done:
+        return len(p), nil
+    case <-time.After(time.Second):
+        return 0, fmt.Errorf("set locked while serializing")
+    }
+}
+
+func TestORSetSerializeUnlocked(t *testing.T) {
+    a := NewORSet[string]()
+    a.Insert("a")
+
+    if e := a.Serialize(insertingWriter{func() { a.Insert("b") }}); e != nil { t.Fatal(e) }
+    if !a.Contains("b") { t.Error("Expected insert while serializing!") }
+}
+
+// A tombstone read without its insert must survive a round trip.
+func TestORSetSerializeTombstoneOnly(t *testing.T) {
+    buff := new(bytes.Buffer)
+    buff.Write(ORSET_HEADER_MAGIC)
+    binary.Write(buff, binary.LittleEndian, uint32(1))
+    writeElement[string](buff, StringCodec{}, "a")
+    binary.Write(buff, binary.LittleEndian, uint32(0))
+    binary.Write(buff, binary.LittleEndian, uint32(1))
+    buff.Write(bytes.Repeat([]byte{7}, ORSET_TAG_SIZE))
+
+    a := NewORSet[string]()
+    if e := a.Deserialize(buff); e != nil { t.Fatal(e) }
+
+    out := new(bytes.Buffer)
+    if e := a.Serialize(out); e != nil { t.Fatal(e) }
+
+    b := NewORSet[string]()
+    if e := b.Deserialize(out); e != nil { t.Fatal(e) }
+    if !a.Equals(b) || b.Equals(NewORSet[string]()) { t.Error("Round trip lost the tombstone!") }
+}
+
+// Checks malformed input is refused without merging anything, and that any
+// input accepted round trips.
+func FuzzORSetDeserialize(f *testing.F) {
+    a := NewORSet[string]()
+    a.Insert("alpha")
+    a.Insert("beta")
+    a.Remove("alpha")
+    a.InsertWithMetadata("gamma", Metadata{Timestamp: 1, ReplicaId: "r", Labels: map[string]string{"k": "v"}})
+
+    out := new(bytes.Buffer)
+    a.Serialize(out)
+
+    f.Add(out.Bytes())
+    f.Add(orsetV1())
+    f.Add(ORSET_HEADER_MAGIC)
+
+    f.Fuzz(func(t *testing.T, data []byte) {
+        b := NewORSet[string]()
+        if e := b.Deserialize(bytes.NewReader(data)); e != nil {
+            if !b.Equals(NewORSet[string]()) { t.Error("Failed deserialize modified the set!") }
+            return
+        }
+
+        out := new(bytes.Buffer)
+        if e := b.Serialize(out); e != nil { t.Fatal(e) }
+
+        c := NewORSet[string]()
+        if e := c.Deserialize(out); e != nil { t.Fatal(e) }
+        if !b.Equals(c) { t.Error("Round trip does not match!") }
+    })
+}
@@ -23,6 +23,8 @@
 
 package set
 
+import "iter"
+
 // Common Go representation of a math set.
 type Set map[interface{}]struct{}
 
@@ -71,13 +73,14 @@ func (s Set) Merge(other Set) {
     }
 }
 
-func (s Set) Iterate() <-chan interface{} {
-    ch := make(chan interface{})
-    go func() {
-        for i := range s { ch <- i }
-        close(ch)
-    }()
-    return ch
+// The Iterate() method ranges over the set itself, which must not be modified
+// other than by Remove before the loop ends.
+func (s Set) Iterate() iter.Seq[interface{}] {
+    return func(yield func(interface{}) bool) {
+        for i := range s {
+            if !yield(i) { return }
+        }
+    }
 }
 
 func (s Set) ToSlice() []interface{} {
@@ -25,95 +25,501 @@ package set
 
 import (
     "bytes"
+    "crypto/sha256"
     "fmt"
+    "io"
+    "iter"
+    "sort"
+    "sync"
 )
 
-type TwoPhase struct {
-    added   GSet
-    removed GSet
+// Common Go representation of a two-phase set, a pair of grow only sets of
+// inserted and removed elements. Once removed, an element can never return.
+//
+// A TwoPhase set is safe for concurrent use. Its own lock is held across both
+// grow only sets, so an Insert can never interleave with a Remove of the same
+// element, and Length is never computed between the two halves of a Merge.
+//
+// Removed elements are kept as tombstones until Compact finds that every
+// replica of the set holds them, see Acknowledge. Only a digest of each is then
+// kept, so that it can never be inserted again.
+type TwoPhase[T comparable] struct {
+    sync.RWMutex
+    added     *GSet[T]
+    removed   *GSet[T]
+    acks      map[string]uint64   // Removal history acknowledged by each replica.
+    compacted map[digest]struct{} // Digests of the elements forgotten by Compact.
+    unknown   sections            // Sections of a newer format, kept to write back out.
 }
 
-func New2P() *TwoPhase {
-  s := new(TwoPhase)
-  s.added   = NewGSet()
-  s.removed = NewGSet()
+// Size in bytes of the digest kept of each element forgotten by Compact.
+const TWOPHASESET_DIGEST_SIZE = 16
+
+// The digest type holds the truncated SHA-256 of the encoding of an element.
+type digest [TWOPHASESET_DIGEST_SIZE]byte
+
+// The New2P function returns a set serialized by the DefaultCodec of T.
+func New2P[T comparable]() *TwoPhase[T] {
+    return New2PWithCodec(DefaultCodec[T]())
+}
+
+func New2PWithCodec[T comparable](codec Codec[T]) *TwoPhase[T] {
+  s := new(TwoPhase[T])
+  s.added     = NewGSetWithCodec(codec)
+  s.removed   = NewGSetWithCodec(codec)
+  s.acks      = make(map[string]uint64)
+  s.compacted = make(map[digest]struct{})
   return s
 }
 
-func (s *TwoPhase) Insert(item interface{}) bool {
-    if s.removed.Contains(item) {
+func (s *TwoPhase[T]) Insert(item T) bool {
+    s.Lock()
+    defer s.Unlock()
+
+    if s.removed.Contains(item) || s.forgotten(item) {
         return false
     }
     return s.added.Insert(item)
 }
 
-func (s *TwoPhase) Remove(item interface{}) bool {
+func (s *TwoPhase[T]) Remove(item T) bool {
+    s.Lock()
+    defer s.Unlock()
+
     if !s.added.Contains(item) {
         return false
     }
     return s.removed.Insert(item)
 }
 
-func (s *TwoPhase) Length() int {
+// The InsertWithMetadata() method inserts item as Insert() does, recording md
+// as its metadata. Metadata is discarded along with the tombstone of its
+// element by Compact.
+func (s *TwoPhase[T]) InsertWithMetadata(item T, md Metadata) bool {
+    s.Lock()
+    defer s.Unlock()
+
+    if s.removed.Contains(item) || s.forgotten(item) {
+        return false
+    }
+    return s.added.InsertWithMetadata(item, md)
+}
+
+// The Metadata() method returns the metadata of item while it is contained.
+func (s *TwoPhase[T]) Metadata(item T) (Metadata, bool) {
+    s.RLock()
+    defer s.RUnlock()
+
+    if s.removed.Contains(item) { return Metadata{}, false }
+    return s.added.Metadata(item)
+}
+
+func (s *TwoPhase[T]) Length() int {
+    s.RLock()
+    defer s.RUnlock()
+
     return s.added.Length() - s.removed.Length()
 }
 
-func (s *TwoPhase) Contains(item interface{}) bool {
+func (s *TwoPhase[T]) Contains(item T) bool {
+    s.RLock()
+    defer s.RUnlock()
+
     return s.added.Contains(item) && !s.removed.Contains(item)
 }
 
-func (s *TwoPhase) Equals(other *TwoPhase) bool {
-    return s.added.Equals(other.added) && s.removed.Equals(other.removed)
+func (s *TwoPhase[T]) Equals(other *TwoPhase[T]) bool {
+    in := other.Clone()
+
+    s.RLock()
+    defer s.RUnlock()
+
+    return s.added.Equals(in.added) && s.removed.Equals(in.removed)
 }
 
-func (s *TwoPhase) Merge(other *TwoPhase) *TwoPhase {
-    result := New2P()
-    s.added.Merge(other.added)
-    s.removed.Merge(other.removed)
+func (s *TwoPhase[T]) Merge(other *TwoPhase[T]) *TwoPhase[T] {
+    // Snapshot the other set first, so two sets merging into each other
+    // can never deadlock.
+    in := other.Clone()
+
+    s.Lock()
+    defer s.Unlock()
+
+    result := New2PWithCodec(s.added.codec)
+    s.drop(in.added, in.removed)
+    s.added.Merge(in.added)
+    s.removed.Merge(in.removed)
+    s.unknown = s.unknown.union(in.unknown)
     return result
 }
 
-func (s *TwoPhase) Clone() *TwoPhase {
-    result := New2P()
+func (s *TwoPhase[T]) Clone() *TwoPhase[T] {
+    s.RLock()
+    defer s.RUnlock()
+
+    result := New2PWithCodec(s.added.codec)
     result.added = s.added.Clone()
     result.removed = s.removed.Clone()
+    for d := range s.compacted { result.compacted[d] = struct{}{} }
+    result.unknown = s.unknown
     return result
 }
 
-func (s *TwoPhase) Iterate() <-chan interface{} {
-    in := s.ToSet()
-    return in.Iterate()
+func (s *TwoPhase[T]) Iterate() iter.Seq[T] {
+    return iterate(s.ToSlice())
 }
 
-func (s *TwoPhase) ToSet() Set {
-    result := Set{}
-    for i := range s.added.Iterate() {
-        if !s.removed.Contains(i) { result.Insert(i) }
+func (s *TwoPhase[T]) IterateWithMetadata() iter.Seq2[T, Metadata] {
+    s.RLock()
+    defer s.RUnlock()
+
+    return iterateWithMetadata(s.toSlice(), s.added.provenance())
+}
+
+func (s *TwoPhase[T]) ToSlice() []T {
+    s.RLock()
+    defer s.RUnlock()
+
+    return s.toSlice()
+}
+
+// The caller must hold the lock.
+func (s *TwoPhase[T]) toSlice() []T {
+    result := make([]T, 0)
+    for _, i := range s.added.ToSlice() {
+        if !s.removed.Contains(i) { result = append(result, i) }
     }
     return result
 }
 
+func (s *TwoPhase[T]) ToSet() Set {
+    result := Set{}
+    for _, i := range s.ToSlice() { result.Insert(i) }
+    return result
+}
+
 var TWOPHASESET_HEADER_MAGIC = []byte{'c', 'r', 'd', 't', ':', '2', 'p', 's', 'e', 't', 0x00}
 
-func (s *TwoPhase) Serialize(buff *bytes.Buffer) error {
-    buff.Write(TWOPHASESET_HEADER_MAGIC)
+// Sections of a v2 TwoPhase container, each encoded as a GSet section.
+const (
+    TWOPHASESET_SECTION_ADDED     = 1
+    TWOPHASESET_SECTION_REMOVED   = 2
+    TWOPHASESET_SECTION_METADATA  = 3 // Encoded as a GSet metadata section.
+    TWOPHASESET_SECTION_ACKS      = 4 // Tombstones acknowledged by each replica.
+    TWOPHASESET_SECTION_COMPACTED = 5 // Digests of the elements forgotten by Compact.
+)
+
+// Returns the section holding, for each replica which acknowledged the set,
+// how many of the tombstones in the removed section it holds. Tombstones are
+// written in history order, so the count is the position of the replica in a
+// restored history. The caller must hold the lock.
+func (s *TwoPhase[T]) acknowledgements(tag uint64) []sectionWriter {
+    if len(s.acks) == 0 { return nil }
+
+    replicas := make([]string, 0, len(s.acks))
+    for replica := range s.acks { replicas = append(replicas, replica) }
+    sort.Strings(replicas)
+
+    held := make([]uint64, len(replicas))
+    for i, replica := range replicas { held[i] = s.removed.heldTo(s.acks[replica]) }
+
+    return []sectionWriter{{tag, func(emit func([]byte) error) error {
+        scratch := new(bytes.Buffer)
+        for i, replica := range replicas {
+            scratch.Reset()
+            writeBytes(scratch, []byte(replica))
+            writeUvarint(scratch, held[i])
+            if e := emit(scratch.Bytes()); e != nil { return e }
+        }
+        return nil
+    }}}
+}
+
+// Returns the section holding the digests of the elements forgotten by
+// Compact, sorted so that equal sets serialize equally. The caller must hold
+// the lock.
+func (s *TwoPhase[T]) compactedSection(tag uint64) []sectionWriter {
+    if len(s.compacted) == 0 { return nil }
+
+    digests := make([]digest, 0, len(s.compacted))
+    for d := range s.compacted { digests = append(digests, d) }
+    sort.Slice(digests, func(a, b int) bool { return bytes.Compare(digests[a][:], digests[b][:]) < 0 })
+
+    return []sectionWriter{{tag, func(emit func([]byte) error) error {
+        for _, d := range digests {
+            if e := emit(d[:]); e != nil { return e }
+        }
+        return nil
+    }}}
+}
+
+func readCompacted(compacted map[digest]struct{}) sectionReader {
+    return func(in *limitedReader) error {
+        if in.n % TWOPHASESET_DIGEST_SIZE != 0 { return fmt.Errorf("invalid format") }
+
+        for in.n > 0 {
+            data, e := readN(in, TWOPHASESET_DIGEST_SIZE)
+            if e != nil { return e }
+            compacted[digest(data)] = struct{}{}
+        }
+        return nil
+    }
+}
+
+func readAcknowledgements(acks map[string]uint64) sectionReader {
+    return func(in *limitedReader) error {
+        for in.n > 0 {
+            replica, e := readBytes(in)
+            if e != nil { return e }
+
+            n, e := readUvarint(in)
+            if e != nil { return e }
+            acks[string(replica)] = n
+        }
+        return nil
+    }
+}
+
+// The Serialize() method streams a snapshot of the set to w, without holding
+// the lock while writing. Both halves are snapshot under the lock, so they are
+// from the same moment.
+func (s *TwoPhase[T]) Serialize(w io.Writer) error {
+    s.RLock()
+    added := s.added.elements(TWOPHASESET_SECTION_ADDED)
+    removed := s.removed.elements(TWOPHASESET_SECTION_REMOVED)
+    metadata := s.added.provenance()
+    acks := s.acknowledgements(TWOPHASESET_SECTION_ACKS)
+    compacted := s.compactedSection(TWOPHASESET_SECTION_COMPACTED)
+    unknown := s.unknown
+    s.RUnlock()
+
+    known := append([]sectionWriter{added, removed},
+                    metadata.sections(TWOPHASESET_SECTION_METADATA, s.added.codec)...)
+    known = append(known, acks...)
+    known = append(known, compacted...)
+    return writeContainer(w, TWOPHASESET_HEADER_MAGIC, known, unknown)
+}
+
+// Deserialize merges the serialized state, in either format, into this set.
+// Nothing is merged unless all of it is read successfully. Acknowledgements and
+// the digests of compacted elements are only restored into an empty set, which
+// takes the history of the serialized set as it is; merged into any other
+// history their positions mean nothing. Elements this set has compacted are
+// never merged back, as by Merge().
+func (s *TwoPhase[T]) Deserialize(r io.Reader) error {
+    v2, e := readFormat(r, TWOPHASESET_HEADER_MAGIC)
+    if e != nil { return e }
+    if !v2 { return s.deserializeV1(r) }
+
+    codec := s.added.codec
+    added := NewGSetWithCodec(codec)
+    removed := NewGSetWithCodec(codec)
+
+    readInto := func(set *GSet[T]) sectionReader {
+        return func(in *limitedReader) error {
+            items, e := readElements(in, codec)
+            for _, i := range items { set.Insert(i) }
+            return e
+        }
+    }
+
+    metadata := make(provenance[T])
+    acks := make(map[string]uint64)
+    compacted := make(map[digest]struct{})
+    unknown, e := readContainer(r, TWOPHASESET_HEADER_MAGIC, map[uint64]sectionReader{
+        TWOPHASESET_SECTION_ADDED:     readInto(added),
+        TWOPHASESET_SECTION_REMOVED:   readInto(removed),
+        TWOPHASESET_SECTION_METADATA:  metadata.reader(codec),
+        TWOPHASESET_SECTION_ACKS:      readAcknowledgements(acks),
+        TWOPHASESET_SECTION_COMPACTED: readCompacted(compacted),
+    })
+    if e != nil { return e }
+    added.annotate(metadata)
+
+    s.Lock()
+    defer s.Unlock()
+
+    s.unknown = s.unknown.union(unknown)
 
-    s.added.Serialize(buff)
-    s.removed.Serialize(buff)
+    if s.added.Marker().get(0) != 0 || s.removed.Marker().get(0) != 0 {
+        s.drop(added, removed)
+        s.added.Merge(added)
+        s.removed.Merge(removed)
+        return nil
+    }
 
+    s.added = added
+    s.removed = removed
+    s.compacted = compacted
+    for replica, n := range acks {
+        if n <= removed.Marker().get(0) { s.acks[replica] = n }
+    }
     return nil
 }
 
-func (s *TwoPhase) Deserialize(buff *bytes.Buffer) error {
-    if buff.Len() < len(TWOPHASESET_HEADER_MAGIC) { return fmt.Errorf("data too small") }
+// Reads the v1 format after its header, two v1 GSet blobs.
+func (s *TwoPhase[T]) deserializeV1(r io.Reader) error {
+    added := NewGSetWithCodec(s.added.codec)
+    if e := added.Deserialize(r); e != nil { return e }
 
-    header := make([]byte, len(TWOPHASESET_HEADER_MAGIC))
-    _, e := buff.Read(header)
-    if e != nil || !bytes.Equal(header, TWOPHASESET_HEADER_MAGIC) { return fmt.Errorf("invalid header") }
+    removed := NewGSetWithCodec(s.added.codec)
+    if e := removed.Deserialize(r); e != nil { return e }
 
-    if e := s.added.Deserialize(buff); e != nil { return e }
-    if e := s.removed.Deserialize(buff); e != nil { return e }
+    s.Lock()
+    defer s.Unlock()
 
+    s.drop(added, removed)
+    s.added.Merge(added)
+    s.removed.Merge(removed)
     return nil
 }
 
+
+func (s *TwoPhase[T]) Marker() Marker {
+    s.RLock()
+    defer s.RUnlock()
+
+    return Marker{s.added.Marker().get(0), s.removed.Marker().get(0)}
+}
+
+// The DeltaSince() method returns the inserts and removes made since marker,
+// and the marker to request the next delta from.
+func (s *TwoPhase[T]) DeltaSince(marker Marker) (*TwoPhase[T], Marker) {
+    s.RLock()
+    defer s.RUnlock()
+
+    next := Marker{s.added.Marker().get(0), s.removed.Marker().get(0)}
+
+    result := New2PWithCodec(s.added.codec)
+    result.added = s.added.since(marker.get(0))
+    result.removed = s.removed.since(marker.get(1))
+    return result, next
+}
+
+func (s *TwoPhase[T]) ApplyDelta(delta *TwoPhase[T]) {
+    s.Merge(delta)
+}
+
+func (s *TwoPhase[T]) SerializeDelta(buff *bytes.Buffer, marker Marker) (Marker, error) {
+    delta, next := s.DeltaSince(marker)
+
+    writeDeltaHeader(buff, marker, next)
+    if e := delta.Serialize(buff); e != nil { return nil, e }
+    return next, nil
+}
+
+// DeserializeDelta applies a serialized delta, returning the marker it reaches
+// in the history of the set which produced it.
+func (s *TwoPhase[T]) DeserializeDelta(buff *bytes.Buffer) (Marker, error) {
+    next, e := readDeltaHeader(buff)
+    if e != nil { return nil, e }
+
+    delta := New2PWithCodec(s.added.codec)
+    if e := delta.Deserialize(buff); e != nil { return nil, e }
+
+    s.ApplyDelta(delta)
+    return next, nil
+}
+
+// The Acknowledge() method records that replica holds every insert and remove
+// of this set up to marker, as returned with a delta the replica has applied.
+// Acknowledgements only ever advance, and are serialized with the set so that
+// a restored set keeps them. A marker must come from this set instance; one
+// beyond its history is ignored.
+func (s *TwoPhase[T]) Acknowledge(replica string, marker Marker) {
+    s.Lock()
+    defer s.Unlock()
+
+    n := marker.get(1)
+    if n > s.removed.Marker().get(0) { return }
+    if acked, found := s.acks[replica]; !found || n > acked { s.acks[replica] = n }
+}
+
+// Returns the digest kept of item once it is compacted, or false when item can
+// not be encoded by the codec of the set.
+func (s *TwoPhase[T]) digestOf(item T) (digest, bool) {
+    if s.added.codec == nil { return digest{}, false }
+
+    data, e := s.added.codec.Encode(item)
+    if e != nil { return digest{}, false }
+
+    sum := sha256.Sum256(data)
+    return digest(sum[:TWOPHASESET_DIGEST_SIZE]), true
+}
+
+// Returns true when item was forgotten by Compact. The caller must hold the
+// lock.
+func (s *TwoPhase[T]) forgotten(item T) bool {
+    if len(s.compacted) == 0 { return false }
+
+    d, ok := s.digestOf(item)
+    if !ok { return false }
+
+    _, found := s.compacted[d]
+    return found
+}
+
+// Drops the elements forgotten by Compact from halves about to be merged into
+// this set, which no one else holds. The caller must hold the lock.
+func (s *TwoPhase[T]) drop(added, removed *GSet[T]) {
+    if len(s.compacted) == 0 { return }
+
+    for _, half := range []*GSet[T]{added, removed} {
+        for i := range half.contents {
+            if s.forgotten(i) { half.forget(i) }
+        }
+    }
+}
+
+// The Compact() method forgets the tombstones which every one of replicas has
+// acknowledged, returning how many were forgotten. Such an element is already
+// removed everywhere, and only a digest of it is kept: it can never be
+// inserted again, and merging a replica which has not yet compacted it, or an
+// older serialized state, does not bring back its insert or its tombstone.
+// Until each of replicas has acknowledged the set nothing is known to be safe,
+// and nothing is forgotten; nor is it when no replicas are named. Elements the
+// codec of the set can not encode keep their tombstones.
+//
+// The replicas must name every other replica exchanging state with this set,
+// or state merged from an unknown replica may bring forgotten elements back.
+// Acknowledgements of replicas not named are ignored.
+func (s *TwoPhase[T]) Compact(replicas []string) int {
+    s.Lock()
+    defer s.Unlock()
+
+    if len(replicas) == 0 { return 0 }
+
+    stable := s.removed.Marker().get(0)
+    for _, replica := range replicas {
+        n, found := s.acks[replica]
+        if !found { return 0 }
+        if n < stable { stable = n }
+    }
+
+    s.removed.Lock()
+    s.added.Lock()
+    defer s.removed.Unlock()
+    defer s.added.Unlock()
+
+    count := 0
+    for i, seq := range s.removed.contents {
+        if seq > stable { continue }
+
+        d, ok := s.digestOf(i)
+        if !ok { continue }
+
+        s.compacted[d] = struct{}{}
+        s.removed.forget(i)
+        s.added.forget(i)
+        count++
+    }
+    return count
+}
+
+// The Tombstones() method returns the number of removed elements still held.
+func (s *TwoPhase[T]) Tombstones() int {
+    s.RLock()
+    defer s.RUnlock()
+
+    return s.removed.Length()
+}
@@ -27,16 +27,18 @@ import "testing"
 import "bytes"
 import "encoding/base64"
 import "crypto/rand"
+import "fmt"
+import "sync"
 
 func TestNew2P(t *testing.T) {
-    a := New2P()
+    a := New2P[interface{}]()
     if a.Length() != 0 {
         t.Error("New Set should have 0 length!")
     }
 }
 
 func Test2PInsert(t *testing.T) {
-    a := New2P()
+    a := New2P[interface{}]()
 
     if !a.Insert(1) {
         t.Error("Failed to insert 1 into Set!")
@@ -56,7 +58,7 @@ func Test2PInsert(t *testing.T) {
 }
 
 func Test2PRemoveAndContains(t *testing.T) {
-    a := New2P()
+    a := New2P[interface{}]()
 
     a.Insert(1)
     a.Insert(2)
@@ -74,7 +76,7 @@ func Test2PRemoveAndContains(t *testing.T) {
 }
 
 func Test2PLength(t *testing.T) {
-    a := New2P()
+    a := New2P[interface{}]()
 
     for i := 1; i <= 10; i++ {
         a.Insert(i)
@@ -93,9 +95,9 @@ func Test2PLength(t *testing.T) {
 
 
 func Test2PEquals(t *testing.T) {
-    a := New2P()
-    b := New2P()
-    c := New2P()
+    a := New2P[interface{}]()
+    b := New2P[interface{}]()
+    c := New2P[interface{}]()
 
     for i := 1; i <= 10; i++ { a.Insert(i); b.Insert(i) }
     for i := 1; i <= 5; i++ { c.Insert(i) }
@@ -110,7 +112,7 @@ func Test2PEquals(t *testing.T) {
 }
 
 func Test2PClone(t *testing.T) {
-    a := New2P()
+    a := New2P[interface{}]()
 
     for i := 1; i <= 10; i++ {a.Insert(i)}
 
@@ -132,9 +134,9 @@ func Test2PClone(t *testing.T) {
 }
 
 func Test2PMerge(t *testing.T) {
-    a := New2P()
-    b := New2P()
-    c := New2P()
+    a := New2P[interface{}]()
+    b := New2P[interface{}]()
+    c := New2P[interface{}]()
 
     for i := 1; i <= 10; i++ {
         a.Insert(i)
@@ -153,8 +155,8 @@ func Test2PMerge(t *testing.T) {
 }
 
 func Test2PSerialize(t *testing.T) {
-    a := New2P()
-    b := New2P()
+    a := New2P[interface{}]()
+    b := New2P[interface{}]()
 
     for i := 0; i < 10; i++ {
         data := make([]byte, 4)
@@ -171,3 +173,267 @@ func Test2PSerialize(t *testing.T) {
     if !a.Equals(b) { t.Error("Match failed") }
 }
 
+func Test2PSerializeUnlocked(t *testing.T) {
+    a := New2P[string]()
+    a.Insert("a")
+    a.Remove("a")
+    a.Acknowledge("b", a.Marker())
+
+    if e := a.Serialize(insertingWriter{func() { a.Insert("b") }}); e != nil { t.Fatal(e) }
+    if !a.Contains("b") { t.Error("Expected insert while serializing!") }
+}
+
+
+func Test2PConcurrentInsertRemove(t *testing.T) {
+    a := New2P[interface{}]()
+
+    var wg sync.WaitGroup
+    for w := 0; w < 8; w++ {
+        wg.Add(2)
+        go func() {
+            defer wg.Done()
+            for i := 0; i < 500; i++ { a.Insert(i) }
+        }()
+        go func() {
+            defer wg.Done()
+            for i := 0; i < 500; i += 2 {
+                a.Remove(i)
+                if n := a.Length(); n < 0 { t.Errorf("Negative length %d!", n) }
+            }
+        }()
+    }
+    wg.Wait()
+
+    // Removes racing ahead of inserts fail, so retry them now every insert
+    // has landed.
+    for i := 0; i < 500; i += 2 { a.Remove(i) }
+
+    if a.Length() != 250 {
+        t.Errorf("Expected length of 250, got %d", a.Length())
+    }
+    for i := 0; i < 500; i++ {
+        if a.Contains(i) != (i % 2 == 1) { t.Errorf("Unexpected membership of %d!", i) }
+    }
+}
+
+func Test2PConcurrentMerge(t *testing.T) {
+    a := New2P[interface{}]()
+    b := New2P[interface{}]()
+
+    for i := 0; i < 100; i++ { a.Insert(i); b.Insert(i + 100) }
+    b.Remove(150)
+
+    var wg sync.WaitGroup
+    for w := 0; w < 4; w++ {
+        wg.Add(2)
+        go func() { defer wg.Done(); a.Merge(b); a.Equals(b) }()
+        go func() { defer wg.Done(); b.Merge(a); b.Equals(a) }()
+    }
+    wg.Wait()
+
+    if !a.Equals(b) || a.Length() != 199 {
+        t.Error("Equals failed after concurrent merge!")
+    }
+}
+
+func Test2PCompact(t *testing.T) {
+    a := New2P[string]()
+    b := New2P[string]()
+
+    a.Insert("1")
+    a.Insert("2")
+    a.Remove("1")
+
+    if a.Compact([]string{"b"}) != 0 || a.Tombstones() != 1 {
+        t.Fatal("Nothing should be compacted without acknowledgements!")
+    }
+
+    delta, marker := a.DeltaSince(nil)
+    b.ApplyDelta(delta)
+    a.Acknowledge("b", marker)
+
+    if n := a.Compact([]string{"b"}); n != 1 || a.Tombstones() != 0 {
+        t.Fatalf("Expected one tombstone compacted, got %d", n)
+    }
+
+    if a.Contains("1") || !a.Contains("2") || a.Length() != 1 {
+        t.Error("Unexpected contents after compaction!")
+    }
+
+    // Merging a replica which still holds the tombstone can not bring the
+    // element back.
+    a.Merge(b)
+    if a.Contains("1") { t.Error("Compacted element was resurrected by merge!") }
+}
+
+// A compacted element stays removed, whether inserted again, merged from a
+// replica which has not compacted it yet or restored from older state.
+func Test2PCompactedStaysRemoved(t *testing.T) {
+    a := New2P[string]()
+    b := New2P[string]()
+
+    a.Insert("x")
+    a.Insert("y")
+    a.Remove("x")
+    b.Merge(a)
+
+    old := new(bytes.Buffer)
+    if e := a.Serialize(old); e != nil { t.Fatal(e) }
+
+    a.Acknowledge("b", a.Marker())
+    if a.Compact([]string{"b"}) != 1 { t.Fatal("Expected the tombstone to be compacted!") }
+
+    if a.Insert("x") { t.Error("Compacted element should not be inserted again!") }
+
+    // The replica which has not compacted yet still refuses the insert, and
+    // its tombstone does not return on merge.
+    if b.Insert("x") { t.Error("Removed element should not be inserted again!") }
+    a.Merge(b)
+    b.Merge(a)
+
+    if a.Contains("x") || b.Contains("x") || a.Tombstones() != 0 {
+        t.Error("Compacted element returned through merge!")
+    }
+
+    if e := a.Deserialize(old); e != nil { t.Fatal(e) }
+    if a.Contains("x") || a.Tombstones() != 0 { t.Error("Compacted element returned through restore!") }
+
+    // The digests of compacted elements survive a restore.
+    out := new(bytes.Buffer)
+    if e := a.Serialize(out); e != nil { t.Fatal(e) }
+
+    c := New2P[string]()
+    if e := c.Deserialize(out); e != nil { t.Fatal(e) }
+    if c.Insert("x") || !c.Contains("y") || !a.Equals(c) {
+        t.Error("Compacted element should stay removed after restore!")
+    }
+}
+
+func Test2PCompactWaitsForReplicas(t *testing.T) {
+    a := New2P[string]()
+
+    a.Insert("1")
+    a.Insert("2")
+    _, old := a.DeltaSince(nil)
+
+    a.Remove("1")
+    _, marker := a.DeltaSince(nil)
+
+    a.Acknowledge("b", marker)
+    a.Acknowledge("c", old)
+
+    if a.Compact([]string{"b", "c"}) != 0 { t.Error("Tombstone compacted before every replica held it!") }
+
+    // Stale and unknown markers never move an acknowledgement.
+    a.Acknowledge("c", Marker{2, 100})
+    if a.Compact([]string{"b", "c"}) != 0 { t.Error("Marker beyond history should be ignored!") }
+
+    a.Acknowledge("c", marker)
+    a.Acknowledge("c", old)
+    if a.Compact([]string{"b", "c"}) != 1 { t.Error("Tombstone should be compacted once every replica holds it!") }
+}
+
+func Test2PCompactKeepsMarkers(t *testing.T) {
+    a := New2P[string]()
+
+    for i := 0; i < 10; i++ { a.Insert(fmt.Sprint(i)) }
+    for i := 0; i < 5; i++ { a.Remove(fmt.Sprint(i)) }
+
+    _, marker := a.DeltaSince(nil)
+    a.Acknowledge("b", marker)
+    a.Compact([]string{"b"})
+
+    a.Insert("10")
+    a.Remove("5")
+
+    delta, _ := a.DeltaSince(marker)
+    if delta.added.Length() != 1 || !delta.added.Contains("10") ||
+       delta.removed.Length() != 1 || !delta.removed.Contains("5") {
+        t.Errorf("Unexpected delta after compaction: %v, %v", delta.added.ToSlice(), delta.removed.ToSlice())
+    }
+
+    // History order survives the gaps left by compaction.
+    out := new(bytes.Buffer)
+    if e := a.Serialize(out); e != nil { t.Fatal(e) }
+
+    b := New2P[string]()
+    if e := b.Deserialize(out); e != nil { t.Fatal(e) }
+    if !a.Equals(b) { t.Error("Compacted set should round trip!") }
+}
+
+func Test2PCompactNeedsEveryReplica(t *testing.T) {
+    a := New2P[string]()
+    a.Insert("x")
+    a.Remove("x")
+    a.Acknowledge("b", a.Marker())
+
+    if a.Compact([]string{"b", "c"}) != 0 { t.Error("Tombstone compacted before c acknowledged it!") }
+    if a.Compact([]string{"c"}) != 0 { t.Error("Acknowledgement of a replica not named should be ignored!") }
+    if a.Compact(nil) != 0 { t.Error("Nothing should be compacted without replicas!") }
+    if a.Compact([]string{"b"}) != 1 { t.Error("Tombstone should be compacted once every replica holds it!") }
+}
+
+func Test2PAcknowledgementsSurviveRestore(t *testing.T) {
+    a := New2P[string]()
+
+    for i := 0; i < 10; i++ { a.Insert(fmt.Sprint(i)) }
+    for i := 0; i < 5; i++ { a.Remove(fmt.Sprint(i)) }
+    a.Acknowledge("b", a.Marker())
+    a.Remove("5")
+
+    out := new(bytes.Buffer)
+    if e := a.Serialize(out); e != nil { t.Fatal(e) }
+    data := out.Bytes()
+
+    b := New2P[string]()
+    if e := b.Deserialize(bytes.NewBuffer(data)); e != nil { t.Fatal(e) }
+    if n := b.Compact([]string{"b"}); n != 5 || b.Tombstones() != 1 {
+        t.Errorf("Expected 5 tombstones compacted after restore, got %d", n)
+    }
+
+    // Positions of a compacted set shift when restored, the acknowledgement
+    // must still only cover the tombstones the replica holds.
+    out.Reset()
+    if e := b.Serialize(out); e != nil { t.Fatal(e) }
+
+    c := New2P[string]()
+    if e := c.Deserialize(out); e != nil { t.Fatal(e) }
+    if n := c.Compact([]string{"b"}); n != 0 || c.Tombstones() != 1 {
+        t.Errorf("Unacknowledged tombstone compacted after restore, got %d", n)
+    }
+
+    // Merged into another history the acknowledgements mean nothing.
+    d := New2P[string]()
+    d.Insert("x")
+    if e := d.Deserialize(bytes.NewBuffer(data)); e != nil { t.Fatal(e) }
+    if d.Compact([]string{"b"}) != 0 { t.Error("Acknowledgements merged into another history!") }
+}
+
+func Fuzz2PDeserialize(f *testing.F) {
+    a := New2P[string]()
+    a.Insert("alpha")
+    a.Insert("beta")
+    a.Remove("alpha")
+
+    out := new(bytes.Buffer)
+    a.Serialize(out)
+
+    f.Add(out.Bytes())
+    f.Add(append(append(append([]byte{}, TWOPHASESET_HEADER_MAGIC...), gsetV1("alpha", "beta")...), gsetV1("alpha")...))
+    f.Add(TWOPHASESET_HEADER_MAGIC)
+
+    f.Fuzz(func(t *testing.T, data []byte) {
+        b := New2P[string]()
+        if e := b.Deserialize(bytes.NewReader(data)); e != nil {
+            if b.Length() != 0 || b.Tombstones() != 0 { t.Error("Failed deserialize modified the set!") }
+            return
+        }
+
+        out := new(bytes.Buffer)
+        if e := b.Serialize(out); e != nil { t.Fatal(e) }
+
+        c := New2P[string]()
+        if e := c.Deserialize(out); e != nil { t.Fatal(e) }
+        if !b.Equals(c) { t.Error("Round trip does not match!") }
+    })
+}
@@ -0,0 +1,119 @@
+/*
+ * Copyright (c) 2015 Tom Swindell (t.swindell@rubyx.co.uk)
+ *
+ * Permission is hereby granted, free of charge, to any person obtaining a copy
+ * of this software and associated documentation files (the "Software"), to deal
+ * in the Software without restriction, including without limitation the rights
+ * to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+ * copies of the Software, and to permit persons to whom the Software is
+ * furnished to do so, subject to the following conditions:
+ *
+ * The above copyright notice and this permission notice shall be included in
+ * all copies or substantial portions of the Software.
+ *
+ * THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+ * IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+ * FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+ * AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+ * LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+ * OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+ * SOFTWARE.
+ *
+ */
+
+package set
+
+import (
+    "bytes"
+    "encoding/binary"
+    "fmt"
+    "hash/crc32"
+    "io"
+    "iter"
+)
+
+// Reads an element in the v1 layout, as length, crc32 and the raw data, and
+// decodes it with codec.
+func readElement[T comparable](r io.Reader, codec Codec[T]) (T, error) {
+    var item T
+    var datl uint64
+    var datc uint32
+
+    if codec == nil { return item, fmt.Errorf("no codec for element type") }
+
+    if e := binary.Read(r, binary.LittleEndian, &datl); e != nil { return item, e }
+    if e := binary.Read(r, binary.LittleEndian, &datc); e != nil { return item, e }
+
+    object, e := readN(r, datl)
+    if e != nil { return item, e }
+
+    if datc != crc32.ChecksumIEEE(object) { return item, fmt.Errorf("crc32 failure") }
+
+    return codec.Decode(object)
+}
+
+// Returns a sequence yielding each of items, stopping as soon as the consumer
+// does.
+func iterate[T any](items []T) iter.Seq[T] {
+    return func(yield func(T) bool) {
+        for _, i := range items {
+            if !yield(i) { return }
+        }
+    }
+}
+
+// The ReadHeader function reads and checks the type header magic, shared by
+// every type serialized with one.
+func ReadHeader(buff *bytes.Buffer, magic []byte) error {
+    if buff.Len() < len(magic) { return fmt.Errorf("data too small") }
+
+    header := make([]byte, len(magic))
+    _, e := buff.Read(header)
+    if e != nil || !bytes.Equal(header, magic) { return fmt.Errorf("invalid header") }
+
+    return nil
+}
+
+// The WriteString function writes a replica id or other short string as uint16
+// length and raw data.
+func WriteString(buff *bytes.Buffer, s string) error {
+    if len(s) > 0xffff { return fmt.Errorf("string too long") }
+
+    binary.Write(buff, binary.LittleEndian, uint16(len(s)))
+    buff.WriteString(s)
+    return nil
+}
+
+func ReadString(buff *bytes.Buffer) (string, error) {
+    var l uint16
+    if e := binary.Read(buff, binary.LittleEndian, &l); e != nil { return "", e }
+
+    data := make([]byte, l)
+    if _, e := io.ReadFull(buff, data); e != nil { return "", fmt.Errorf("invalid format") }
+    return string(data), nil
+}
+
+// The WriteValue function writes an opaque value as length, crc32 and raw
+// data, the v1 layout of an element.
+func WriteValue(buff *bytes.Buffer, value []byte) {
+    binary.Write(buff, binary.LittleEndian, uint64(len(value)))
+    binary.Write(buff, binary.LittleEndian, crc32.ChecksumIEEE(value))
+    buff.Write(value)
+}
+
+func ReadValue(buff *bytes.Buffer) ([]byte, error) {
+    var datl uint64
+    var datc uint32
+
+    if e := binary.Read(buff, binary.LittleEndian, &datl); e != nil { return nil, e }
+    if e := binary.Read(buff, binary.LittleEndian, &datc); e != nil { return nil, e }
+
+    if datl > uint64(buff.Len()) { return nil, fmt.Errorf("invalid format") }
+
+    value := make([]byte, datl)
+    if _, e := io.ReadFull(buff, value); e != nil { return nil, fmt.Errorf("invalid format") }
+
+    if datc != crc32.ChecksumIEEE(value) { return nil, fmt.Errorf("crc32 failure") }
+
+    return value, nil
+}
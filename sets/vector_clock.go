@@ -0,0 +1,145 @@
+/*
+ * Copyright (c) 2015 Tom Swindell (t.swindell@rubyx.co.uk)
+ *
+ * Permission is hereby granted, free of charge, to any person obtaining a copy
+ * of this software and associated documentation files (the "Software"), to deal
+ * in the Software without restriction, including without limitation the rights
+ * to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+ * copies of the Software, and to permit persons to whom the Software is
+ * furnished to do so, subject to the following conditions:
+ *
+ * The above copyright notice and this permission notice shall be included in
+ * all copies or substantial portions of the Software.
+ *
+ * THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+ * IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+ * FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+ * AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+ * LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+ * OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+ * SOFTWARE.
+ *
+ */
+
+package set
+
+import (
+    "bytes"
+    "encoding/binary"
+    "sort"
+)
+
+// The Ordering type describes how two vector clocks relate.
+type Ordering int
+
+const (
+    CLOCK_EQUAL      Ordering = iota
+    CLOCK_BEFORE              // Every event of a was observed by b.
+    CLOCK_AFTER               // Every event of b was observed by a.
+    CLOCK_CONCURRENT          // Neither observed all events of the other.
+)
+
+// Common Go representation of a vector clock, mapping replica ids to the
+// number of events observed from that replica. Missing replicas count as
+// zero. Like Set, a VectorClock is not safe for concurrent use, types which
+// embed one guard it with their own lock.
+type VectorClock map[string]uint64
+
+func NewVectorClock() VectorClock {
+    return VectorClock{}
+}
+
+func (v VectorClock) Get(replicaId string) uint64 {
+    return v[replicaId]
+}
+
+// The Increment() method records a new event from replicaId, returning its
+// counter.
+func (v VectorClock) Increment(replicaId string) uint64 {
+    v[replicaId]++
+    return v[replicaId]
+}
+
+// The Merge() method advances v to the pointwise maximum of both clocks.
+func (v VectorClock) Merge(other VectorClock) {
+    for id, n := range other {
+        if n > v[id] { v[id] = n }
+    }
+}
+
+// Returns true when v has observed every event other has observed.
+func (v VectorClock) Descends(other VectorClock) bool {
+    for id, n := range other {
+        if v[id] < n { return false }
+    }
+    return true
+}
+
+func (v VectorClock) Compare(other VectorClock) Ordering {
+    descends := v.Descends(other)
+    dominated := other.Descends(v)
+
+    switch {
+        case descends && dominated: return CLOCK_EQUAL
+        case dominated: return CLOCK_BEFORE
+        case descends: return CLOCK_AFTER
+    }
+    return CLOCK_CONCURRENT
+}
+
+func (v VectorClock) Equals(other VectorClock) bool {
+    return v.Compare(other) == CLOCK_EQUAL
+}
+
+func (v VectorClock) Concurrent(other VectorClock) bool {
+    return v.Compare(other) == CLOCK_CONCURRENT
+}
+
+func (v VectorClock) Clone() VectorClock {
+    result := NewVectorClock()
+    for id, n := range v { result[id] = n }
+    return result
+}
+
+var VCLOCK_HEADER_MAGIC = []byte{'c', 'r', 'd', 't', ':', 'v', 'c', 'l', 'o', 'c', 'k', 0x00}
+
+// Entries are written in replica id order, so equal clocks serialize to the
+// same bytes. Zero entries are skipped.
+func (v VectorClock) Serialize(buff *bytes.Buffer) error {
+    ids := make([]string, 0, len(v))
+    for id, n := range v {
+        if n > 0 { ids = append(ids, id) }
+    }
+    sort.Strings(ids)
+
+    buff.Write(VCLOCK_HEADER_MAGIC)
+    binary.Write(buff, binary.LittleEndian, uint32(len(ids)))
+
+    for _, id := range ids {
+        if e := WriteString(buff, id); e != nil { return e }
+        binary.Write(buff, binary.LittleEndian, v[id])
+    }
+
+    return nil
+}
+
+// Deserialize merges the serialized clock into v.
+func (v VectorClock) Deserialize(buff *bytes.Buffer) error {
+    if e := ReadHeader(buff, VCLOCK_HEADER_MAGIC); e != nil { return e }
+
+    var sizeof uint32
+    if e := binary.Read(buff, binary.LittleEndian, &sizeof); e != nil { return e }
+
+    in := NewVectorClock()
+    for i := uint32(0); i < sizeof; i++ {
+        id, e := ReadString(buff)
+        if e != nil { return e }
+
+        var n uint64
+        if e := binary.Read(buff, binary.LittleEndian, &n); e != nil { return e }
+        in[id] = n
+    }
+
+    v.Merge(in)
+    return nil
+}
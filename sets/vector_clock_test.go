@@ -0,0 +1,173 @@
+/*
+ * Copyright (c) 2015 Tom Swindell (t.swindell@rubyx.co.uk)
+ *
+ * Permission is hereby granted, free of charge, to any person obtaining a copy
+ * of this software and associated documentation files (the "Software"), to deal
+ * in the Software without restriction, including without limitation the rights
+ * to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+ * copies of the Software, and to permit persons to whom the Software is
+ * furnished to do so, subject to the following conditions:
+ *
+ * The above copyright notice and this permission notice shall be included in
+ * all copies or substantial portions of the Software.
+ *
+ * THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+ * IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+ * FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+ * AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+ * LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+ * OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+ * SOFTWARE.
+ *
+ */
+
+package set
+
+import "testing"
+import "bytes"
+
+func TestNewVectorClock(t *testing.T) {
+    a := NewVectorClock()
+    if a.Get("a") != 0 {
+        t.Error("New clock should have zero entries!")
+    }
+
+    if a.Compare(NewVectorClock()) != CLOCK_EQUAL {
+        t.Error("New clocks should be equal!")
+    }
+}
+
+func TestVectorClockIncrement(t *testing.T) {
+    a := NewVectorClock()
+
+    if a.Increment("a") != 1 || a.Increment("a") != 2 || a.Get("a") != 2 {
+        t.Error("Increment should count events!")
+    }
+
+    b := a.Clone()
+    b.Increment("b")
+
+    if a.Compare(b) != CLOCK_BEFORE || b.Compare(a) != CLOCK_AFTER {
+        t.Error("Increment should advance clock past its origin!")
+    }
+}
+
+func TestVectorClockCompare(t *testing.T) {
+    tests := []struct {
+        a, b     VectorClock
+        expected Ordering
+    }{
+        {VectorClock{}, VectorClock{}, CLOCK_EQUAL},
+        {VectorClock{"a": 1}, VectorClock{"a": 1}, CLOCK_EQUAL},
+        {VectorClock{"a": 1, "b": 0}, VectorClock{"a": 1}, CLOCK_EQUAL},
+        {VectorClock{}, VectorClock{"a": 1}, CLOCK_BEFORE},
+        {VectorClock{"a": 1}, VectorClock{"a": 2}, CLOCK_BEFORE},
+        {VectorClock{"a": 1}, VectorClock{"a": 1, "b": 1}, CLOCK_BEFORE},
+        {VectorClock{"a": 2, "b": 1}, VectorClock{"a": 1}, CLOCK_AFTER},
+        {VectorClock{"a": 1}, VectorClock{"b": 1}, CLOCK_CONCURRENT},
+        {VectorClock{"a": 2, "b": 1}, VectorClock{"a": 1, "b": 2}, CLOCK_CONCURRENT},
+    }
+
+    for i, test := range tests {
+        if o := test.a.Compare(test.b); o != test.expected {
+            t.Errorf("Test %d: expected %d, got %d", i, test.expected, o)
+        }
+    }
+}
+
+func TestVectorClockCompareAntisymmetric(t *testing.T) {
+    clocks := []VectorClock{
+        {}, {"a": 1}, {"b": 1}, {"a": 1, "b": 1}, {"a": 2}, {"a": 2, "b": 1}, {"c": 3},
+    }
+
+    inverse := map[Ordering]Ordering{
+        CLOCK_EQUAL: CLOCK_EQUAL,
+        CLOCK_BEFORE: CLOCK_AFTER,
+        CLOCK_AFTER: CLOCK_BEFORE,
+        CLOCK_CONCURRENT: CLOCK_CONCURRENT,
+    }
+
+    for _, a := range clocks {
+        for _, b := range clocks {
+            if a.Compare(b) != inverse[b.Compare(a)] {
+                t.Errorf("Compare of %v and %v is not antisymmetric!", a, b)
+            }
+
+            for _, c := range clocks {
+                if a.Descends(b) && b.Descends(c) && !a.Descends(c) {
+                    t.Errorf("Descends is not transitive for %v, %v, %v!", a, b, c)
+                }
+            }
+        }
+    }
+}
+
+func TestVectorClockMerge(t *testing.T) {
+    a := VectorClock{"a": 2, "b": 1}
+    b := VectorClock{"a": 1, "b": 3, "c": 1}
+    c := VectorClock{"c": 2}
+
+    ab := a.Clone()
+    ab.Merge(b)
+    ba := b.Clone()
+    ba.Merge(a)
+
+    if !ab.Equals(ba) || !ab.Equals(VectorClock{"a": 2, "b": 3, "c": 1}) {
+        t.Errorf("Merge should be commutative, got %v and %v", ab, ba)
+    }
+
+    if !ab.Descends(a) || !ab.Descends(b) {
+        t.Error("Merge should descend from both clocks!")
+    }
+
+    abc := ab.Clone()
+    abc.Merge(c)
+    bc := b.Clone()
+    bc.Merge(c)
+    a_bc := a.Clone()
+    a_bc.Merge(bc)
+
+    if !abc.Equals(a_bc) {
+        t.Error("Merge should be associative!")
+    }
+
+    aa := a.Clone()
+    aa.Merge(a)
+    if !aa.Equals(a) {
+        t.Error("Merge should be idempotent!")
+    }
+}
+
+func TestVectorClockSerialize(t *testing.T) {
+    a := VectorClock{"a": 2, "b": 1, "c": 0}
+
+    buff := new(bytes.Buffer)
+    if e := a.Serialize(buff); e != nil {
+        t.Fatal(e)
+    }
+
+    data := buff.Bytes()
+
+    b := NewVectorClock()
+    if e := b.Deserialize(buff); e != nil {
+        t.Fatal(e)
+    }
+
+    if !a.Equals(b) {
+        t.Error("Deserialized clock should equal original!")
+    }
+
+    buff.Reset()
+    b.Serialize(buff)
+    if !bytes.Equal(data, buff.Bytes()) {
+        t.Error("Equal clocks should serialize identically!")
+    }
+
+    if e := b.Deserialize(bytes.NewBuffer(data[:len(data) - 1])); e == nil {
+        t.Error("Expected truncated data error!")
+    }
+
+    if e := b.Deserialize(bytes.NewBufferString("crdt:gset")); e == nil {
+        t.Error("Expected header error!")
+    }
+}
@@ -0,0 +1,314 @@
+/*
+ * Copyright (c) 2015 Tom Swindell (t.swindell@rubyx.co.uk)
+ *
+ * Permission is hereby granted, free of charge, to any person obtaining a copy
+ * of this software and associated documentation files (the "Software"), to deal
+ * in the Software without restriction, including without limitation the rights
+ * to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+ * copies of the Software, and to permit persons to whom the Software is
+ * furnished to do so, subject to the following conditions:
+ *
+ * The above copyright notice and this permission notice shall be included in
+ * all copies or substantial portions of the Software.
+ *
+ * THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+ * IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+ * FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+ * AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+ * LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+ * OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+ * SOFTWARE.
+ *
+ */
+
+package tree
+
+import (
+    "bytes"
+    "encoding/binary"
+    "fmt"
+    "sort"
+    "sync"
+
+    "github.com/tswindell/go-crdt/sets"
+)
+
+var (
+    E_UNKNOWN_NODE = fmt.Errorf("crdt:error-unknown-node")
+    E_NODE_EXISTS  = fmt.Errorf("crdt:error-node-exists")
+    E_INVALID_NODE = fmt.Errorf("crdt:error-invalid-node")
+    E_CYCLE        = fmt.Errorf("crdt:error-cycle")
+)
+
+const (
+    // The root node always exists, and can not be moved or deleted.
+    ROOT_NODE = ""
+
+    // Deleted nodes are moved beneath the trash node, which is never visible.
+    TRASH_NODE = "\x00"
+)
+
+// The Timestamp type is a Lamport timestamp, with the replica id breaking ties
+// so that every replica orders operations the same way.
+type Timestamp struct {
+    Counter   uint64
+    ReplicaId string
+}
+
+func (t Timestamp) Less(other Timestamp) bool {
+    if t.Counter != other.Counter { return t.Counter < other.Counter }
+    return t.ReplicaId < other.ReplicaId
+}
+
+// The Move type records a single operation on the tree. Creating, moving and
+// deleting a node are all moves, deleting being a move beneath TRASH_NODE.
+type Move struct {
+    Time   Timestamp
+    Node   string
+    Parent string
+}
+
+// Common Go representation of a replicated tree with a move operation. The
+// state is the log of all moves, ordered by timestamp, and the tree is found
+// by replaying the log, skipping any move that would make a node its own
+// ancestor. As every replica replays the same log in the same order, moves
+// made concurrently on different replicas can never form a cycle.
+type Tree struct {
+    sync.RWMutex
+    log     []Move
+    parents map[string]string
+}
+
+func NewTree() *Tree {
+    return &Tree{log: make([]Move, 0), parents: make(map[string]string)}
+}
+
+// Returns true when ancestor is node, or one of its ancestors. The caller must
+// hold the lock.
+func (t *Tree) isAncestor(ancestor, node string) bool {
+    for {
+        if node == ancestor { return true }
+
+        parent, found := t.parents[node]
+        if !found { return false }
+        node = parent
+    }
+}
+
+// Returns true when node is known, including deleted nodes.
+func (t *Tree) known(node string) bool {
+    if node == ROOT_NODE || node == TRASH_NODE { return true }
+
+    _, found := t.parents[node]
+    return found
+}
+
+// Applies a move to the current tree, unless it would introduce a cycle.
+func (t *Tree) apply(m Move) {
+    if !t.known(m.Parent) || t.isAncestor(m.Node, m.Parent) { return }
+    t.parents[m.Node] = m.Parent
+}
+
+// Rebuilds the tree by replaying the whole log.
+func (t *Tree) replay() {
+    t.parents = make(map[string]string)
+    for _, m := range t.log { t.apply(m) }
+}
+
+// Returns the next timestamp for an operation by replicaId.
+func (t *Tree) next(replicaId string) Timestamp {
+    var counter uint64
+    if len(t.log) > 0 { counter = t.log[len(t.log) - 1].Time.Counter }
+    return Timestamp{counter + 1, replicaId}
+}
+
+// Appends a local move, which always has the highest timestamp in the log.
+func (t *Tree) move(replicaId, node, parent string) {
+    m := Move{t.next(replicaId), node, parent}
+    t.log = append(t.log, m)
+    t.apply(m)
+}
+
+// Returns true when node is in the tree, and not deleted.
+func (t *Tree) contains(node string) bool {
+    return node != TRASH_NODE && t.known(node) && t.isAncestor(ROOT_NODE, node)
+}
+
+// The Create() method adds node to the tree, beneath parent. Node ids can not
+// be reused, even after the node is deleted.
+func (t *Tree) Create(replicaId, node, parent string) error {
+    t.Lock()
+    defer t.Unlock()
+
+    if node == ROOT_NODE || node == TRASH_NODE { return E_INVALID_NODE }
+    if t.known(node) { return E_NODE_EXISTS }
+    if !t.contains(parent) { return E_UNKNOWN_NODE }
+
+    t.move(replicaId, node, parent)
+    return nil
+}
+
+// The Move() method moves node, and all of its descendants, beneath parent.
+func (t *Tree) Move(replicaId, node, parent string) error {
+    t.Lock()
+    defer t.Unlock()
+
+    if node == ROOT_NODE || node == TRASH_NODE { return E_INVALID_NODE }
+    if !t.contains(node) || !t.contains(parent) { return E_UNKNOWN_NODE }
+    if t.isAncestor(node, parent) { return E_CYCLE }
+
+    t.move(replicaId, node, parent)
+    return nil
+}
+
+// The Delete() method removes node, and all of its descendants, from the tree.
+// A descendant moved elsewhere concurrently is kept.
+func (t *Tree) Delete(replicaId, node string) error {
+    t.Lock()
+    defer t.Unlock()
+
+    if node == ROOT_NODE || node == TRASH_NODE { return E_INVALID_NODE }
+    if !t.contains(node) { return E_UNKNOWN_NODE }
+
+    t.move(replicaId, node, TRASH_NODE)
+    return nil
+}
+
+func (t *Tree) Contains(node string) bool {
+    t.RLock()
+    defer t.RUnlock()
+
+    return t.contains(node)
+}
+
+func (t *Tree) Parent(node string) (string, error) {
+    t.RLock()
+    defer t.RUnlock()
+
+    if node == ROOT_NODE || !t.contains(node) { return "", E_UNKNOWN_NODE }
+    return t.parents[node], nil
+}
+
+// The Children() method returns the children of node, in sorted order.
+func (t *Tree) Children(node string) ([]string, error) {
+    t.RLock()
+    defer t.RUnlock()
+
+    if !t.contains(node) { return nil, E_UNKNOWN_NODE }
+
+    children := make([]string, 0)
+    for child, parent := range t.parents {
+        if parent == node { children = append(children, child) }
+    }
+    sort.Strings(children)
+    return children, nil
+}
+
+func (t *Tree) Equals(other *Tree) bool {
+    in := other.Clone()
+
+    t.RLock()
+    defer t.RUnlock()
+
+    if len(t.log) != len(in.log) { return false }
+
+    for i := range t.log {
+        if t.log[i] != in.log[i] { return false }
+    }
+    return true
+}
+
+// Merges moves into the log, keeping it sorted and free of duplicates, then
+// replays it. The caller must hold the lock.
+func (t *Tree) merge(moves []Move) {
+    log := make([]Move, 0, len(t.log) + len(moves))
+
+    i, j := 0, 0
+    for i < len(t.log) || j < len(moves) {
+        switch {
+        case j == len(moves) || (i < len(t.log) && t.log[i].Time.Less(moves[j].Time)):
+            log = append(log, t.log[i]); i++
+        case i == len(t.log) || moves[j].Time.Less(t.log[i].Time):
+            log = append(log, moves[j]); j++
+        default:
+            log = append(log, t.log[i]); i++; j++
+        }
+    }
+
+    t.log = log
+    t.replay()
+}
+
+func (t *Tree) Merge(other *Tree) {
+    other.RLock()
+    moves := append([]Move(nil), other.log...)
+    other.RUnlock()
+
+    t.Lock()
+    defer t.Unlock()
+
+    t.merge(moves)
+}
+
+func (t *Tree) Clone() *Tree {
+    t.RLock()
+    defer t.RUnlock()
+
+    result := NewTree()
+    result.log = append(result.log, t.log...)
+    for node, parent := range t.parents { result.parents[node] = parent }
+    return result
+}
+
+var TREE_HEADER_MAGIC = []byte{'c', 'r', 'd', 't', ':', 't', 'r', 'e', 'e', 0x00}
+
+func (t *Tree) Serialize(buff *bytes.Buffer) error {
+    t.RLock()
+    defer t.RUnlock()
+
+    buff.Write(TREE_HEADER_MAGIC)
+    binary.Write(buff, binary.LittleEndian, uint32(len(t.log)))
+
+    for _, m := range t.log {
+        binary.Write(buff, binary.LittleEndian, m.Time.Counter)
+        if e := set.WriteString(buff, m.Time.ReplicaId); e != nil { return e }
+        if e := set.WriteString(buff, m.Node); e != nil { return e }
+        if e := set.WriteString(buff, m.Parent); e != nil { return e }
+    }
+
+    return nil
+}
+
+// Deserialize merges the serialized log into this tree. A log moving the root
+// or trash nodes is refused, as Move() refuses to.
+func (t *Tree) Deserialize(buff *bytes.Buffer) error {
+    if e := set.ReadHeader(buff, TREE_HEADER_MAGIC); e != nil { return e }
+
+    var count uint32
+    if e := binary.Read(buff, binary.LittleEndian, &count); e != nil { return e }
+
+    moves := make([]Move, 0)
+    for i := uint32(0); i < count; i++ {
+        var m Move
+        var e error
+
+        if e = binary.Read(buff, binary.LittleEndian, &m.Time.Counter); e != nil { return e }
+        if m.Time.ReplicaId, e = set.ReadString(buff); e != nil { return e }
+        if m.Node, e = set.ReadString(buff); e != nil { return e }
+        if m.Parent, e = set.ReadString(buff); e != nil { return e }
+
+        if m.Node == ROOT_NODE || m.Node == TRASH_NODE {
+            return fmt.Errorf("invalid format")
+        }
+        if len(moves) > 0 && !moves[len(moves) - 1].Time.Less(m.Time) {
+            return fmt.Errorf("invalid format")
+        }
+        moves = append(moves, m)
+    }
+
+    t.Lock()
+    defer t.Unlock()
+
+    t.merge(moves)
+    return nil
+}
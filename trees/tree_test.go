@@ -0,0 +1,233 @@
+/*
+ * Copyright (c) 2015 Tom Swindell (t.swindell@rubyx.co.uk)
+ *
+ * Permission is hereby granted, free of charge, to any person obtaining a copy
+ * of this software and associated documentation files (the "Software"), to deal
+ * in the Software without restriction, including without limitation the rights
+ * to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+ * copies of the Software, and to permit persons to whom the Software is
+ * furnished to do so, subject to the following conditions:
+ *
+ * The above copyright notice and this permission notice shall be included in
+ * all copies or substantial portions of the Software.
+ *
+ * THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+ * IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+ * FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+ * AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+ * LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+ * OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+ * SOFTWARE.
+ *
+ */
+
+package tree
+
+import "testing"
+import "bytes"
+
+func TestNewTree(t *testing.T) {
+    a := NewTree()
+
+    if !a.Contains(ROOT_NODE) {
+        t.Error("New tree should contain the root node!")
+    }
+
+    if c, _ := a.Children(ROOT_NODE); len(c) != 0 {
+        t.Error("New tree should have no nodes!")
+    }
+}
+
+func TestTreeCreate(t *testing.T) {
+    a := NewTree()
+
+    if e := a.Create("A", "docs", ROOT_NODE); e != nil {
+        t.Fatal(e)
+    }
+
+    if e := a.Create("A", "docs", ROOT_NODE); e != E_NODE_EXISTS {
+        t.Error("Expected node exists error!")
+    }
+
+    if e := a.Create("A", "notes", "missing"); e != E_UNKNOWN_NODE {
+        t.Error("Expected unknown node error!")
+    }
+
+    if e := a.Create("A", ROOT_NODE, "docs"); e != E_INVALID_NODE {
+        t.Error("Expected invalid node error!")
+    }
+
+    a.Create("A", "notes", "docs")
+
+    if p, e := a.Parent("notes"); e != nil || p != "docs" {
+        t.Error("Unexpected parent of node!", p, e)
+    }
+}
+
+func TestTreeMove(t *testing.T) {
+    a := NewTree()
+    a.Create("A", "a", ROOT_NODE)
+    a.Create("A", "b", "a")
+    a.Create("A", "c", ROOT_NODE)
+
+    if e := a.Move("A", "a", "b"); e != E_CYCLE {
+        t.Error("Expected cycle error!")
+    }
+
+    if e := a.Move("A", "a", "a"); e != E_CYCLE {
+        t.Error("Expected cycle error!")
+    }
+
+    if e := a.Move("A", "a", "c"); e != nil {
+        t.Fatal(e)
+    }
+
+    if c, _ := a.Children("c"); len(c) != 1 || c[0] != "a" {
+        t.Error("Unexpected children of node!", c)
+    }
+
+    if p, _ := a.Parent("b"); p != "a" {
+        t.Error("Descendants should move along with node!")
+    }
+}
+
+func TestTreeDelete(t *testing.T) {
+    a := NewTree()
+    a.Create("A", "a", ROOT_NODE)
+    a.Create("A", "b", "a")
+
+    if e := a.Delete("A", "a"); e != nil {
+        t.Fatal(e)
+    }
+
+    if a.Contains("a") || a.Contains("b") {
+        t.Error("Deleted node and descendants should not be contained!")
+    }
+
+    if e := a.Move("A", "b", ROOT_NODE); e != E_UNKNOWN_NODE {
+        t.Error("Expected unknown node error!")
+    }
+
+    if e := a.Create("A", "a", ROOT_NODE); e != E_NODE_EXISTS {
+        t.Error("Node ids should not be reused!")
+    }
+
+    if e := a.Delete("A", ROOT_NODE); e != E_INVALID_NODE {
+        t.Error("Expected invalid node error!")
+    }
+}
+
+func TestTreeConcurrentMoves(t *testing.T) {
+    a := NewTree()
+    a.Create("A", "x", ROOT_NODE)
+    a.Create("A", "y", ROOT_NODE)
+
+    b := a.Clone()
+
+    // Concurrently moving x beneath y and y beneath x must not form a cycle.
+    if e := a.Move("A", "x", "y"); e != nil {
+        t.Fatal(e)
+    }
+    if e := b.Move("B", "y", "x"); e != nil {
+        t.Fatal(e)
+    }
+
+    a.Merge(b)
+    b.Merge(a)
+
+    if !a.Equals(b) {
+        t.Fatal("Trees should be equal after merge!")
+    }
+
+    // B's move has the greater timestamp, so is skipped.
+    if p, _ := a.Parent("x"); p != "y" {
+        t.Error("Unexpected parent of x!", p)
+    }
+    if p, _ := b.Parent("y"); p != ROOT_NODE {
+        t.Error("Unexpected parent of y!", p)
+    }
+}
+
+func TestTreeConcurrentDeleteAndMove(t *testing.T) {
+    a := NewTree()
+    a.Create("A", "a", ROOT_NODE)
+    a.Create("A", "b", "a")
+
+    b := a.Clone()
+    a.Delete("A", "a")
+    b.Move("B", "b", ROOT_NODE)
+
+    a.Merge(b)
+
+    if a.Contains("a") || !a.Contains("b") {
+        t.Error("Node moved out of deleted subtree should be kept!")
+    }
+}
+
+func TestTreeMerge(t *testing.T) {
+    a := NewTree()
+    b := NewTree()
+
+    a.Create("A", "a", ROOT_NODE)
+    b.Create("B", "b", ROOT_NODE)
+
+    a.Merge(b)
+    a.Merge(b)
+
+    if c, _ := a.Children(ROOT_NODE); len(c) != 2 {
+        t.Error("Unexpected children after merge!", c)
+    }
+}
+
+func TestTreeSerialization(t *testing.T) {
+    a := NewTree()
+    a.Create("A", "a", ROOT_NODE)
+    a.Create("A", "b", "a")
+    a.Delete("A", "b")
+
+    buff := new(bytes.Buffer)
+    if e := a.Serialize(buff); e != nil {
+        t.Fatal(e)
+    }
+
+    b := NewTree()
+    if e := b.Deserialize(buff); e != nil {
+        t.Fatal(e)
+    }
+
+    if !a.Equals(b) || !b.Contains("a") || b.Contains("b") {
+        t.Error("Deserialized tree should equal original!")
+    }
+
+    if e := b.Deserialize(bytes.NewBuffer([]byte("crdt:tre"))); e == nil {
+        t.Error("Expected error deserializing invalid data!")
+    }
+}
+
+func TestTreeDeserializeReservedNodes(t *testing.T) {
+    for _, node := range []string{ROOT_NODE, TRASH_NODE} {
+        a := NewTree()
+        a.Create("A", "x", ROOT_NODE)
+        a.Delete("A", "x")
+
+        b := NewTree()
+        b.log = []Move{{Timestamp{100, "B"}, node, ROOT_NODE}}
+
+        buff := new(bytes.Buffer)
+        if e := b.Serialize(buff); e != nil {
+            t.Fatal(e)
+        }
+
+        if e := a.Deserialize(buff); e == nil {
+            t.Errorf("Expected error deserializing a move of %q!", node)
+        }
+
+        if a.Contains("x") {
+            t.Error("Deleted node should not return!")
+        }
+
+        if children, _ := a.Children(ROOT_NODE); len(children) != 0 {
+            t.Errorf("Unexpected children of root: %q", children)
+        }
+    }
+}